@@ -28,10 +28,13 @@ func main() {
 		panic(err)
 	}
 
-	err = setupWatcher(ctx)
+	watcher, err := setupWatcher(ctx)
 	if err != nil {
 		panic(err)
 	}
+	defer func() {
+		_ = watcher.Stop()
+	}()
 
 	config := sarah.NewConfig()
 	err = sarah.Run(ctx, config)
@@ -51,19 +54,25 @@ func main() {
 	}
 }
 
-func setupWatcher(ctx context.Context) error {
+func setupWatcher(ctx context.Context) (githubconfig.Watcher, error) {
 	cfg := githubconfig.NewConfig("oklahomer", "go-sarah-githubconfig-example", "config")
 	token := os.Getenv("GITHUB_TOKEN")
 	if token == "" {
-		return errors.New("GITHUB_TOKEN is not set")
+		return nil, errors.New("GITHUB_TOKEN is not set")
+	}
+	watcher, err := githubconfig.New(cfg, githubconfig.WithToken(ctx, token))
+	if err != nil {
+		return nil, fmt.Errorf("failed to construct ConfigWatcher: %w", err)
 	}
-	watcher, err := githubconfig.New(ctx, cfg, githubconfig.WithToken(ctx, token))
+
+	// Start polling before registration since go-sarah's runner calls Watch for every Command and ScheduledTask on Run.
+	err = watcher.Start(ctx)
 	if err != nil {
-		return fmt.Errorf("failed to construct ConfigWatcher: %w", err)
+		return nil, fmt.Errorf("failed to start ConfigWatcher: %w", err)
 	}
 
 	sarah.RegisterConfigWatcher(watcher)
-	return nil
+	return watcher, nil
 }
 
 func setupBot() error {
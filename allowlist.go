@@ -0,0 +1,139 @@
+package githubconfig
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"github.com/oklahomer/go-sarah/v4"
+	"github.com/shurcooL/githubv4"
+	"os"
+	"strings"
+)
+
+// WithAllowlist applies only the configuration files whose Git blob object IDs are listed in the allowlist file
+// at the given path of the repository, so the contents can be signed off out of band before they take effect.
+// The allowlist is read from the branch given by Config on each refresh.
+// Each line of the allowlist starts with an object ID, optionally followed by a note such as the file name; a line starting with "#" is a comment.
+// The defaults file, the fragments and the other files merged into a configuration must be listed as well.
+func WithAllowlist(p string) Option {
+	return func(w *watcher) {
+		w.allowlistPath = p
+	}
+}
+
+// WithLocalAllowlist is similar to WithAllowlist, but reads the allowlist from the given file on the local filesystem on each refresh.
+func WithLocalAllowlist(name string) Option {
+	return func(w *watcher) {
+		w.localAllowlist = name
+	}
+}
+
+// NotAllowlistedError describes a configuration file that is refused since its content is not in the allowlist.
+type NotAllowlistedError struct {
+	BotType sarah.BotType
+	ID      string
+	// FileName is the name of the file that is not listed.
+	// This may differ from the id's own file when the defaults file or any other merged file is not listed.
+	FileName string
+	ObjectID string
+}
+
+// Error returns stringified representation of the error.
+func (err *NotAllowlistedError) Error() string {
+	return fmt.Sprintf("%s for %s:%s is not in the allowlist: %s", err.FileName, err.BotType, err.ID, err.ObjectID)
+}
+
+var _ error = (*NotAllowlistedError)(nil)
+
+// parseAllowlist returns the object IDs listed in the content of the allowlist.
+func parseAllowlist(content string) map[string]bool {
+	allowed := map[string]bool{}
+	scanner := bufio.NewScanner(strings.NewReader(content))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		allowed[strings.Fields(line)[0]] = true
+	}
+	return allowed
+}
+
+// allowlist returns the allowed object IDs, or nil when no allowlist is given.
+func (w *watcher) allowlist(ctx context.Context) (map[string]bool, error) {
+	if w.localAllowlist != "" {
+		b, err := os.ReadFile(w.localAllowlist)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read allowlist: %w", err)
+		}
+		return parseAllowlist(string(b)), nil
+	}
+
+	if w.allowlistPath == "" {
+		return nil, nil
+	}
+
+	q := &blobQuery{}
+	variables := map[string]interface{}{
+		"owner":      githubv4.String(w.config.Owner),
+		"name":       githubv4.String(w.config.Name),
+		"expression": githubv4.String(expression(w.branch(), w.allowlistPath)),
+	}
+	err := w.client.Query(ctx, q, variables)
+	if err != nil {
+		return nil, w.queryError("", repositoryName(w.config.Owner, w.config.Name), err)
+	}
+
+	b := q.Repository.Object.Blob
+	if b.Oid == "" {
+		return nil, fmt.Errorf("allowlist %s is not found", w.allowlistPath)
+	}
+	return parseAllowlist(string(b.Text)), nil
+}
+
+// disallowedKey returns the key of w.disallowed for the given file's content.
+func disallowedKey(botType sarah.BotType, id string, f *file) string {
+	return fmt.Sprintf("%s:%s@%s", botType, id, f.revision())
+}
+
+// enforceAllowlist refuses the files whose contents are not in the allowlist and keeps the previous ones effective,
+// or drops the ones that did not exist before.
+// An error is returned when the allowlist cannot be read, in which case none of the files must be applied.
+func (w *watcher) enforceAllowlist(ctx context.Context, botType sarah.BotType, old map[string]*file, files map[string]*file) error {
+	allowed, err := w.allowlist(ctx)
+	if err != nil || allowed == nil {
+		return err
+	}
+
+	for id, f := range files {
+		var refused *NotAllowlistedError
+		for _, layer := range f.inputs() {
+			if !allowed[layer.objectID] {
+				refused = &NotAllowlistedError{BotType: botType, ID: id, FileName: layer.fileName, ObjectID: layer.objectID}
+				break
+			}
+		}
+		if refused == nil {
+			continue
+		}
+
+		// Report once for each content.
+		key := disallowedKey(botType, id, f)
+		if !w.disallowed[key] {
+			if w.disallowed == nil {
+				w.disallowed = map[string]bool{}
+			}
+			w.disallowed[key] = true
+			w.log(botType, id).Warnf("Refusing to apply configuration not in the allowlist: %s", refused.Error())
+			w.emit(Event{Type: EventPolicyViolation, BotType: botType, ID: id, Err: refused})
+		}
+
+		if o, ok := old[id]; ok {
+			files[id] = o
+		} else {
+			delete(files, id)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,162 @@
+package githubconfig
+
+import (
+	"context"
+	"errors"
+	"github.com/shurcooL/githubv4"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestWithAllowlist(t *testing.T) {
+	w := &watcher{}
+	WithAllowlist("allowlist.txt")(w)
+
+	if w.allowlistPath != "allowlist.txt" {
+		t.Errorf("Unexpected path is set: %s.", w.allowlistPath)
+	}
+}
+
+func TestWithLocalAllowlist(t *testing.T) {
+	w := &watcher{}
+	WithLocalAllowlist("/etc/bot/allowlist.txt")(w)
+
+	if w.localAllowlist != "/etc/bot/allowlist.txt" {
+		t.Errorf("Unexpected file is set: %s.", w.localAllowlist)
+	}
+}
+
+func TestParseAllowlist(t *testing.T) {
+	allowed := parseAllowlist("# Approved by the change advisory board\nabc  config/bot/hello.yml\n\n  def\n")
+
+	expected := map[string]bool{"abc": true, "def": true}
+	if !reflect.DeepEqual(allowed, expected) {
+		t.Errorf("Unexpected object IDs are returned: %v.", allowed)
+	}
+}
+
+func TestWatcher_allowlist(t *testing.T) {
+	t.Run("repository", func(t *testing.T) {
+		w := &watcher{
+			client: &DummyQuerier{
+				QueryFunc: func(_ context.Context, q interface{}, v map[string]interface{}) error {
+					if v["expression"] != githubv4.String("master:allowlist.txt") {
+						t.Errorf("Unexpected expression is given: %s.", v["expression"])
+					}
+					q.(*blobQuery).Repository.Object.Blob = blob{Oid: "list", Text: "abc\n"}
+					return nil
+				},
+			},
+			config:        &Config{Branch: "master"},
+			allowlistPath: "allowlist.txt",
+		}
+
+		allowed, err := w.allowlist(context.TODO())
+		if err != nil {
+			t.Fatalf("Unexpected error is returned: %s.", err.Error())
+		}
+		if !allowed["abc"] {
+			t.Errorf("Unexpected object IDs are returned: %v.", allowed)
+		}
+	})
+
+	t.Run("missing", func(t *testing.T) {
+		w := &watcher{
+			client: &DummyQuerier{
+				QueryFunc: func(_ context.Context, _ interface{}, _ map[string]interface{}) error {
+					return nil
+				},
+			},
+			config:        &Config{Branch: "master"},
+			allowlistPath: "allowlist.txt",
+		}
+
+		_, err := w.allowlist(context.TODO())
+		if err == nil {
+			t.Error("Expected error is not returned.")
+		}
+	})
+
+	t.Run("none", func(t *testing.T) {
+		w := &watcher{}
+		allowed, err := w.allowlist(context.TODO())
+		if err != nil || allowed != nil {
+			t.Errorf("Unexpected result is returned: %v, %v.", allowed, err)
+		}
+	})
+}
+
+func TestWatcher_enforceAllowlist(t *testing.T) {
+	name := filepath.Join(t.TempDir(), "allowlist.txt")
+	err := os.WriteFile(name, []byte("old\nnew\nguess\nanchored\n"), 0o644)
+	if err != nil {
+		t.Fatalf("Unexpected error is returned: %s.", err.Error())
+	}
+
+	w := &watcher{
+		localAllowlist: name,
+		events:         make(chan Event, 10),
+	}
+
+	old := map[string]*file{
+		"hello":   newFile("hello.yml", "old", ""),
+		"unknown": newFile("unknown.yml", "old", ""),
+	}
+	for i := 0; i < 2; i++ {
+		files := map[string]*file{
+			"hello":   newFile("hello.yml", "new", ""),
+			"unknown": newFile("unknown.yml", "unknown", ""),
+			"guess":   newFile("guess.yml", "guess", ""),
+			"added":   newFile("added.yml", "added", ""),
+		}
+		files["guess"].defaults = newFile("_defaults.yml", "defaults", "")
+		files["anchored"] = newFile("anchored.yml", "anchored", "<<: *admin\n")
+		files["anchored"].anchors = newFile("_anchors.yml", "anchors", "admin: &admin\n  admin: true\n")
+
+		err = w.enforceAllowlist(context.TODO(), "bot", old, files)
+		if err != nil {
+			t.Fatalf("Unexpected error is returned: %s.", err.Error())
+		}
+
+		if files["hello"].objectID != "new" {
+			t.Errorf("Allowed file is not applied: %+v.", files["hello"])
+		}
+		if files["unknown"].objectID != "old" {
+			t.Errorf("Previous file is not kept: %+v.", files["unknown"])
+		}
+		if _, ok := files["guess"]; ok {
+			t.Error("File with unlisted defaults is applied.")
+		}
+		if _, ok := files["added"]; ok {
+			t.Error("Unlisted file is applied.")
+		}
+		if _, ok := files["anchored"]; ok {
+			t.Error("File with unlisted anchors is applied.")
+		}
+	}
+
+	if len(w.events) != 4 {
+		t.Fatalf("Unexpected number of events are emitted: %d.", len(w.events))
+	}
+	for len(w.events) > 0 {
+		event := <-w.events
+		var refused *NotAllowlistedError
+		if event.Type != EventPolicyViolation || !errors.As(event.Err, &refused) {
+			t.Errorf("Unexpected event is emitted: %+v.", event)
+		}
+		if event.ID == "guess" && refused.FileName != "_defaults.yml" {
+			t.Errorf("Unexpected file is reported: %s.", refused.FileName)
+		}
+		if event.ID == "anchored" && refused.FileName != "_anchors.yml" {
+			t.Errorf("Unexpected file is reported: %s.", refused.FileName)
+		}
+	}
+
+	w.localAllowlist = filepath.Join(t.TempDir(), "missing.txt")
+	err = w.enforceAllowlist(context.TODO(), "bot", old, map[string]*file{})
+	if err == nil {
+		t.Error("Expected error is not returned.")
+	}
+}
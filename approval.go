@@ -0,0 +1,264 @@
+package githubconfig
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/oklahomer/go-sarah/v4"
+	"github.com/shurcooL/githubv4"
+	"strings"
+)
+
+// WithRequiredApprovals requires an updated configuration file to be merged through a pull request
+// that is approved by at least the given number of distinct reviewers.
+// As with WithCodeOwnerApproval, a change that does not satisfy the policy is not applied; the previous content stays effective
+// and an EventPolicyViolation is emitted instead.
+func WithRequiredApprovals(approvals int) Option {
+	return func(w *watcher) {
+		w.requiredApprovals = approvals
+	}
+}
+
+// PolicyViolationError describes a change that is refused because its pull request does not satisfy the approval policy.
+type PolicyViolationError struct {
+	BotType sarah.BotType
+	ID      string
+	// Path is the path of the changed file relative to the repository root.
+	Path string
+	// Commit is the SHA of the last commit that touched the file.
+	Commit string
+	Reason string
+}
+
+// Error returns stringified representation of the error.
+func (err *PolicyViolationError) Error() string {
+	return fmt.Sprintf("change on %s for %s:%s by %s violates the approval policy: %s", err.Path, err.BotType, err.ID, err.Commit, err.Reason)
+}
+
+var _ error = (*PolicyViolationError)(nil)
+
+// pullRequestQuery represents a Graphql query to fetch the pull requests that merged a commit and their approvals.
+// Formatted query is as below:
+//
+// 	query ($owner: String!, $name: String!, $oid: GitObjectID!) {
+//    repository(owner: $owner, name: $name) {
+//      object(oid: $oid) {
+//        ... on Commit {
+//          associatedPullRequests(first: 10) {
+//            nodes {
+//              number
+//              merged
+//              reviews(first: 100, states: APPROVED) {
+//                nodes {
+//                  author {
+//                    login
+//                  }
+//                }
+//              }
+//            }
+//          }
+//        }
+//      }
+//    }
+// 	}
+type pullRequestQuery struct {
+	Repository struct {
+		Object struct {
+			Commit struct {
+				AssociatedPullRequests struct {
+					Nodes []pullRequest
+				} `graphql:"associatedPullRequests(first: 10)"`
+			} `graphql:"... on Commit"`
+		} `graphql:"object(oid: $oid)"`
+	} `graphql:"repository(owner: $owner, name: $name)"`
+}
+
+type pullRequest struct {
+	Number  githubv4.Int
+	Merged  githubv4.Boolean
+	Reviews struct {
+		Nodes []struct {
+			Author struct {
+				Login githubv4.String
+			}
+		}
+	} `graphql:"reviews(first: 100, states: APPROVED)"`
+}
+
+// approvers returns the distinct logins of the reviewers that approved the pull request.
+func (pr *pullRequest) approvers() []string {
+	seen := map[string]bool{}
+	var logins []string
+	for _, review := range pr.Reviews.Nodes {
+		login := strings.ToLower(string(review.Author.Login))
+		if login == "" || seen[login] {
+			continue
+		}
+		seen[login] = true
+		logins = append(logins, login)
+	}
+	return logins
+}
+
+// enforceApprovalPolicy refuses the updated files whose pull requests do not satisfy the approval policy
+// and keeps the previous ones effective, or drops the ones that did not exist before.
+// Each changed file that a configuration depends on, such as the defaults or a fragment, must satisfy the policy as well.
+// Give nil as old on the first fetch of a BotType so every file and every file it depends on is checked.
+// The result is memorized per file content so the same change is neither queried nor reported twice.
+func (w *watcher) enforceApprovalPolicy(ctx context.Context, botType sarah.BotType, old map[string]*file, files map[string]*file) {
+	if !w.codeOwnerApproval && w.requiredApprovals <= 0 {
+		return
+	}
+
+	for _, id := range changedIDs(old, files) {
+		f := files[id]
+		if f == nil {
+			// Deleted.
+			continue
+		}
+
+		checked, err := w.checkLayers(ctx, botType, id, old[id], f)
+		if err == nil {
+			continue
+		}
+
+		if !checked {
+			w.log(botType, id).Warnf("Refusing to apply configuration without required approval: %+v", err)
+			w.emit(Event{Type: EventPolicyViolation, BotType: botType, ID: id, Err: err})
+			var violation *PolicyViolationError
+			if errors.As(err, &violation) {
+				w.commentFailure(ctx, botType, f, err)
+			}
+		}
+		if o, ok := old[id]; ok {
+			files[id] = o
+		} else {
+			delete(files, id)
+		}
+	}
+}
+
+// approvalKey returns the key of w.approvals for the given file's content.
+func approvalKey(botType sarah.BotType, f *file) string {
+	return fmt.Sprintf("%s:%s@%s", botType, f.path, f.objectID)
+}
+
+// checkLayers checks the approval of each changed file among the given file and the files it depends on, and returns the first failure.
+// Every one of them is checked when the configuration did not exist before.
+// checked tells the failure is already reported.
+func (w *watcher) checkLayers(ctx context.Context, botType sarah.BotType, id string, old *file, f *file) (checked bool, err error) {
+	var changed []*file
+	if old == nil {
+		changed = f.inputs()
+	} else {
+		for _, pair := range changedLayers(old, f) {
+			changed = append(changed, pair[1])
+		}
+	}
+
+	for _, layer := range changed {
+		if layer == nil || layer.path == "" {
+			// Removed from the dependencies, or not stored in the repository.
+			continue
+		}
+
+		key := approvalKey(botType, layer)
+		err, checked = w.approvals[key]
+		if !checked {
+			err = w.checkApproval(ctx, botType, id, layer)
+			var violation *PolicyViolationError
+			if errors.As(err, &violation) || err == nil {
+				if w.approvals == nil {
+					w.approvals = map[string]error{}
+				}
+				w.approvals[key] = err
+			}
+		}
+		if err != nil {
+			return checked, err
+		}
+	}
+	return false, nil
+}
+
+// checkApproval checks if the pull request that merged the last commit on the file satisfies the approval policy.
+// The file is either the configuration with the given id or a file it depends on.
+// A *PolicyViolationError is returned when it does not, while any other error tells the approval could not be checked.
+func (w *watcher) checkApproval(ctx context.Context, botType sarah.BotType, id string, f *file) error {
+	commit, err := w.lastCommit(ctx, botType, f)
+	if err != nil {
+		return err
+	}
+
+	src := f.source
+	if src == nil {
+		src = w.source(botType)
+	}
+	var owners []string
+	if w.codeOwnerApproval {
+		owners, err = w.codeOwners(ctx, botType, src, commit.SHA, f.path)
+		if err != nil {
+			return err
+		}
+	}
+	if len(owners) == 0 && w.requiredApprovals <= 0 {
+		// No code owner to approve the file.
+		return nil
+	}
+
+	violation := func(reason string) error {
+		return &PolicyViolationError{
+			BotType: botType,
+			ID:      id,
+			Path:    f.path,
+			Commit:  commit.SHA,
+			Reason:  reason,
+		}
+	}
+
+	q := &pullRequestQuery{}
+	variables := map[string]interface{}{
+		"owner": githubv4.String(src.Owner),
+		"name":  githubv4.String(src.Name),
+		"oid":   githubv4.GitObjectID(commit.SHA),
+	}
+	err = w.client.Query(ctx, q, variables)
+	if err != nil {
+		return w.queryError(botType, repositoryName(src.Owner, src.Name), err)
+	}
+
+	var merged []pullRequest
+	for _, pr := range q.Repository.Object.Commit.AssociatedPullRequests.Nodes {
+		if pr.Merged {
+			merged = append(merged, pr)
+		}
+	}
+	if len(merged) == 0 {
+		return violation("the commit is not merged through a pull request")
+	}
+
+	if w.requiredApprovals > 0 {
+		approved := 0
+		for _, pr := range merged {
+			if n := len(pr.approvers()); n > approved {
+				approved = n
+			}
+		}
+		if approved < w.requiredApprovals {
+			return violation(fmt.Sprintf("pull request #%d is approved by %d of %d required reviewers", merged[0].Number, approved, w.requiredApprovals))
+		}
+	}
+
+	if len(owners) == 0 {
+		return nil
+	}
+
+	ok, err := w.codeOwnerApproved(ctx, botType, owners, merged)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return violation(fmt.Sprintf("pull request #%d is not approved by any of %s", merged[0].Number, strings.Join(owners, ", ")))
+	}
+	return nil
+}
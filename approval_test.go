@@ -0,0 +1,235 @@
+package githubconfig
+
+import (
+	"context"
+	"errors"
+	"github.com/oklahomer/go-sarah/v4"
+	"github.com/shurcooL/githubv4"
+	"reflect"
+	"strconv"
+	"testing"
+)
+
+func TestWithRequiredApprovals(t *testing.T) {
+	w := &watcher{}
+	WithRequiredApprovals(2)(w)
+
+	if w.requiredApprovals != 2 {
+		t.Errorf("Unexpected number of approvals is set: %d.", w.requiredApprovals)
+	}
+}
+
+func TestPullRequest_approvers(t *testing.T) {
+	pr := &pullRequest{}
+	for _, login := range []string{"alice", "Bob", "ALICE", "bob", ""} {
+		review := struct {
+			Author struct{ Login githubv4.String }
+		}{}
+		review.Author.Login = githubv4.String(login)
+		pr.Reviews.Nodes = append(pr.Reviews.Nodes, review)
+	}
+
+	approvers := pr.approvers()
+	if !reflect.DeepEqual(approvers, []string{"alice", "bob"}) {
+		t.Errorf("Unexpected approvers are returned: %v.", approvers)
+	}
+}
+
+func TestWatcher_checkApproval_requiredApprovals(t *testing.T) {
+	tests := []struct {
+		approvers []string
+		violation bool
+	}{
+		{approvers: []string{"alice", "bob"}, violation: false},
+		{approvers: []string{"alice", "bob", "carol"}, violation: false},
+		{approvers: []string{"alice", "Alice"}, violation: true},
+		{approvers: nil, violation: true},
+	}
+
+	for i, tt := range tests {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			w := &watcher{
+				client: &DummyQuerier{
+					QueryFunc: func(_ context.Context, q interface{}, _ map[string]interface{}) error {
+						switch typed := q.(type) {
+						case *commitQuery:
+							typed.Repository.Ref.Target.Commit.History.Nodes = []commit{{Oid: "sha"}}
+
+						case *blobQuery:
+							t.Error("CODEOWNERS must not be fetched without WithCodeOwnerApproval.")
+
+						case *pullRequestQuery:
+							pr := pullRequest{Number: 1, Merged: true}
+							for _, approver := range tt.approvers {
+								review := struct {
+									Author struct{ Login githubv4.String }
+								}{}
+								review.Author.Login = githubv4.String(approver)
+								pr.Reviews.Nodes = append(pr.Reviews.Nodes, review)
+							}
+							typed.Repository.Object.Commit.AssociatedPullRequests.Nodes = []pullRequest{pr}
+
+						}
+						return nil
+					},
+				},
+				config:            &Config{Owner: "oklahomer", Name: "config", Branch: "master"},
+				requiredApprovals: 2,
+			}
+
+			err := w.checkApproval(context.TODO(), "bot", "hello", &file{id: "hello", path: "config/bot/hello.yml"})
+			var violation *PolicyViolationError
+			if tt.violation {
+				if !errors.As(err, &violation) {
+					t.Errorf("Expected violation is not returned: %#v.", err)
+				}
+				return
+			}
+
+			if err != nil {
+				t.Errorf("Unexpected error is returned: %s.", err.Error())
+			}
+		})
+	}
+}
+
+func TestWatcher_enforceApprovalPolicy(t *testing.T) {
+	queried := 0
+	w := &watcher{
+		client: &DummyQuerier{
+			QueryFunc: func(_ context.Context, q interface{}, _ map[string]interface{}) error {
+				switch typed := q.(type) {
+				case *commitQuery:
+					queried++
+					typed.Repository.Ref.Target.Commit.History.Nodes = []commit{{Oid: "sha"}}
+
+				case *blobQuery:
+					typed.Repository.Object.Blob = blob{Oid: "owners", Text: "* @oklahomer"}
+
+				case *pullRequestQuery:
+					// Pushed directly
+
+				}
+				return nil
+			},
+		},
+		config:            &Config{Owner: "oklahomer", Name: "config", Branch: "master"},
+		codeOwnerApproval: true,
+		events:            make(chan Event, 10),
+	}
+
+	old := map[string]*file{
+		"hello": {id: "hello", objectID: "old", path: "config/bot/hello.yml"},
+	}
+	for i := 0; i < 2; i++ {
+		files := map[string]*file{
+			"hello": {id: "hello", objectID: "new", path: "config/bot/hello.yml"},
+			"new":   {id: "new", objectID: "new", path: "config/bot/new.yml"},
+		}
+		w.enforceApprovalPolicy(context.TODO(), "bot", old, files)
+
+		if files["hello"].objectID != "old" {
+			t.Errorf("Previous file is not kept: %+v.", files["hello"])
+		}
+		if _, ok := files["new"]; ok {
+			t.Error("Unapproved file is added.")
+		}
+	}
+
+	if queried != 2 {
+		t.Errorf("Unexpected number of checks: %d.", queried)
+	}
+
+	if len(w.events) != 2 {
+		t.Fatalf("Unexpected number of events are emitted: %d.", len(w.events))
+	}
+	event := <-w.events
+	if event.Type != EventPolicyViolation {
+		t.Errorf("Unexpected event is emitted: %s.", event.Type)
+	}
+	var violation *PolicyViolationError
+	if !errors.As(event.Err, &violation) {
+		t.Errorf("Unexpected error is passed: %#v.", event.Err)
+	}
+}
+
+func TestWatcher_enforceApprovalPolicy_dependency(t *testing.T) {
+	var paths []string
+	w := &watcher{
+		client: &DummyQuerier{
+			QueryFunc: func(_ context.Context, q interface{}, variables map[string]interface{}) error {
+				switch typed := q.(type) {
+				case *commitQuery:
+					paths = append(paths, string(variables["path"].(githubv4.String)))
+					typed.Repository.Ref.Target.Commit.History.Nodes = []commit{{Oid: "sha"}}
+
+				case *pullRequestQuery:
+					// Pushed directly
+
+				}
+				return nil
+			},
+		},
+		config:            &Config{Owner: "oklahomer", Name: "config", Branch: "master"},
+		requiredApprovals: 1,
+		events:            make(chan Event, 10),
+	}
+
+	oldDefaults := &file{id: defaultsID, objectID: "old", path: "config/bot/_default.yml"}
+	old := map[string]*file{
+		"hello": {id: "hello", objectID: "hello", path: "config/bot/hello.yml", defaults: oldDefaults},
+	}
+	files := map[string]*file{
+		"hello": {id: "hello", objectID: "hello", path: "config/bot/hello.yml", defaults: &file{id: defaultsID, objectID: "new", path: "config/bot/_default.yml"}},
+	}
+	w.enforceApprovalPolicy(context.TODO(), "bot", old, files)
+
+	if files["hello"].defaults != oldDefaults {
+		t.Error("Unapproved change on the defaults is applied.")
+	}
+	if len(paths) != 1 || paths[0] != "config/bot/_default.yml" {
+		t.Errorf("Unexpected files are checked: %v.", paths)
+	}
+
+	event := <-w.events
+	var violation *PolicyViolationError
+	if !errors.As(event.Err, &violation) || violation.ID != "hello" || violation.Path != "config/bot/_default.yml" {
+		t.Errorf("Unexpected error is passed: %#v.", event.Err)
+	}
+}
+
+func TestWatcher_cached_approvalPolicy(t *testing.T) {
+	hello := newBundleFile(&file{id: "hello", fileName: "hello.yml", extension: ".yml", objectID: "hello", path: "config/bot/hello.yml", content: "message: hello\n"})
+	w := &watcher{
+		bundle: &bundle{BotTypes: map[sarah.BotType]*bundleEntry{"bot": {Files: []*bundleFile{hello}}}},
+		client: &DummyQuerier{
+			QueryFunc: func(_ context.Context, q interface{}, _ map[string]interface{}) error {
+				switch typed := q.(type) {
+				case *commitQuery:
+					typed.Repository.Ref.Target.Commit.History.Nodes = []commit{{Oid: "sha"}}
+
+				case *pullRequestQuery:
+					// Pushed directly while the bot was down
+
+				}
+				return nil
+			},
+		},
+		config:            &Config{Owner: "oklahomer", Name: "config", Branch: "master"},
+		requiredApprovals: 1,
+		events:            make(chan Event, 10),
+	}
+
+	files, err := w.cached(context.TODO(), map[sarah.BotType]map[string]*file{}, statuses{}, "bot")
+	if err != nil {
+		t.Fatalf("Unexpected error is returned: %s.", err.Error())
+	}
+
+	if _, ok := files["hello"]; ok {
+		t.Error("Unapproved file is applied on the first fetch.")
+	}
+	event := <-w.events
+	if event.Type != EventPolicyViolation {
+		t.Errorf("Unexpected event is emitted: %s.", event.Type)
+	}
+}
@@ -0,0 +1,142 @@
+package githubconfig
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/oklahomer/go-sarah/v4"
+	"io"
+	"os"
+	"sort"
+	"sync"
+	"time"
+)
+
+// AuditRecord describes a configuration file that is applied, updated or removed.
+type AuditRecord struct {
+	BotType sarah.BotType `json:"bot_type"`
+	ID      string        `json:"id"`
+	// OldObjectID is the Git blob object ID of the previous content. This is empty when the file is added.
+	OldObjectID string `json:"old_object_id"`
+	// NewObjectID is the Git blob object ID of the applied content. This is empty when the file is removed.
+	NewObjectID string `json:"new_object_id"`
+	// CommitSHA is the head commit of the branch the applied content is fetched at.
+	// This is empty when the content does not come from the repository such as a local override file.
+	CommitSHA string    `json:"commit_sha"`
+	AppliedAt time.Time `json:"applied_at"`
+}
+
+// AuditSink records the configuration changes the watcher applies,
+// so one can reconstruct which configuration was live at a given time.
+// Record is called from the watcher's operating goroutine in the order the changes are applied, so it should return quickly.
+type AuditSink interface {
+	Record(ctx context.Context, record *AuditRecord) error
+}
+
+// WithAuditSink records every configuration file the watcher applies to the given AuditSink,
+// including the ones served on the first read of each BotType.
+func WithAuditSink(sink AuditSink) Option {
+	return func(w *watcher) {
+		w.auditSink = sink
+	}
+}
+
+// WriterAuditSink is an AuditSink that writes each record to the underlying writer as a line of JSON.
+type WriterAuditSink struct {
+	mutex  sync.Mutex
+	writer io.Writer
+}
+
+var _ AuditSink = (*WriterAuditSink)(nil)
+
+// NewWriterAuditSink returns an AuditSink that writes each record to the given writer as a line of JSON.
+func NewWriterAuditSink(writer io.Writer) *WriterAuditSink {
+	return &WriterAuditSink{
+		writer: writer,
+	}
+}
+
+// NewStdoutAuditSink returns an AuditSink that writes each record to the standard output as a line of JSON.
+func NewStdoutAuditSink() *WriterAuditSink {
+	return NewWriterAuditSink(os.Stdout)
+}
+
+// Record writes the given record as a line of JSON.
+func (s *WriterAuditSink) Record(_ context.Context, record *AuditRecord) error {
+	b, err := json.Marshal(record)
+	if err != nil {
+		return fmt.Errorf("failed to encode audit record: %w", err)
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	_, err = s.writer.Write(append(b, '\n'))
+	return err
+}
+
+// FileAuditSink is an AuditSink that appends each record to a file as a line of JSON.
+type FileAuditSink struct {
+	*WriterAuditSink
+	file *os.File
+}
+
+var _ AuditSink = (*FileAuditSink)(nil)
+
+// NewFileAuditSink opens the given file in append mode, or creates it, and returns an AuditSink that writes to it.
+// Call Close when the watcher is stopped.
+func NewFileAuditSink(name string) (*FileAuditSink, error) {
+	f, err := os.OpenFile(name, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o644)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open audit log %s: %w", name, err)
+	}
+
+	return &FileAuditSink{
+		WriterAuditSink: NewWriterAuditSink(f),
+		file:            f,
+	}, nil
+}
+
+// Close closes the underlying file.
+func (s *FileAuditSink) Close() error {
+	return s.file.Close()
+}
+
+// audit records the files that are added, updated or removed from the old files to the applied ones.
+func (w *watcher) audit(ctx context.Context, botType sarah.BotType, old map[string]*file, files map[string]*file) {
+	if w.auditSink == nil {
+		return
+	}
+
+	// The head commit is shared by the files fetched at once, so a removed file is recorded with the commit that removed it.
+	head := ""
+	for _, f := range files {
+		if f.commitSHA != "" {
+			head = f.commitSHA
+			break
+		}
+	}
+
+	ids := changedIDs(old, files)
+	sort.Strings(ids)
+	now := time.Now()
+	for _, id := range ids {
+		record := &AuditRecord{
+			BotType:   botType,
+			ID:        id,
+			CommitSHA: head,
+			AppliedAt: now,
+		}
+		if o, ok := old[id]; ok {
+			record.OldObjectID = o.objectID
+		}
+		if f, ok := files[id]; ok {
+			record.NewObjectID = f.objectID
+			record.CommitSHA = f.commitSHA
+		}
+
+		err := w.auditSink.Record(ctx, record)
+		if err != nil {
+			w.log(botType, id, commitAttr(record.CommitSHA)).Errorf("Failed to record the change on %s:%s to the audit sink: %+v", botType, id, err)
+		}
+	}
+}
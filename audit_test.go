@@ -0,0 +1,145 @@
+package githubconfig
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+type DummyAuditSink struct {
+	RecordFunc func(ctx context.Context, record *AuditRecord) error
+}
+
+func (s *DummyAuditSink) Record(ctx context.Context, record *AuditRecord) error {
+	return s.RecordFunc(ctx, record)
+}
+
+func TestWithAuditSink(t *testing.T) {
+	sink := &DummyAuditSink{}
+	w := &watcher{}
+	WithAuditSink(sink)(w)
+
+	if w.auditSink != sink {
+		t.Error("Audit sink is not set.")
+	}
+}
+
+func TestWriterAuditSink_Record(t *testing.T) {
+	buf := &bytes.Buffer{}
+	sink := NewWriterAuditSink(buf)
+
+	appliedAt := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	for _, id := range []string{"hello", "guess"} {
+		err := sink.Record(context.TODO(), &AuditRecord{BotType: "bot", ID: id, NewObjectID: "new", CommitSHA: "sha", AppliedAt: appliedAt})
+		if err != nil {
+			t.Fatalf("Unexpected error is returned: %s.", err.Error())
+		}
+	}
+
+	lines := strings.Split(strings.TrimSuffix(buf.String(), "\n"), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("Unexpected number of lines are written: %d.", len(lines))
+	}
+
+	expected := `{"bot_type":"bot","id":"hello","old_object_id":"","new_object_id":"new","commit_sha":"sha","applied_at":"2024-01-02T03:04:05Z"}`
+	if lines[0] != expected {
+		t.Errorf("Unexpected line is written: %s.", lines[0])
+	}
+}
+
+func TestNewFileAuditSink(t *testing.T) {
+	name := filepath.Join(t.TempDir(), "audit.log")
+	err := os.WriteFile(name, []byte("existing\n"), 0o644)
+	if err != nil {
+		t.Fatalf("Unexpected error is returned: %s.", err.Error())
+	}
+
+	sink, err := NewFileAuditSink(name)
+	if err != nil {
+		t.Fatalf("Unexpected error is returned: %s.", err.Error())
+	}
+
+	err = sink.Record(context.TODO(), &AuditRecord{BotType: "bot", ID: "hello"})
+	if err != nil {
+		t.Fatalf("Unexpected error is returned: %s.", err.Error())
+	}
+
+	err = sink.Close()
+	if err != nil {
+		t.Fatalf("Unexpected error is returned: %s.", err.Error())
+	}
+
+	b, err := os.ReadFile(name)
+	if err != nil {
+		t.Fatalf("Unexpected error is returned: %s.", err.Error())
+	}
+
+	lines := strings.Split(strings.TrimSuffix(string(b), "\n"), "\n")
+	if len(lines) != 2 || lines[0] != "existing" {
+		t.Fatalf("Record is not appended: %s.", string(b))
+	}
+
+	record := &AuditRecord{}
+	err = json.Unmarshal([]byte(lines[1]), record)
+	if err != nil {
+		t.Fatalf("Unexpected error is returned: %s.", err.Error())
+	}
+	if record.ID != "hello" {
+		t.Errorf("Unexpected record is written: %+v.", record)
+	}
+}
+
+func TestNewFileAuditSink_error(t *testing.T) {
+	_, err := NewFileAuditSink(filepath.Join(t.TempDir(), "missing", "audit.log"))
+	if err == nil {
+		t.Error("Expected error is not returned.")
+	}
+}
+
+func TestWatcher_audit(t *testing.T) {
+	var records []*AuditRecord
+	w := &watcher{
+		auditSink: &DummyAuditSink{
+			RecordFunc: func(_ context.Context, record *AuditRecord) error {
+				records = append(records, record)
+				return errors.New("the error must be logged and ignored")
+			},
+		},
+	}
+
+	old := map[string]*file{
+		"deleted":   {id: "deleted", objectID: "deleted"},
+		"unchanged": {id: "unchanged", objectID: "unchanged"},
+		"updated":   {id: "updated", objectID: "old"},
+	}
+	files := map[string]*file{
+		"added":     {id: "added", objectID: "added", commitSHA: "sha"},
+		"unchanged": {id: "unchanged", objectID: "unchanged", commitSHA: "sha"},
+		"updated":   {id: "updated", objectID: "new", commitSHA: "sha"},
+	}
+	w.audit(context.TODO(), "bot", old, files)
+
+	expected := []*AuditRecord{
+		{BotType: "bot", ID: "added", NewObjectID: "added", CommitSHA: "sha"},
+		{BotType: "bot", ID: "deleted", OldObjectID: "deleted", CommitSHA: "sha"},
+		{BotType: "bot", ID: "updated", OldObjectID: "old", NewObjectID: "new", CommitSHA: "sha"},
+	}
+	if len(records) != len(expected) {
+		t.Fatalf("Unexpected number of records: %d.", len(records))
+	}
+	for i, record := range records {
+		if record.AppliedAt.IsZero() {
+			t.Errorf("Applied time is not set: %+v.", record)
+		}
+		record.AppliedAt = time.Time{}
+		if *record != *expected[i] {
+			t.Errorf("Unexpected record is given: %+v.", record)
+		}
+	}
+}
@@ -0,0 +1,82 @@
+package githubconfig
+
+import (
+	"context"
+	"fmt"
+	"github.com/oklahomer/go-sarah/v4"
+	"io/ioutil"
+	"net/http"
+	"strings"
+)
+
+// defaultRESTURL is the base URL of GitHub's REST API.
+const defaultRESTURL = "https://api.github.com"
+
+// Decoder decodes the raw content of a configuration file into out.
+// The content is passed as is, so a binary format such as Protocol Buffers or MessagePack can be decoded.
+type Decoder func(raw []byte, out interface{}) error
+
+// WithDecoder registers a decoder for the files with the given extension such as ".pb" or ".msgpack".
+// A registered decoder takes precedence over the built-in decoder for the same extension.
+// Since GitHub's GraphQL API does not serve binary content, a binary file is fetched via the REST API,
+// which requires WithToken or WithRESTClient.
+func WithDecoder(extension string, decoder Decoder) Option {
+	return func(w *watcher) {
+		if w.decoders == nil {
+			w.decoders = map[string]Decoder{}
+		}
+		w.decoders[extension] = decoder
+	}
+}
+
+// WithRESTClient sets the HTTP client and the base URL of GitHub's REST API to fetch binary files with.
+// This is required to read binary files along with WithClient, e.g. "https://example.com/api/v3" for GitHub Enterprise.
+// The HTTP client is responsible for authentication.
+func WithRESTClient(httpClient *http.Client, baseURL string) Option {
+	return func(w *watcher) {
+		w.rest = &restClient{httpClient: httpClient, baseURL: baseURL}
+	}
+}
+
+type restClient struct {
+	httpClient *http.Client
+	baseURL    string
+}
+
+// rawBlob fetches the raw content of the blob with the given object ID via the REST API.
+// The content is cached by the repository and the object ID, so an unchanged blob is not fetched on every refresh.
+func (w *watcher) rawBlob(ctx context.Context, botType sarah.BotType, owner string, name string, oid string) ([]byte, error) {
+	key := fmt.Sprintf("blob:%s/%s:%s", owner, name, oid)
+	if content, ok := w.blobs.get(key); ok {
+		return content, nil
+	}
+
+	rest := w.restClient(owner, name)
+	if rest == nil {
+		return nil, fmt.Errorf("REST client is required to fetch binary blob %s: give WithToken or WithRESTClient", oid)
+	}
+
+	url := fmt.Sprintf("%s/repos/%s/%s/git/blobs/%s", strings.TrimSuffix(rest.baseURL, "/"), owner, name, oid)
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", "application/vnd.github.raw")
+
+	resp, err := rest.httpClient.Do(req)
+	if err != nil {
+		return nil, w.queryError(botType, repositoryName(owner, name), err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, w.queryError(botType, repositoryName(owner, name), fmt.Errorf("non-200 OK status code: %s", resp.Status))
+	}
+
+	content, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	w.blobs.put(key, content)
+	return content, nil
+}
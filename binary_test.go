@@ -0,0 +1,148 @@
+package githubconfig
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestWithDecoder(t *testing.T) {
+	w := &watcher{}
+	WithDecoder(".bin", func(raw []byte, out interface{}) error {
+		*(out.(*[]byte)) = raw
+		return nil
+	})(w)
+
+	if _, ok := w.decoders[".bin"]; !ok {
+		t.Fatal("Decoder is not registered.")
+	}
+
+	if !w.candidate("hello.bin") {
+		t.Error("File with registered extension must be a candidate.")
+	}
+
+	out := []byte{}
+	err := w.decode("bot", newFile("hello.bin", "oid", "\x00\x01\x02"), &out)
+	if err != nil {
+		t.Fatalf("Unexpected error is returned: %s.", err.Error())
+	}
+
+	if string(out) != "\x00\x01\x02" {
+		t.Errorf("Unexpected content is decoded: %v.", out)
+	}
+}
+
+func TestWithRESTClient(t *testing.T) {
+	w := &watcher{}
+	client := &http.Client{}
+	WithRESTClient(client, "https://example.com/api/v3")(w)
+
+	if w.rest == nil || w.rest.httpClient != client || w.rest.baseURL != "https://example.com/api/v3" {
+		t.Errorf("REST client is not set: %+v.", w.rest)
+	}
+}
+
+func TestWatcher_rawBlob(t *testing.T) {
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		if r.URL.Path != "/repos/oklahomer/config/git/blobs/abc" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+
+		if r.Header.Get("Accept") != "application/vnd.github.raw" {
+			t.Errorf("Unexpected Accept header is given: %s.", r.Header.Get("Accept"))
+		}
+		_, _ = w.Write([]byte{0x00, 0xff})
+	}))
+	defer server.Close()
+
+	w := &watcher{
+		config: &Config{Owner: "oklahomer", Name: "config"},
+		rest:   &restClient{httpClient: server.Client(), baseURL: server.URL},
+	}
+
+	t.Run("found", func(t *testing.T) {
+		content, err := w.rawBlob(context.TODO(), "bot", "oklahomer", "config", "abc")
+		if err != nil {
+			t.Fatalf("Unexpected error is returned: %s.", err.Error())
+		}
+
+		if string(content) != "\x00\xff" {
+			t.Errorf("Unexpected content is returned: %v.", content)
+		}
+	})
+
+	t.Run("cached", func(t *testing.T) {
+		before := requests
+		content, err := w.rawBlob(context.TODO(), "bot", "oklahomer", "config", "abc")
+		if err != nil {
+			t.Fatalf("Unexpected error is returned: %s.", err.Error())
+		}
+
+		if string(content) != "\x00\xff" {
+			t.Errorf("Unexpected content is returned: %v.", content)
+		}
+
+		if requests != before {
+			t.Error("Cached blob is fetched again.")
+		}
+	})
+
+	t.Run("not found", func(t *testing.T) {
+		_, err := w.rawBlob(context.TODO(), "bot", "oklahomer", "config", "unknown")
+		var queryErr *QueryError
+		if !errors.As(err, &queryErr) {
+			t.Errorf("Unexpected error is returned: %#v.", err)
+		}
+	})
+
+	t.Run("without client", func(t *testing.T) {
+		_, err := (&watcher{}).rawBlob(context.TODO(), "bot", "oklahomer", "config", "abc")
+		if err == nil {
+			t.Error("Expected error is not returned.")
+		}
+	})
+}
+
+func TestWatcher_get_binary(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		_, _ = w.Write([]byte{0x00, 0x01})
+	}))
+	defer server.Close()
+
+	w := &watcher{
+		client: &DummyQuerier{
+			QueryFunc: func(_ context.Context, q interface{}, _ map[string]interface{}) error {
+				typed := q.(*query)
+				typed.Repository.Head.Commit.Oid = "sha"
+				typed.Repository.Object.Tree.Entries = []entry{
+					{Name: "hello.bin", Type: "blob", Object: entryObject{Blob: blob{Oid: "abc", IsBinary: true}}},
+				}
+				return nil
+			},
+		},
+		config: &Config{Owner: "oklahomer", Name: "config", BaseDir: "config", Branch: "master"},
+		rest:   &restClient{httpClient: server.Client(), baseURL: server.URL},
+	}
+	WithDecoder(".bin", func(_ []byte, _ interface{}) error {
+		return nil
+	})(w)
+
+	files, err := w.get(context.TODO(), "bot")
+	if err != nil {
+		t.Fatalf("Unexpected error is returned: %s.", err.Error())
+	}
+
+	f, ok := files["hello"]
+	if !ok {
+		t.Fatal("Expected file is not returned.")
+	}
+
+	if f.content != "\x00\x01" {
+		t.Errorf("Unexpected content is set: %v.", []byte(f.content))
+	}
+}
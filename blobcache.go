@@ -0,0 +1,54 @@
+package githubconfig
+
+import "sync"
+
+// blobCache holds contents fetched outside GraphQL API, keyed by an identifier of the content such as an object ID.
+// Since those identifiers change along with the content, an entry never goes stale.
+// Instead, the entries that are not used in a refresh cycle are dropped by rotate so the cache does not grow with the history.
+type blobCache struct {
+	mutex sync.Mutex
+	// current holds the entries used in the ongoing refresh cycle.
+	current map[string][]byte
+	// previous holds the entries used in the last refresh cycle.
+	previous map[string][]byte
+}
+
+// get returns the cached content for the given key.
+func (c *blobCache) get(key string) ([]byte, bool) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	if content, ok := c.current[key]; ok {
+		return content, true
+	}
+
+	content, ok := c.previous[key]
+	if ok {
+		c.store(key, content)
+	}
+	return content, ok
+}
+
+// put caches the content for the given key.
+func (c *blobCache) put(key string, content []byte) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	c.store(key, content)
+}
+
+func (c *blobCache) store(key string, content []byte) {
+	if c.current == nil {
+		c.current = map[string][]byte{}
+	}
+	c.current[key] = content
+}
+
+// rotate starts a new refresh cycle and drops the entries that are not used in the last one.
+func (c *blobCache) rotate() {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	c.previous = c.current
+	c.current = nil
+}
@@ -0,0 +1,22 @@
+package githubconfig
+
+import "testing"
+
+func TestBlobCache(t *testing.T) {
+	c := &blobCache{}
+	c.put("used", []byte("used"))
+	c.put("unused", []byte("unused"))
+
+	c.rotate()
+	if _, ok := c.get("used"); !ok {
+		t.Fatal("Entry cached in the last cycle is not returned.")
+	}
+
+	c.rotate()
+	if content, ok := c.get("used"); !ok || string(content) != "used" {
+		t.Errorf("Entry used in the last cycle is not kept: %s.", content)
+	}
+	if _, ok := c.get("unused"); ok {
+		t.Error("Entry not used in the last cycle is kept.")
+	}
+}
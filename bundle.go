@@ -0,0 +1,347 @@
+package githubconfig
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/oklahomer/go-sarah/v4"
+	"github.com/shurcooL/githubv4"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// BundleSignatureMismatch is returned by New when the detached signature of the bundle given by WithBundle does not match its content.
+var BundleSignatureMismatch = errors.New("bundle signature does not match")
+
+// OfflineMode is returned when an operation that requires GitHub is called on a watcher that serves a bundle without a client.
+var OfflineMode = errors.New("GitHub is not reachable in bundle mode")
+
+// WithBundleExport writes the configuration files of every fetched BotType into a bundle file with the given name on each refresh,
+// and writes the detached signature of the bundle made with the given key to the file with the ".sig" suffix,
+// so a bot in a network with no outbound access can authenticate and serve the bundle with WithBundle.
+// The key is either an ed25519.PrivateKey or an *ecdsa.PrivateKey.
+// The signature is base64-encoded as cosign's sign-blob command writes, so "cosign verify-blob" can verify the bundle as well.
+// The files are written as they are stored in the repository, so the files encrypted with SOPS or decrypted by WithDecrypter stay encrypted.
+func WithBundleExport(name string, key crypto.Signer) Option {
+	return func(w *watcher) {
+		w.bundleExport = name
+		w.bundleSigningKey = key
+	}
+}
+
+// WithBundle serves the configuration files from the bundle file with the given name, which is written by WithBundleExport,
+// instead of fetching them from GitHub.
+// New verifies the detached signature in the file with the ".sig" suffix with the given key,
+// and returns BundleSignatureMismatch when it does not match.
+// The key is either an ed25519.PublicKey or an *ecdsa.PublicKey, so a bundle signed by "cosign sign-blob" is accepted as well.
+// WithClient and WithToken are optional in this mode; without them, an operation that requires GitHub such as History returns OfflineMode.
+func WithBundle(name string, key crypto.PublicKey) Option {
+	return func(w *watcher) {
+		w.bundlePath = name
+		w.bundleVerifyingKey = key
+	}
+}
+
+// signatureSuffix is the suffix of the file that holds the detached signature of a bundle.
+const signatureSuffix = ".sig"
+
+// bundle holds the configuration files of each BotType as they are fetched from GitHub, before they are assembled.
+type bundle struct {
+	CreatedAt time.Time                      `json:"created_at"`
+	BotTypes  map[sarah.BotType]*bundleEntry `json:"bot_types"`
+}
+
+type bundleEntry struct {
+	Files   []*bundleFile   `json:"files"`
+	Schemas []*bundleSchema `json:"schemas,omitempty"`
+}
+
+type bundleFile struct {
+	ID        string        `json:"id"`
+	FileName  string        `json:"file_name"`
+	Path      string        `json:"path,omitempty"`
+	Extension string        `json:"extension"`
+	ObjectID  string        `json:"object_id"`
+	CommitSHA string        `json:"commit_sha,omitempty"`
+	Branch    string        `json:"branch,omitempty"`
+	URL       string        `json:"url,omitempty"`
+	Source    *Source       `json:"source,omitempty"`
+	Content   string        `json:"content"`
+	FetchedAt time.Time     `json:"fetched_at"`
+	Bases     []*bundleFile `json:"bases,omitempty"`
+}
+
+type bundleSchema struct {
+	Name    string `json:"name"`
+	Content string `json:"content"`
+}
+
+func newBundleFile(f *file) *bundleFile {
+	b := &bundleFile{
+		ID:        f.id,
+		FileName:  f.fileName,
+		Path:      f.path,
+		Extension: f.extension,
+		ObjectID:  f.objectID,
+		CommitSHA: f.commitSHA,
+		Branch:    f.branch,
+		URL:       f.url,
+		Source:    f.source,
+		Content:   f.content,
+		FetchedAt: f.fetchedAt,
+	}
+	for _, base := range f.bases {
+		b.Bases = append(b.Bases, newBundleFile(base))
+	}
+	return b
+}
+
+func (b *bundleFile) file() *file {
+	f := &file{
+		id:        b.ID,
+		fileName:  b.FileName,
+		path:      b.Path,
+		extension: b.Extension,
+		objectID:  b.ObjectID,
+		commitSHA: b.CommitSHA,
+		branch:    b.Branch,
+		url:       b.URL,
+		source:    b.Source,
+		content:   b.Content,
+		fetchedAt: b.FetchedAt,
+	}
+	for _, base := range b.Bases {
+		f.bases = append(f.bases, base.file())
+	}
+	return f
+}
+
+// files returns a fresh copy of the given BotType's files and schemas, so the assembly never modifies the bundle.
+// This also tells if the BotType is in the bundle; a BotType missing in the bundle has no file.
+func (b *bundle) files(botType sarah.BotType) (map[string]*file, []entry, bool) {
+	files := map[string]*file{}
+	e, ok := b.BotTypes[botType]
+	if !ok {
+		return files, nil, false
+	}
+
+	for _, f := range e.Files {
+		files[f.ID] = f.file()
+	}
+	var schemas []entry
+	for _, s := range e.Schemas {
+		schemas = append(schemas, entry{
+			Name: githubv4.String(s.Name),
+			Type: "blob",
+			Object: entryObject{
+				Blob: blob{Text: githubv4.String(s.Content)},
+			},
+		})
+	}
+	return files, schemas, true
+}
+
+// signBundle returns the signature of the given bundle content.
+// An Ed25519 key signs the content itself while an ECDSA key signs its SHA-256 digest, as cosign does.
+func signBundle(key crypto.Signer, payload []byte) ([]byte, error) {
+	switch key.Public().(type) {
+	case ed25519.PublicKey:
+		return key.Sign(rand.Reader, payload, crypto.Hash(0))
+
+	case *ecdsa.PublicKey:
+		digest := sha256.Sum256(payload)
+		return key.Sign(rand.Reader, digest[:], crypto.SHA256)
+
+	default:
+		return nil, fmt.Errorf("unsupported key to sign bundle with: %T", key)
+
+	}
+}
+
+// verifyBundle verifies the signature of the given bundle content.
+func verifyBundle(key crypto.PublicKey, payload []byte, signature []byte) error {
+	switch k := key.(type) {
+	case ed25519.PublicKey:
+		if len(k) != ed25519.PublicKeySize || !ed25519.Verify(k, payload, signature) {
+			return BundleSignatureMismatch
+		}
+		return nil
+
+	case *ecdsa.PublicKey:
+		digest := sha256.Sum256(payload)
+		if !ecdsa.VerifyASN1(k, digest[:], signature) {
+			return BundleSignatureMismatch
+		}
+		return nil
+
+	default:
+		return fmt.Errorf("unsupported key to verify bundle with: %T", key)
+
+	}
+}
+
+// validateBundleExport validates the settings given by WithBundleExport.
+func (w *watcher) validateBundleExport() error {
+	if w.bundleExport == "" {
+		return nil
+	}
+
+	switch k := w.bundleSigningKey.(type) {
+	case nil:
+		return errors.New("key to sign bundle is not given")
+
+	case ed25519.PrivateKey:
+		if len(k) != ed25519.PrivateKeySize {
+			return errors.New("invalid ed25519 key to sign bundle")
+		}
+
+	case *ecdsa.PrivateKey:
+		if k == nil {
+			return errors.New("key to sign bundle is not given")
+		}
+
+	default:
+		switch k.Public().(type) {
+		case ed25519.PublicKey, *ecdsa.PublicKey:
+			// O.K.
+
+		default:
+			return fmt.Errorf("unsupported key to sign bundle with: %T", k)
+
+		}
+
+	}
+	return nil
+}
+
+// loadBundle reads the bundle given by WithBundle and verifies its detached signature.
+func (w *watcher) loadBundle() error {
+	if w.bundlePath == "" {
+		return nil
+	}
+
+	switch k := w.bundleVerifyingKey.(type) {
+	case nil:
+		return errors.New("key to verify bundle is not given")
+
+	case *ecdsa.PublicKey:
+		if k == nil {
+			return errors.New("key to verify bundle is not given")
+		}
+
+	}
+
+	payload, err := os.ReadFile(w.bundlePath)
+	if err != nil {
+		return fmt.Errorf("failed to read bundle: %w", err)
+	}
+
+	encoded, err := os.ReadFile(w.bundlePath + signatureSuffix)
+	if err != nil {
+		return fmt.Errorf("failed to read bundle signature: %w", err)
+	}
+	signature, err := base64.StdEncoding.DecodeString(strings.TrimSpace(string(encoded)))
+	if err != nil {
+		return fmt.Errorf("failed to decode bundle signature: %w", err)
+	}
+
+	err = verifyBundle(w.bundleVerifyingKey, payload, signature)
+	if err != nil {
+		return err
+	}
+
+	w.bundle = &bundle{}
+	err = json.Unmarshal(payload, w.bundle)
+	if err != nil {
+		return fmt.Errorf("failed to parse bundle %s: %w", w.bundlePath, err)
+	}
+
+	if w.client == nil {
+		w.client = &offlineQuerier{}
+	}
+	return nil
+}
+
+// exportBundle replaces the given BotType's files in the bundle given by WithBundleExport and rewrites the bundle file.
+func (w *watcher) exportBundle(botType sarah.BotType, files map[string]*file, schemas []entry) error {
+	if w.bundleExport == "" {
+		return nil
+	}
+
+	e := &bundleEntry{}
+	for _, f := range files {
+		e.Files = append(e.Files, newBundleFile(f))
+	}
+	sort.Slice(e.Files, func(i, j int) bool {
+		return e.Files[i].ID < e.Files[j].ID
+	})
+	for _, s := range schemas {
+		e.Schemas = append(e.Schemas, &bundleSchema{Name: string(s.Name), Content: string(s.Object.Blob.Text)})
+	}
+
+	// Verify may fetch the files outside the operating goroutine.
+	w.bundleMutex.Lock()
+	defer w.bundleMutex.Unlock()
+
+	if w.bundled == nil {
+		w.bundled = map[sarah.BotType]*bundleEntry{}
+	}
+	w.bundled[botType] = e
+
+	payload, err := json.Marshal(&bundle{CreatedAt: time.Now(), BotTypes: w.bundled})
+	if err != nil {
+		return err
+	}
+	signature, err := signBundle(w.bundleSigningKey, payload)
+	if err != nil {
+		return err
+	}
+
+	// A reader that reads the files while they are replaced sees a mismatching pair, which is refused rather than trusted.
+	err = writeFile(w.bundleExport+signatureSuffix, []byte(base64.StdEncoding.EncodeToString(signature)))
+	if err != nil {
+		return err
+	}
+	return writeFile(w.bundleExport, payload)
+}
+
+// writeFile writes to a temporary file and then renames it so a reader never sees a partially written file.
+func writeFile(name string, b []byte) error {
+	tmp, err := os.CreateTemp(filepath.Dir(name), ".tmp-")
+	if err != nil {
+		return fmt.Errorf("failed to create temporary file for %s: %w", name, err)
+	}
+	_, err = tmp.Write(b)
+	closeErr := tmp.Close()
+	if err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Rename(tmp.Name(), name)
+	}
+	if err != nil {
+		_ = os.Remove(tmp.Name())
+		return fmt.Errorf("failed to write %s: %w", name, err)
+	}
+	return nil
+}
+
+// offlineQuerier is the client of a watcher that serves a bundle without any client given.
+type offlineQuerier struct{}
+
+var _ querier = (*offlineQuerier)(nil)
+
+// Query always fails with OfflineMode.
+func (*offlineQuerier) Query(_ context.Context, _ interface{}, _ map[string]interface{}) error {
+	return OfflineMode
+}
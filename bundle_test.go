@@ -0,0 +1,219 @@
+package githubconfig
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"errors"
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+	"time"
+)
+
+func TestWithBundleExport(t *testing.T) {
+	_, key, _ := ed25519.GenerateKey(nil)
+	w := &watcher{}
+	WithBundleExport("bundle.json", key)(w)
+
+	if w.bundleExport != "bundle.json" {
+		t.Errorf("Unexpected file is set: %s.", w.bundleExport)
+	}
+	if k, ok := w.bundleSigningKey.(ed25519.PrivateKey); !ok || !k.Equal(key) {
+		t.Error("Unexpected key is set.")
+	}
+}
+
+func TestWithBundle(t *testing.T) {
+	key, _, _ := ed25519.GenerateKey(nil)
+	w := &watcher{}
+	WithBundle("bundle.json", key)(w)
+
+	if w.bundlePath != "bundle.json" {
+		t.Errorf("Unexpected file is set: %s.", w.bundlePath)
+	}
+	if k, ok := w.bundleVerifyingKey.(ed25519.PublicKey); !ok || !k.Equal(key) {
+		t.Error("Unexpected key is set.")
+	}
+}
+
+func TestBundle(t *testing.T) {
+	public, private, _ := ed25519.GenerateKey(nil)
+	name := filepath.Join(t.TempDir(), "bundle.json")
+
+	hello := newFile("hello.yml", "abc", "message: hello\n")
+	hello.path = "config/bot/hello.yml"
+	hello.commitSHA = "sha"
+	hello.bases = []*file{newFile("hello.yml", "org", "message: org\n")}
+	files := map[string]*file{
+		"hello":    hello,
+		defaultsID: newFile("_default.yml", "def", "lang: en\n"),
+	}
+	schemas := []entry{{Name: "hello.json", Object: entryObject{Blob: blob{Text: `{"type": "object"}`}}}}
+
+	exporter := &watcher{bundleExport: name, bundleSigningKey: private}
+	err := exporter.exportBundle("bot", files, schemas)
+	if err != nil {
+		t.Fatalf("Unexpected error is returned: %s.", err.Error())
+	}
+
+	w, err := New(&Config{TimeOut: time.Second}, WithBundle(name, public))
+	if err != nil {
+		t.Fatalf("Unexpected error is returned: %s.", err.Error())
+	}
+	served := w.(*watcher)
+
+	t.Run("get", func(t *testing.T) {
+		got, err := served.get(context.TODO(), "bot")
+		if err != nil {
+			t.Fatalf("Unexpected error is returned: %s.", err.Error())
+		}
+
+		f, ok := got["hello"]
+		if !ok {
+			t.Fatal("Bundled file is not served.")
+		}
+		if f.objectID != "abc" || f.path != "config/bot/hello.yml" || f.commitSHA != "sha" || len(f.bases) != 1 || f.defaults == nil {
+			t.Errorf("Unexpected file is served: %+v.", f)
+		}
+
+		config := &struct {
+			Message string `yaml:"message"`
+			Lang    string `yaml:"lang"`
+		}{}
+		err = served.read(context.TODO(), "bot", f, config)
+		if err != nil {
+			t.Fatalf("Unexpected error is returned: %s.", err.Error())
+		}
+		if config.Message != "hello" || config.Lang != "en" {
+			t.Errorf("Unexpected configuration is read: %+v.", config)
+		}
+	})
+
+	t.Run("missing BotType", func(t *testing.T) {
+		_, err := served.get(context.TODO(), "other")
+		var notFound *DirectoryNotFoundError
+		if !errors.As(err, &notFound) {
+			t.Errorf("Unexpected error is returned: %#v.", err)
+		}
+	})
+
+	t.Run("offline", func(t *testing.T) {
+		err := served.client.Query(context.TODO(), &blobQuery{}, map[string]interface{}{})
+		if !errors.Is(err, OfflineMode) {
+			t.Errorf("Unexpected error is returned: %#v.", err)
+		}
+	})
+
+	t.Run("tampered", func(t *testing.T) {
+		tampered := filepath.Join(t.TempDir(), "bundle.json")
+		_ = os.WriteFile(tampered, []byte(`{"bot_types": {}}`), 0o600)
+		signature, _ := os.ReadFile(name + ".sig")
+		_ = os.WriteFile(tampered+".sig", signature, 0o600)
+
+		_, err := New(&Config{TimeOut: time.Second}, WithBundle(tampered, public))
+		if err != BundleSignatureMismatch {
+			t.Errorf("Unexpected error is returned: %#v.", err)
+		}
+	})
+
+	t.Run("unsigned", func(t *testing.T) {
+		unsigned := filepath.Join(t.TempDir(), "bundle.json")
+		payload, _ := os.ReadFile(name)
+		_ = os.WriteFile(unsigned, payload, 0o600)
+
+		_, err := New(&Config{TimeOut: time.Second}, WithBundle(unsigned, public))
+		if err == nil {
+			t.Error("Expected error is not returned.")
+		}
+	})
+}
+
+func TestBundleSignature(t *testing.T) {
+	ed25519Public, ed25519Private, _ := ed25519.GenerateKey(nil)
+	ecdsaPrivate, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	otherPublic, _, _ := ed25519.GenerateKey(nil)
+
+	tests := []struct {
+		signer   crypto.Signer
+		verifier crypto.PublicKey
+		err      error
+	}{
+		{signer: ed25519Private, verifier: ed25519Public},
+		{signer: ecdsaPrivate, verifier: &ecdsaPrivate.PublicKey},
+		{signer: ed25519Private, verifier: otherPublic, err: BundleSignatureMismatch},
+		{signer: ecdsaPrivate, verifier: ed25519Public, err: BundleSignatureMismatch},
+	}
+
+	payload := []byte(`{"bot_types": {}}`)
+	for i, tt := range tests {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			signature, err := signBundle(tt.signer, payload)
+			if err != nil {
+				t.Fatalf("Unexpected error is returned: %s.", err.Error())
+			}
+
+			err = verifyBundle(tt.verifier, payload, signature)
+			if err != tt.err {
+				t.Errorf("Unexpected error is returned: %#v.", err)
+			}
+		})
+	}
+}
+
+func TestBundleSignature_cosign(t *testing.T) {
+	// A signature made by "cosign sign-blob" with an ECDSA P-256 key is an ASN.1 signature of the SHA-256 digest.
+	key, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	payload := []byte(`{"bot_types": {}}`)
+	digest := sha256.Sum256(payload)
+	signature, err := ecdsa.SignASN1(rand.Reader, key, digest[:])
+	if err != nil {
+		t.Fatalf("Unexpected error is returned: %s.", err.Error())
+	}
+
+	err = verifyBundle(&key.PublicKey, payload, signature)
+	if err != nil {
+		t.Errorf("Unexpected error is returned: %s.", err.Error())
+	}
+}
+
+func TestNew_bundleKeys(t *testing.T) {
+	_, private, _ := ed25519.GenerateKey(nil)
+	decrypter := &DummyDecrypter{}
+	name := filepath.Join(t.TempDir(), "bundle.json")
+
+	tests := []struct {
+		opts []Option
+		err  bool
+	}{
+		{opts: []Option{WithBundleExport(name, nil)}, err: true},
+		{opts: []Option{WithBundleExport(name, (*ecdsa.PrivateKey)(nil))}, err: true},
+		{opts: []Option{WithBundleExport(name, ed25519.PrivateKey(nil))}, err: true},
+		{opts: []Option{WithBundle(name, nil)}, err: true},
+		{opts: []Option{WithBundle(name, (*ecdsa.PublicKey)(nil))}, err: true},
+		{opts: []Option{WithBundleExport(name, private), WithDecrypter("*.yml", decrypter)}},
+		{opts: []Option{WithBundleExport(name, private)}},
+	}
+
+	for i, tt := range tests {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			opts := append([]Option{WithToken(context.TODO(), "token")}, tt.opts...)
+			_, err := New(&Config{TimeOut: time.Second}, opts...)
+			if tt.err {
+				if err == nil {
+					t.Error("Expected error is not returned.")
+				}
+				return
+			}
+
+			if err != nil {
+				t.Errorf("Unexpected error is returned: %s.", err.Error())
+			}
+		})
+	}
+}
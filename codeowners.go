@@ -0,0 +1,195 @@
+package githubconfig
+
+import (
+	"bufio"
+	"context"
+	"github.com/oklahomer/go-sarah/v4"
+	"github.com/shurcooL/githubv4"
+	"strings"
+)
+
+// codeOwnersLocations are the paths GitHub looks up for the CODEOWNERS file, in order of precedence.
+var codeOwnersLocations = []string{".github/CODEOWNERS", "CODEOWNERS", "docs/CODEOWNERS"}
+
+// WithCodeOwnerApproval requires an updated configuration file to be merged through a pull request that is approved
+// by one of the code owners of the file, as defined by the CODEOWNERS file of the repository.
+// A change that does not satisfy the policy is not applied; the previous content stays effective
+// and an EventPolicyViolation is emitted instead.
+// The files that have no code owner are applied as is.
+func WithCodeOwnerApproval() Option {
+	return func(w *watcher) {
+		w.codeOwnerApproval = true
+	}
+}
+
+// codeOwnersRule is a line of the CODEOWNERS file.
+type codeOwnersRule struct {
+	pattern string
+	owners  []string
+}
+
+// parseCodeOwners parses the content of the CODEOWNERS file.
+func parseCodeOwners(content string) []*codeOwnersRule {
+	var rules []*codeOwnersRule
+	scanner := bufio.NewScanner(strings.NewReader(content))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		fields := strings.Fields(line)
+		rule := &codeOwnersRule{pattern: fields[0]}
+		for _, owner := range fields[1:] {
+			if strings.HasPrefix(owner, "#") {
+				break
+			}
+			rule.owners = append(rule.owners, owner)
+		}
+		rules = append(rules, rule)
+	}
+	return rules
+}
+
+// codeOwnersOf returns the owners of the given path. The last matching rule takes precedence as GitHub does.
+func codeOwnersOf(rules []*codeOwnersRule, p string) []string {
+	p = strings.TrimPrefix(p, "/")
+	for i := len(rules) - 1; i >= 0; i-- {
+		if codeOwnersMatch(rules[i].pattern, p) {
+			return rules[i].owners
+		}
+	}
+	return nil
+}
+
+// codeOwnersMatch tells if the slash-separated path matches the CODEOWNERS pattern.
+// As with .gitignore, a pattern with a leading or middle slash is relative to the repository root,
+// a pattern without a slash matches at any depth, and a pattern with a trailing slash matches the files under the directory.
+func codeOwnersMatch(pattern string, p string) bool {
+	directory := strings.HasSuffix(pattern, "/")
+	pattern = strings.TrimSuffix(pattern, "/")
+	if !strings.Contains(pattern, "/") {
+		pattern = "**/" + pattern
+	}
+	segments := strings.Split(strings.TrimPrefix(pattern, "/"), "/")
+	name := strings.Split(p, "/")
+
+	if !directory && matchSegments(segments, name) {
+		return true
+	}
+	// A pattern matching a directory matches everything under it.
+	return matchSegments(append(segments, "*", "**"), name)
+}
+
+// teamMembersQuery represents a Graphql query to fetch the members of a team.
+// Formatted query is as below:
+//
+// 	query ($org: String!, $slug: String!) {
+//    organization(login: $org) {
+//      team(slug: $slug) {
+//        members(first: 100) {
+//          nodes {
+//            login
+//          }
+//        }
+//      }
+//    }
+// 	}
+type teamMembersQuery struct {
+	Organization struct {
+		Team *struct {
+			Members struct {
+				Nodes []struct {
+					Login githubv4.String
+				}
+			} `graphql:"members(first: 100)"`
+		} `graphql:"team(slug: $slug)"`
+	} `graphql:"organization(login: $org)"`
+}
+
+// codeOwnerApproved tells if any of the pull requests is approved by one of the given owners.
+func (w *watcher) codeOwnerApproved(ctx context.Context, botType sarah.BotType, owners []string, pulls []pullRequest) (bool, error) {
+	members := map[string][]string{}
+	for _, pr := range pulls {
+		for _, review := range pr.Reviews.Nodes {
+			approver := string(review.Author.Login)
+			for _, owner := range owners {
+				ok, err := w.isCodeOwner(ctx, botType, owner, approver, members)
+				if err != nil {
+					return false, err
+				}
+				if ok {
+					return true, nil
+				}
+			}
+		}
+	}
+	return false, nil
+}
+
+// isCodeOwner tells if the given login is the owner, or is a member of the owning team.
+// The members of each team are memorized in the given map during a check.
+// An owner given by an email address cannot be resolved to a login, so it never matches.
+func (w *watcher) isCodeOwner(ctx context.Context, botType sarah.BotType, owner string, login string, members map[string][]string) (bool, error) {
+	if !strings.HasPrefix(owner, "@") {
+		return false, nil
+	}
+
+	org, slug, isTeam := strings.Cut(strings.TrimPrefix(owner, "@"), "/")
+	if !isTeam {
+		return strings.EqualFold(org, login), nil
+	}
+
+	logins, ok := members[owner]
+	if !ok {
+		q := &teamMembersQuery{}
+		variables := map[string]interface{}{
+			"org":  githubv4.String(org),
+			"slug": githubv4.String(slug),
+		}
+		err := w.client.Query(ctx, q, variables)
+		if err != nil {
+			return false, w.queryError(botType, org, err)
+		}
+
+		if team := q.Organization.Team; team != nil {
+			for _, member := range team.Members.Nodes {
+				logins = append(logins, string(member.Login))
+			}
+		}
+		members[owner] = logins
+	}
+
+	for _, member := range logins {
+		if strings.EqualFold(member, login) {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// codeOwners returns the code owners of the given path as of the given commit.
+// No owner is returned when the repository has no CODEOWNERS file.
+func (w *watcher) codeOwners(ctx context.Context, botType sarah.BotType, src *Source, ref string, p string) ([]string, error) {
+	for _, location := range codeOwnersLocations {
+		q := &blobQuery{}
+		variables := map[string]interface{}{
+			"owner":      githubv4.String(src.Owner),
+			"name":       githubv4.String(src.Name),
+			"expression": githubv4.String(expression(ref, location)),
+		}
+		err := w.client.Query(ctx, q, variables)
+		if err != nil {
+			return nil, w.queryError(botType, repositoryName(src.Owner, src.Name), err)
+		}
+
+		b := q.Repository.Object.Blob
+		if b.Oid == "" {
+			continue
+		}
+
+		return codeOwnersOf(parseCodeOwners(string(b.Text)), p), nil
+	}
+
+	return nil, nil
+}
@@ -0,0 +1,157 @@
+package githubconfig
+
+import (
+	"context"
+	"errors"
+	"github.com/shurcooL/githubv4"
+	"reflect"
+	"strconv"
+	"testing"
+)
+
+func TestWithCodeOwnerApproval(t *testing.T) {
+	w := &watcher{}
+	WithCodeOwnerApproval()(w)
+
+	if !w.codeOwnerApproval {
+		t.Error("Code owner approval is not required.")
+	}
+}
+
+func TestCodeOwnersMatch(t *testing.T) {
+	tests := []struct {
+		pattern  string
+		path     string
+		expected bool
+	}{
+		{pattern: "*", path: "config/bot/hello.yml", expected: true},
+		{pattern: "*.yml", path: "config/bot/hello.yml", expected: true},
+		{pattern: "*.json", path: "config/bot/hello.yml", expected: false},
+		{pattern: "/config/", path: "config/bot/hello.yml", expected: true},
+		{pattern: "/bot/", path: "config/bot/hello.yml", expected: false},
+		{pattern: "bot/", path: "config/bot/hello.yml", expected: true},
+		{pattern: "hello.yml/", path: "config/bot/hello.yml", expected: false},
+		{pattern: "config/bot", path: "config/bot/hello.yml", expected: true},
+		{pattern: "config/bot/hello.yml", path: "config/bot/hello.yml", expected: true},
+		{pattern: "config/*/hello.yml", path: "config/bot/hello.yml", expected: true},
+		{pattern: "config/**/hello.yml", path: "config/bot/hello.yml", expected: true},
+		{pattern: "bot/hello.yml", path: "config/bot/hello.yml", expected: false},
+	}
+
+	for i, tt := range tests {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			matched := codeOwnersMatch(tt.pattern, tt.path)
+			if matched != tt.expected {
+				t.Errorf("Unexpected result is returned: %t.", matched)
+			}
+		})
+	}
+}
+
+func TestCodeOwnersOf(t *testing.T) {
+	rules := parseCodeOwners(`# Default owners
+*       @oklahomer
+
+/config/ @org/config-team # Configuration
+/config/bot/generated.yml
+`)
+
+	tests := []struct {
+		path     string
+		expected []string
+	}{
+		{path: "README.md", expected: []string{"@oklahomer"}},
+		{path: "/config/bot/hello.yml", expected: []string{"@org/config-team"}},
+		{path: "config/bot/generated.yml", expected: nil},
+	}
+
+	for i, tt := range tests {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			owners := codeOwnersOf(rules, tt.path)
+			if !reflect.DeepEqual(owners, tt.expected) {
+				t.Errorf("Unexpected owners are returned: %v.", owners)
+			}
+		})
+	}
+}
+
+func TestWatcher_checkApproval_codeOwner(t *testing.T) {
+	tests := []struct {
+		codeOwners string
+		merged     bool
+		approvers  []string
+		violation  bool
+	}{
+		{codeOwners: "", merged: false, violation: false},
+		{codeOwners: "/config/ @oklahomer", merged: true, approvers: []string{"Oklahomer"}, violation: false},
+		{codeOwners: "/config/ @org/team", merged: true, approvers: []string{"someone", "member"}, violation: false},
+		{codeOwners: "/config/ @org/team", merged: true, approvers: []string{"someone"}, violation: true},
+		{codeOwners: "/config/ @oklahomer", merged: true, approvers: nil, violation: true},
+		{codeOwners: "/config/ @oklahomer", merged: false, approvers: []string{"oklahomer"}, violation: true},
+	}
+
+	for i, tt := range tests {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			w := &watcher{
+				client: &DummyQuerier{
+					QueryFunc: func(_ context.Context, q interface{}, v map[string]interface{}) error {
+						switch typed := q.(type) {
+						case *commitQuery:
+							typed.Repository.Ref.Target.Commit.History.Nodes = []commit{{Oid: "sha"}}
+
+						case *blobQuery:
+							if tt.codeOwners != "" && v["expression"] == githubv4.String("sha:.github/CODEOWNERS") {
+								typed.Repository.Object.Blob = blob{Oid: "owners", Text: githubv4.String(tt.codeOwners)}
+							}
+
+						case *pullRequestQuery:
+							if v["oid"] != githubv4.GitObjectID("sha") {
+								t.Errorf("Unexpected commit is given: %s.", v["oid"])
+							}
+							pr := pullRequest{Number: 1, Merged: githubv4.Boolean(tt.merged)}
+							for _, approver := range tt.approvers {
+								review := struct {
+									Author struct{ Login githubv4.String }
+								}{}
+								review.Author.Login = githubv4.String(approver)
+								pr.Reviews.Nodes = append(pr.Reviews.Nodes, review)
+							}
+							typed.Repository.Object.Commit.AssociatedPullRequests.Nodes = []pullRequest{pr}
+
+						case *teamMembersQuery:
+							if v["org"] != githubv4.String("org") || v["slug"] != githubv4.String("team") {
+								t.Errorf("Unexpected team is given: %s/%s.", v["org"], v["slug"])
+							}
+							typed.Organization.Team = &struct {
+								Members struct {
+									Nodes []struct{ Login githubv4.String }
+								} `graphql:"members(first: 100)"`
+							}{}
+							typed.Organization.Team.Members.Nodes = []struct{ Login githubv4.String }{{Login: "member"}}
+
+						}
+						return nil
+					},
+				},
+				config:            &Config{Owner: "oklahomer", Name: "config", Branch: "master"},
+				codeOwnerApproval: true,
+			}
+
+			err := w.checkApproval(context.TODO(), "bot", "hello", &file{id: "hello", path: "config/bot/hello.yml"})
+			var violation *PolicyViolationError
+			if tt.violation {
+				if !errors.As(err, &violation) {
+					t.Fatalf("Expected violation is not returned: %#v.", err)
+				}
+				if violation.Commit != "sha" || violation.Path != "config/bot/hello.yml" {
+					t.Errorf("Unexpected violation is returned: %+v.", violation)
+				}
+				return
+			}
+
+			if err != nil {
+				t.Errorf("Unexpected error is returned: %s.", err.Error())
+			}
+		})
+	}
+}
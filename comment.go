@@ -0,0 +1,101 @@
+package githubconfig
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/oklahomer/go-sarah/v4"
+	"github.com/shurcooL/githubv4"
+)
+
+// WithFailureComment comments on the pull request that merged a change, or on the commit when no pull request is found,
+// when the change is refused since it fails to decode, does not conform to its schema, or violates the code owner policy,
+// so the author of the change finds out immediately.
+// This calls GitHub's REST API, which requires WithToken or WithRESTClient, and the token needs the permission to write comments.
+func WithFailureComment() Option {
+	return func(w *watcher) {
+		w.failureComment = true
+	}
+}
+
+// issueComment is the request body of GitHub's REST API to comment on a commit or an issue.
+type issueComment struct {
+	Body string `json:"body"`
+}
+
+// commentFailure comments the refusal of the given file with the error on GitHub.
+// A comment is made only once for each content of the file, and asynchronously so a slow API call never blocks the refresh.
+func (w *watcher) commentFailure(ctx context.Context, botType sarah.BotType, f *file, err error) {
+	if !w.failureComment || f.path == "" {
+		return
+	}
+
+	key := fmt.Sprintf("%s:%s@%s", botType, f.path, f.objectID)
+	if w.commented[key] {
+		return
+	}
+	if w.commented == nil {
+		w.commented = map[string]bool{}
+	}
+	w.commented[key] = true
+
+	src := f.source
+	if src == nil {
+		src = w.source(botType)
+	}
+	body := failureComment(botType, f, w.redactError(botType, f, err))
+	sha := ""
+	var violation *PolicyViolationError
+	if errors.As(err, &violation) {
+		sha = violation.Commit
+	}
+
+	w.callbacks.Add(1)
+	go func() {
+		defer w.callbacks.Done()
+		err := w.postComment(ctx, botType, src, f, sha, body)
+		if err != nil {
+			w.log(botType, f.id).Errorf("Failed to comment the failure of %s: %+v", f.path, err)
+		}
+	}()
+}
+
+// failureComment returns the body of the comment that describes the refusal.
+func failureComment(botType sarah.BotType, f *file, err error) string {
+	return fmt.Sprintf("**sarah-config** refused to apply `%s` for `%s:%s`, and the previous configuration stays effective.\n\n```\n%s\n```\n",
+		f.path, botType, f.id, err.Error())
+}
+
+// postComment comments on the pull request associated with the given commit, or on the commit itself when no pull request is found.
+// The last commit that touched the file is looked up when the commit is not given.
+func (w *watcher) postComment(ctx context.Context, botType sarah.BotType, src *Source, f *file, sha string, body string) error {
+	if w.restClient(src.Owner, src.Name) == nil {
+		return fmt.Errorf("REST client is required to comment on %s: give WithToken or WithRESTClient", f.path)
+	}
+
+	if sha == "" {
+		c, err := w.lastCommit(ctx, botType, f)
+		if err != nil {
+			return err
+		}
+		sha = c.SHA
+	}
+
+	q := &pullRequestQuery{}
+	variables := map[string]interface{}{
+		"owner": githubv4.String(src.Owner),
+		"name":  githubv4.String(src.Name),
+		"oid":   githubv4.GitObjectID(sha),
+	}
+	err := w.client.Query(ctx, q, variables)
+	if err != nil {
+		return w.queryError(botType, repositoryName(src.Owner, src.Name), err)
+	}
+
+	comment := &issueComment{Body: body}
+	pulls := q.Repository.Object.Commit.AssociatedPullRequests.Nodes
+	if len(pulls) > 0 {
+		return w.post(ctx, botType, src, fmt.Sprintf("/repos/%s/%s/issues/%d/comments", src.Owner, src.Name, pulls[0].Number), comment, nil)
+	}
+	return w.post(ctx, botType, src, fmt.Sprintf("/repos/%s/%s/commits/%s/comments", src.Owner, src.Name, sha), comment, nil)
+}
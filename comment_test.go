@@ -0,0 +1,128 @@
+package githubconfig
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"github.com/shurcooL/githubv4"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestWithFailureComment(t *testing.T) {
+	w := &watcher{}
+	WithFailureComment()(w)
+
+	if !w.failureComment {
+		t.Error("Failure comment is not enabled.")
+	}
+}
+
+func TestWatcher_postComment(t *testing.T) {
+	tests := []struct {
+		sha      string
+		pulls    []pullRequest
+		expected string
+	}{
+		{sha: "", pulls: []pullRequest{{Number: 12}}, expected: "/repos/oklahomer/config/issues/12/comments"},
+		{sha: "violated", pulls: nil, expected: "/repos/oklahomer/config/commits/violated/comments"},
+		{sha: "", pulls: nil, expected: "/repos/oklahomer/config/commits/last/comments"},
+	}
+
+	for i, tt := range tests {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			var requested string
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				requested = r.URL.Path
+				comment := &issueComment{}
+				err := json.NewDecoder(r.Body).Decode(comment)
+				if err != nil {
+					t.Fatalf("Unexpected error is returned: %s.", err.Error())
+				}
+				if comment.Body != "body" {
+					t.Errorf("Unexpected comment is given: %s.", comment.Body)
+				}
+				w.WriteHeader(http.StatusCreated)
+			}))
+			defer server.Close()
+
+			w := &watcher{
+				client: &DummyQuerier{
+					QueryFunc: func(_ context.Context, q interface{}, v map[string]interface{}) error {
+						switch typed := q.(type) {
+						case *commitQuery:
+							typed.Repository.Ref.Target.Commit.History.Nodes = []commit{{Oid: "last"}}
+
+						case *pullRequestQuery:
+							expected := tt.sha
+							if expected == "" {
+								expected = "last"
+							}
+							if v["oid"] != githubv4.GitObjectID(expected) {
+								t.Errorf("Unexpected commit is given: %s.", v["oid"])
+							}
+							typed.Repository.Object.Commit.AssociatedPullRequests.Nodes = tt.pulls
+
+						}
+						return nil
+					},
+				},
+				config: &Config{Branch: "master", TimeOut: time.Second},
+				rest:   &restClient{httpClient: server.Client(), baseURL: server.URL},
+			}
+
+			src := &Source{Owner: "oklahomer", Name: "config", Branch: "master"}
+			err := w.postComment(context.TODO(), "bot", src, &file{id: "hello", path: "config/bot/hello.yml"}, tt.sha, "body")
+			if err != nil {
+				t.Fatalf("Unexpected error is returned: %s.", err.Error())
+			}
+
+			if requested != tt.expected {
+				t.Errorf("Unexpected endpoint is called: %s.", requested)
+			}
+		})
+	}
+}
+
+func TestWatcher_commentFailure(t *testing.T) {
+	comments := make(chan string, 10)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		comment := &issueComment{}
+		_ = json.NewDecoder(r.Body).Decode(comment)
+		comments <- r.URL.Path + " " + comment.Body
+		w.WriteHeader(http.StatusCreated)
+	}))
+	defer server.Close()
+
+	w := &watcher{
+		client: &DummyQuerier{
+			QueryFunc: func(_ context.Context, _ interface{}, _ map[string]interface{}) error {
+				// No pull request
+				return nil
+			},
+		},
+		config:         &Config{Owner: "oklahomer", Name: "config", TimeOut: time.Second},
+		rest:           &restClient{httpClient: server.Client(), baseURL: server.URL},
+		failureComment: true,
+	}
+
+	f := &file{id: "hello", path: "config/bot/hello.yml", objectID: "1"}
+	violation := &PolicyViolationError{BotType: "bot", ID: "hello", Path: f.path, Commit: "sha", Reason: "not approved"}
+	w.commentFailure(context.TODO(), "bot", f, violation)
+	w.commentFailure(context.TODO(), "bot", f, violation)
+	w.commentFailure(context.TODO(), "bot", &file{id: "local"}, errors.New("local file is never commented"))
+	w.callbacks.Wait()
+	close(comments)
+
+	if len(comments) != 1 {
+		t.Fatalf("Unexpected number of comments are made: %d.", len(comments))
+	}
+	comment := <-comments
+	if !strings.HasPrefix(comment, "/repos/oklahomer/config/commits/sha/comments ") || !strings.Contains(comment, "not approved") {
+		t.Errorf("Unexpected comment is made: %s.", comment)
+	}
+}
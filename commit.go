@@ -0,0 +1,280 @@
+package githubconfig
+
+import (
+	"context"
+	"fmt"
+	"github.com/oklahomer/go-sarah/v4"
+	"github.com/shurcooL/githubv4"
+	"strings"
+	"time"
+)
+
+// Change describes an added, updated, or removed configuration file.
+type Change struct {
+	BotType sarah.BotType
+	ID      string
+	// OldObjectID is the Git blob object ID of the previous content. This is empty when the file is added.
+	OldObjectID string
+	// NewObjectID is the Git blob object ID of the current content. This is empty when the file is removed.
+	NewObjectID string
+	// Commit is the latest commit that touched the changed file.
+	// This is nil when the commit is not available such as when the change is made on a local override file.
+	Commit *Commit
+	// Diff is the unified diff between the old and the new content of the changed file.
+	Diff string
+	// DiffTruncated tells if Diff is truncated due to its size, or omitted because too many lines are changed.
+	DiffTruncated bool
+}
+
+// Commit describes a Git commit.
+type Commit struct {
+	SHA         string
+	Author      string
+	AuthorEmail string
+	Message     string
+	CommittedAt time.Time
+}
+
+// commitQuery represents a Graphql query to fetch the recent commits that touched the given path.
+// Formatted query is as below:
+//
+// 	query ($owner: String!, $name: String!, $branch:String!, $path:String!, $limit:Int!) {
+//    repository(owner: $owner, name: $name) {
+//      ref(qualifiedName: $branch) {
+//        target {
+//          ... on Commit {
+//            history(first: $limit, path: $path) {
+//              nodes {
+//                oid
+//                message
+//                committedDate
+//                author {
+//                  name
+//                  email
+//                }
+//              }
+//            }
+//          }
+//        }
+//      }
+//    }
+// 	}
+type commitQuery struct {
+	Repository struct {
+		Ref struct {
+			Target struct {
+				Commit struct {
+					History struct {
+						Nodes []commit
+					} `graphql:"history(first: $limit, path: $path)"`
+				} `graphql:"... on Commit"`
+			}
+		} `graphql:"ref(qualifiedName: $branch)"`
+	} `graphql:"repository(owner: $owner, name: $name)"`
+}
+
+// pinnedCommitQuery is similar to commitQuery, but fetches the commits from the commit a BotType is rolled back to.
+// Formatted query is as below:
+//
+// 	query ($owner: String!, $name: String!, $sha:String!, $path:String!, $limit:Int!) {
+//    repository(owner: $owner, name: $name) {
+//      object(expression: $sha) {
+//        ... on Commit {
+//          history(first: $limit, path: $path) {
+//            nodes {
+//              oid
+//              message
+//              committedDate
+//              author {
+//                name
+//                email
+//              }
+//            }
+//          }
+//        }
+//      }
+//    }
+// 	}
+type pinnedCommitQuery struct {
+	Repository struct {
+		Object struct {
+			Commit struct {
+				History struct {
+					Nodes []commit
+				} `graphql:"history(first: $limit, path: $path)"`
+			} `graphql:"... on Commit"`
+		} `graphql:"object(expression: $sha)"`
+	} `graphql:"repository(owner: $owner, name: $name)"`
+}
+
+type commit struct {
+	Oid           githubv4.String
+	Message       githubv4.String
+	CommittedDate githubv4.DateTime
+	Author        struct {
+		Name  githubv4.String
+		Email githubv4.String
+	}
+}
+
+// newChange builds a Change for the given id from the previous and the current files.
+// Either of the files is nil when the file is added or removed.
+// The commit that made the change is fetched only when withCommit is true.
+func (w *watcher) newChange(ctx context.Context, botType sarah.BotType, id string, old *file, current *file, withCommit bool) *Change {
+	change := &Change{
+		BotType: botType,
+		ID:      id,
+	}
+	if old != nil {
+		change.OldObjectID = old.objectID
+	}
+	if current != nil {
+		change.NewObjectID = current.objectID
+	}
+
+	before, after := changedFiles(old, current)
+	change.Diff, change.DiffTruncated = unifiedDiff(before, after, w.maxDiffSize)
+	change.Diff = w.redact(botType, id, change.Diff, before, after)
+
+	if !withCommit {
+		return change
+	}
+
+	changed := after
+	if changed == nil {
+		changed = before
+	}
+	if changed == nil || changed.path == "" {
+		return change
+	}
+
+	c, err := w.lastCommit(ctx, botType, changed)
+	if err != nil {
+		w.log(botType, id).Warnf("Failed to fetch the commit that changed %s: %+v", changed.path, err)
+		return change
+	}
+	change.Commit = c
+
+	return change
+}
+
+// changedFiles returns the previous and the current versions of the file that actually changed
+// among the given file and the files it depends on.
+// When only the defaults file is updated, for example, the defaults files are returned so the commit that touched it can be found.
+func changedFiles(old *file, current *file) (*file, *file) {
+	if old == nil || current == nil || old.objectID != current.objectID {
+		return old, current
+	}
+
+	changed := changedLayers(old, current)
+	if len(changed) > 0 {
+		return changed[0][0], changed[0][1]
+	}
+
+	return old, current
+}
+
+// changedLayers returns the pairs of the previous and the current versions of the given file and the files it depends on, which differ from each other.
+// Either of a pair is nil when the file is added to or removed from the dependencies.
+func changedLayers(old *file, current *file) [][2]*file {
+	pairs := [][2]*file{
+		{old, current},
+		{old.defaults, current.defaults},
+		{old.overlay, current.overlay},
+		{old.anchors, current.anchors},
+	}
+	pairs = append(pairs, pairLayers(old.fragments, current.fragments)...)
+	pairs = append(pairs, pairLayers(old.bases, current.bases)...)
+
+	var changed [][2]*file
+	for _, pair := range pairs {
+		if pair[0] == nil && pair[1] == nil {
+			continue
+		}
+		if pair[0] == nil || pair[1] == nil || pair[0].objectID != pair[1].objectID {
+			changed = append(changed, pair)
+		}
+	}
+	return changed
+}
+
+func pairLayers(old []*file, current []*file) [][2]*file {
+	var pairs [][2]*file
+	for i := 0; i < len(old) || i < len(current); i++ {
+		var pair [2]*file
+		if i < len(old) {
+			pair[0] = old[i]
+		}
+		if i < len(current) {
+			pair[1] = current[i]
+		}
+		pairs = append(pairs, pair)
+	}
+	return pairs
+}
+
+// lastCommit fetches the latest commit that touched the given file.
+func (w *watcher) lastCommit(ctx context.Context, botType sarah.BotType, f *file) (*Commit, error) {
+	commits, err := w.commits(ctx, botType, f, 1)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(commits) == 0 {
+		return nil, fmt.Errorf("no commit is found for %s", f.path)
+	}
+
+	return commits[0], nil
+}
+
+// commits fetches the recent commits that touched the given file on the branch it is fetched from, newest first.
+// The BotType's repository and branch are used when the file does not tell.
+// The commits are fetched from the pinned commit instead when the file is fetched at the commit the BotType is rolled back to.
+func (w *watcher) commits(ctx context.Context, botType sarah.BotType, f *file, limit int) ([]*Commit, error) {
+	src := f.source
+	if src == nil {
+		src = w.source(botType)
+	}
+	branch := f.branch
+	if branch == "" {
+		branch = src.Branch
+	}
+	variables := map[string]interface{}{
+		"owner": githubv4.String(src.Owner),
+		"name":  githubv4.String(src.Name),
+		"path":  githubv4.String(strings.TrimPrefix(f.path, "/")),
+		"limit": githubv4.Int(limit),
+	}
+
+	var nodes []commit
+	if sha, ok := w.pinned(botType); ok && branch == sha {
+		q := &pinnedCommitQuery{}
+		variables["sha"] = githubv4.String(sha)
+		err := w.client.Query(ctx, q, variables)
+		if err != nil {
+			return nil, w.queryError(botType, repositoryName(src.Owner, src.Name), err)
+		}
+		nodes = q.Repository.Object.Commit.History.Nodes
+	} else {
+		q := &commitQuery{}
+		variables["branch"] = githubv4.String(branch)
+		err := w.client.Query(ctx, q, variables)
+		if err != nil {
+			return nil, w.queryError(botType, repositoryName(src.Owner, src.Name), err)
+		}
+		nodes = q.Repository.Ref.Target.Commit.History.Nodes
+	}
+
+	var commits []*Commit
+	for _, c := range nodes {
+		commits = append(commits, &Commit{
+			SHA:         string(c.Oid),
+			Author:      string(c.Author.Name),
+			AuthorEmail: string(c.Author.Email),
+			Message:     string(c.Message),
+			CommittedAt: c.CommittedDate.Time,
+		})
+	}
+
+	return commits, nil
+}
@@ -0,0 +1,269 @@
+package githubconfig
+
+import (
+	"context"
+	"errors"
+	"github.com/shurcooL/githubv4"
+	"strconv"
+	"testing"
+	"time"
+)
+
+func TestChangedFiles(t *testing.T) {
+	defaults := &file{id: "_default", objectID: "defaults"}
+	updatedDefaults := &file{id: "_default", objectID: "updatedDefaults"}
+	overlay := &file{id: "hello.production", objectID: "overlay"}
+	old := &file{objectID: "old"}
+	current := &file{objectID: "new"}
+
+	tests := []struct {
+		old     *file
+		current *file
+		before  *file
+		after   *file
+	}{
+		{
+			old:     nil,
+			current: current,
+			before:  nil,
+			after:   current,
+		},
+		{
+			old:     old,
+			current: nil,
+			before:  old,
+			after:   nil,
+		},
+		{
+			old:     old,
+			current: current,
+			before:  old,
+			after:   current,
+		},
+		{
+			old:     &file{objectID: "same", defaults: defaults},
+			current: &file{objectID: "same", defaults: updatedDefaults},
+			before:  defaults,
+			after:   updatedDefaults,
+		},
+		{
+			old:     &file{objectID: "same", overlay: overlay},
+			current: &file{objectID: "same"},
+			before:  overlay,
+			after:   nil,
+		},
+	}
+
+	for i, tt := range tests {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			before, after := changedFiles(tt.old, tt.current)
+			if before != tt.before {
+				t.Errorf("Unexpected previous file is returned: %+v.", before)
+			}
+
+			if after != tt.after {
+				t.Errorf("Unexpected current file is returned: %+v.", after)
+			}
+		})
+	}
+}
+
+func TestWatcher_lastCommit(t *testing.T) {
+	committedAt := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	tests := []struct {
+		nodes []commit
+		err   error
+	}{
+		{
+			nodes: []commit{
+				{
+					Oid:           "sha",
+					Message:       "Update hello",
+					CommittedDate: githubv4.DateTime{Time: committedAt},
+				},
+			},
+		},
+		{
+			nodes: nil,
+		},
+		{
+			err: errors.New("API error"),
+		},
+	}
+
+	for i, tt := range tests {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			w := &watcher{
+				client: &DummyQuerier{
+					QueryFunc: func(_ context.Context, q interface{}, variables map[string]interface{}) error {
+						if variables["path"] != githubv4.String("config/bot/hello.yml") {
+							t.Errorf("Unexpected path is given: %s.", variables["path"])
+						}
+
+						typed := q.(*commitQuery)
+						typed.Repository.Ref.Target.Commit.History.Nodes = tt.nodes
+						for i := range typed.Repository.Ref.Target.Commit.History.Nodes {
+							node := &typed.Repository.Ref.Target.Commit.History.Nodes[i]
+							node.Author.Name = "oklahomer"
+							node.Author.Email = "oklahomer@example.com"
+						}
+						return tt.err
+					},
+				},
+				config: &Config{Branch: "master"},
+			}
+
+			c, err := w.lastCommit(context.TODO(), "bot", &file{path: "/config/bot/hello.yml"})
+			if len(tt.nodes) == 0 {
+				if err == nil {
+					t.Fatal("Expected error is not returned.")
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("Unexpected error is returned: %s.", err.Error())
+			}
+
+			if c.SHA != "sha" || c.Message != "Update hello" || c.Author != "oklahomer" || c.AuthorEmail != "oklahomer@example.com" {
+				t.Errorf("Unexpected commit is returned: %+v.", c)
+			}
+
+			if !c.CommittedAt.Equal(committedAt) {
+				t.Errorf("Unexpected commit time is returned: %s.", c.CommittedAt)
+			}
+		})
+	}
+}
+
+func TestWatcher_newChange(t *testing.T) {
+	queried := false
+	w := &watcher{
+		client: &DummyQuerier{
+			QueryFunc: func(_ context.Context, q interface{}, _ map[string]interface{}) error {
+				queried = true
+				typed := q.(*commitQuery)
+				typed.Repository.Ref.Target.Commit.History.Nodes = []commit{{Oid: "sha"}}
+				return nil
+			},
+		},
+		config: &Config{Branch: "master"},
+	}
+
+	old := &file{id: "hello", objectID: "old", path: "bot/hello.yml"}
+	current := &file{id: "hello", objectID: "new", path: "bot/hello.yml"}
+
+	t.Run("without commit", func(t *testing.T) {
+		queried = false
+		change := w.newChange(context.TODO(), "bot", "hello", old, current, false)
+
+		if queried {
+			t.Error("Commit must not be fetched.")
+		}
+
+		if change.OldObjectID != "old" || change.NewObjectID != "new" || change.Commit != nil {
+			t.Errorf("Unexpected change is returned: %+v.", change)
+		}
+
+		if change.Diff != "" {
+			t.Errorf("Unexpected diff is returned: %s.", change.Diff)
+		}
+	})
+
+	t.Run("with commit", func(t *testing.T) {
+		change := w.newChange(context.TODO(), "bot", "hello", old, current, true)
+
+		if change.Commit == nil || change.Commit.SHA != "sha" {
+			t.Errorf("Expected commit is not set: %+v.", change.Commit)
+		}
+	})
+
+	t.Run("local", func(t *testing.T) {
+		queried = false
+		local := &file{id: "hello", objectID: "local:abc"}
+		change := w.newChange(context.TODO(), "bot", "hello", old, local, true)
+
+		if queried {
+			t.Error("Commit must not be fetched for a local file.")
+		}
+
+		if change.Commit != nil {
+			t.Errorf("Unexpected commit is set: %+v.", change.Commit)
+		}
+	})
+}
+
+func TestWatcher_WatchChange(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	oid := make(chan string, 2)
+	oid <- "first"
+	oid <- "second"
+	current := ""
+	w := &watcher{
+		client: &DummyQuerier{
+			QueryFunc: func(_ context.Context, q interface{}, _ map[string]interface{}) error {
+				switch typed := q.(type) {
+				case *query:
+					select {
+					case current = <-oid:
+					default:
+					}
+
+					typed.Repository.Object.Tree.Entries = []entry{
+						{
+							Name:   "hello.json",
+							Object: entryObject{Blob: blob{Oid: githubv4.String(current), Text: "{}"}},
+						},
+					}
+
+				case *commitQuery:
+					typed.Repository.Ref.Target.Commit.History.Nodes = []commit{{Oid: "sha"}}
+
+				}
+				return nil
+			},
+		},
+		config: &Config{
+			TimeOut:  100 * time.Millisecond,
+			Interval: 10 * time.Millisecond,
+		},
+		request:      make(chan *request),
+		subscription: make(chan *subscription),
+	}
+	w.started.Store(true)
+	go w.operate(ctx)
+
+	err := w.Read(ctx, "bot", "hello", &struct{}{})
+	if err != nil {
+		t.Fatalf("Unexpected error is returned: %s.", err.Error())
+	}
+
+	called := make(chan *Change, 1)
+	_, err = w.WatchChange(ctx, "bot", "hello", func(_ context.Context, change *Change) {
+		called <- change
+	})
+	if err != nil {
+		t.Fatalf("Unexpected error is returned: %s.", err.Error())
+	}
+
+	select {
+	case change := <-called:
+		if change.BotType != "bot" || change.ID != "hello" {
+			t.Errorf("Unexpected change is passed: %+v.", change)
+		}
+
+		if change.OldObjectID != "first" || change.NewObjectID != "second" {
+			t.Errorf("Unexpected object IDs are passed: %+v.", change)
+		}
+
+		if change.Commit == nil || change.Commit.SHA != "sha" {
+			t.Errorf("Expected commit is not passed: %+v.", change.Commit)
+		}
+
+	case <-time.NewTimer(1 * time.Second).C:
+		t.Error("Callback is not called.")
+
+	}
+}
@@ -0,0 +1,132 @@
+package githubconfig
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/oklahomer/go-sarah/v4"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// commitStatusContext is the prefix of the context of the commit status that tells a change is applied.
+const commitStatusContext = "sarah-config"
+
+// WithCommitStatus sets a successful commit status on the commit that a change is fetched at once the change is applied,
+// so the author of the change is notified that it took effect.
+// The given name identifies the bot, e.g. "bot-xyz" results in "sarah-config/bot-xyz: applied by bot-xyz at ...",
+// so the statuses set by multiple bots sharing the repository do not overwrite each other.
+// This calls GitHub's REST API, which requires WithToken or WithRESTClient, and the token needs the permission to write commit statuses.
+func WithCommitStatus(name string) Option {
+	return func(w *watcher) {
+		w.commitStatusName = name
+	}
+}
+
+// commitStatus is the request body of GitHub's REST API to create a commit status.
+type commitStatus struct {
+	State       string `json:"state"`
+	Context     string `json:"context"`
+	Description string `json:"description"`
+}
+
+// reportApplied sets the commit status, and creates the deployment, for each commit that the applied files among the given ids are fetched at.
+// A file that is refused as invalid is not reported as applied.
+// A commit is reported only once, and the API calls are made asynchronously so a slow call never blocks the refresh.
+func (w *watcher) reportApplied(ctx context.Context, botType sarah.BotType, files map[string]*file, ids []string) {
+	if w.commitStatusName == "" && w.deploymentEnvironment == "" {
+		return
+	}
+
+	for _, id := range ids {
+		f := files[id]
+		if f == nil || f.commitSHA == "" {
+			continue
+		}
+		if f.invalid != nil {
+			// The invalid content of an added file is not applied, and the failure is reported by commentFailure.
+			continue
+		}
+
+		src := f.source
+		if src == nil {
+			src = w.source(botType)
+		}
+		key := fmt.Sprintf("%s/%s@%s", src.Owner, src.Name, f.commitSHA)
+		if w.reportedCommits[key] {
+			continue
+		}
+		if w.reportedCommits == nil {
+			w.reportedCommits = map[string]bool{}
+		}
+		w.reportedCommits[key] = true
+
+		w.callbacks.Add(1)
+		go func(src *Source, sha string) {
+			defer w.callbacks.Done()
+			if w.commitStatusName != "" {
+				err := w.setCommitStatus(ctx, botType, src, sha, time.Now())
+				if err != nil {
+					w.log(botType, "", commitAttr(sha)).Errorf("Failed to set the commit status on %s: %+v", sha, err)
+				}
+			}
+
+			if w.deploymentEnvironment != "" {
+				err := w.createDeployment(ctx, botType, src, sha)
+				if err != nil {
+					w.log(botType, "", commitAttr(sha)).Errorf("Failed to create the deployment of %s: %+v", sha, err)
+				}
+			}
+		}(src, f.commitSHA)
+	}
+}
+
+// setCommitStatus sets a successful commit status on the given commit via the REST API.
+func (w *watcher) setCommitStatus(ctx context.Context, botType sarah.BotType, src *Source, sha string, appliedAt time.Time) error {
+	if w.restClient(src.Owner, src.Name) == nil {
+		return fmt.Errorf("REST client is required to set commit status on %s: give WithToken or WithRESTClient", sha)
+	}
+
+	status := &commitStatus{
+		State:       "success",
+		Context:     fmt.Sprintf("%s/%s", commitStatusContext, w.commitStatusName),
+		Description: fmt.Sprintf("applied by %s at %s", w.commitStatusName, appliedAt.Format(time.RFC3339)),
+	}
+	return w.post(ctx, botType, src, fmt.Sprintf("/repos/%s/%s/statuses/%s", src.Owner, src.Name, sha), status, nil)
+}
+
+// post creates a resource with the given request body via GitHub's REST API, and decodes the created resource into out unless it is nil.
+func (w *watcher) post(ctx context.Context, botType sarah.BotType, src *Source, p string, in interface{}, out interface{}) error {
+	rest := w.restClient(src.Owner, src.Name)
+	body, err := json.Marshal(in)
+	if err != nil {
+		return err
+	}
+
+	url := strings.TrimSuffix(rest.baseURL, "/") + p
+	ctx, cancel := context.WithTimeout(ctx, w.config.TimeOut)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "application/vnd.github+json")
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := rest.httpClient.Do(req)
+	if err != nil {
+		return w.queryError(botType, repositoryName(src.Owner, src.Name), err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusCreated {
+		return w.queryError(botType, repositoryName(src.Owner, src.Name), fmt.Errorf("non-201 Created status code: %s", resp.Status))
+	}
+
+	if out == nil {
+		return nil
+	}
+	return json.NewDecoder(resp.Body).Decode(out)
+}
@@ -0,0 +1,118 @@
+package githubconfig
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestWithCommitStatus(t *testing.T) {
+	w := &watcher{}
+	WithCommitStatus("bot-xyz")(w)
+
+	if w.commitStatusName != "bot-xyz" {
+		t.Errorf("Unexpected name is set: %s.", w.commitStatusName)
+	}
+}
+
+func TestWatcher_setCommitStatus(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost || r.URL.Path != "/repos/oklahomer/config/statuses/sha" {
+			t.Errorf("Unexpected request is made: %s %s.", r.Method, r.URL.Path)
+		}
+
+		status := &commitStatus{}
+		err := json.NewDecoder(r.Body).Decode(status)
+		if err != nil {
+			t.Fatalf("Unexpected error is returned: %s.", err.Error())
+		}
+		expected := commitStatus{
+			State:       "success",
+			Context:     "sarah-config/bot-xyz",
+			Description: "applied by bot-xyz at 2024-01-02T03:04:05Z",
+		}
+		if *status != expected {
+			t.Errorf("Unexpected status is given: %+v.", status)
+		}
+
+		w.WriteHeader(http.StatusCreated)
+	}))
+	defer server.Close()
+
+	w := &watcher{
+		config:           &Config{TimeOut: time.Second},
+		rest:             &restClient{httpClient: server.Client(), baseURL: server.URL},
+		commitStatusName: "bot-xyz",
+	}
+
+	src := &Source{Owner: "oklahomer", Name: "config"}
+	err := w.setCommitStatus(context.TODO(), "bot", src, "sha", time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC))
+	if err != nil {
+		t.Errorf("Unexpected error is returned: %s.", err.Error())
+	}
+}
+
+func TestWatcher_setCommitStatus_error(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusForbidden)
+	}))
+	defer server.Close()
+
+	src := &Source{Owner: "oklahomer", Name: "config"}
+	w := &watcher{
+		config:           &Config{Owner: "oklahomer", Name: "config", TimeOut: time.Second},
+		commitStatusName: "bot-xyz",
+	}
+	err := w.setCommitStatus(context.TODO(), "bot", src, "sha", time.Now())
+	if err == nil {
+		t.Error("Expected error is not returned without REST client.")
+	}
+
+	w.rest = &restClient{httpClient: server.Client(), baseURL: server.URL}
+	err = w.setCommitStatus(context.TODO(), "bot", src, "sha", time.Now())
+	if err == nil {
+		t.Error("Expected error is not returned.")
+	}
+}
+
+func TestWatcher_reportApplied(t *testing.T) {
+	requested := make(chan string, 10)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requested <- r.URL.Path
+		w.WriteHeader(http.StatusCreated)
+	}))
+	defer server.Close()
+
+	w := &watcher{
+		config:           &Config{Owner: "oklahomer", Name: "config", TimeOut: time.Second},
+		rest:             &restClient{httpClient: server.Client(), baseURL: server.URL},
+		commitStatusName: "bot-xyz",
+	}
+
+	files := map[string]*file{
+		"hello":    {id: "hello", commitSHA: "sha"},
+		"guess":    {id: "guess", commitSHA: "sha"},
+		"override": {id: "override"},
+		"team":     {id: "team", commitSHA: "other", source: &Source{Owner: "oklahomer", Name: "team"}},
+		"added":    {id: "added", commitSHA: "invalid", invalid: errors.New("ERROR")},
+	}
+	w.reportApplied(context.TODO(), "bot", files, []string{"hello", "guess", "override", "team", "added", "deleted"})
+	w.reportApplied(context.TODO(), "bot", files, []string{"hello"})
+	w.callbacks.Wait()
+	close(requested)
+
+	if len(requested) != 2 {
+		t.Fatalf("Unexpected number of requests are made: %d.", len(requested))
+	}
+	paths := map[string]bool{}
+	for p := range requested {
+		paths[p] = true
+	}
+	if !paths["/repos/oklahomer/config/statuses/sha"] || !paths["/repos/oklahomer/team/statuses/other"] {
+		t.Errorf("Unexpected requests are made: %v.", paths)
+	}
+}
@@ -0,0 +1,60 @@
+package githubconfig
+
+import (
+	"context"
+	"fmt"
+	"github.com/oklahomer/go-sarah/v4"
+)
+
+// Compose returns a sarah.ConfigWatcher that reads from primary and falls back to fallback when primary fails.
+// A typical setup is to pass the GitHub-based watcher as primary and sarah's file-based watcher as fallback.
+// To build a longer chain, pass another composed watcher as fallback.
+func Compose(primary sarah.ConfigWatcher, fallback sarah.ConfigWatcher) sarah.ConfigWatcher {
+	return &compositeWatcher{
+		primary:  primary,
+		fallback: fallback,
+	}
+}
+
+type compositeWatcher struct {
+	primary  sarah.ConfigWatcher
+	fallback sarah.ConfigWatcher
+}
+
+var _ sarah.ConfigWatcher = (*compositeWatcher)(nil)
+
+func (c *compositeWatcher) Read(ctx context.Context, botType sarah.BotType, id string, out interface{}) error {
+	err := c.primary.Read(ctx, botType, id, out)
+	if err == nil {
+		return nil
+	}
+
+	fallbackErr := c.fallback.Read(ctx, botType, id, out)
+	if fallbackErr == nil {
+		return nil
+	}
+
+	return fmt.Errorf("failed to read configuration from both primary (%s) and fallback: %w", err.Error(), fallbackErr)
+}
+
+// Watch subscribes to both watchers so a change on either side triggers callback.
+// An error is returned only when neither of them accepts the subscription.
+func (c *compositeWatcher) Watch(ctx context.Context, botType sarah.BotType, id string, callback func()) error {
+	err := c.primary.Watch(ctx, botType, id, callback)
+	fallbackErr := c.fallback.Watch(ctx, botType, id, callback)
+	if err != nil && fallbackErr != nil {
+		return fmt.Errorf("failed to watch configuration on both primary (%s) and fallback: %w", err.Error(), fallbackErr)
+	}
+
+	return nil
+}
+
+func (c *compositeWatcher) Unwatch(botType sarah.BotType) error {
+	err := c.primary.Unwatch(botType)
+	fallbackErr := c.fallback.Unwatch(botType)
+	if err != nil {
+		return err
+	}
+
+	return fallbackErr
+}
@@ -0,0 +1,184 @@
+package githubconfig
+
+import (
+	"context"
+	"errors"
+	"github.com/oklahomer/go-sarah/v4"
+	"strconv"
+	"testing"
+)
+
+type DummyConfigWatcher struct {
+	ReadFunc    func(ctx context.Context, botType sarah.BotType, id string, out interface{}) error
+	WatchFunc   func(ctx context.Context, botType sarah.BotType, id string, callback func()) error
+	UnwatchFunc func(botType sarah.BotType) error
+}
+
+var _ sarah.ConfigWatcher = (*DummyConfigWatcher)(nil)
+
+func (d *DummyConfigWatcher) Read(ctx context.Context, botType sarah.BotType, id string, out interface{}) error {
+	return d.ReadFunc(ctx, botType, id, out)
+}
+
+func (d *DummyConfigWatcher) Watch(ctx context.Context, botType sarah.BotType, id string, callback func()) error {
+	return d.WatchFunc(ctx, botType, id, callback)
+}
+
+func (d *DummyConfigWatcher) Unwatch(botType sarah.BotType) error {
+	return d.UnwatchFunc(botType)
+}
+
+func TestCompose(t *testing.T) {
+	primary := &DummyConfigWatcher{}
+	fallback := &DummyConfigWatcher{}
+
+	w := Compose(primary, fallback)
+
+	typed, ok := w.(*compositeWatcher)
+	if !ok {
+		t.Fatalf("Unexpected typed value is returned: %T", w)
+	}
+
+	if typed.primary != primary {
+		t.Error("Given primary watcher is not set.")
+	}
+
+	if typed.fallback != fallback {
+		t.Error("Given fallback watcher is not set.")
+	}
+}
+
+func TestCompositeWatcher_Read(t *testing.T) {
+	tests := []struct {
+		primary  error
+		fallback error
+		called   bool
+		error    bool
+	}{
+		{
+			primary: nil,
+			called:  false,
+			error:   false,
+		},
+		{
+			primary:  &sarah.ConfigNotFoundError{},
+			fallback: nil,
+			called:   true,
+			error:    false,
+		},
+		{
+			primary:  errors.New("primary"),
+			fallback: errors.New("fallback"),
+			called:   true,
+			error:    true,
+		},
+	}
+
+	for i, tt := range tests {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			called := false
+			w := &compositeWatcher{
+				primary: &DummyConfigWatcher{
+					ReadFunc: func(_ context.Context, _ sarah.BotType, _ string, _ interface{}) error {
+						return tt.primary
+					},
+				},
+				fallback: &DummyConfigWatcher{
+					ReadFunc: func(_ context.Context, _ sarah.BotType, _ string, _ interface{}) error {
+						called = true
+						return tt.fallback
+					},
+				},
+			}
+
+			err := w.Read(context.Background(), "bot", "id", &struct{}{})
+
+			if called != tt.called {
+				t.Errorf("Unexpected fallback call: %t.", called)
+			}
+
+			if tt.error && !errors.Is(err, tt.fallback) {
+				t.Errorf("Expected error is not returned: %+v.", err)
+			}
+
+			if !tt.error && err != nil {
+				t.Errorf("Unexpected error is returned: %s.", err.Error())
+			}
+		})
+	}
+}
+
+func TestCompositeWatcher_Watch(t *testing.T) {
+	tests := []struct {
+		primary  error
+		fallback error
+		error    bool
+	}{
+		{
+			error: false,
+		},
+		{
+			primary: errors.New("primary"),
+			error:   false,
+		},
+		{
+			fallback: errors.New("fallback"),
+			error:    false,
+		},
+		{
+			primary:  errors.New("primary"),
+			fallback: errors.New("fallback"),
+			error:    true,
+		},
+	}
+
+	for i, tt := range tests {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			w := &compositeWatcher{
+				primary: &DummyConfigWatcher{
+					WatchFunc: func(_ context.Context, _ sarah.BotType, _ string, _ func()) error {
+						return tt.primary
+					},
+				},
+				fallback: &DummyConfigWatcher{
+					WatchFunc: func(_ context.Context, _ sarah.BotType, _ string, _ func()) error {
+						return tt.fallback
+					},
+				},
+			}
+
+			err := w.Watch(context.Background(), "bot", "id", func() {})
+
+			if tt.error && err == nil {
+				t.Error("Expected error is not returned.")
+			}
+
+			if !tt.error && err != nil {
+				t.Errorf("Unexpected error is returned: %s.", err.Error())
+			}
+		})
+	}
+}
+
+func TestCompositeWatcher_Unwatch(t *testing.T) {
+	var botType sarah.BotType = "bot"
+	var unwatched []sarah.BotType
+	unwatch := func(b sarah.BotType) error {
+		unwatched = append(unwatched, b)
+		return nil
+	}
+	w := &compositeWatcher{
+		primary:  &DummyConfigWatcher{UnwatchFunc: unwatch},
+		fallback: &DummyConfigWatcher{UnwatchFunc: unwatch},
+	}
+
+	err := w.Unwatch(botType)
+
+	if err != nil {
+		t.Fatalf("Unexpected error is returned: %s.", err.Error())
+	}
+
+	if len(unwatched) != 2 {
+		t.Errorf("Both watchers must be unwatched: %d.", len(unwatched))
+	}
+}
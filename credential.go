@@ -0,0 +1,167 @@
+package githubconfig
+
+import (
+	"context"
+	"fmt"
+	"github.com/shurcooL/githubv4"
+	"golang.org/x/oauth2"
+	"net/http"
+	"regexp"
+	"strings"
+)
+
+// credentialPatterns match the credentials that may appear in an error message regardless of the given token,
+// such as an echoed Authorization header, a token in a URL query, and a GitHub token with its well-known prefix.
+// The first group of each pattern, if any, is kept and the rest is masked.
+var credentialPatterns = []*regexp.Regexp{
+	regexp.MustCompile(`(?i)(authorization["']?\s*[:=]\s*["']?(?:bearer|token|basic)?\s*)[^\s"',}]+`),
+	regexp.MustCompile(`(?i)([?&](?:access_token|token|client_secret)=)[^&\s"']+`),
+	regexp.MustCompile(`()\b(?:gh[pousr]_[A-Za-z0-9]{20,}|github_pat_[A-Za-z0-9_]{20,})`),
+}
+
+// scrubCredentials masks the given secrets and anything that looks like a credential in the text.
+func scrubCredentials(text string, secrets ...string) string {
+	for _, secret := range secrets {
+		if secret != "" {
+			text = strings.ReplaceAll(text, secret, redacted)
+		}
+	}
+
+	for _, pattern := range credentialPatterns {
+		text = pattern.ReplaceAllString(text, "${1}"+redacted)
+	}
+	return text
+}
+
+// scrubError returns the error with the credentials in its message masked, or the error as is when the message contains none.
+func scrubError(err error, secrets ...string) error {
+	message := scrubCredentials(err.Error(), secrets...)
+	if message == err.Error() {
+		return err
+	}
+	return &redactedError{message: message, err: err}
+}
+
+// scrubbingTransport masks the credentials in the errors the underlying transport returns,
+// so a misbehaving transport never leaks the token through a wrapped error string.
+type scrubbingTransport struct {
+	base    http.RoundTripper
+	secrets []string
+}
+
+var _ http.RoundTripper = (*scrubbingTransport)(nil)
+
+// RoundTrip executes the request with the underlying transport.
+// A panic in the underlying transport is returned as an error with its credentials masked instead of crashing the bot with the token in its message.
+func (t *scrubbingTransport) RoundTrip(req *http.Request) (resp *http.Response, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			resp = nil
+			err = fmt.Errorf("transport panicked: %s", scrubCredentials(fmt.Sprint(r), t.secrets...))
+		}
+	}()
+
+	base := t.base
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	resp, err = base.RoundTrip(req)
+	if err != nil {
+		return nil, scrubError(err, t.secrets...)
+	}
+	return resp, nil
+}
+
+// WithRepositoryToken authenticates the requests to the given repository, in the form of "owner/name", with the given token
+// instead of the one given by WithToken, so the bot does not need a single credential with access to every aggregated repository.
+func WithRepositoryToken(ctx context.Context, repository string, token string) Option {
+	return func(w *watcher) {
+		src := oauth2.StaticTokenSource(
+			&oauth2.Token{AccessToken: token},
+		)
+		httpClient := oauth2.NewClient(ctx, src)
+		httpClient.Transport = &scrubbingTransport{base: httpClient.Transport, secrets: []string{token}}
+		w.credentials = append(w.credentials, token)
+		WithRepositoryHTTPClient(repository, httpClient)(w)
+	}
+}
+
+// WithRepositoryHTTPClient sends the requests to the given repository, in the form of "owner/name", with the given HTTP client
+// that is responsible for authentication, e.g. with the transport of a GitHub App installation that is granted access to the repository.
+// The GraphQL requests are sent to the endpoint given by WithGraphQLURL or to github.com,
+// and the REST API requests to the base URL given by WithRESTClient if any.
+func WithRepositoryHTTPClient(repository string, httpClient *http.Client) Option {
+	return func(w *watcher) {
+		if w.repositoryClients == nil {
+			w.repositoryClients = map[string]*http.Client{}
+		}
+		w.repositoryClients[strings.ToLower(repository)] = httpClient
+	}
+}
+
+// routingQuerier sends each query to the client of the queried repository, which is told by the "owner" and "name" variables.
+// A query to any other repository, or a query that is not specific to a repository, is sent to the default client.
+type routingQuerier struct {
+	fallback querier
+	clients  map[string]querier
+}
+
+var _ querier = (*routingQuerier)(nil)
+
+// Query sends the query with the client of the queried repository.
+func (r *routingQuerier) Query(ctx context.Context, q interface{}, variables map[string]interface{}) error {
+	owner, _ := variables["owner"].(githubv4.String)
+	name, _ := variables["name"].(githubv4.String)
+	client, ok := r.clients[strings.ToLower(fmt.Sprintf("%s/%s", owner, name))]
+	if !ok {
+		client = r.fallback
+	}
+	return client.Query(ctx, q, variables)
+}
+
+// routeQueries makes the queries to each repository given by WithRepositoryToken or WithRepositoryHTTPClient sent with its own client.
+func (w *watcher) routeQueries() {
+	if len(w.repositoryClients) == 0 {
+		return
+	}
+
+	clients := map[string]querier{}
+	for repository, httpClient := range w.repositoryClients {
+		clients[repository] = w.newGraphQLClient(httpClient)
+	}
+	w.client = &routingQuerier{
+		fallback: w.client,
+		clients:  clients,
+	}
+}
+
+// WithGraphQLURL sets the endpoint of GitHub's GraphQL API such as "https://example.com/api/graphql" for GitHub Enterprise Server.
+// This applies to the clients the watcher builds with the HTTP clients given by WithToken, WithRepositoryToken and WithRepositoryHTTPClient,
+// while the githubv4.Client given by WithClient is used as is.
+func WithGraphQLURL(url string) Option {
+	return func(w *watcher) {
+		w.graphqlURL = url
+	}
+}
+
+// newGraphQLClient returns a GraphQL API client that sends the requests with the given HTTP client to the endpoint given by WithGraphQLURL or to github.com.
+func (w *watcher) newGraphQLClient(httpClient *http.Client) *githubv4.Client {
+	if w.graphqlURL != "" {
+		return githubv4.NewEnterpriseClient(w.graphqlURL, httpClient)
+	}
+	return githubv4.NewClient(httpClient)
+}
+
+// restClient returns the REST API client for the given repository, or nil when no HTTP client is given.
+func (w *watcher) restClient(owner string, name string) *restClient {
+	httpClient, ok := w.repositoryClients[strings.ToLower(fmt.Sprintf("%s/%s", owner, name))]
+	if !ok {
+		return w.rest
+	}
+
+	baseURL := defaultRESTURL
+	if w.rest != nil {
+		baseURL = w.rest.baseURL
+	}
+	return &restClient{httpClient: httpClient, baseURL: baseURL}
+}
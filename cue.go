@@ -0,0 +1,24 @@
+package githubconfig
+
+import (
+	"cuelang.org/go/cue"
+	"cuelang.org/go/cue/cuecontext"
+	"fmt"
+)
+
+// decodeCUE evaluates the CUE content, enforces its embedded constraints, and decodes the concrete value into out.
+// Fields are mapped with json struct tags.
+func decodeCUE(f *file, out interface{}) error {
+	v := cuecontext.New().CompileString(f.content, cue.Filename(f.fileName))
+	if err := v.Err(); err != nil {
+		return fmt.Errorf("failed to evaluate %s: %w", f.fileName, err)
+	}
+
+	// Require all values to be concrete so an unresolved constraint such as "int & >0" is not silently decoded as a zero value.
+	err := v.Validate(cue.Concrete(true))
+	if err != nil {
+		return fmt.Errorf("failed to validate %s: %w", f.fileName, err)
+	}
+
+	return v.Decode(out)
+}
@@ -0,0 +1,78 @@
+package githubconfig
+
+import (
+	"context"
+	"fmt"
+	"github.com/oklahomer/go-sarah/v4"
+)
+
+// Decrypter decrypts the content of an encrypted configuration file.
+// Implement this to plug AWS KMS, GCP KMS or HSM-backed decryption without this package depending on their SDKs.
+type Decrypter interface {
+	Decrypt(ctx context.Context, ciphertext []byte) ([]byte, error)
+}
+
+// WithDecrypter decrypts the configuration files matching the given glob pattern such as "secrets/*.yml" with the given Decrypter
+// right after they are fetched. See WithInclude for the pattern syntax.
+// When multiple patterns match a file, the Decrypter given first is used.
+func WithDecrypter(pattern string, decrypter Decrypter) Option {
+	return func(w *watcher) {
+		w.decrypters = append(w.decrypters, &patternDecrypter{pattern: pattern, decrypter: decrypter})
+	}
+}
+
+type patternDecrypter struct {
+	pattern   string
+	decrypter Decrypter
+}
+
+// validateDecrypters validates the patterns given by WithDecrypter.
+func (w *watcher) validateDecrypters() error {
+	for _, d := range w.decrypters {
+		err := globs{include: []string{d.pattern}}.validate()
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// decryptContent returns the file with its content decrypted when its name matches any of the patterns given by WithDecrypter,
+// or the file as is otherwise.
+// This is called right before decoding, so the fetched file keeps the ciphertext and the plaintext never appears in a Change,
+// the FS, ReadRaw or the exported files.
+// The plaintext is cached by the repository and the object ID of the blob, so the Decrypter is not called on every decode.
+func (w *watcher) decryptContent(botType sarah.BotType, f *file) (*file, error) {
+	if f.path == "" {
+		// A local override file is stored in plaintext.
+		return f, nil
+	}
+
+	for _, d := range w.decrypters {
+		if !glob(d.pattern, f.fileName) {
+			continue
+		}
+
+		src := f.source
+		if src == nil {
+			src = w.source(botType)
+		}
+		key := fmt.Sprintf("decrypted:%s/%s:%s", src.Owner, src.Name, f.objectID)
+		plaintext, ok := w.blobs.get(key)
+		if !ok {
+			ctx, cancel := context.WithTimeout(context.Background(), w.config.TimeOut)
+			var err error
+			plaintext, err = d.decrypter.Decrypt(ctx, []byte(f.content))
+			cancel()
+			if err != nil {
+				return nil, fmt.Errorf("failed to decrypt %s: %w", f.fileName, err)
+			}
+			w.blobs.put(key, plaintext)
+		}
+
+		decrypted := *f
+		decrypted.content = string(plaintext)
+		return &decrypted, nil
+	}
+	return f, nil
+}
@@ -0,0 +1,167 @@
+package githubconfig
+
+import (
+	"context"
+	"errors"
+	"github.com/shurcooL/githubv4"
+	"strings"
+	"testing"
+	"time"
+)
+
+type DummyDecrypter struct {
+	DecryptFunc func(ctx context.Context, ciphertext []byte) ([]byte, error)
+}
+
+func (d *DummyDecrypter) Decrypt(ctx context.Context, ciphertext []byte) ([]byte, error) {
+	return d.DecryptFunc(ctx, ciphertext)
+}
+
+func TestWithDecrypter(t *testing.T) {
+	decrypter := &DummyDecrypter{}
+	w := &watcher{}
+	WithDecrypter("secrets/*.yml", decrypter)(w)
+
+	if len(w.decrypters) != 1 || w.decrypters[0].pattern != "secrets/*.yml" || w.decrypters[0].decrypter != decrypter {
+		t.Errorf("Unexpected decrypters are set: %+v.", w.decrypters)
+	}
+}
+
+func TestNew_invalidDecrypterPattern(t *testing.T) {
+	_, err := New(&Config{}, func(w *watcher) {
+		w.client = &DummyQuerier{}
+	}, WithDecrypter("[", &DummyDecrypter{}))
+	if err == nil {
+		t.Error("Expected error is not returned.")
+	}
+}
+
+func TestWatcher_get_withDecrypter(t *testing.T) {
+	tokenID := "token"
+	decrypted := 0
+	w := &watcher{
+		client: &DummyQuerier{
+			QueryFunc: func(_ context.Context, q interface{}, _ map[string]interface{}) error {
+				typed := q.(*query)
+				typed.Repository.Head.Commit.Oid = "sha"
+				typed.Repository.Object.Tree.Entries = []entry{
+					{Name: "hello.yml", Object: entryObject{Blob: blob{Oid: "hello", Text: "name: hello\n"}}},
+					{Name: "token.yml", Object: entryObject{Blob: blob{Oid: githubv4.String(tokenID), Text: "ciphertext"}}},
+				}
+				return nil
+			},
+		},
+		config: &Config{
+			BaseDir: "config",
+			Branch:  "master",
+			TimeOut: time.Second,
+		},
+		decrypters: []*patternDecrypter{
+			{
+				pattern: "token.yml",
+				decrypter: &DummyDecrypter{
+					DecryptFunc: func(_ context.Context, ciphertext []byte) ([]byte, error) {
+						decrypted++
+						if string(ciphertext) != "ciphertext" {
+							t.Errorf("Unexpected ciphertext is given: %s.", string(ciphertext))
+						}
+						return []byte("token: secret\n"), nil
+					},
+				},
+			},
+		},
+	}
+
+	files, err := w.get(context.TODO(), "bot")
+	if err != nil {
+		t.Fatalf("Unexpected error is returned: %s.", err.Error())
+	}
+
+	if files["token"].content != "ciphertext" {
+		t.Errorf("Plaintext is held by the fetched file: %s.", files["token"].content)
+	}
+
+	config := &struct {
+		Token string `yaml:"token"`
+	}{}
+	err = w.read(context.TODO(), "bot", files["token"], config)
+	if err != nil {
+		t.Fatalf("Unexpected error is returned: %s.", err.Error())
+	}
+	if config.Token != "secret" {
+		t.Errorf("Matched file is not decrypted: %+v.", config)
+	}
+
+	err = w.read(context.TODO(), "bot", files["hello"], &struct{}{})
+	if err != nil {
+		t.Fatalf("Unexpected error is returned: %s.", err.Error())
+	}
+
+	files, err = w.get(context.TODO(), "bot")
+	if err != nil {
+		t.Fatalf("Unexpected error is returned: %s.", err.Error())
+	}
+	err = w.read(context.TODO(), "bot", files["token"], config)
+	if err != nil {
+		t.Fatalf("Unexpected error is returned: %s.", err.Error())
+	}
+	if decrypted != 1 {
+		t.Errorf("Unchanged blob is not served from the cache: %d.", decrypted)
+	}
+
+	tokenID = "rotated"
+	expected := errors.New("access denied")
+	w.decrypters[0].decrypter = &DummyDecrypter{
+		DecryptFunc: func(_ context.Context, _ []byte) ([]byte, error) {
+			return nil, expected
+		},
+	}
+	files, err = w.get(context.TODO(), "bot")
+	if err != nil {
+		t.Fatalf("Unexpected error is returned: %s.", err.Error())
+	}
+	err = files["token"].invalid
+	if !errors.Is(err, expected) || !strings.Contains(err.Error(), "token.yml") {
+		t.Errorf("Expected error is not returned: %#v.", err)
+	}
+}
+
+func TestWatcher_decryptContent_sources(t *testing.T) {
+	w := &watcher{
+		config: &Config{Owner: "oklahomer", Name: "config", TimeOut: time.Second},
+		decrypters: []*patternDecrypter{
+			{
+				pattern: "*.yml",
+				decrypter: &DummyDecrypter{
+					DecryptFunc: func(_ context.Context, ciphertext []byte) ([]byte, error) {
+						return []byte("plain " + string(ciphertext)), nil
+					},
+				},
+			},
+		},
+	}
+
+	// Same file names in different repositories must not share the plaintext.
+	base := &file{fileName: "token.yml", path: "config/bot/token.yml", objectID: "abc", content: "base"}
+	layered := &file{fileName: "token.yml", path: "config/bot/token.yml", objectID: "def", content: "layer", source: &Source{Owner: "oklahomer", Name: "layer"}}
+	for i := 0; i < 2; i++ {
+		for _, f := range []*file{base, layered} {
+			decrypted, err := w.decryptContent("bot", f)
+			if err != nil {
+				t.Fatalf("Unexpected error is returned: %s.", err.Error())
+			}
+			if decrypted.content != "plain "+f.content {
+				t.Errorf("Unexpected plaintext is returned: %s.", decrypted.content)
+			}
+		}
+	}
+
+	local := &file{fileName: "token.yml", objectID: "local", content: "token: local\n"}
+	decrypted, err := w.decryptContent("bot", local)
+	if err != nil {
+		t.Fatalf("Unexpected error is returned: %s.", err.Error())
+	}
+	if decrypted.content != local.content {
+		t.Errorf("Local override file is decrypted: %s.", decrypted.content)
+	}
+}
@@ -0,0 +1,66 @@
+package githubconfig
+
+import (
+	"context"
+	"fmt"
+	"github.com/oklahomer/go-sarah/v4"
+)
+
+// deploymentTask is the task of the deployments the watcher creates, which distinguishes them from the deployments of code.
+const deploymentTask = "deploy:config"
+
+// WithDeployment creates a successful GitHub Deployment to the given environment, typically the bot's name,
+// on the commit that a change is fetched at once the change is applied,
+// so the rollouts of the configuration appear in the repository's deployment timeline alongside the code deployments.
+// This calls GitHub's REST API, which requires WithToken or WithRESTClient, and the token needs the permission to write deployments.
+func WithDeployment(environment string) Option {
+	return func(w *watcher) {
+		w.deploymentEnvironment = environment
+	}
+}
+
+// deployment is the request body of GitHub's REST API to create a deployment.
+type deployment struct {
+	Ref         string `json:"ref"`
+	Task        string `json:"task"`
+	Environment string `json:"environment"`
+	Description string `json:"description"`
+	AutoMerge   bool   `json:"auto_merge"`
+	// RequiredContexts is empty to skip the commit status checks, which the configuration does not need to pass to be applied.
+	RequiredContexts []string `json:"required_contexts"`
+}
+
+// deploymentStatus is the request body of GitHub's REST API to create a deployment status.
+type deploymentStatus struct {
+	State       string `json:"state"`
+	Environment string `json:"environment"`
+	Description string `json:"description"`
+}
+
+// createDeployment creates a deployment of the given commit and marks it successful via the REST API.
+func (w *watcher) createDeployment(ctx context.Context, botType sarah.BotType, src *Source, sha string) error {
+	if w.restClient(src.Owner, src.Name) == nil {
+		return fmt.Errorf("REST client is required to create deployment of %s: give WithToken or WithRESTClient", sha)
+	}
+
+	created := &struct {
+		ID int64 `json:"id"`
+	}{}
+	err := w.post(ctx, botType, src, fmt.Sprintf("/repos/%s/%s/deployments", src.Owner, src.Name), &deployment{
+		Ref:              sha,
+		Task:             deploymentTask,
+		Environment:      w.deploymentEnvironment,
+		Description:      fmt.Sprintf("configuration for %s", botType),
+		AutoMerge:        false,
+		RequiredContexts: []string{},
+	}, created)
+	if err != nil {
+		return err
+	}
+
+	return w.post(ctx, botType, src, fmt.Sprintf("/repos/%s/%s/deployments/%d/statuses", src.Owner, src.Name, created.ID), &deploymentStatus{
+		State:       "success",
+		Environment: w.deploymentEnvironment,
+		Description: fmt.Sprintf("applied by %s", w.deploymentEnvironment),
+	}, nil)
+}
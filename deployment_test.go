@@ -0,0 +1,98 @@
+package githubconfig
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestWithDeployment(t *testing.T) {
+	w := &watcher{}
+	WithDeployment("bot-xyz")(w)
+
+	if w.deploymentEnvironment != "bot-xyz" {
+		t.Errorf("Unexpected environment is set: %s.", w.deploymentEnvironment)
+	}
+}
+
+func TestWatcher_createDeployment(t *testing.T) {
+	var statused bool
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/repos/oklahomer/config/deployments":
+			d := &deployment{}
+			err := json.NewDecoder(r.Body).Decode(d)
+			if err != nil {
+				t.Fatalf("Unexpected error is returned: %s.", err.Error())
+			}
+			if d.Ref != "sha" || d.Environment != "bot-xyz" || d.Task != deploymentTask || d.RequiredContexts == nil {
+				t.Errorf("Unexpected deployment is given: %+v.", d)
+			}
+			w.WriteHeader(http.StatusCreated)
+			_, _ = w.Write([]byte(`{"id": 123}`))
+
+		case "/repos/oklahomer/config/deployments/123/statuses":
+			status := &deploymentStatus{}
+			err := json.NewDecoder(r.Body).Decode(status)
+			if err != nil {
+				t.Fatalf("Unexpected error is returned: %s.", err.Error())
+			}
+			if status.State != "success" || status.Environment != "bot-xyz" {
+				t.Errorf("Unexpected status is given: %+v.", status)
+			}
+			statused = true
+			w.WriteHeader(http.StatusCreated)
+
+		default:
+			t.Errorf("Unexpected request is made: %s.", r.URL.Path)
+			w.WriteHeader(http.StatusNotFound)
+
+		}
+	}))
+	defer server.Close()
+
+	w := &watcher{
+		config:                &Config{TimeOut: time.Second},
+		rest:                  &restClient{httpClient: server.Client(), baseURL: server.URL},
+		deploymentEnvironment: "bot-xyz",
+	}
+
+	err := w.createDeployment(context.TODO(), "bot", &Source{Owner: "oklahomer", Name: "config"}, "sha")
+	if err != nil {
+		t.Fatalf("Unexpected error is returned: %s.", err.Error())
+	}
+
+	if !statused {
+		t.Error("Deployment status is not created.")
+	}
+}
+
+func TestWatcher_createDeployment_error(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/repos/oklahomer/config/deployments" {
+			t.Errorf("Status must not be created for a failed deployment: %s.", r.URL.Path)
+		}
+		w.WriteHeader(http.StatusConflict)
+	}))
+	defer server.Close()
+
+	w := &watcher{
+		config:                &Config{Owner: "oklahomer", Name: "config", TimeOut: time.Second},
+		deploymentEnvironment: "bot-xyz",
+	}
+	src := &Source{Owner: "oklahomer", Name: "config"}
+
+	err := w.createDeployment(context.TODO(), "bot", src, "sha")
+	if err == nil {
+		t.Error("Expected error is not returned without REST client.")
+	}
+
+	w.rest = &restClient{httpClient: server.Client(), baseURL: server.URL}
+	err = w.createDeployment(context.TODO(), "bot", src, "sha")
+	if err == nil {
+		t.Error("Expected error is not returned.")
+	}
+}
@@ -0,0 +1,261 @@
+package githubconfig
+
+import (
+	"fmt"
+	"strings"
+)
+
+const (
+	// defaultMaxDiffSize is the default maximum size of Change.Diff in bytes.
+	defaultMaxDiffSize = 16 * 1024
+	// diffContext is the number of unchanged lines shown around each change.
+	diffContext = 3
+	// maxDiffEdits limits the number of changed lines a diff is computed for.
+	// The computation time is proportional to the line counts multiplied by this, and the memory to the square of this.
+	maxDiffEdits = 1000
+)
+
+// diffOp is a single line in an edit script.
+type diffOp struct {
+	kind byte // ' ', '-' or '+'
+	line string
+}
+
+// unifiedDiff returns the unified diff between the given files and tells if the diff is truncated.
+// Either of the files is nil when the file is added or removed.
+// A diff longer than maxSize bytes is truncated, and a diff that changes more than maxDiffEdits lines is omitted.
+func unifiedDiff(old *file, current *file, maxSize int) (string, bool) {
+	oldName, newName := "/dev/null", "/dev/null"
+	var oldLines, newLines []string
+	if old != nil {
+		oldName = "a/" + diffName(old)
+		oldLines = splitLines(old.content)
+	}
+	if current != nil {
+		newName = "b/" + diffName(current)
+		newLines = splitLines(current.content)
+	}
+
+	ops, ok := editScript(oldLines, newLines)
+	if !ok {
+		return "", true
+	}
+	hunks := diffHunks(ops)
+	if len(hunks) == 0 {
+		return "", false
+	}
+
+	b := &strings.Builder{}
+	fmt.Fprintf(b, "--- %s\n+++ %s\n", oldName, newName)
+	for _, h := range hunks {
+		b.WriteString(h)
+	}
+
+	diff := b.String()
+	if maxSize > 0 && len(diff) > maxSize {
+		// Cut at a line boundary so the truncated diff is still readable.
+		cut := strings.LastIndex(diff[:maxSize], "\n") + 1
+		return diff[:cut], true
+	}
+	return diff, false
+}
+
+// diffName returns the name of the file to show in the diff header.
+func diffName(f *file) string {
+	if f.path != "" {
+		return strings.TrimPrefix(f.path, "/")
+	}
+	return f.fileName
+}
+
+func splitLines(content string) []string {
+	if content == "" {
+		return nil
+	}
+	return strings.Split(strings.TrimSuffix(content, "\n"), "\n")
+}
+
+// editScript returns the shortest edit script that turns a into b, and tells if the script is computed.
+// The script is not computed when more than maxDiffEdits lines are changed.
+func editScript(a []string, b []string) ([]diffOp, bool) {
+	// Lines common to both ends are not part of the search.
+	prefix := 0
+	for prefix < len(a) && prefix < len(b) && a[prefix] == b[prefix] {
+		prefix++
+	}
+	suffix := 0
+	for suffix < len(a)-prefix && suffix < len(b)-prefix && a[len(a)-1-suffix] == b[len(b)-1-suffix] {
+		suffix++
+	}
+
+	middle, ok := myers(a[prefix:len(a)-suffix], b[prefix:len(b)-suffix])
+	if !ok {
+		return nil, false
+	}
+
+	ops := make([]diffOp, 0, prefix+len(middle)+suffix)
+	for _, line := range a[:prefix] {
+		ops = append(ops, diffOp{kind: ' ', line: line})
+	}
+	ops = append(ops, middle...)
+	for _, line := range a[len(a)-suffix:] {
+		ops = append(ops, diffOp{kind: ' ', line: line})
+	}
+	return ops, true
+}
+
+// myers returns the shortest edit script that turns a into b with Myers' O((N+M)D) algorithm.
+// See "An O(ND) Difference Algorithm and Its Variations" by Eugene W. Myers.
+func myers(a []string, b []string) ([]diffOp, bool) {
+	n, m := len(a), len(b)
+	limit := min(n+m, maxDiffEdits)
+
+	// v[offset+k] is the furthest x reached on the diagonal k, where y = x - k.
+	offset := limit + 1
+	v := make([]int, 2*limit+3)
+	// trace holds v[k] for -d-1 <= k <= d+1 as of the beginning of each d to backtrack the path.
+	var trace [][]int
+	found := false
+	for d := 0; d <= limit && !found; d++ {
+		trace = append(trace, append([]int(nil), v[offset-d-1:offset+d+2]...))
+		for k := -d; k <= d; k += 2 {
+			var x int
+			if k == -d || (k != d && v[offset+k-1] < v[offset+k+1]) {
+				x = v[offset+k+1] // Insertion
+			} else {
+				x = v[offset+k-1] + 1 // Deletion
+			}
+			y := x - k
+			for x < n && y < m && a[x] == b[y] {
+				x++
+				y++
+			}
+			v[offset+k] = x
+			if x >= n && y >= m {
+				found = true
+				break
+			}
+		}
+	}
+	if !found {
+		return nil, false
+	}
+
+	// Backtrack from the end, so the edit script is built in reverse.
+	var ops []diffOp
+	x, y := n, m
+	for d := len(trace) - 1; d >= 0; d-- {
+		v := trace[d]
+		k := x - y
+		var prevK int
+		if k == -d || (k != d && v[k-1+d+1] < v[k+1+d+1]) {
+			prevK = k + 1
+		} else {
+			prevK = k - 1
+		}
+		prevX := v[prevK+d+1]
+		prevY := prevX - prevK
+
+		for x > prevX && y > prevY {
+			x--
+			y--
+			ops = append(ops, diffOp{kind: ' ', line: a[x]})
+		}
+		if d > 0 {
+			if x == prevX {
+				y--
+				ops = append(ops, diffOp{kind: '+', line: b[y]})
+			} else {
+				x--
+				ops = append(ops, diffOp{kind: '-', line: a[x]})
+			}
+		}
+		x, y = prevX, prevY
+	}
+
+	for i, j := 0, len(ops)-1; i < j; i, j = i+1, j-1 {
+		ops[i], ops[j] = ops[j], ops[i]
+	}
+	return ops, true
+}
+
+// diffHunks groups the edit script into hunks with surrounding context lines.
+func diffHunks(ops []diffOp) []string {
+	var hunks []string
+	oldLine, newLine := 0, 0 // Number of lines consumed before ops[i]
+	i := 0
+	for i < len(ops) {
+		if ops[i].kind == ' ' {
+			oldLine++
+			newLine++
+			i++
+			continue
+		}
+
+		// Include the preceding context lines.
+		start := i - diffContext
+		if start < 0 {
+			start = 0
+		}
+		oldStart := oldLine - (i - start)
+		newStart := newLine - (i - start)
+
+		// Extend the hunk until more than twice the context of unchanged lines follow.
+		end := i
+		for end < len(ops) {
+			if ops[end].kind != ' ' {
+				end++
+				continue
+			}
+
+			next := end
+			for next < len(ops) && ops[next].kind == ' ' {
+				next++
+			}
+			if next == len(ops) || next-end > 2*diffContext {
+				end += min(diffContext, next-end)
+				break
+			}
+			end = next
+		}
+
+		b := &strings.Builder{}
+		oldCount, newCount := 0, 0
+		for _, op := range ops[start:end] {
+			if op.kind != '+' {
+				oldCount++
+			}
+			if op.kind != '-' {
+				newCount++
+			}
+			b.WriteByte(op.kind)
+			b.WriteString(op.line)
+			b.WriteByte('\n')
+		}
+		hunks = append(hunks, fmt.Sprintf("@@ -%s +%s @@\n", hunkRange(oldStart, oldCount), hunkRange(newStart, newCount))+b.String())
+
+		for _, op := range ops[i:end] {
+			if op.kind != '+' {
+				oldLine++
+			}
+			if op.kind != '-' {
+				newLine++
+			}
+		}
+		i = end
+	}
+
+	return hunks
+}
+
+// hunkRange returns the range of a hunk header.
+// start is the number of lines preceding the hunk, so an empty range refers to the line before the hunk as the unified format requires.
+func hunkRange(start int, count int) string {
+	if count == 0 {
+		return fmt.Sprintf("%d,0", start)
+	}
+	if count == 1 {
+		return fmt.Sprintf("%d", start+1)
+	}
+	return fmt.Sprintf("%d,%d", start+1, count)
+}
@@ -0,0 +1,186 @@
+package githubconfig
+
+import (
+	"math/rand"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func TestWithMaxDiffSize(t *testing.T) {
+	w := &watcher{}
+	WithMaxDiffSize(100)(w)
+
+	if w.maxDiffSize != 100 {
+		t.Errorf("Expected size is not set: %d.", w.maxDiffSize)
+	}
+}
+
+func TestUnifiedDiff(t *testing.T) {
+	lines := func(from int, to int) string {
+		b := &strings.Builder{}
+		for i := from; i <= to; i++ {
+			b.WriteString("line" + strconv.Itoa(i) + "\n")
+		}
+		return b.String()
+	}
+
+	tests := []struct {
+		old       *file
+		current   *file
+		maxSize   int
+		expected  string
+		truncated bool
+	}{
+		{
+			old:     &file{fileName: "hello.yml", path: "config/bot/hello.yml", content: "foo: bar\nbaz: 1\n"},
+			current: &file{fileName: "hello.yml", path: "config/bot/hello.yml", content: "foo: buzz\nbaz: 1\n"},
+			expected: "--- a/config/bot/hello.yml\n" +
+				"+++ b/config/bot/hello.yml\n" +
+				"@@ -1,2 +1,2 @@\n" +
+				"-foo: bar\n" +
+				"+foo: buzz\n" +
+				" baz: 1\n",
+		},
+		{
+			old:     nil,
+			current: &file{fileName: "hello.yml", content: "foo: bar\n"},
+			expected: "--- /dev/null\n" +
+				"+++ b/hello.yml\n" +
+				"@@ -0,0 +1 @@\n" +
+				"+foo: bar\n",
+		},
+		{
+			old:     &file{fileName: "hello.yml", content: "foo: bar\n"},
+			current: nil,
+			expected: "--- a/hello.yml\n" +
+				"+++ /dev/null\n" +
+				"@@ -1 +0,0 @@\n" +
+				"-foo: bar\n",
+		},
+		{
+			old:      &file{fileName: "hello.yml", content: "foo: bar\n"},
+			current:  &file{fileName: "hello.yml", content: "foo: bar\n"},
+			expected: "",
+		},
+		{
+			old:     &file{fileName: "hello.yml", content: lines(1, 20)},
+			current: &file{fileName: "hello.yml", content: strings.Replace(strings.Replace(lines(1, 20), "line2\n", "changed2\n", 1), "line18\n", "changed18\n", 1)},
+			expected: "--- a/hello.yml\n" +
+				"+++ b/hello.yml\n" +
+				"@@ -1,5 +1,5 @@\n" +
+				" line1\n" +
+				"-line2\n" +
+				"+changed2\n" +
+				" line3\n" +
+				" line4\n" +
+				" line5\n" +
+				"@@ -15,6 +15,6 @@\n" +
+				" line15\n" +
+				" line16\n" +
+				" line17\n" +
+				"-line18\n" +
+				"+changed18\n" +
+				" line19\n" +
+				" line20\n",
+		},
+		{
+			old:     &file{fileName: "hello.yml", content: "foo: bar\n"},
+			current: &file{fileName: "hello.yml", content: "foo: buzz\n"},
+			maxSize: 40,
+			expected: "--- a/hello.yml\n" +
+				"+++ b/hello.yml\n",
+			truncated: true,
+		},
+	}
+
+	for i, tt := range tests {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			diff, truncated := unifiedDiff(tt.old, tt.current, tt.maxSize)
+			if diff != tt.expected {
+				t.Errorf("Unexpected diff is returned:\n%s", diff)
+			}
+
+			if truncated != tt.truncated {
+				t.Errorf("Unexpected truncation flag is returned: %t.", truncated)
+			}
+		})
+	}
+}
+
+func TestEditScript(t *testing.T) {
+	// lcs returns the length of the longest common subsequence, which a shortest edit script keeps unchanged.
+	lcs := func(a []string, b []string) int {
+		table := make([][]int, len(a)+1)
+		for i := range table {
+			table[i] = make([]int, len(b)+1)
+		}
+		for i := len(a) - 1; i >= 0; i-- {
+			for j := len(b) - 1; j >= 0; j-- {
+				if a[i] == b[j] {
+					table[i][j] = table[i+1][j+1] + 1
+				} else {
+					table[i][j] = max(table[i+1][j], table[i][j+1])
+				}
+			}
+		}
+		return table[0][0]
+	}
+
+	random := rand.New(rand.NewSource(1))
+	generate := func() []string {
+		lines := make([]string, random.Intn(30))
+		for i := range lines {
+			lines[i] = strconv.Itoa(random.Intn(4))
+		}
+		return lines
+	}
+
+	for i := 0; i < 500; i++ {
+		a, b := generate(), generate()
+		ops, ok := editScript(a, b)
+		if !ok {
+			t.Fatalf("Edit script is not computed for %v and %v.", a, b)
+		}
+
+		var from, to []string
+		unchanged := 0
+		for _, op := range ops {
+			if op.kind != '+' {
+				from = append(from, op.line)
+			}
+			if op.kind != '-' {
+				to = append(to, op.line)
+			}
+			if op.kind == ' ' {
+				unchanged++
+			}
+		}
+		if strings.Join(from, ",") != strings.Join(a, ",") || strings.Join(to, ",") != strings.Join(b, ",") {
+			t.Fatalf("Edit script does not turn %v into %v: %v.", a, b, ops)
+		}
+		if expected := lcs(a, b); unchanged != expected {
+			t.Fatalf("Edit script for %v and %v is not the shortest: %d unchanged lines while %d are expected.", a, b, unchanged, expected)
+		}
+	}
+}
+
+func TestUnifiedDiff_large(t *testing.T) {
+	lines := make([]string, 100*1000)
+	for i := range lines {
+		lines[i] = "line" + strconv.Itoa(i)
+	}
+	content := strings.Join(lines, "\n") + "\n"
+
+	changed := strings.Replace(content, "line50000\n", "changed\n", 1)
+	diff, truncated := unifiedDiff(&file{fileName: "hello.yml", content: content}, &file{fileName: "hello.yml", content: changed}, 0)
+	if truncated || !strings.Contains(diff, "-line50000\n+changed\n") {
+		t.Errorf("Unexpected diff is returned for a small change in a large file: %t, %s.", truncated, diff)
+	}
+
+	rewritten := strings.ReplaceAll(content, "line", "row")
+	diff, truncated = unifiedDiff(&file{fileName: "hello.yml", content: content}, &file{fileName: "hello.yml", content: rewritten}, 0)
+	if !truncated || diff != "" {
+		t.Errorf("Diff with too many changes must be omitted: %t, %d.", truncated, len(diff))
+	}
+}
@@ -0,0 +1,154 @@
+package githubconfig
+
+import (
+	"context"
+	"fmt"
+	"github.com/oklahomer/go-sarah/v4"
+	"github.com/shurcooL/githubv4"
+	"path"
+	"sort"
+)
+
+// WithTopicDiscovery aggregates the configuration files of the repositories in the given organization that carry the given topic,
+// e.g. "sarah-config", so a team can onboard by tagging its repository instead of editing the bot's deployment configuration.
+// Each discovered repository is read on its default branch at {BASE_DIR}/{BOT_TYPE}/ with Config.BaseDir.
+// A file in the configured repository wins over the discovered ones, and among the discovered repositories,
+// the one that comes first in the order of "owner/name" wins.
+// The repositories are searched on every refresh, and an archived repository is ignored.
+func WithTopicDiscovery(org string, topic string) Option {
+	return func(w *watcher) {
+		w.discovery = &discovery{
+			org:   org,
+			topic: topic,
+		}
+	}
+}
+
+type discovery struct {
+	org   string
+	topic string
+}
+
+// searchQuery represents a Graphql query to search repositories.
+// Formatted query is as below:
+//
+// 	query ($query: String!, $after: String) {
+//    search(query: $query, type: REPOSITORY, first: 100, after: $after) {
+//      nodes {
+//        ... on Repository {
+//          name
+//          owner {
+//            login
+//          }
+//          isArchived
+//          defaultBranchRef {
+//            name
+//          }
+//        }
+//      }
+//      pageInfo {
+//        hasNextPage
+//        endCursor
+//      }
+//    }
+// 	}
+type searchQuery struct {
+	Search struct {
+		Nodes    []searchNode
+		PageInfo struct {
+			HasNextPage githubv4.Boolean
+			EndCursor   githubv4.String
+		}
+	} `graphql:"search(query: $query, type: REPOSITORY, first: 100, after: $after)"`
+}
+
+type searchNode struct {
+	Repository searchRepository `graphql:"... on Repository"`
+}
+
+type searchRepository struct {
+	Name  githubv4.String
+	Owner struct {
+		Login githubv4.String
+	}
+	IsArchived       githubv4.Boolean
+	DefaultBranchRef struct {
+		Name githubv4.String
+	}
+}
+
+// discover returns the repositories in the organization that carry the topic in the order of "owner/name".
+// The BotType's own repository and an archived or empty repository are excluded.
+func (w *watcher) discover(ctx context.Context, botType sarah.BotType) ([]*Source, error) {
+	own := w.source(botType)
+	var sources []*Source
+	variables := map[string]interface{}{
+		"query": githubv4.String(fmt.Sprintf("org:%s topic:%s", w.discovery.org, w.discovery.topic)),
+		"after": (*githubv4.String)(nil),
+	}
+	for {
+		q := &searchQuery{}
+		err := w.client.Query(ctx, q, variables)
+		if err != nil {
+			return nil, w.queryError(botType, w.discovery.org, err)
+		}
+
+		for _, node := range q.Search.Nodes {
+			repository := node.Repository
+			if repository.IsArchived || repository.DefaultBranchRef.Name == "" {
+				continue
+			}
+
+			src := &Source{
+				Owner:   string(repository.Owner.Login),
+				Name:    string(repository.Name),
+				BaseDir: own.BaseDir,
+				Branch:  string(repository.DefaultBranchRef.Name),
+			}
+			if src.Owner == own.Owner && src.Name == own.Name {
+				continue
+			}
+			sources = append(sources, src)
+		}
+
+		if !q.Search.PageInfo.HasNextPage {
+			break
+		}
+		cursor := q.Search.PageInfo.EndCursor
+		variables["after"] = &cursor
+	}
+
+	sort.Slice(sources, func(i, j int) bool {
+		return sources[i].Owner+"/"+sources[i].Name < sources[j].Owner+"/"+sources[j].Name
+	})
+	return sources, nil
+}
+
+// fetchDiscovered adds the configuration files of the given BotType in the discovered repositories to the given files.
+// A repository that fails to be read is skipped with a log so one team's broken repository does not stop the others.
+func (w *watcher) fetchDiscovered(ctx context.Context, botType sarah.BotType, files map[string]*file) error {
+	sources, err := w.discover(ctx, botType)
+	if err != nil {
+		return err
+	}
+
+	for _, src := range sources {
+		fetched, _, _, err := w.fetchDir(ctx, botType, src, src.Branch, path.Join(src.BaseDir, botType.String()))
+		if err != nil {
+			w.log(botType, "").Warnf("Skipping repository %s/%s for %s: %+v", src.Owner, src.Name, botType, err)
+			continue
+		}
+
+		for id, f := range fetched {
+			if existing, ok := files[id]; ok {
+				w.log(botType, id).Warnf("Ignoring %s on %s/%s for %s: %s is already defined in %s", f.path, src.Owner, src.Name, botType, id, existing.path)
+				continue
+			}
+			f.branch = src.Branch
+			f.source = src
+			files[id] = f
+		}
+	}
+
+	return nil
+}
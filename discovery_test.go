@@ -0,0 +1,198 @@
+package githubconfig
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"github.com/shurcooL/githubv4"
+	"log/slog"
+	"strings"
+	"testing"
+)
+
+func TestWithTopicDiscovery(t *testing.T) {
+	w := &watcher{}
+	WithTopicDiscovery("oklahomer", "sarah-config")(w)
+
+	if w.discovery == nil {
+		t.Fatal("Discovery is not set.")
+	}
+
+	if w.discovery.org != "oklahomer" || w.discovery.topic != "sarah-config" {
+		t.Errorf("Unexpected discovery is set: %+v.", w.discovery)
+	}
+}
+
+func TestWatcher_get_withTopicDiscovery(t *testing.T) {
+	repository := func(owner string, name string, branch string, archived bool) searchNode {
+		r := searchRepository{Name: githubv4.String(name), IsArchived: githubv4.Boolean(archived)}
+		r.Owner.Login = githubv4.String(owner)
+		r.DefaultBranchRef.Name = githubv4.String(branch)
+		return searchNode{Repository: r}
+	}
+
+	w := &watcher{
+		client: &DummyQuerier{
+			QueryFunc: func(_ context.Context, q interface{}, v map[string]interface{}) error {
+				switch typed := q.(type) {
+				case *searchQuery:
+					if v["query"] != githubv4.String("org:oklahomer topic:sarah-config") {
+						t.Errorf("Unexpected search query is given: %s.", v["query"])
+					}
+
+					if v["after"] == (*githubv4.String)(nil) {
+						typed.Search.Nodes = []searchNode{
+							repository("oklahomer", "team-b", "main", false),
+							repository("oklahomer", "config", "master", false),
+						}
+						typed.Search.PageInfo.HasNextPage = true
+						typed.Search.PageInfo.EndCursor = "cursor"
+						return nil
+					}
+
+					typed.Search.Nodes = []searchNode{
+						repository("oklahomer", "team-a", "develop", false),
+						repository("oklahomer", "retired", "main", true),
+						repository("oklahomer", "empty", "", false),
+					}
+
+				case *query:
+					typed.Repository.Head.Commit.Oid = "sha"
+					switch v["name"] {
+					case githubv4.String("config"):
+						typed.Repository.Object.Tree.Entries = []entry{
+							{Name: "hello.yml", Object: entryObject{Blob: blob{Oid: "hello", Text: "name: hello\n"}}},
+						}
+
+					case githubv4.String("team-a"):
+						if v["ref"] != githubv4.String("develop") {
+							t.Errorf("Unexpected ref is given: %s.", v["ref"])
+						}
+						typed.Repository.Object.Tree.Entries = []entry{
+							{Name: "hello.yml", Object: entryObject{Blob: blob{Oid: "team-a-hello", Text: "name: team-a\n"}}},
+							{Name: "guess.yml", Object: entryObject{Blob: blob{Oid: "team-a-guess", Text: "name: team-a\n"}}},
+						}
+
+					case githubv4.String("team-b"):
+						typed.Repository.Object.Tree.Entries = []entry{
+							{Name: "guess.yml", Object: entryObject{Blob: blob{Oid: "team-b-guess", Text: "name: team-b\n"}}},
+							{Name: "weather.yml", Object: entryObject{Blob: blob{Oid: "team-b-weather", Text: "name: team-b\n"}}},
+						}
+
+					default:
+						t.Errorf("Unexpected repository is queried: %s.", v["name"])
+
+					}
+
+				}
+				return nil
+			},
+		},
+		config: &Config{
+			Owner:   "oklahomer",
+			Name:    "config",
+			BaseDir: "config",
+			Branch:  "master",
+		},
+		discovery: &discovery{
+			org:   "oklahomer",
+			topic: "sarah-config",
+		},
+	}
+
+	files, err := w.get(context.TODO(), "bot")
+	if err != nil {
+		t.Fatalf("Unexpected error is returned: %s.", err.Error())
+	}
+
+	expected := map[string]string{
+		"hello":   "hello",
+		"guess":   "team-a-guess",
+		"weather": "team-b-weather",
+	}
+	if len(files) != len(expected) {
+		t.Fatalf("Unexpected number of files are returned: %d.", len(files))
+	}
+	for id, objectID := range expected {
+		f, ok := files[id]
+		if !ok {
+			t.Fatalf("Expected file is not returned: %s.", id)
+		}
+
+		if f.objectID != objectID {
+			t.Errorf("Unexpected file is returned for %s: %s.", id, f.objectID)
+		}
+	}
+
+	weather := files["weather"]
+	if weather.source == nil || weather.source.Name != "team-b" || weather.branch != "main" {
+		t.Errorf("Unexpected source is set: %+v on %s.", weather.source, weather.branch)
+	}
+}
+
+func TestWatcher_get_withTopicDiscovery_queryError(t *testing.T) {
+	t.Run("search", func(t *testing.T) {
+		w := &watcher{
+			client: &DummyQuerier{
+				QueryFunc: func(_ context.Context, q interface{}, _ map[string]interface{}) error {
+					if _, ok := q.(*searchQuery); ok {
+						return errors.New("API error")
+					}
+					typed := q.(*query)
+					typed.Repository.Head.Commit.Oid = "sha"
+					return nil
+				},
+			},
+			config:    &Config{Owner: "oklahomer", Name: "config", BaseDir: "config", Branch: "master"},
+			discovery: &discovery{org: "team-org", topic: "sarah-config"},
+		}
+
+		_, err := w.get(context.TODO(), "bot")
+		var queryErr *QueryError
+		if !errors.As(err, &queryErr) {
+			t.Fatalf("Expected error is not returned: %#v.", err)
+		}
+		if queryErr.Repository != "team-org" {
+			t.Errorf("Searched organization is not reported: %s.", queryErr.Repository)
+		}
+	})
+
+	t.Run("discovered repository", func(t *testing.T) {
+		buf := &bytes.Buffer{}
+		w := &watcher{
+			client: &DummyQuerier{
+				QueryFunc: func(_ context.Context, q interface{}, v map[string]interface{}) error {
+					switch typed := q.(type) {
+					case *searchQuery:
+						r := searchRepository{Name: "team-a"}
+						r.Owner.Login = "team-org"
+						r.DefaultBranchRef.Name = "main"
+						typed.Search.Nodes = []searchNode{{Repository: r}}
+
+					case *query:
+						if v["name"] == githubv4.String("team-a") {
+							return errors.New("API error")
+						}
+						typed.Repository.Head.Commit.Oid = "sha"
+						typed.Repository.Object.Tree.Entries = []entry{
+							{Name: "hello.yml", Object: entryObject{Blob: blob{Oid: "hello", Text: "name: hello\n"}}},
+						}
+
+					}
+					return nil
+				},
+			},
+			config:    &Config{Owner: "oklahomer", Name: "config", BaseDir: "config", Branch: "master"},
+			discovery: &discovery{org: "team-org", topic: "sarah-config"},
+			slogger:   slog.New(slog.NewTextHandler(buf, nil)),
+		}
+
+		_, err := w.get(context.TODO(), "bot")
+		if err != nil {
+			t.Fatalf("Unexpected error is returned: %s.", err.Error())
+		}
+		if !strings.Contains(buf.String(), "failed to query team-org/team-a on GitHub") {
+			t.Errorf("Discovered repository is not reported: %s.", buf.String())
+		}
+	})
+}
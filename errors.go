@@ -0,0 +1,147 @@
+package githubconfig
+
+import (
+	"fmt"
+	"github.com/oklahomer/go-sarah/v4"
+	"strings"
+)
+
+// QueryError is returned when a query to GitHub's GraphQL API fails.
+type QueryError struct {
+	// BotType is the BotType the query is made for. This is empty when the query is not specific to a BotType.
+	BotType sarah.BotType
+	// Repository is the queried repository in the form of "owner/name", or the organization searched or queried for its teams.
+	Repository string
+	Err        error
+}
+
+// Error returns stringified representation of the error.
+func (err *QueryError) Error() string {
+	if err.BotType == "" {
+		return fmt.Sprintf("failed to query %s on GitHub: %s", err.Repository, err.Err.Error())
+	}
+	return fmt.Sprintf("failed to query %s on GitHub for %s: %s", err.Repository, err.BotType, err.Err.Error())
+}
+
+// Unwrap returns the underlying error.
+func (err *QueryError) Unwrap() error {
+	return err.Err
+}
+
+var _ error = (*QueryError)(nil)
+
+// AuthError is returned when GitHub rejects the given credential.
+type AuthError struct {
+	// Repository is the queried repository in the form of "owner/name".
+	Repository string
+	Err        error
+}
+
+// Error returns stringified representation of the error.
+func (err *AuthError) Error() string {
+	return fmt.Sprintf("failed to authenticate to query %s on GitHub: %s", err.Repository, err.Err.Error())
+}
+
+// Unwrap returns the underlying error.
+func (err *AuthError) Unwrap() error {
+	return err.Err
+}
+
+var _ error = (*AuthError)(nil)
+
+// DecodeError is returned when a configuration file cannot be decoded.
+type DecodeError struct {
+	BotType sarah.BotType
+	ID      string
+	// FileName is the name of the file that failed to decode.
+	// This may differ from the id's own file when the defaults file or the environment overlay fails.
+	FileName string
+	Err      error
+}
+
+// Error returns stringified representation of the error.
+func (err *DecodeError) Error() string {
+	return fmt.Sprintf("failed to decode %s for %s:%s: %s", err.FileName, err.BotType, err.ID, err.Err.Error())
+}
+
+// Unwrap returns the underlying error.
+func (err *DecodeError) Unwrap() error {
+	return err.Err
+}
+
+var _ error = (*DecodeError)(nil)
+
+// DirectoryNotFoundError is returned when the directory for a BotType does not exist on the repository,
+// and no file is mapped to the BotType in the manifest or placed in the local override directory.
+// An existing directory whose files are all skipped is not reported by this error but treated as empty.
+type DirectoryNotFoundError struct {
+	BotType sarah.BotType
+	// Repository is the queried repository in the form of "owner/name".
+	Repository string
+	// Path is the path of the directory relative to the repository root.
+	Path string
+}
+
+// Error returns stringified representation of the error.
+func (err *DirectoryNotFoundError) Error() string {
+	return fmt.Sprintf("directory %s for %s is not found on %s", err.Path, err.BotType, err.Repository)
+}
+
+var _ error = (*DirectoryNotFoundError)(nil)
+
+// RefOrPathNotFoundError is returned when the Git object expression for a BotType resolves to nothing,
+// which means the configured branch, or the ref given to ReadAt, does not exist or does not point to a commit.
+type RefOrPathNotFoundError struct {
+	BotType sarah.BotType
+	// Repository is the queried repository in the form of "owner/name".
+	Repository string
+	// Expression is the Git object expression such as "master:config/slack".
+	Expression string
+}
+
+// Error returns stringified representation of the error.
+func (err *RefOrPathNotFoundError) Error() string {
+	return fmt.Sprintf("%s for %s does not resolve to any object on %s: check the branch and the base directory", err.Expression, err.BotType, err.Repository)
+}
+
+var _ error = (*RefOrPathNotFoundError)(nil)
+
+// repository returns the BotType's repository in the form of "owner/name".
+func (w *watcher) repository(botType sarah.BotType) string {
+	src := w.source(botType)
+	return repositoryName(src.Owner, src.Name)
+}
+
+// repositoryName returns the repository in the form of "owner/name".
+func repositoryName(owner string, name string) string {
+	return fmt.Sprintf("%s/%s", owner, name)
+}
+
+// directoryNotFound returns an error that tells the directory for the BotType does not exist.
+func (w *watcher) directoryNotFound(botType sarah.BotType) error {
+	return &DirectoryNotFoundError{
+		BotType:    botType,
+		Repository: w.repository(botType),
+		Path:       w.directory(botType),
+	}
+}
+
+// queryError classifies the error returned by the GraphQL client for the query made on the given repository,
+// which may differ from the BotType's own repository such as a layer or a discovered repository.
+func (w *watcher) queryError(botType sarah.BotType, repository string, err error) error {
+	err = scrubError(err, w.credentials...)
+
+	// The client does not expose the HTTP status code but includes the status in the error message.
+	if strings.Contains(err.Error(), "401 Unauthorized") {
+		return &AuthError{
+			Repository: repository,
+			Err:        err,
+		}
+	}
+
+	return &QueryError{
+		BotType:    botType,
+		Repository: repository,
+		Err:        err,
+	}
+}
@@ -0,0 +1,224 @@
+package githubconfig
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"testing"
+)
+
+func TestQueryError(t *testing.T) {
+	cause := errors.New("API error")
+	tests := []struct {
+		err      *QueryError
+		expected string
+	}{
+		{
+			err:      &QueryError{Repository: "oklahomer/config", Err: cause},
+			expected: "failed to query oklahomer/config on GitHub: API error",
+		},
+		{
+			err:      &QueryError{BotType: "bot", Repository: "oklahomer/config", Err: cause},
+			expected: "failed to query oklahomer/config on GitHub for bot: API error",
+		},
+	}
+
+	for i, tt := range tests {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			if tt.err.Error() != tt.expected {
+				t.Errorf("Unexpected message is returned: %s.", tt.err.Error())
+			}
+
+			if !errors.Is(tt.err, cause) {
+				t.Error("Underlying error is not returned.")
+			}
+		})
+	}
+}
+
+func TestAuthError(t *testing.T) {
+	cause := errors.New("401 Unauthorized")
+	err := &AuthError{Repository: "oklahomer/config", Err: cause}
+
+	if err.Error() != "failed to authenticate to query oklahomer/config on GitHub: 401 Unauthorized" {
+		t.Errorf("Unexpected message is returned: %s.", err.Error())
+	}
+
+	if !errors.Is(err, cause) {
+		t.Error("Underlying error is not returned.")
+	}
+}
+
+func TestDecodeError(t *testing.T) {
+	cause := errors.New("syntax error")
+	err := &DecodeError{BotType: "bot", ID: "hello", FileName: "_default.yml", Err: cause}
+
+	if err.Error() != "failed to decode _default.yml for bot:hello: syntax error" {
+		t.Errorf("Unexpected message is returned: %s.", err.Error())
+	}
+
+	if !errors.Is(err, cause) {
+		t.Error("Underlying error is not returned.")
+	}
+}
+
+func TestDirectoryNotFoundError(t *testing.T) {
+	err := &DirectoryNotFoundError{BotType: "bot", Repository: "oklahomer/config", Path: "config/bot"}
+
+	if err.Error() != "directory config/bot for bot is not found on oklahomer/config" {
+		t.Errorf("Unexpected message is returned: %s.", err.Error())
+	}
+}
+
+func TestWatcher_queryError(t *testing.T) {
+	w := &watcher{
+		config: &Config{
+			Owner: "oklahomer",
+			Name:  "config",
+		},
+	}
+
+	t.Run("auth", func(t *testing.T) {
+		err := w.queryError("bot", "oklahomer/config", errors.New(`non-200 OK status code: 401 Unauthorized body: "Bad credentials"`))
+
+		var authErr *AuthError
+		if !errors.As(err, &authErr) {
+			t.Fatalf("Expected error is not returned: %#v.", err)
+		}
+
+		if authErr.Repository != "oklahomer/config" {
+			t.Errorf("Unexpected repository is set: %s.", authErr.Repository)
+		}
+	})
+
+	t.Run("other", func(t *testing.T) {
+		err := w.queryError("bot", "oklahomer/config", errors.New("API error"))
+
+		var queryErr *QueryError
+		if !errors.As(err, &queryErr) {
+			t.Fatalf("Expected error is not returned: %#v.", err)
+		}
+
+		if queryErr.BotType != "bot" || queryErr.Repository != "oklahomer/config" {
+			t.Errorf("Unexpected context is set: %+v.", queryErr)
+		}
+	})
+}
+
+func TestWatcher_get_withAbsentDirectory(t *testing.T) {
+	w := &watcher{
+		client: &DummyQuerier{
+			QueryFunc: func(_ context.Context, q interface{}, _ map[string]interface{}) error {
+				// The branch exists while the directory resolves to null
+				q.(*query).Repository.Head.Commit.Oid = "sha"
+				return nil
+			},
+		},
+		config: &Config{
+			Owner:   "oklahomer",
+			Name:    "config",
+			BaseDir: "config",
+			Branch:  "master",
+		},
+	}
+
+	_, err := w.get(context.Background(), "bot")
+
+	var notFound *DirectoryNotFoundError
+	if !errors.As(err, &notFound) {
+		t.Fatalf("Expected error is not returned: %#v.", err)
+	}
+
+	if notFound.Path != "config/bot" {
+		t.Errorf("Unexpected path is set: %s.", notFound.Path)
+	}
+}
+
+func TestWatcher_get_withSkippedEntries(t *testing.T) {
+	w := &watcher{
+		client: &DummyQuerier{
+			QueryFunc: func(_ context.Context, q interface{}, _ map[string]interface{}) error {
+				// The directory exists while none of its entries is a configuration file
+				typed := q.(*query)
+				typed.Repository.Head.Commit.Oid = "sha"
+				typed.Repository.Object.Tree.Oid = "tree"
+				typed.Repository.Object.Tree.Entries = []entry{
+					{
+						Name:   "README.md",
+						Object: entryObject{Blob: blob{Oid: "oid", Text: "# bot"}},
+					},
+				}
+				return nil
+			},
+		},
+		config: &Config{
+			Owner:   "oklahomer",
+			Name:    "config",
+			BaseDir: "config",
+			Branch:  "master",
+		},
+	}
+
+	files, err := w.get(context.Background(), "bot")
+	if err != nil {
+		t.Fatalf("Unexpected error is returned: %s.", err.Error())
+	}
+
+	if len(files) != 0 {
+		t.Errorf("Unexpected files are returned: %+v.", files)
+	}
+}
+
+func TestRefOrPathNotFoundError(t *testing.T) {
+	err := &RefOrPathNotFoundError{BotType: "bot", Repository: "oklahomer/config", Expression: "main:config/bot"}
+
+	expected := "main:config/bot for bot does not resolve to any object on oklahomer/config: check the branch and the base directory"
+	if err.Error() != expected {
+		t.Errorf("Unexpected message is returned: %s.", err.Error())
+	}
+}
+
+func TestWatcher_get_withAbsentRef(t *testing.T) {
+	w := &watcher{
+		client: &DummyQuerier{
+			QueryFunc: func(_ context.Context, _ interface{}, _ map[string]interface{}) error {
+				// Both the branch and the directory resolve to null
+				return nil
+			},
+		},
+		config: &Config{
+			Owner:   "oklahomer",
+			Name:    "config",
+			BaseDir: "config",
+			Branch:  "main",
+		},
+	}
+
+	_, err := w.get(context.Background(), "bot")
+
+	var notFound *RefOrPathNotFoundError
+	if !errors.As(err, &notFound) {
+		t.Fatalf("Expected error is not returned: %#v.", err)
+	}
+
+	if notFound.Expression != "main:config/bot" {
+		t.Errorf("Unexpected expression is set: %s.", notFound.Expression)
+	}
+}
+
+func TestWatcher_read_withDecodeError(t *testing.T) {
+	w := &watcher{}
+	f := newFile("hello.json", "oid", "{")
+	f.defaults = newFile("_default.json", "defaults", "{}")
+
+	err := w.read(context.TODO(), "bot", f, &struct{}{})
+
+	var decodeErr *DecodeError
+	if !errors.As(err, &decodeErr) {
+		t.Fatalf("Expected error is not returned: %#v.", err)
+	}
+
+	if decodeErr.BotType != "bot" || decodeErr.ID != "hello" || decodeErr.FileName != "hello.json" {
+		t.Errorf("Unexpected context is set: %+v.", decodeErr)
+	}
+}
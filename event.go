@@ -0,0 +1,122 @@
+package githubconfig
+
+import (
+	"github.com/oklahomer/go-sarah/v4"
+)
+
+// eventBufferSize is the capacity of the channel returned by Watcher.Events.
+const eventBufferSize = 100
+
+// EventType represents the type of Event.
+type EventType int
+
+const (
+	// EventFetched indicates that the configuration files of a BotType are successfully fetched.
+	EventFetched EventType = iota
+	// EventChanged indicates that a configuration file is added or updated.
+	EventChanged
+	// EventDeleted indicates that a configuration file is removed.
+	EventDeleted
+	// EventError indicates that fetching the configuration files of a BotType failed.
+	EventError
+	// EventPolicyViolation indicates that a changed configuration file is not applied since it violates the approval policy.
+	EventPolicyViolation
+	// EventChangeRateExceeded indicates that a configuration file changes more often than the limit given by WithChangeRateAlert.
+	EventChangeRateExceeded
+	// EventShadowEvaluated indicates that a changed configuration file is evaluated by the functions given by WithShadowHook.
+	EventShadowEvaluated
+)
+
+// String returns stringified representation of the event type.
+func (t EventType) String() string {
+	switch t {
+	case EventFetched:
+		return "fetched"
+
+	case EventChanged:
+		return "changed"
+
+	case EventDeleted:
+		return "deleted"
+
+	case EventError:
+		return "error"
+
+	case EventPolicyViolation:
+		return "policy_violation"
+
+	case EventChangeRateExceeded:
+		return "change_rate_exceeded"
+
+	case EventShadowEvaluated:
+		return "shadow_evaluated"
+
+	default:
+		return "unknown"
+
+	}
+}
+
+// Event describes what happened to the configuration files.
+type Event struct {
+	Type    EventType
+	BotType sarah.BotType
+	// ID is the id of the changed, deleted or refused configuration file. This is empty for EventFetched and EventError.
+	ID string
+	// Change describes the change for EventChanged and EventDeleted.
+	Change *Change
+	// Err is the cause of EventError, the *PolicyViolationError of EventPolicyViolation, the *ChangeRateError of EventChangeRateExceeded,
+	// or the *ShadowEvaluationError of EventShadowEvaluated that fails the evaluation.
+	Err error
+}
+
+func (w *watcher) Events() <-chan Event {
+	w.eventsMutex.Lock()
+	defer w.eventsMutex.Unlock()
+
+	if w.events == nil {
+		w.events = make(chan Event, eventBufferSize)
+	}
+	return w.events
+}
+
+// eventChannel returns the events channel, or nil when Events is never called.
+func (w *watcher) eventChannel() chan Event {
+	w.eventsMutex.Lock()
+	defer w.eventsMutex.Unlock()
+
+	return w.events
+}
+
+// emit sends the given event to the events channel.
+// The event is dropped when no one consumes the events, or when the channel is full so a slow consumer never blocks the operating goroutine.
+func (w *watcher) emit(event Event) {
+	events := w.eventChannel()
+	if events == nil {
+		return
+	}
+
+	select {
+	case events <- event:
+		// Successfully enqueued
+
+	default:
+		w.log(event.BotType, event.ID).Warnf("Dropping %s event for %s since the events channel is full", event.Type, event.BotType)
+
+	}
+}
+
+// changeEvent returns an Event that corresponds to the given change.
+func changeEvent(change *Change) Event {
+	eventType := EventChanged
+	if change.NewObjectID == "" {
+		eventType = EventDeleted
+	}
+
+	return Event{
+		Type:    eventType,
+		BotType: change.BotType,
+		ID:      change.ID,
+		Change:  change,
+	}
+}
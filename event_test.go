@@ -0,0 +1,216 @@
+package githubconfig
+
+import (
+	"context"
+	"errors"
+	"github.com/shurcooL/githubv4"
+	"strconv"
+	"testing"
+	"time"
+)
+
+func TestEventType_String(t *testing.T) {
+	tests := []struct {
+		eventType EventType
+		expected  string
+	}{
+		{eventType: EventFetched, expected: "fetched"},
+		{eventType: EventChanged, expected: "changed"},
+		{eventType: EventDeleted, expected: "deleted"},
+		{eventType: EventError, expected: "error"},
+		{eventType: EventPolicyViolation, expected: "policy_violation"},
+		{eventType: EventChangeRateExceeded, expected: "change_rate_exceeded"},
+		{eventType: EventShadowEvaluated, expected: "shadow_evaluated"},
+		{eventType: EventType(100), expected: "unknown"},
+	}
+
+	for i, tt := range tests {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			if tt.eventType.String() != tt.expected {
+				t.Errorf("Unexpected string is returned: %s.", tt.eventType.String())
+			}
+		})
+	}
+}
+
+func TestWatcher_emit(t *testing.T) {
+	t.Run("without channel", func(t *testing.T) {
+		w := &watcher{}
+
+		// Must not block or panic
+		w.emit(Event{Type: EventFetched})
+	})
+
+	t.Run("lazy channel", func(t *testing.T) {
+		w := &watcher{}
+		w.emit(Event{Type: EventFetched, BotType: "dropped"})
+
+		events := w.Events()
+		if events != w.Events() {
+			t.Fatal("Different channel is returned on the second call.")
+		}
+
+		w.emit(Event{Type: EventFetched, BotType: "delivered"})
+		event := <-events
+		if event.BotType != "delivered" {
+			t.Errorf("Unexpected event is received: %+v.", event)
+		}
+	})
+
+	t.Run("full", func(t *testing.T) {
+		w := &watcher{
+			events: make(chan Event, 1),
+		}
+
+		w.emit(Event{Type: EventFetched, BotType: "first"})
+		// Must not block
+		w.emit(Event{Type: EventFetched, BotType: "second"})
+
+		event := <-w.Events()
+		if event.BotType != "first" {
+			t.Errorf("Unexpected event is received: %+v.", event)
+		}
+	})
+}
+
+func TestChangeEvent(t *testing.T) {
+	tests := []struct {
+		change   *Change
+		expected EventType
+	}{
+		{
+			change:   &Change{ID: "hello", NewObjectID: "new"},
+			expected: EventChanged,
+		},
+		{
+			change:   &Change{ID: "hello", OldObjectID: "old", NewObjectID: "new"},
+			expected: EventChanged,
+		},
+		{
+			change:   &Change{ID: "hello", OldObjectID: "old"},
+			expected: EventDeleted,
+		},
+	}
+
+	for i, tt := range tests {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			event := changeEvent(tt.change)
+			if event.Type != tt.expected {
+				t.Errorf("Unexpected event type is returned: %s.", event.Type)
+			}
+
+			if event.ID != tt.change.ID || event.Change != tt.change {
+				t.Errorf("Unexpected event is returned: %+v.", event)
+			}
+		})
+	}
+}
+
+func TestWatcher_Events(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	responses := make(chan func(*query) error, 3)
+	responses <- func(q *query) error {
+		q.Repository.Object.Tree.Entries = []entry{
+			{Name: "hello.json", Object: entryObject{Blob: blob{Oid: "first", Text: "{}"}}},
+			{Name: "bye.json", Object: entryObject{Blob: blob{Oid: "bye", Text: "{}"}}},
+		}
+		return nil
+	}
+	responses <- func(_ *query) error {
+		return errors.New("API error")
+	}
+	responses <- func(q *query) error {
+		q.Repository.Object.Tree.Entries = []entry{
+			{Name: "hello.json", Object: entryObject{Blob: blob{Oid: "second", Text: "{}"}}},
+		}
+		return nil
+	}
+	last := func(q *query) error {
+		q.Repository.Object.Tree.Entries = []entry{
+			{Name: "hello.json", Object: entryObject{Blob: blob{Oid: githubv4.String("second"), Text: "{}"}}},
+		}
+		return nil
+	}
+
+	w := &watcher{
+		client: &DummyQuerier{
+			QueryFunc: func(_ context.Context, q interface{}, _ map[string]interface{}) error {
+				select {
+				case response := <-responses:
+					return response(q.(*query))
+				default:
+					return last(q.(*query))
+				}
+			},
+		},
+		config: &Config{
+			TimeOut:  100 * time.Millisecond,
+			Interval: 10 * time.Millisecond,
+		},
+		request:      make(chan *request),
+		subscription: make(chan *subscription),
+		events:       make(chan Event, eventBufferSize),
+	}
+	w.started.Store(true)
+	go w.operate(ctx)
+
+	err := w.Read(ctx, "bot", "hello", &struct{}{})
+	if err != nil {
+		t.Fatalf("Unexpected error is returned: %s.", err.Error())
+	}
+
+	_, err = w.WatchAll(ctx, "bot", func(_ context.Context, _ string) {})
+	if err != nil {
+		t.Fatalf("Unexpected error is returned: %s.", err.Error())
+	}
+
+	expected := []struct {
+		eventType EventType
+	}{
+		{eventType: EventFetched},
+		{eventType: EventError},
+		{eventType: EventFetched},
+	}
+	for i, e := range expected {
+		select {
+		case event := <-w.Events():
+			if event.Type != e.eventType || event.BotType != "bot" {
+				t.Fatalf("Unexpected event is received at %d: %+v.", i, event)
+			}
+
+			if event.Type == EventError && event.Err == nil {
+				t.Error("Error is not set.")
+			}
+
+		case <-time.NewTimer(1 * time.Second).C:
+			t.Fatalf("Event is not received at %d.", i)
+
+		}
+	}
+
+	received := map[string]EventType{}
+	for len(received) < 2 {
+		select {
+		case event := <-w.Events():
+			if event.ID == "" {
+				// Ignore subsequent fetches
+				continue
+			}
+			received[event.ID] = event.Type
+
+		case <-time.NewTimer(1 * time.Second).C:
+			t.Fatalf("Change events are not received: %+v.", received)
+
+		}
+	}
+
+	if received["hello"] != EventChanged {
+		t.Errorf("Changed event is not received: %s.", received["hello"])
+	}
+
+	if received["bye"] != EventDeleted {
+		t.Errorf("Deleted event is not received: %s.", received["bye"])
+	}
+}
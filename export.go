@@ -0,0 +1,80 @@
+package githubconfig
+
+import (
+	"bytes"
+	"fmt"
+	"io/fs"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+)
+
+// export mirrors the given files into the directory so sidecar tools and sarah's file-based watcher can consume the same data.
+// A file is rewritten only when its content differs so file system event subscribers are not notified in vain,
+// and a file that no longer exists on the repository is removed.
+func export(dir string, files map[string]*file) error {
+	err := os.MkdirAll(dir, 0755)
+	if err != nil {
+		return fmt.Errorf("failed to create export directory %s: %w", dir, err)
+	}
+
+	names := map[string]struct{}{}
+	for _, f := range files {
+		name := filepath.FromSlash(f.fileName)
+		names[name] = struct{}{}
+
+		dest := filepath.Join(dir, name)
+		current, err := ioutil.ReadFile(dest)
+		if err == nil && bytes.Equal(current, []byte(f.content)) {
+			continue
+		}
+
+		// A file in a subdirectory such as alerts/pagerduty.yml is exported to the same subdirectory.
+		destDir := filepath.Dir(dest)
+		err = os.MkdirAll(destDir, 0755)
+		if err != nil {
+			return fmt.Errorf("failed to create export directory %s: %w", destDir, err)
+		}
+
+		// Write to a temporary file and then rename so a reader never sees a partially written file.
+		tmp, err := ioutil.TempFile(destDir, ".tmp-")
+		if err != nil {
+			return fmt.Errorf("failed to create temporary file in %s: %w", destDir, err)
+		}
+		_, err = tmp.WriteString(f.content)
+		closeErr := tmp.Close()
+		if err == nil {
+			err = closeErr
+		}
+		if err == nil {
+			err = os.Rename(tmp.Name(), dest)
+		}
+		if err != nil {
+			_ = os.Remove(tmp.Name())
+			return fmt.Errorf("failed to export %s: %w", dest, err)
+		}
+	}
+
+	return filepath.WalkDir(dir, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return fmt.Errorf("failed to read export directory %s: %w", p, err)
+		}
+		if !d.Type().IsRegular() {
+			return nil
+		}
+
+		rel, err := filepath.Rel(dir, p)
+		if err != nil {
+			return err
+		}
+		if _, ok := names[rel]; ok {
+			return nil
+		}
+
+		err = os.Remove(p)
+		if err != nil {
+			return fmt.Errorf("failed to remove stale file %s: %w", rel, err)
+		}
+		return nil
+	})
+}
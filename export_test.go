@@ -0,0 +1,109 @@
+package githubconfig
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestWithExport(t *testing.T) {
+	dir := "export/dir"
+	opt := WithExport(dir)
+	w := &watcher{}
+
+	opt(w)
+
+	if w.exportDir != dir {
+		t.Errorf("Expected directory is not set: %s.", w.exportDir)
+	}
+}
+
+func TestExport(t *testing.T) {
+	root, err := ioutil.TempDir("", "githubconfig")
+	if err != nil {
+		t.Fatalf("Failed to create a temporary directory: %s.", err.Error())
+	}
+	defer os.RemoveAll(root)
+
+	dir := filepath.Join(root, "botType")
+	err = os.Mkdir(dir, 0755)
+	if err != nil {
+		t.Fatalf("Failed to create a directory: %s.", err.Error())
+	}
+	stale := filepath.Join(dir, "stale.yml")
+	err = ioutil.WriteFile(stale, []byte("foo: bar"), 0644)
+	if err != nil {
+		t.Fatalf("Failed to write a file: %s.", err.Error())
+	}
+
+	content := `{"value": "exported"}`
+	files := map[string]*file{
+		"hello": newFile("hello.json", "oid", content),
+	}
+
+	err = export(dir, files)
+	if err != nil {
+		t.Fatalf("Unexpected error is returned: %s.", err.Error())
+	}
+
+	exported, err := ioutil.ReadFile(filepath.Join(dir, "hello.json"))
+	if err != nil {
+		t.Fatalf("Exported file can not be read: %s.", err.Error())
+	}
+	if string(exported) != content {
+		t.Errorf("Unexpected content is exported: %s.", string(exported))
+	}
+
+	if _, err := os.Stat(stale); !os.IsNotExist(err) {
+		t.Error("Stale file must be removed.")
+	}
+
+	infos, err := ioutil.ReadDir(dir)
+	if err != nil {
+		t.Fatalf("Failed to read directory: %s.", err.Error())
+	}
+	if len(infos) != 1 {
+		t.Errorf("Unexpected number of files are left: %d.", len(infos))
+	}
+}
+
+func TestExport_subdirectory(t *testing.T) {
+	dir, err := ioutil.TempDir("", "githubconfig")
+	if err != nil {
+		t.Fatalf("Failed to create a temporary directory: %s.", err.Error())
+	}
+	defer os.RemoveAll(dir)
+
+	err = os.Mkdir(filepath.Join(dir, "old"), 0755)
+	if err != nil {
+		t.Fatalf("Failed to create a directory: %s.", err.Error())
+	}
+	stale := filepath.Join(dir, "old", "stale.yml")
+	err = ioutil.WriteFile(stale, []byte("foo: bar"), 0644)
+	if err != nil {
+		t.Fatalf("Failed to write a file: %s.", err.Error())
+	}
+
+	content := "service: pagerduty\n"
+	files := map[string]*file{
+		"alerts/pagerduty": newFile("alerts/pagerduty.yml", "oid", content),
+	}
+
+	err = export(dir, files)
+	if err != nil {
+		t.Fatalf("Unexpected error is returned: %s.", err.Error())
+	}
+
+	exported, err := ioutil.ReadFile(filepath.Join(dir, "alerts", "pagerduty.yml"))
+	if err != nil {
+		t.Fatalf("Exported file can not be read: %s.", err.Error())
+	}
+	if string(exported) != content {
+		t.Errorf("Unexpected content is exported: %s.", string(exported))
+	}
+
+	if _, err := os.Stat(stale); !os.IsNotExist(err) {
+		t.Error("Stale file in a subdirectory must be removed.")
+	}
+}
@@ -0,0 +1,64 @@
+package githubconfig
+
+import (
+	"context"
+	"github.com/oklahomer/go-sarah/v4"
+	"reflect"
+	"strings"
+)
+
+func (w *watcher) WatchKeys(ctx context.Context, botType sarah.BotType, id string, keys []string, callback func(ctx context.Context, change *Change)) (*Subscription, error) {
+	return w.subscribe(ctx, &subscription{
+		botType:    botType,
+		id:         id,
+		keys:       keys,
+		callback:   callback,
+		withCommit: true,
+		done:       make(chan struct{}),
+	})
+}
+
+// keysChanged tells if any of the values at the given keys differ between the previous and the current files.
+// A change is assumed when the file is added or removed, or when the file cannot be decoded into a generic value,
+// so a subscriber never misses a change that the filter cannot evaluate.
+func (w *watcher) keysChanged(botType sarah.BotType, old *file, current *file, keys []string) bool {
+	if old == nil || current == nil || !supportsSchema(old) || !supportsSchema(current) {
+		return true
+	}
+
+	oldValue, err := w.generic(botType, old)
+	if err != nil {
+		return true
+	}
+	currentValue, err := w.generic(botType, current)
+	if err != nil {
+		return true
+	}
+
+	for _, key := range keys {
+		o, oldOk := lookup(oldValue, key)
+		c, currentOk := lookup(currentValue, key)
+		if oldOk != currentOk || !reflect.DeepEqual(o, c) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// lookup returns the value located at the given dot-separated key such as "thresholds.max".
+func lookup(value interface{}, key string) (interface{}, bool) {
+	for _, k := range strings.Split(key, ".") {
+		m, ok := value.(map[string]interface{})
+		if !ok {
+			return nil, false
+		}
+
+		value, ok = m[k]
+		if !ok {
+			return nil, false
+		}
+	}
+
+	return value, true
+}
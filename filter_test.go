@@ -0,0 +1,177 @@
+package githubconfig
+
+import (
+	"context"
+	"github.com/shurcooL/githubv4"
+	"strconv"
+	"testing"
+	"time"
+)
+
+func TestLookup(t *testing.T) {
+	value := map[string]interface{}{
+		"message": "hello",
+		"thresholds": map[string]interface{}{
+			"max": 10,
+		},
+	}
+
+	tests := []struct {
+		key      string
+		expected interface{}
+		found    bool
+	}{
+		{key: "message", expected: "hello", found: true},
+		{key: "thresholds.max", expected: 10, found: true},
+		{key: "thresholds.min", found: false},
+		{key: "message.text", found: false},
+		{key: "unknown", found: false},
+	}
+
+	for i, tt := range tests {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			v, found := lookup(value, tt.key)
+			if found != tt.found {
+				t.Fatalf("Unexpected result is returned: %t.", found)
+			}
+
+			if v != tt.expected {
+				t.Errorf("Unexpected value is returned: %v.", v)
+			}
+		})
+	}
+}
+
+func TestWatcher_keysChanged(t *testing.T) {
+	base := "message: hello\nthresholds:\n  max: 10\n  min: 1\n"
+	tests := []struct {
+		old      *file
+		current  *file
+		keys     []string
+		expected bool
+	}{
+		{
+			old:      newFile("hello.yml", "old", base),
+			current:  newFile("hello.yml", "new", "# comment\n"+base),
+			keys:     []string{"message", "thresholds.max"},
+			expected: false,
+		},
+		{
+			old:      newFile("hello.yml", "old", base),
+			current:  newFile("hello.yml", "new", "message: hello\nthresholds:\n  max: 10\n  min: 2\n"),
+			keys:     []string{"message", "thresholds.max"},
+			expected: false,
+		},
+		{
+			old:      newFile("hello.yml", "old", base),
+			current:  newFile("hello.yml", "new", "message: hello\nthresholds:\n  max: 20\n  min: 1\n"),
+			keys:     []string{"message", "thresholds.max"},
+			expected: true,
+		},
+		{
+			old:      newFile("hello.yml", "old", base),
+			current:  newFile("hello.yml", "new", "thresholds:\n  max: 10\n  min: 1\n"),
+			keys:     []string{"message"},
+			expected: true,
+		},
+		{
+			old:      nil,
+			current:  newFile("hello.yml", "new", base),
+			keys:     []string{"message"},
+			expected: true,
+		},
+		{
+			old:      newFile("hello.ini", "old", "message = hello\n"),
+			current:  newFile("hello.ini", "new", "message = hello\n"),
+			keys:     []string{"message"},
+			expected: true,
+		},
+		{
+			old:      newFile("hello.yml", "old", base),
+			current:  newFile("hello.yml", "new", "message: [\n"),
+			keys:     []string{"message"},
+			expected: true,
+		},
+	}
+
+	for i, tt := range tests {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			w := &watcher{}
+			changed := w.keysChanged("bot", tt.old, tt.current, tt.keys)
+			if changed != tt.expected {
+				t.Errorf("Unexpected result is returned: %t.", changed)
+			}
+		})
+	}
+}
+
+func TestWatcher_WatchKeys(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	contents := make(chan string, 3)
+	contents <- `{"message": "hello", "other": 1}`
+	contents <- `{"message": "hello", "other": 2}`
+	contents <- `{"message": "bye", "other": 2}`
+	current := ""
+	revision := 0
+	w := &watcher{
+		client: &DummyQuerier{
+			QueryFunc: func(_ context.Context, q interface{}, _ map[string]interface{}) error {
+				switch typed := q.(type) {
+				case *query:
+					select {
+					case current = <-contents:
+						revision++
+					default:
+					}
+
+					typed.Repository.Object.Tree.Entries = []entry{
+						{
+							Name:   "hello.json",
+							Object: entryObject{Blob: blob{Oid: githubv4.String(strconv.Itoa(revision)), Text: githubv4.String(current)}},
+						},
+					}
+
+				case *commitQuery:
+					// No commit history
+
+				}
+				return nil
+			},
+		},
+		config: &Config{
+			TimeOut:  100 * time.Millisecond,
+			Interval: 10 * time.Millisecond,
+		},
+		request:      make(chan *request),
+		subscription: make(chan *subscription),
+	}
+	w.started.Store(true)
+	go w.operate(ctx)
+
+	err := w.Read(ctx, "bot", "hello", &struct{}{})
+	if err != nil {
+		t.Fatalf("Unexpected error is returned: %s.", err.Error())
+	}
+
+	called := make(chan *Change, 2)
+	_, err = w.WatchKeys(ctx, "bot", "hello", []string{"message"}, func(_ context.Context, change *Change) {
+		called <- change
+	})
+	if err != nil {
+		t.Fatalf("Unexpected error is returned: %s.", err.Error())
+	}
+
+	select {
+	case change := <-called:
+		// The change on "other" must be filtered out.
+		if change.NewObjectID != "3" {
+			t.Errorf("Unexpected change is passed: %+v.", change)
+		}
+
+	case <-time.NewTimer(1 * time.Second).C:
+		t.Error("Callback is not called.")
+
+	}
+}
@@ -0,0 +1,282 @@
+package githubconfig
+
+import (
+	"bufio"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf16"
+)
+
+// parseEnv parses .env formatted content.
+// Each line is formatted as KEY=VALUE with an optional "export " prefix, and a value may be surrounded by single or double quotes.
+func parseEnv(content string) (map[string]string, error) {
+	return parseFlat(content, "#", func(line string) (string, string, bool) {
+		line = strings.TrimPrefix(line, "export ")
+		key, value, ok := strings.Cut(line, "=")
+		if !ok {
+			return "", "", false
+		}
+
+		value = strings.TrimSpace(value)
+		if len(value) >= 2 && (value[0] == '"' || value[0] == '\'') && value[len(value)-1] == value[0] {
+			value = value[1 : len(value)-1]
+		}
+		return strings.TrimSpace(key), value, true
+	})
+}
+
+// parseProperties parses Java-properties formatted content in the same way as java.util.Properties.
+// A key and a value are separated by "=", ":" or white spaces, and a line starting with "#" or "!" is a comment.
+// A line ending with an odd number of backslashes continues to the next line, whose leading white spaces are ignored.
+// Escape sequences such as "\t", "\=" and "\uXXXX" are unescaped in both the key and the value.
+func parseProperties(content string) (map[string]string, error) {
+	values := map[string]string{}
+	lines := strings.Split(strings.ReplaceAll(content, "\r\n", "\n"), "\n")
+	for i := 0; i < len(lines); i++ {
+		number := i + 1
+		line := strings.TrimLeft(lines[i], propertiesSpaces)
+		if line == "" || line[0] == '#' || line[0] == '!' {
+			continue
+		}
+
+		for continued(line) {
+			line = line[:len(line)-1]
+			if i+1 == len(lines) {
+				break
+			}
+			i++
+			line += strings.TrimLeft(lines[i], propertiesSpaces)
+		}
+
+		key, value, err := splitProperty(line)
+		if err != nil {
+			return nil, fmt.Errorf("invalid line at %d: %w", number, err)
+		}
+		values[key] = value
+	}
+
+	return values, nil
+}
+
+// propertiesSpaces are the white spaces of Java-properties.
+const propertiesSpaces = " \t\f"
+
+// continued tells if the line ends with an odd number of backslashes, which continues the line to the next one.
+func continued(line string) bool {
+	backslashes := 0
+	for i := len(line) - 1; i >= 0 && line[i] == '\\'; i-- {
+		backslashes++
+	}
+	return backslashes%2 == 1
+}
+
+// splitProperty splits the logical line of Java-properties into the unescaped key and value.
+func splitProperty(line string) (string, string, error) {
+	end := len(line)
+	for i := 0; i < len(line); i++ {
+		if line[i] == '\\' {
+			// The escaped character is a part of the key.
+			i++
+			continue
+		}
+		if strings.IndexByte("=:"+propertiesSpaces, line[i]) >= 0 {
+			end = i
+			break
+		}
+	}
+
+	rest := strings.TrimLeft(line[end:], propertiesSpaces)
+	if rest != "" && (rest[0] == '=' || rest[0] == ':') {
+		rest = strings.TrimLeft(rest[1:], propertiesSpaces)
+	}
+
+	key, err := unescapeProperty(line[:end])
+	if err != nil {
+		return "", "", err
+	}
+	value, err := unescapeProperty(rest)
+	if err != nil {
+		return "", "", err
+	}
+	return key, value, nil
+}
+
+// unescapeProperty unescapes the escape sequences of Java-properties.
+// A backslash followed by any other character than "t", "n", "r", "f" and "u" stands for the character itself.
+func unescapeProperty(s string) (string, error) {
+	if !strings.Contains(s, "\\") {
+		return s, nil
+	}
+
+	var units []uint16
+	b := &strings.Builder{}
+	flush := func() {
+		b.WriteString(string(utf16.Decode(units)))
+		units = nil
+	}
+	for i := 0; i < len(s); i++ {
+		if s[i] != '\\' || i+1 == len(s) {
+			flush()
+			b.WriteByte(s[i])
+			continue
+		}
+
+		i++
+		if s[i] == 'u' {
+			if i+4 >= len(s) {
+				return "", fmt.Errorf("malformed \\uXXXX encoding: %s", s[i-1:])
+			}
+			u, err := strconv.ParseUint(s[i+1:i+5], 16, 16)
+			if err != nil {
+				return "", fmt.Errorf("malformed \\uXXXX encoding: %s", s[i-1:i+5])
+			}
+			// Hold the UTF-16 code unit so a surrogate pair is decoded into a single character.
+			units = append(units, uint16(u))
+			i += 4
+			continue
+		}
+
+		flush()
+		switch s[i] {
+		case 't':
+			b.WriteByte('\t')
+		case 'n':
+			b.WriteByte('\n')
+		case 'r':
+			b.WriteByte('\r')
+		case 'f':
+			b.WriteByte('\f')
+		default:
+			b.WriteByte(s[i])
+		}
+	}
+	flush()
+
+	return b.String(), nil
+}
+
+func parseFlat(content string, commentPrefixes string, parseLine func(string) (string, string, bool)) (map[string]string, error) {
+	values := map[string]string{}
+	scanner := bufio.NewScanner(strings.NewReader(content))
+	for i := 1; scanner.Scan(); i++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.ContainsAny(line[:1], commentPrefixes) {
+			continue
+		}
+
+		key, value, ok := parseLine(line)
+		if !ok {
+			return nil, fmt.Errorf("invalid line at %d: %s", i, line)
+		}
+		values[key] = value
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	return values, nil
+}
+
+// decodeFlat applies the given key-value pairs to out.
+// out must be a pointer to map[string]string or a pointer to a struct.
+// For a struct, each field is mapped to the key given by the struct tag of tagName or, when absent, to the field name.
+func decodeFlat(values map[string]string, out interface{}, tagName string) error {
+	if m, ok := out.(*map[string]string); ok {
+		if *m == nil {
+			*m = map[string]string{}
+		}
+		for k, v := range values {
+			(*m)[k] = v
+		}
+		return nil
+	}
+
+	rv := reflect.ValueOf(out)
+	if rv.Kind() != reflect.Ptr || rv.Elem().Kind() != reflect.Struct {
+		return fmt.Errorf("unsupported output type for flat key-value configuration: %T", out)
+	}
+
+	rv = rv.Elem()
+	rt := rv.Type()
+	for i := 0; i < rt.NumField(); i++ {
+		field := rt.Field(i)
+		if field.PkgPath != "" {
+			// Unexported
+			continue
+		}
+
+		key := field.Tag.Get(tagName)
+		if key == "-" {
+			continue
+		}
+		if key == "" {
+			key = field.Name
+		}
+
+		value, ok := values[key]
+		if !ok {
+			continue
+		}
+
+		err := setFlatValue(rv.Field(i), value)
+		if err != nil {
+			return fmt.Errorf("failed to set %s to %s: %w", key, field.Name, err)
+		}
+	}
+
+	return nil
+}
+
+func setFlatValue(v reflect.Value, value string) error {
+	if v.Type() == reflect.TypeOf(time.Duration(0)) {
+		d, err := time.ParseDuration(value)
+		if err != nil {
+			return err
+		}
+		v.SetInt(int64(d))
+		return nil
+	}
+
+	switch v.Kind() {
+	case reflect.String:
+		v.SetString(value)
+
+	case reflect.Bool:
+		b, err := strconv.ParseBool(value)
+		if err != nil {
+			return err
+		}
+		v.SetBool(b)
+
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		i, err := strconv.ParseInt(value, 10, v.Type().Bits())
+		if err != nil {
+			return err
+		}
+		v.SetInt(i)
+
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		u, err := strconv.ParseUint(value, 10, v.Type().Bits())
+		if err != nil {
+			return err
+		}
+		v.SetUint(u)
+
+	case reflect.Float32, reflect.Float64:
+		f, err := strconv.ParseFloat(value, v.Type().Bits())
+		if err != nil {
+			return err
+		}
+		v.SetFloat(f)
+
+	default:
+		return fmt.Errorf("unsupported field type: %s", v.Type())
+
+	}
+
+	return nil
+}
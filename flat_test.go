@@ -0,0 +1,157 @@
+package githubconfig
+
+import (
+	"reflect"
+	"strconv"
+	"testing"
+	"time"
+)
+
+func TestParseEnv(t *testing.T) {
+	content := "# comment\n\nexport TOKEN=\"secret\"\nNAME = oklahomer\nQUOTED='single'\n"
+
+	values, err := parseEnv(content)
+	if err != nil {
+		t.Fatalf("Unexpected error is returned: %s.", err.Error())
+	}
+
+	expected := map[string]string{
+		"TOKEN":  "secret",
+		"NAME":   "oklahomer",
+		"QUOTED": "single",
+	}
+	if !reflect.DeepEqual(values, expected) {
+		t.Errorf("Unexpected values are returned: %+v.", values)
+	}
+
+	_, err = parseEnv("INVALID")
+	if err == nil {
+		t.Error("Expected error is not returned for an invalid line.")
+	}
+}
+
+func TestParseProperties(t *testing.T) {
+	content := "# comment\n! another comment\nserver.host=localhost\nserver.port : 8080\n"
+
+	values, err := parseProperties(content)
+	if err != nil {
+		t.Fatalf("Unexpected error is returned: %s.", err.Error())
+	}
+
+	expected := map[string]string{
+		"server.host": "localhost",
+		"server.port": "8080",
+	}
+	if !reflect.DeepEqual(values, expected) {
+		t.Errorf("Unexpected values are returned: %+v.", values)
+	}
+
+	content = "fruits = apple, \\\n    banana, \\\n    cherry\n" +
+		"greeting hello\\tworld\n" +
+		"path\\=with\\:separators = C:\\\\dir\n" +
+		"unicode=\\u3053\\u3093\\uD83D\\uDE00\n" +
+		"empty\n" +
+		"# comment \\\n" +
+		"trailing = backslash\\\\\n"
+	values, err = parseProperties(content)
+	if err != nil {
+		t.Fatalf("Unexpected error is returned: %s.", err.Error())
+	}
+
+	expected = map[string]string{
+		"fruits":               "apple, banana, cherry",
+		"greeting":             "hello\tworld",
+		"path=with:separators": "C:\\dir",
+		"unicode":              "\u3053\u3093\U0001F600",
+		"empty":                "",
+		"trailing":             "backslash\\",
+	}
+	if !reflect.DeepEqual(values, expected) {
+		t.Errorf("Unexpected values are returned: %+v.", values)
+	}
+
+	_, err = parseProperties("malformed=\\u30\n")
+	if err == nil {
+		t.Error("Expected error is not returned for a malformed escape.")
+	}
+}
+
+func TestDecodeFlat(t *testing.T) {
+	t.Run("map", func(t *testing.T) {
+		out := map[string]string{}
+		err := decodeFlat(map[string]string{"KEY": "value"}, &out, "env")
+		if err != nil {
+			t.Fatalf("Unexpected error is returned: %s.", err.Error())
+		}
+
+		if out["KEY"] != "value" {
+			t.Errorf("Unexpected value is set: %+v.", out)
+		}
+	})
+
+	t.Run("struct", func(t *testing.T) {
+		type config struct {
+			Name     string        `env:"NAME"`
+			Enabled  bool          `env:"ENABLED"`
+			Count    int           `env:"COUNT"`
+			Ratio    float64       `env:"RATIO"`
+			Interval time.Duration `env:"INTERVAL"`
+			Ignored  string        `env:"-"`
+			Plain    uint
+		}
+
+		values := map[string]string{
+			"NAME":     "oklahomer",
+			"ENABLED":  "true",
+			"COUNT":    "3",
+			"RATIO":    "0.5",
+			"INTERVAL": "1m",
+			"-":        "ignored",
+			"Plain":    "10",
+		}
+		out := &config{}
+		err := decodeFlat(values, out, "env")
+		if err != nil {
+			t.Fatalf("Unexpected error is returned: %s.", err.Error())
+		}
+
+		expected := &config{
+			Name:     "oklahomer",
+			Enabled:  true,
+			Count:    3,
+			Ratio:    0.5,
+			Interval: time.Minute,
+			Plain:    10,
+		}
+		if !reflect.DeepEqual(out, expected) {
+			t.Errorf("Unexpected values are set: %+v.", out)
+		}
+	})
+
+	t.Run("error", func(t *testing.T) {
+		tests := []struct {
+			values map[string]string
+			out    interface{}
+		}{
+			{
+				values: map[string]string{},
+				out:    "string",
+			},
+			{
+				values: map[string]string{"Count": "NaN"},
+				out: &struct {
+					Count int
+				}{},
+			},
+		}
+
+		for i, tt := range tests {
+			t.Run(strconv.Itoa(i), func(t *testing.T) {
+				err := decodeFlat(tt.values, tt.out, "env")
+				if err == nil {
+					t.Error("Expected error is not returned.")
+				}
+			})
+		}
+	})
+}
@@ -0,0 +1,74 @@
+package githubconfig
+
+import (
+	"sort"
+	"strings"
+)
+
+// fragmentSuffix is the suffix of a directory whose files are deep-merged into a single configuration.
+// e.g. the files under hello.d/ are merged in lexical order into the configuration with the id "hello".
+const fragmentSuffix = ".d"
+
+// fragmentOf returns the id of the configuration that the file with the given id is a fragment of.
+func fragmentOf(id string) (string, bool) {
+	i := strings.Index(id, fragmentSuffix+"/")
+	if i <= 0 {
+		return "", false
+	}
+	return id[:i], true
+}
+
+// mergeFragments groups the fragments such as hello.d/10-base.yml and hello.d/20-replies.yml into the configuration with the id "hello".
+// The fragments are merged over hello.yml in lexical order when it exists.
+// Otherwise, the first fragment serves as the base of the configuration.
+func mergeFragments(files map[string]*file) {
+	grouped := map[string][]string{}
+	for id := range files {
+		if parent, ok := fragmentOf(id); ok {
+			grouped[parent] = append(grouped[parent], id)
+		}
+	}
+
+	for parent, ids := range grouped {
+		sort.Strings(ids)
+		fragments := make([]*file, 0, len(ids))
+		for _, id := range ids {
+			fragments = append(fragments, files[id])
+			delete(files, id)
+		}
+
+		base, ok := files[parent]
+		if !ok {
+			base = fragments[0]
+			base.id = parent
+			fragments = fragments[1:]
+			files[parent] = base
+		}
+		base.fragments = fragments
+	}
+}
+
+// layers returns the files to be merged into the configuration in the order of application.
+func (f *file) layers() []*file {
+	var layers []*file
+	if f.defaults != nil {
+		layers = append(layers, f.defaults)
+	}
+	layers = append(layers, f.bases...)
+	layers = append(layers, f)
+	layers = append(layers, f.fragments...)
+	if f.overlay != nil {
+		layers = append(layers, f.overlay)
+	}
+	return layers
+}
+
+// inputs returns every file the configuration is built from.
+// This includes the YAML anchors file, which is not merged as a layer but can still put any value into the configuration.
+func (f *file) inputs() []*file {
+	layers := f.layers()
+	if f.anchors != nil {
+		layers = append(layers, f.anchors)
+	}
+	return layers
+}
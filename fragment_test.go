@@ -0,0 +1,118 @@
+package githubconfig
+
+import (
+	"context"
+	"strconv"
+	"testing"
+)
+
+func TestFragmentOf(t *testing.T) {
+	tests := []struct {
+		id       string
+		parent   string
+		fragment bool
+	}{
+		{id: "hello.d/10-base", parent: "hello", fragment: true},
+		{id: "alerts/hello.d/10-base", parent: "alerts/hello", fragment: true},
+		{id: "hello", fragment: false},
+		{id: "alerts/hello", fragment: false},
+		{id: ".d/hello", fragment: false},
+	}
+
+	for i, tt := range tests {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			parent, ok := fragmentOf(tt.id)
+			if ok != tt.fragment {
+				t.Fatalf("Unexpected result is returned: %t.", ok)
+			}
+
+			if parent != tt.parent {
+				t.Errorf("Unexpected parent is returned: %s.", parent)
+			}
+		})
+	}
+}
+
+func TestMergeFragments(t *testing.T) {
+	t.Run("with base", func(t *testing.T) {
+		base := newFile("hello.yml", "base", "")
+		first := newFile("hello.d/10-replies.yml", "first", "")
+		second := newFile("hello.d/20-limits.json", "second", "")
+		files := map[string]*file{
+			"hello":              base,
+			"hello.d/20-limits":  second,
+			"hello.d/10-replies": first,
+		}
+
+		mergeFragments(files)
+
+		if len(files) != 1 || files["hello"] != base {
+			t.Fatalf("Unexpected files are left: %+v.", files)
+		}
+
+		if len(base.fragments) != 2 || base.fragments[0] != first || base.fragments[1] != second {
+			t.Errorf("Unexpected fragments are set: %+v.", base.fragments)
+		}
+	})
+
+	t.Run("without base", func(t *testing.T) {
+		first := newFile("hello.d/10-replies.yml", "first", "")
+		second := newFile("hello.d/20-limits.yml", "second", "")
+		files := map[string]*file{
+			"hello.d/10-replies": first,
+			"hello.d/20-limits":  second,
+		}
+
+		mergeFragments(files)
+
+		f, ok := files["hello"]
+		if !ok || f != first {
+			t.Fatalf("Unexpected files are left: %+v.", files)
+		}
+
+		if f.id != "hello" {
+			t.Errorf("Unexpected id is set: %s.", f.id)
+		}
+
+		if len(f.fragments) != 1 || f.fragments[0] != second {
+			t.Errorf("Unexpected fragments are set: %+v.", f.fragments)
+		}
+	})
+}
+
+func TestWatcher_read_withFragments(t *testing.T) {
+	base := newFile("hello.yml", "base", "name: hello\nlimits:\n  max: 10\n  min: 1\n")
+	base.fragments = []*file{
+		newFile("hello.d/10-limits.yml", "limits", "limits:\n  max: 20\n"),
+		newFile("hello.d/20-name.json", "name", `{"name": "merged"}`),
+	}
+
+	config := &struct {
+		Name   string `yaml:"name" json:"name"`
+		Limits struct {
+			Max int `yaml:"max" json:"max"`
+			Min int `yaml:"min" json:"min"`
+		} `yaml:"limits" json:"limits"`
+	}{}
+	w := &watcher{}
+	err := w.read(context.TODO(), "bot", base, config)
+	if err != nil {
+		t.Fatalf("Unexpected error is returned: %s.", err.Error())
+	}
+
+	if config.Name != "merged" || config.Limits.Max != 20 || config.Limits.Min != 1 {
+		t.Errorf("Unexpected value is read: %+v.", config)
+	}
+}
+
+func TestChangedFiles_fragments(t *testing.T) {
+	oldFragment := &file{id: "hello.d/10-limits", objectID: "old"}
+	newFragment := &file{id: "hello.d/10-limits", objectID: "new"}
+	old := &file{objectID: "same", fragments: []*file{oldFragment}}
+	current := &file{objectID: "same", fragments: []*file{newFragment}}
+
+	before, after := changedFiles(old, current)
+	if before != oldFragment || after != newFragment {
+		t.Errorf("Unexpected files are returned: %+v, %+v.", before, after)
+	}
+}
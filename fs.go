@@ -0,0 +1,214 @@
+package githubconfig
+
+import (
+	"bytes"
+	"context"
+	"github.com/oklahomer/go-sarah/v4"
+	"io"
+	"io/fs"
+	"path"
+	"sort"
+	"strings"
+	"time"
+)
+
+func (w *watcher) FS(botType sarah.BotType) fs.FS {
+	return &configFS{
+		watcher: w,
+		botType: botType,
+	}
+}
+
+// configFS is an fs.FS implementation that serves the cached configuration files of a given BotType.
+// The files are laid out as in the BotType's directory, so a file in a subdirectory such as "alerts/pagerduty.yml" is served under the "alerts" directory.
+type configFS struct {
+	watcher *watcher
+	botType sarah.BotType
+}
+
+var _ fs.FS = (*configFS)(nil)
+
+func (c *configFS) Open(name string) (fs.File, error) {
+	if !fs.ValidPath(name) {
+		return nil, &fs.PathError{Op: "open", Path: name, Err: fs.ErrInvalid}
+	}
+
+	files, err := c.watcher.snapshotFiles(context.Background(), c.botType)
+	if err != nil {
+		return nil, &fs.PathError{Op: "open", Path: name, Err: err}
+	}
+
+	// Multiple configurations may be expanded from one file, so serve the file stored in the repository.
+	stored := map[string]*file{}
+	for _, f := range files {
+		if f.origin != nil {
+			f = f.origin
+		}
+		stored[f.fileName] = f
+	}
+
+	if f, ok := stored[name]; ok {
+		return &fileHandle{
+			file:   f,
+			Reader: bytes.NewReader([]byte(f.content)),
+		}, nil
+	}
+
+	if entries, ok := readDir(stored, name); ok {
+		return &dirHandle{name: name, entries: entries}, nil
+	}
+
+	return nil, &fs.PathError{Op: "open", Path: name, Err: fs.ErrNotExist}
+}
+
+// readDir returns the entries right under the given directory, and tells if the directory exists.
+// A directory exists only when it contains any file, since Git does not track an empty directory.
+func readDir(stored map[string]*file, dir string) ([]fs.DirEntry, bool) {
+	prefix := ""
+	if dir != "." {
+		prefix = dir + "/"
+	}
+
+	found := false
+	subdirs := map[string]bool{}
+	var entries []fs.DirEntry
+	for fileName, f := range stored {
+		if !strings.HasPrefix(fileName, prefix) {
+			continue
+		}
+		found = true
+
+		rel := strings.TrimPrefix(fileName, prefix)
+		if subdir, _, nested := strings.Cut(rel, "/"); nested {
+			if !subdirs[subdir] {
+				subdirs[subdir] = true
+				entries = append(entries, fs.FileInfoToDirEntry(&dirInfo{name: subdir}))
+			}
+			continue
+		}
+		entries = append(entries, fs.FileInfoToDirEntry(&entryInfo{file: f}))
+	}
+	if !found && dir != "." {
+		return nil, false
+	}
+
+	sort.Slice(entries, func(i, j int) bool {
+		return entries[i].Name() < entries[j].Name()
+	})
+	return entries, true
+}
+
+// entryInfo is an fs.FileInfo implementation for a cached configuration file.
+type entryInfo struct {
+	file *file
+}
+
+var _ fs.FileInfo = (*entryInfo)(nil)
+
+func (fi *entryInfo) Name() string {
+	return path.Base(fi.file.fileName)
+}
+
+func (fi *entryInfo) Size() int64 {
+	return int64(len(fi.file.content))
+}
+
+func (fi *entryInfo) Mode() fs.FileMode {
+	return 0444
+}
+
+func (fi *entryInfo) ModTime() time.Time {
+	return fi.file.fetchedAt
+}
+
+func (fi *entryInfo) IsDir() bool {
+	return false
+}
+
+func (fi *entryInfo) Sys() interface{} {
+	return nil
+}
+
+type fileHandle struct {
+	*bytes.Reader
+	file *file
+}
+
+var _ fs.File = (*fileHandle)(nil)
+
+func (h *fileHandle) Stat() (fs.FileInfo, error) {
+	return &entryInfo{file: h.file}, nil
+}
+
+func (h *fileHandle) Close() error {
+	return nil
+}
+
+// dirHandle represents a directory of configFS.
+type dirHandle struct {
+	name    string
+	entries []fs.DirEntry
+	offset  int
+}
+
+var _ fs.ReadDirFile = (*dirHandle)(nil)
+
+func (d *dirHandle) Stat() (fs.FileInfo, error) {
+	return &dirInfo{name: path.Base(d.name)}, nil
+}
+
+func (d *dirHandle) Read(_ []byte) (int, error) {
+	return 0, &fs.PathError{Op: "read", Path: d.name, Err: fs.ErrInvalid}
+}
+
+func (d *dirHandle) Close() error {
+	return nil
+}
+
+func (d *dirHandle) ReadDir(n int) ([]fs.DirEntry, error) {
+	remaining := d.entries[d.offset:]
+	if n <= 0 {
+		d.offset = len(d.entries)
+		return remaining, nil
+	}
+
+	if len(remaining) == 0 {
+		return nil, io.EOF
+	}
+
+	if n > len(remaining) {
+		n = len(remaining)
+	}
+	d.offset += n
+	return remaining[:n], nil
+}
+
+type dirInfo struct {
+	name string
+}
+
+var _ fs.FileInfo = (*dirInfo)(nil)
+
+func (di *dirInfo) Name() string {
+	return di.name
+}
+
+func (*dirInfo) Size() int64 {
+	return 0
+}
+
+func (*dirInfo) Mode() fs.FileMode {
+	return fs.ModeDir | 0555
+}
+
+func (*dirInfo) ModTime() time.Time {
+	return time.Time{}
+}
+
+func (*dirInfo) IsDir() bool {
+	return true
+}
+
+func (*dirInfo) Sys() interface{} {
+	return nil
+}
@@ -0,0 +1,137 @@
+package githubconfig
+
+import (
+	"context"
+	"errors"
+	"github.com/oklahomer/go-sarah/v4"
+	"io/fs"
+	"testing"
+	"testing/fstest"
+	"time"
+)
+
+func TestWatcher_FS(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	content := "name: oklahomer\n"
+	w := &watcher{
+		client: &DummyQuerier{
+			QueryFunc: func(_ context.Context, q interface{}, _ map[string]interface{}) error {
+				typed := q.(*query)
+				typed.Repository.Object.Tree.Entries = []entry{
+					{
+						Name: "hello.yml",
+						Object: entryObject{
+							Blob: blob{
+								Oid:  "oid",
+								Text: "name: oklahomer\n",
+							},
+						},
+					},
+					{
+						Name: "world.json",
+						Object: entryObject{
+							Blob: blob{
+								Oid:  "oid",
+								Text: `{"name": "oklahomer"}`,
+							},
+						},
+					},
+				}
+				return nil
+			},
+		},
+		config: &Config{
+			TimeOut:  100 * time.Millisecond,
+			Interval: 10 * time.Second,
+		},
+		request:  make(chan *request),
+		snapshot: make(chan *snapshotRequest),
+	}
+	w.started.Store(true)
+	go w.operate(ctx)
+
+	fsys := w.FS(sarah.BotType("dummy"))
+
+	err := fstest.TestFS(fsys, "hello.yml", "world.json")
+	if err != nil {
+		t.Fatalf("Unexpected file system behavior: %s.", err.Error())
+	}
+
+	b, err := fs.ReadFile(fsys, "hello.yml")
+	if err != nil {
+		t.Fatalf("Unexpected error is returned: %s.", err.Error())
+	}
+	if string(b) != content {
+		t.Errorf("Unexpected content is returned: %s.", string(b))
+	}
+
+	_, err = fsys.Open("absent.yml")
+	if !errors.Is(err, fs.ErrNotExist) {
+		t.Errorf("Expected error is not returned: %+v.", err)
+	}
+}
+
+func TestWatcher_FS_nested(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	w := &watcher{
+		client: &DummyQuerier{
+			QueryFunc: func(_ context.Context, q interface{}, _ map[string]interface{}) error {
+				switch typed := q.(type) {
+				case *query:
+					typed.Repository.Object.Tree.Entries = []entry{
+						{Name: "hello.yml", Type: "blob", Object: entryObject{Blob: blob{Oid: "hello", Text: "name: hello\n"}}},
+						{Name: "alerts", Type: "tree"},
+					}
+
+				case *treeQuery:
+					typed.Repository.Object.Tree.Entries = []entry{
+						{Name: "pagerduty.yml", Type: "blob", Object: entryObject{Blob: blob{Oid: "pagerduty", Text: "service: pagerduty\n"}}},
+					}
+
+				}
+				return nil
+			},
+		},
+		config: &Config{
+			TimeOut:  100 * time.Millisecond,
+			Interval: 10 * time.Second,
+		},
+		request:  make(chan *request),
+		snapshot: make(chan *snapshotRequest),
+	}
+	w.started.Store(true)
+	go w.operate(ctx)
+
+	fsys := w.FS(sarah.BotType("dummy"))
+
+	err := fstest.TestFS(fsys, "hello.yml", "alerts/pagerduty.yml")
+	if err != nil {
+		t.Fatalf("Unexpected file system behavior: %s.", err.Error())
+	}
+
+	entries, err := fs.ReadDir(fsys, ".")
+	if err != nil {
+		t.Fatalf("Unexpected error is returned: %s.", err.Error())
+	}
+	if len(entries) != 2 || entries[0].Name() != "alerts" || !entries[0].IsDir() || entries[1].Name() != "hello.yml" {
+		t.Errorf("Unexpected entries are returned: %+v.", entries)
+	}
+
+	dir, err := fsys.Open("alerts")
+	if err != nil {
+		t.Fatalf("Unexpected error is returned: %s.", err.Error())
+	}
+	info, err := dir.Stat()
+	if err != nil || !info.IsDir() || info.Name() != "alerts" {
+		t.Errorf("Unexpected directory is opened: %+v, %v.", info, err)
+	}
+
+	_, err = fsys.Open("absent")
+	if !errors.Is(err, fs.ErrNotExist) {
+		t.Errorf("Expected error is not returned: %+v.", err)
+	}
+}
@@ -0,0 +1,181 @@
+package githubconfig
+
+import (
+	"fmt"
+	"path"
+	"strings"
+)
+
+// WithInclude limits the configuration files to those matching any of the given glob patterns such as "*.yml".
+// A pattern is matched against the path relative to the BotType's directory such as "alerts/pagerduty.yml",
+// while a pattern without a slash is matched against the file name at any depth. "**" matches any number of directories.
+func WithInclude(patterns ...string) Option {
+	return func(w *watcher) {
+		w.globs.include = append(w.globs.include, patterns...)
+	}
+}
+
+// WithExclude skips the files and the directories matching any of the given glob patterns such as "*_test.yml" or "archive/**",
+// so a repository containing auxiliary files does not trip the unsupported extension error.
+// See WithInclude for the pattern syntax. An exclusion takes precedence over an inclusion.
+func WithExclude(patterns ...string) Option {
+	return func(w *watcher) {
+		w.globs.exclude = append(w.globs.exclude, patterns...)
+	}
+}
+
+// supportedExtensions are the file extensions that are considered as configuration files by default.
+var supportedExtensions = []string{".yaml", ".yml", ".json", ".jsonc", ".json5", ".cue", ".ini", ".env", ".properties"}
+
+// WithExtensions limits the configuration files to those with any of the given extensions such as ".yml".
+// By default, a file with any of the supported extensions or the extensions registered with WithDecoder is considered as a configuration file,
+// and so is a file without an extension when WithContentSniffing is given.
+func WithExtensions(extensions ...string) Option {
+	return func(w *watcher) {
+		w.extensions = extensions
+	}
+}
+
+// candidate tells if the file at the given slash-separated relative path is a configuration file.
+// Hidden files such as .gitignore, README files and files with unsupported extensions are skipped
+// so they are not cached as ids that later fail to be read.
+func (w *watcher) candidate(name string) bool {
+	base := path.Base(name)
+	if hidden(name) || strings.EqualFold(strings.TrimSuffix(base, path.Ext(base)), "README") {
+		return false
+	}
+
+	supported := false
+	extension := w.extension(base)
+	for _, e := range w.knownExtensions() {
+		if e == extension {
+			supported = true
+			break
+		}
+	}
+
+	return supported && w.filters().matches(name)
+}
+
+// filters returns the include and exclude patterns, which the self-configuration file may replace at runtime.
+func (w *watcher) filters() globs {
+	w.configMutex.RLock()
+	defer w.configMutex.RUnlock()
+
+	return w.globs
+}
+
+// knownExtensions returns the file extensions of the configuration files. An empty string stands for a file without an extension.
+func (w *watcher) knownExtensions() []string {
+	if w.extensions != nil {
+		return w.extensions
+	}
+
+	extensions := supportedExtensions
+	if w.sniffing {
+		extensions = append([]string{""}, extensions...)
+	}
+	for extension := range w.decoders {
+		extensions = append(extensions, extension)
+	}
+	return extensions
+}
+
+// extension returns the extension of the given file name when it is one of the known extensions, or an empty string otherwise,
+// so the dotted name such as "metrics.prod" is not mistaken for the name "metrics" with the extension ".prod".
+func (w *watcher) extension(name string) string {
+	extension := path.Ext(name)
+	for _, e := range w.knownExtensions() {
+		if e != "" && e == extension {
+			return extension
+		}
+	}
+	return ""
+}
+
+// hidden tells if the file or any of its parent directories at the given slash-separated relative path is hidden.
+func hidden(name string) bool {
+	for _, segment := range strings.Split(name, "/") {
+		if strings.HasPrefix(segment, ".") && segment != "." {
+			return true
+		}
+	}
+	return false
+}
+
+// globs holds the glob patterns to filter the configuration files with.
+type globs struct {
+	include []string
+	exclude []string
+}
+
+// validate returns an error when any of the patterns is malformed.
+func (g globs) validate() error {
+	for _, pattern := range append(append([]string{}, g.include...), g.exclude...) {
+		for _, segment := range strings.Split(pattern, "/") {
+			_, err := path.Match(segment, "")
+			if err != nil {
+				return fmt.Errorf("malformed glob pattern %s: %w", pattern, err)
+			}
+		}
+	}
+	return nil
+}
+
+// matches tells if the file at the given slash-separated relative path is a configuration file.
+func (g globs) matches(name string) bool {
+	if g.excluded(name) {
+		return false
+	}
+
+	if len(g.include) == 0 {
+		return true
+	}
+	for _, pattern := range g.include {
+		if glob(pattern, name) {
+			return true
+		}
+	}
+	return false
+}
+
+// excluded tells if the file or the directory at the given slash-separated relative path is excluded.
+func (g globs) excluded(name string) bool {
+	for _, pattern := range g.exclude {
+		if glob(pattern, name) {
+			return true
+		}
+	}
+	return false
+}
+
+// glob tells if the slash-separated name matches the pattern.
+func glob(pattern string, name string) bool {
+	if !strings.Contains(pattern, "/") {
+		ok, _ := path.Match(pattern, path.Base(name))
+		return ok
+	}
+	return matchSegments(strings.Split(pattern, "/"), strings.Split(name, "/"))
+}
+
+func matchSegments(pattern []string, name []string) bool {
+	if len(pattern) == 0 {
+		return len(name) == 0
+	}
+
+	if pattern[0] == "**" {
+		// "**" matches zero or more segments.
+		for i := 0; i <= len(name); i++ {
+			if matchSegments(pattern[1:], name[i:]) {
+				return true
+			}
+		}
+		return false
+	}
+
+	if len(name) == 0 {
+		return false
+	}
+	ok, _ := path.Match(pattern[0], name[0])
+	return ok && matchSegments(pattern[1:], name[1:])
+}
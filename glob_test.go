@@ -0,0 +1,184 @@
+package githubconfig
+
+import (
+	"context"
+	"strconv"
+	"testing"
+)
+
+func TestGlob(t *testing.T) {
+	tests := []struct {
+		pattern  string
+		name     string
+		expected bool
+	}{
+		{pattern: "*.yml", name: "hello.yml", expected: true},
+		{pattern: "*.yml", name: "alerts/pagerduty.yml", expected: true},
+		{pattern: "*.yml", name: "hello.json", expected: false},
+		{pattern: "*_test.yml", name: "alerts/hello_test.yml", expected: true},
+		{pattern: "archive/**", name: "archive", expected: true},
+		{pattern: "archive/**", name: "archive/2019/old.yml", expected: true},
+		{pattern: "archive/**", name: "alerts/archive/old.yml", expected: false},
+		{pattern: "**/archive/*.yml", name: "alerts/archive/old.yml", expected: true},
+		{pattern: "alerts/*.yml", name: "alerts/pagerduty.yml", expected: true},
+		{pattern: "alerts/*.yml", name: "alerts/team/oncall.yml", expected: false},
+	}
+
+	for i, tt := range tests {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			matched := glob(tt.pattern, tt.name)
+			if matched != tt.expected {
+				t.Errorf("Unexpected result is returned for %s and %s: %t.", tt.pattern, tt.name, matched)
+			}
+		})
+	}
+}
+
+func TestGlobs_matches(t *testing.T) {
+	g := globs{
+		include: []string{"*.yml"},
+		exclude: []string{"*_test.yml"},
+	}
+
+	tests := []struct {
+		name     string
+		expected bool
+	}{
+		{name: "hello.yml", expected: true},
+		{name: "hello_test.yml", expected: false},
+		{name: "README.md", expected: false},
+	}
+
+	for i, tt := range tests {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			matched := g.matches(tt.name)
+			if matched != tt.expected {
+				t.Errorf("Unexpected result is returned for %s: %t.", tt.name, matched)
+			}
+		})
+	}
+}
+
+func TestGlobs_validate(t *testing.T) {
+	err := globs{include: []string{"*.yml"}, exclude: []string{"archive/**"}}.validate()
+	if err != nil {
+		t.Errorf("Unexpected error is returned: %s.", err.Error())
+	}
+
+	err = globs{exclude: []string{"[.yml"}}.validate()
+	if err == nil {
+		t.Error("Expected error is not returned.")
+	}
+}
+
+func TestWatcher_get_withGlobs(t *testing.T) {
+	w := &watcher{
+		client: &DummyQuerier{
+			QueryFunc: func(_ context.Context, q interface{}, _ map[string]interface{}) error {
+				switch typed := q.(type) {
+				case *query:
+					typed.Repository.Head.Commit.Oid = "sha"
+					typed.Repository.Object.Tree.Entries = []entry{
+						{Name: "hello.yml", Type: "blob", Object: entryObject{Blob: blob{Oid: "hello", Text: "name: hello\n"}}},
+						{Name: "hello_test.yml", Type: "blob", Object: entryObject{Blob: blob{Oid: "test", Text: "name: test\n"}}},
+						{Name: "README.md", Type: "blob", Object: entryObject{Blob: blob{Oid: "readme", Text: "# Configs\n"}}},
+						{Name: "archive", Type: "tree"},
+					}
+
+				case *treeQuery:
+					t.Error("Excluded directory must not be queried.")
+
+				}
+				return nil
+			},
+		},
+		config: &Config{BaseDir: "config", Branch: "master"},
+	}
+	WithInclude("*.yml")(w)
+	WithExclude("*_test.yml", "archive/**")(w)
+
+	files, err := w.get(context.TODO(), "bot")
+	if err != nil {
+		t.Fatalf("Unexpected error is returned: %s.", err.Error())
+	}
+
+	if len(files) != 1 {
+		t.Fatalf("Unexpected files are returned: %+v.", files)
+	}
+
+	if _, ok := files["hello"]; !ok {
+		t.Error("Expected file is not returned.")
+	}
+}
+
+func TestWatcher_candidate(t *testing.T) {
+	tests := []struct {
+		watcher  *watcher
+		name     string
+		expected bool
+	}{
+		{watcher: &watcher{}, name: "hello.yml", expected: true},
+		{watcher: &watcher{}, name: "alerts/pagerduty.properties", expected: true},
+		{watcher: &watcher{}, name: ".gitignore", expected: false},
+		{watcher: &watcher{}, name: ".hidden/hello.yml", expected: false},
+		{watcher: &watcher{}, name: "README.md", expected: false},
+		{watcher: &watcher{}, name: "readme.yml", expected: false},
+		{watcher: &watcher{}, name: "readme-checker.yml", expected: true},
+		{watcher: &watcher{}, name: "READMEBOT.yaml", expected: true},
+		{watcher: &watcher{}, name: "alerts/Readme.json", expected: false},
+		{watcher: &watcher{}, name: "notes.txt", expected: false},
+		{watcher: &watcher{}, name: "hello", expected: false},
+		{watcher: &watcher{sniffing: true}, name: "hello", expected: true},
+		{watcher: &watcher{}, name: "metrics.prod", expected: false},
+		{watcher: &watcher{sniffing: true}, name: "metrics.prod", expected: true},
+		{watcher: &watcher{extensions: []string{".json"}}, name: "hello.yml", expected: false},
+		{watcher: &watcher{extensions: []string{".json"}}, name: "hello.json", expected: true},
+		{watcher: &watcher{globs: globs{exclude: []string{"*.json"}}}, name: "hello.json", expected: false},
+	}
+
+	for i, tt := range tests {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			candidate := tt.watcher.candidate(tt.name)
+			if candidate != tt.expected {
+				t.Errorf("Unexpected result is returned for %s: %t.", tt.name, candidate)
+			}
+		})
+	}
+}
+
+func TestWatcher_newFile(t *testing.T) {
+	tests := []struct {
+		watcher   *watcher
+		name      string
+		id        string
+		extension string
+	}{
+		{watcher: &watcher{}, name: "hello.yml", id: "hello", extension: ".yml"},
+		{watcher: &watcher{}, name: "v1.hello.yml", id: "v1.hello", extension: ".yml"},
+		{watcher: &watcher{sniffing: true}, name: "metrics.prod", id: "metrics.prod", extension: ""},
+		{watcher: &watcher{sniffing: true}, name: "alerts.v2/pagerduty", id: "alerts.v2/pagerduty", extension: ""},
+		{watcher: &watcher{decoders: map[string]Decoder{".toml": nil}}, name: "hello.toml", id: "hello", extension: ".toml"},
+	}
+
+	for i, tt := range tests {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			f := tt.watcher.newFile(tt.name, "oid", "")
+			if f.id != tt.id {
+				t.Errorf("Unexpected id is derived: %s.", f.id)
+			}
+
+			if f.extension != tt.extension {
+				t.Errorf("Unexpected extension is derived: %s.", f.extension)
+			}
+		})
+	}
+}
+
+func TestWithExtensions(t *testing.T) {
+	w := &watcher{}
+	WithExtensions(".yml", ".json")(w)
+
+	if len(w.extensions) != 2 || w.extensions[0] != ".yml" || w.extensions[1] != ".json" {
+		t.Errorf("Unexpected extensions are set: %v.", w.extensions)
+	}
+}
@@ -0,0 +1,97 @@
+package githubconfig
+
+import (
+	"github.com/oklahomer/go-sarah/v4"
+	"sync"
+)
+
+// defaultHealthTolerance is the default number of consecutive failed fetches the watcher tolerates before it turns unhealthy.
+const defaultHealthTolerance = 3
+
+// WithHealthTolerance sets the number of consecutive failed fetches the watcher tolerates before Healthy returns false.
+// A transient failure such as a single timeout does not make the watcher unhealthy this way.
+func WithHealthTolerance(failures int) Option {
+	return func(w *watcher) {
+		w.health.tolerance = failures
+	}
+}
+
+// health tracks the recent fetch results for health checks.
+// This is guarded by the mutex since probes read it from goroutines other than the operating goroutine.
+type health struct {
+	mutex     sync.Mutex
+	tolerance int
+	// botTypes holds the results of each BotType, so a BotType that keeps failing is not masked by the successful fetches of the others.
+	botTypes map[sarah.BotType]*botTypeHealth
+	// sequence orders the failures so LastError returns the latest one.
+	sequence int
+}
+
+type botTypeHealth struct {
+	failures int
+	lastErr  error
+	failedAt int
+}
+
+func (h *health) record(botType sarah.BotType, err error) {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+
+	if err == nil {
+		delete(h.botTypes, botType)
+		return
+	}
+
+	if h.botTypes == nil {
+		h.botTypes = map[sarah.BotType]*botTypeHealth{}
+	}
+	b, ok := h.botTypes[botType]
+	if !ok {
+		b = &botTypeHealth{}
+		h.botTypes[botType] = b
+	}
+	h.sequence++
+	b.failures++
+	b.lastErr = err
+	b.failedAt = h.sequence
+}
+
+// forget drops the results of the given BotType, which is no longer fetched.
+func (h *health) forget(botType sarah.BotType) {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+
+	delete(h.botTypes, botType)
+}
+
+func (w *watcher) Healthy() bool {
+	w.health.mutex.Lock()
+	defer w.health.mutex.Unlock()
+
+	tolerance := w.health.tolerance
+	if tolerance <= 0 {
+		tolerance = defaultHealthTolerance
+	}
+	for _, b := range w.health.botTypes {
+		if b.failures >= tolerance {
+			return false
+		}
+	}
+	return true
+}
+
+func (w *watcher) LastError() error {
+	w.health.mutex.Lock()
+	defer w.health.mutex.Unlock()
+
+	var latest *botTypeHealth
+	for _, b := range w.health.botTypes {
+		if latest == nil || b.failedAt > latest.failedAt {
+			latest = b
+		}
+	}
+	if latest == nil {
+		return nil
+	}
+	return latest.lastErr
+}
@@ -0,0 +1,95 @@
+package githubconfig
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestWithHealthTolerance(t *testing.T) {
+	w := &watcher{}
+	WithHealthTolerance(5)(w)
+
+	if w.health.tolerance != 5 {
+		t.Errorf("Unexpected tolerance is set: %d.", w.health.tolerance)
+	}
+}
+
+func TestWatcher_Healthy(t *testing.T) {
+	w := &watcher{}
+	WithHealthTolerance(2)(w)
+
+	if !w.Healthy() {
+		t.Error("Watcher must be healthy before any fetch.")
+	}
+
+	err := errors.New("API error")
+	w.health.record("bot", err)
+	if !w.Healthy() {
+		t.Error("Watcher must be healthy within the tolerance.")
+	}
+	if w.LastError() != err {
+		t.Errorf("Unexpected error is returned: %v.", w.LastError())
+	}
+
+	w.health.record("bot", err)
+	if w.Healthy() {
+		t.Error("Watcher must be unhealthy when fetches keep failing.")
+	}
+
+	w.health.record("bot", nil)
+	if !w.Healthy() {
+		t.Error("Watcher must recover on a successful fetch.")
+	}
+	if w.LastError() != nil {
+		t.Errorf("Unexpected error is returned: %v.", w.LastError())
+	}
+}
+
+func TestWatcher_Healthy_defaultTolerance(t *testing.T) {
+	w := &watcher{}
+	for i := 0; i < defaultHealthTolerance-1; i++ {
+		w.health.record("bot", errors.New("API error"))
+	}
+	if !w.Healthy() {
+		t.Error("Watcher must be healthy within the default tolerance.")
+	}
+
+	w.health.record("bot", errors.New("API error"))
+	if w.Healthy() {
+		t.Error("Watcher must be unhealthy beyond the default tolerance.")
+	}
+}
+
+func TestWatcher_Healthy_perBotType(t *testing.T) {
+	w := &watcher{}
+	WithHealthTolerance(2)(w)
+
+	failing := errors.New("API error")
+	for i := 0; i < 2; i++ {
+		w.health.record("failing", failing)
+		// A successful fetch of another BotType must not reset the failures.
+		w.health.record("healthy", nil)
+	}
+	if w.Healthy() {
+		t.Error("Watcher must be unhealthy when a BotType keeps failing.")
+	}
+	if w.LastError() != failing {
+		t.Errorf("Unexpected error is returned: %v.", w.LastError())
+	}
+
+	latest := errors.New("another error")
+	w.health.record("another", latest)
+	if w.LastError() != latest {
+		t.Errorf("Unexpected error is returned: %v.", w.LastError())
+	}
+
+	w.health.record("another", nil)
+	if w.LastError() != failing {
+		t.Errorf("Unexpected error is returned: %v.", w.LastError())
+	}
+
+	w.health.forget("failing")
+	if !w.Healthy() || w.LastError() != nil {
+		t.Errorf("Watcher must recover when every BotType succeeds: %v.", w.LastError())
+	}
+}
@@ -0,0 +1,30 @@
+package githubconfig
+
+import (
+	"context"
+	"github.com/oklahomer/go-sarah/v4"
+)
+
+func (w *watcher) History(ctx context.Context, botType sarah.BotType, id string, limit int) ([]*Commit, error) {
+	files, err := w.snapshotFiles(ctx, botType)
+	if err != nil {
+		return nil, err
+	}
+
+	f, ok := files[w.resolve(botType, files, id)]
+	if !ok {
+		return nil, &sarah.ConfigNotFoundError{
+			BotType: botType,
+			ID:      id,
+		}
+	}
+
+	if f.path == "" {
+		return []*Commit{}, nil
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, w.config.TimeOut)
+	defer cancel()
+
+	return w.commits(ctx, botType, f, limit)
+}
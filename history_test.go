@@ -0,0 +1,91 @@
+package githubconfig
+
+import (
+	"context"
+	"errors"
+	"github.com/oklahomer/go-sarah/v4"
+	"github.com/shurcooL/githubv4"
+	"testing"
+	"time"
+)
+
+func TestWatcher_History(t *testing.T) {
+	remote := newFile("hello.yml", "oid", "name: oklahomer\n")
+	remote.path = "config/bot/hello.yml"
+	local := newFile("local.yml", "local:oid", "name: oklahomer\n")
+	snapshotReq := make(chan *snapshotRequest, 1)
+	w := &watcher{
+		client: &DummyQuerier{
+			QueryFunc: func(_ context.Context, q interface{}, v map[string]interface{}) error {
+				if v["path"] != githubv4.String("config/bot/hello.yml") {
+					t.Errorf("Unexpected path is given: %s.", v["path"])
+				}
+
+				if v["limit"] != githubv4.Int(2) {
+					t.Errorf("Unexpected limit is given: %d.", v["limit"])
+				}
+
+				typed := q.(*commitQuery)
+				typed.Repository.Ref.Target.Commit.History.Nodes = []commit{
+					{Oid: "new", Message: "Update hello"},
+					{Oid: "old", Message: "Add hello"},
+				}
+				return nil
+			},
+		},
+		config: &Config{
+			Branch:  "master",
+			TimeOut: 100 * time.Millisecond,
+		},
+		snapshot: snapshotReq,
+	}
+	w.started.Store(true)
+	go func() {
+		for {
+			select {
+			case req := <-snapshotReq:
+				req.result <- &snapshot{files: map[string]*file{remote.id: remote, local.id: local}}
+
+			case <-time.NewTimer(1 * time.Second).C:
+				// Just to be sure goroutine does not leak
+				return
+
+			}
+		}
+	}()
+
+	t.Run("existing", func(t *testing.T) {
+		commits, err := w.History(context.Background(), "bot", "hello", 2)
+		if err != nil {
+			t.Fatalf("Unexpected error is returned: %s.", err.Error())
+		}
+
+		if len(commits) != 2 {
+			t.Fatalf("Unexpected number of commits are returned: %d.", len(commits))
+		}
+
+		if commits[0].SHA != "new" || commits[1].SHA != "old" {
+			t.Errorf("Unexpected commits are returned: %+v, %+v.", commits[0], commits[1])
+		}
+	})
+
+	t.Run("local", func(t *testing.T) {
+		commits, err := w.History(context.Background(), "bot", "local", 2)
+		if err != nil {
+			t.Fatalf("Unexpected error is returned: %s.", err.Error())
+		}
+
+		if len(commits) != 0 {
+			t.Errorf("Unexpected commits are returned: %+v.", commits)
+		}
+	})
+
+	t.Run("absent", func(t *testing.T) {
+		_, err := w.History(context.Background(), "bot", "absent", 2)
+
+		var notFound *sarah.ConfigNotFoundError
+		if !errors.As(err, &notFound) {
+			t.Errorf("Expected error is not returned: %+v.", err)
+		}
+	})
+}
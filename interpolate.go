@@ -0,0 +1,22 @@
+package githubconfig
+
+import (
+	"os"
+	"regexp"
+)
+
+var variablePattern = regexp.MustCompile(`\$\{([A-Za-z_][A-Za-z0-9_]*)(:-([^}]*))?}`)
+
+// interpolate returns a copy of the file with its environment variable references expanded.
+func interpolate(f *file) *file {
+	interpolated := *f
+	interpolated.content = variablePattern.ReplaceAllStringFunc(f.content, func(s string) string {
+		match := variablePattern.FindStringSubmatch(s)
+		value := os.Getenv(match[1])
+		if value == "" && match[2] != "" {
+			return match[3]
+		}
+		return value
+	})
+	return &interpolated
+}
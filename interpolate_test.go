@@ -0,0 +1,68 @@
+package githubconfig
+
+import (
+	"strconv"
+	"testing"
+)
+
+func TestWithEnvInterpolation(t *testing.T) {
+	opt := WithEnvInterpolation()
+	w := &watcher{}
+
+	opt(w)
+
+	if !w.interpolate {
+		t.Error("Interpolation is not enabled.")
+	}
+}
+
+func TestInterpolate(t *testing.T) {
+	t.Setenv("GITHUBCONFIG_HOST", "example.com")
+	t.Setenv("GITHUBCONFIG_EMPTY", "")
+
+	tests := []struct {
+		content  string
+		expected string
+	}{
+		{
+			content:  "host: ${GITHUBCONFIG_HOST}",
+			expected: "host: example.com",
+		},
+		{
+			content:  "host: ${GITHUBCONFIG_HOST:-localhost}",
+			expected: "host: example.com",
+		},
+		{
+			content:  "host: ${GITHUBCONFIG_EMPTY:-localhost}",
+			expected: "host: localhost",
+		},
+		{
+			content:  "host: ${GITHUBCONFIG_UNSET:-localhost}",
+			expected: "host: localhost",
+		},
+		{
+			content:  "host: ${GITHUBCONFIG_UNSET}",
+			expected: "host: ",
+		},
+		{
+			content:  "price: $100",
+			expected: "price: $100",
+		},
+	}
+
+	for i, tt := range tests {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			f := newFile("hello.yml", "oid", tt.content)
+
+			interpolated := interpolate(f)
+
+			if interpolated.content != tt.expected {
+				t.Errorf("Unexpected content is returned: %s.", interpolated.content)
+			}
+
+			if f.content != tt.content {
+				t.Error("The original file must not be modified.")
+			}
+		})
+	}
+}
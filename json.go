@@ -0,0 +1,57 @@
+package githubconfig
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+)
+
+func (w *watcher) decodeJSON(b []byte, out interface{}) error {
+	if !w.disallowUnknownFields {
+		return json.Unmarshal(b, out)
+	}
+
+	// The metadata is not a part of the configuration.
+	b, err := dropJSONKey(b, metadataKey)
+	if err != nil {
+		return err
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(b))
+	decoder.DisallowUnknownFields()
+	err = decoder.Decode(out)
+	if err != nil {
+		return err
+	}
+
+	// Reject the trailing data just like json.Unmarshal does.
+	_, err = decoder.Token()
+	if errors.Is(err, io.EOF) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	return errors.New("unexpected data after top-level value")
+}
+
+// dropJSONKey removes the given top-level key from the JSON object.
+// Any other top-level value such as an array is returned as-is.
+func dropJSONKey(b []byte, key string) ([]byte, error) {
+	if trimmed := bytes.TrimSpace(b); len(trimmed) == 0 || trimmed[0] != '{' {
+		return b, nil
+	}
+
+	object := map[string]json.RawMessage{}
+	err := json.Unmarshal(b, &object)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, ok := object[key]; !ok {
+		return b, nil
+	}
+	delete(object, key)
+	return json.Marshal(object)
+}
@@ -0,0 +1,90 @@
+package githubconfig
+
+import (
+	"strconv"
+	"testing"
+)
+
+func TestWithDisallowUnknownFields(t *testing.T) {
+	opt := WithDisallowUnknownFields()
+	w := &watcher{}
+
+	opt(w)
+
+	if !w.disallowUnknownFields {
+		t.Error("Option is not enabled.")
+	}
+}
+
+func TestWatcher_decodeJSON(t *testing.T) {
+	tests := []struct {
+		disallow bool
+		input    string
+		error    bool
+	}{
+		{
+			disallow: false,
+			input:    `{"name": "oklahomer", "unknown": true}`,
+			error:    false,
+		},
+		{
+			disallow: true,
+			input:    `{"name": "oklahomer", "unknown": true}`,
+			error:    true,
+		},
+		{
+			disallow: true,
+			input:    `{"name": "oklahomer"}`,
+			error:    false,
+		},
+		{
+			disallow: true,
+			input:    `{"name": "oklahomer"} {"name": "trailing"}`,
+			error:    true,
+		},
+		{
+			disallow: true,
+			input:    `{"name": "oklahomer"}}`,
+			error:    true,
+		},
+	}
+
+	for i, tt := range tests {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			w := &watcher{disallowUnknownFields: tt.disallow}
+			cfg := &struct {
+				Name string `json:"name"`
+			}{}
+			err := w.decodeJSON([]byte(tt.input), cfg)
+
+			if tt.error {
+				if err == nil {
+					t.Fatal("Expected error is not returned.")
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("Unexpected error is returned: %s.", err.Error())
+			}
+
+			if cfg.Name != "oklahomer" {
+				t.Errorf("Unexpected value is decoded: %s.", cfg.Name)
+			}
+		})
+	}
+}
+
+func TestWatcher_decodeJSON_withArray(t *testing.T) {
+	w := &watcher{disallowUnknownFields: true}
+	var cfg []string
+	err := w.decodeJSON([]byte(`["oklahomer", "sarah"]`), &cfg)
+
+	if err != nil {
+		t.Fatalf("Unexpected error is returned: %s.", err.Error())
+	}
+
+	if len(cfg) != 2 || cfg[0] != "oklahomer" || cfg[1] != "sarah" {
+		t.Errorf("Unexpected value is decoded: %+v.", cfg)
+	}
+}
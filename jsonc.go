@@ -0,0 +1,149 @@
+package githubconfig
+
+import (
+	"bytes"
+	"errors"
+)
+
+// standardizeJSON converts annotated JSON content to standard JSON so encoding/json can decode it.
+// This covers the commonly used subset of JSONC and JSON5:
+// line and block comments, trailing commas, single-quoted strings, and unquoted object keys.
+func standardizeJSON(src []byte) ([]byte, error) {
+	buf := &bytes.Buffer{}
+	for i := 0; i < len(src); i++ {
+		c := src[i]
+		switch {
+		case c == '"' || c == '\'':
+			end, err := writeString(buf, src, i)
+			if err != nil {
+				return nil, err
+			}
+			i = end
+
+		case c == '/' && i+1 < len(src) && (src[i+1] == '/' || src[i+1] == '*'):
+			end, err := skipComment(src, i)
+			if err != nil {
+				return nil, err
+			}
+			i = end - 1
+
+		case c == ',':
+			next, err := skipInsignificant(src, i+1)
+			if err != nil {
+				return nil, err
+			}
+			if next < len(src) && (src[next] == '}' || src[next] == ']') {
+				// Drop a trailing comma
+				continue
+			}
+			buf.WriteByte(c)
+
+		case isIdentifierStart(c):
+			end := i
+			for end < len(src) && isIdentifierPart(src[end]) {
+				end++
+			}
+			next, err := skipInsignificant(src, end)
+			if err != nil {
+				return nil, err
+			}
+			if next < len(src) && src[next] == ':' {
+				// An unquoted object key
+				buf.WriteByte('"')
+				buf.Write(src[i:end])
+				buf.WriteByte('"')
+			} else {
+				// A literal such as true, false or null
+				buf.Write(src[i:end])
+			}
+			i = end - 1
+
+		default:
+			buf.WriteByte(c)
+
+		}
+	}
+
+	return buf.Bytes(), nil
+}
+
+// writeString writes a double-quoted string that starts at the given position and returns the position of its closing quote.
+func writeString(buf *bytes.Buffer, src []byte, start int) (int, error) {
+	quote := src[start]
+	buf.WriteByte('"')
+	for i := start + 1; i < len(src); i++ {
+		c := src[i]
+		switch {
+		case c == '\\' && i+1 < len(src):
+			if src[i+1] == '\'' {
+				// \' is only valid in JSON5 and is not required in a double-quoted string
+				buf.WriteByte('\'')
+			} else {
+				buf.Write(src[i : i+2])
+			}
+			i++
+
+		case c == quote:
+			buf.WriteByte('"')
+			return i, nil
+
+		case c == '"':
+			// A double quote in a single-quoted string
+			buf.WriteString(`\"`)
+
+		default:
+			buf.WriteByte(c)
+
+		}
+	}
+
+	return 0, errors.New("unterminated string")
+}
+
+// skipComment returns the position right after the comment that starts at the given position.
+func skipComment(src []byte, start int) (int, error) {
+	if src[start+1] == '/' {
+		end := bytes.IndexByte(src[start:], '\n')
+		if end < 0 {
+			return len(src), nil
+		}
+		return start + end, nil
+	}
+
+	end := bytes.Index(src[start+2:], []byte("*/"))
+	if end < 0 {
+		return 0, errors.New("unterminated block comment")
+	}
+	return start + 2 + end + 2, nil
+}
+
+// skipInsignificant returns the position of the next character that is neither a whitespace nor a part of a comment.
+func skipInsignificant(src []byte, start int) (int, error) {
+	i := start
+	for i < len(src) {
+		switch {
+		case src[i] == ' ' || src[i] == '\t' || src[i] == '\n' || src[i] == '\r':
+			i++
+
+		case src[i] == '/' && i+1 < len(src) && (src[i+1] == '/' || src[i+1] == '*'):
+			end, err := skipComment(src, i)
+			if err != nil {
+				return 0, err
+			}
+			i = end
+
+		default:
+			return i, nil
+
+		}
+	}
+	return i, nil
+}
+
+func isIdentifierStart(c byte) bool {
+	return c == '_' || c == '$' || ('a' <= c && c <= 'z') || ('A' <= c && c <= 'Z')
+}
+
+func isIdentifierPart(c byte) bool {
+	return isIdentifierStart(c) || ('0' <= c && c <= '9')
+}
@@ -0,0 +1,75 @@
+package githubconfig
+
+import (
+	"encoding/json"
+	"reflect"
+	"strconv"
+	"testing"
+)
+
+func TestStandardizeJSON(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected interface{}
+		error    bool
+	}{
+		{
+			input:    `{"key": "value"}`,
+			expected: map[string]interface{}{"key": "value"},
+		},
+		{
+			input:    "{\n  // line comment\n  \"key\": \"value\" // trailing\n}",
+			expected: map[string]interface{}{"key": "value"},
+		},
+		{
+			input:    `{/* block */ "key": /* inline */ "value"}`,
+			expected: map[string]interface{}{"key": "value"},
+		},
+		{
+			input:    `{"list": [1, 2, ], "key": "value", }`,
+			expected: map[string]interface{}{"list": []interface{}{float64(1), float64(2)}, "key": "value"},
+		},
+		{
+			input:    `{key: 'it\'s "quoted"', enabled: true, empty: null}`,
+			expected: map[string]interface{}{"key": `it's "quoted"`, "enabled": true, "empty": nil},
+		},
+		{
+			input:    `{"url": "https://example.com/*path*/"}`,
+			expected: map[string]interface{}{"url": "https://example.com/*path*/"},
+		},
+		{
+			input: `{"key": "unterminated}`,
+			error: true,
+		},
+		{
+			input: `{"key": "value" /* unterminated}`,
+			error: true,
+		},
+	}
+
+	for i, tt := range tests {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			b, err := standardizeJSON([]byte(tt.input))
+			if tt.error {
+				if err == nil {
+					t.Fatal("Expected error is not returned.")
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("Unexpected error is returned: %s.", err.Error())
+			}
+
+			var actual interface{}
+			err = json.Unmarshal(b, &actual)
+			if err != nil {
+				t.Fatalf("Standardized JSON can not be decoded: %s: %s.", string(b), err.Error())
+			}
+
+			if !reflect.DeepEqual(actual, tt.expected) {
+				t.Errorf("Unexpected value is decoded: %+v.", actual)
+			}
+		})
+	}
+}
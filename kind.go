@@ -0,0 +1,36 @@
+package githubconfig
+
+import (
+	"strings"
+)
+
+const (
+	// commandsDir is the subdirectory under a BotType's directory that holds Command configurations in the kind-separated layout.
+	commandsDir = "commands"
+	// tasksDir is the subdirectory under a BotType's directory that holds ScheduledTask configurations in the kind-separated layout.
+	tasksDir = "tasks"
+)
+
+// WithKindDirectories looks up the configuration files of Commands under {BotType}/commands/
+// and those of ScheduledTasks under {BotType}/tasks/ in addition to {BotType}/ itself,
+// so a repository stays organized as a bot accumulates dozens of each.
+// A file such as {BotType}/commands/hello.yml is identified by the id "hello".
+func WithKindDirectories() Option {
+	return func(w *watcher) {
+		w.kindDirectories = true
+	}
+}
+
+// kindless returns the id without the commands/ or tasks/ prefix when the kind-separated layout is enabled.
+func (w *watcher) kindless(id string) string {
+	if !w.kindDirectories {
+		return id
+	}
+
+	for _, dir := range []string{commandsDir, tasksDir} {
+		if strings.HasPrefix(id, dir+"/") {
+			return strings.TrimPrefix(id, dir+"/")
+		}
+	}
+	return id
+}
@@ -0,0 +1,92 @@
+package githubconfig
+
+import (
+	"context"
+	"github.com/shurcooL/githubv4"
+	"strconv"
+	"testing"
+)
+
+func TestWatcher_kindless(t *testing.T) {
+	tests := []struct {
+		kindDirectories bool
+		id              string
+		expected        string
+	}{
+		{kindDirectories: false, id: "commands/hello", expected: "commands/hello"},
+		{kindDirectories: true, id: "commands/hello", expected: "hello"},
+		{kindDirectories: true, id: "tasks/alarm", expected: "alarm"},
+		{kindDirectories: true, id: "tasks/daily/report", expected: "daily/report"},
+		{kindDirectories: true, id: "hello", expected: "hello"},
+		{kindDirectories: true, id: "alerts/commands/hello", expected: "alerts/commands/hello"},
+	}
+
+	for i, tt := range tests {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			w := &watcher{kindDirectories: tt.kindDirectories}
+			id := w.kindless(tt.id)
+			if id != tt.expected {
+				t.Errorf("Unexpected id is returned: %s.", id)
+			}
+		})
+	}
+}
+
+func TestWatcher_get_withKindDirectories(t *testing.T) {
+	w := &watcher{
+		client: &DummyQuerier{
+			QueryFunc: func(_ context.Context, q interface{}, v map[string]interface{}) error {
+				switch typed := q.(type) {
+				case *query:
+					typed.Repository.Head.Commit.Oid = "sha"
+					typed.Repository.Object.Tree.Entries = []entry{
+						{Name: "commands", Type: "tree"},
+						{Name: "tasks", Type: "tree"},
+					}
+
+				case *treeQuery:
+					switch v["expression"] {
+					case githubv4.String("master:config/bot/commands"):
+						typed.Repository.Object.Tree.Entries = []entry{
+							{Name: "hello.yml", Type: "blob", Object: entryObject{Blob: blob{Oid: "hello", Text: "name: hello\n"}}},
+						}
+
+					case githubv4.String("master:config/bot/tasks"):
+						typed.Repository.Object.Tree.Entries = []entry{
+							{Name: "alarm.yml", Type: "blob", Object: entryObject{Blob: blob{Oid: "alarm", Text: "name: alarm\n"}}},
+						}
+
+					}
+
+				}
+				return nil
+			},
+		},
+		config: &Config{BaseDir: "config", Branch: "master"},
+	}
+	WithKindDirectories()(w)
+
+	files, err := w.get(context.TODO(), "bot")
+	if err != nil {
+		t.Fatalf("Unexpected error is returned: %s.", err.Error())
+	}
+
+	expected := map[string]string{
+		"hello": "config/bot/commands/hello.yml",
+		"alarm": "config/bot/tasks/alarm.yml",
+	}
+	if len(files) != len(expected) {
+		t.Fatalf("Unexpected files are returned: %+v.", files)
+	}
+	for id, p := range expected {
+		f, ok := files[id]
+		if !ok {
+			t.Errorf("Expected file is not returned: %s.", id)
+			continue
+		}
+
+		if f.path != p {
+			t.Errorf("Unexpected path is set for %s: %s.", id, f.path)
+		}
+	}
+}
@@ -0,0 +1,136 @@
+package githubconfig
+
+import (
+	"context"
+	"fmt"
+	"github.com/oklahomer/go-sarah/v4"
+	"path"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// layerSources returns the repositories of Config.Layers with the omitted fields inherited from Config.
+func (w *watcher) layerSources() []*Source {
+	w.configMutex.RLock()
+	defer w.configMutex.RUnlock()
+
+	var sources []*Source
+	for _, layer := range w.config.Layers {
+		src := *layer
+		if src.Owner == "" {
+			src.Owner = w.config.Owner
+		}
+		if src.Name == "" {
+			src.Name = w.config.Name
+		}
+		if src.BaseDir == "" {
+			src.BaseDir = w.config.BaseDir
+		}
+		if src.Branch == "" {
+			src.Branch = w.config.Branch
+		}
+		sources = append(sources, &src)
+	}
+	return sources
+}
+
+// fetchLayers fetches the given BotType's configuration files from the repositories of Config.Layers
+// and stacks the files with the same id beneath the given files.
+// An id that only exists in the layers is added to the given files with its topmost file.
+func (w *watcher) fetchLayers(ctx context.Context, botType sarah.BotType, files map[string]*file) error {
+	stack := map[string]*file{}
+	for _, src := range w.layerSources() {
+		fetched, _, _, err := w.fetchDir(ctx, botType, src, src.Branch, path.Join(src.BaseDir, botType.String()))
+		if err != nil {
+			return fmt.Errorf("failed to fetch layer %s/%s: %w", src.Owner, src.Name, err)
+		}
+
+		for id, f := range fetched {
+			f.branch = src.Branch
+			f.source = src
+			stack[id] = stacked(stack[id], f)
+		}
+	}
+
+	for id, lower := range stack {
+		files[id] = stacked(lower, files[id])
+	}
+
+	return nil
+}
+
+// stacked returns the upper file with the lower file and its bases placed beneath it.
+func stacked(lower *file, upper *file) *file {
+	if upper == nil {
+		return lower
+	}
+	if lower == nil {
+		return upper
+	}
+
+	bases := make([]*file, 0, len(lower.bases)+1)
+	bases = append(bases, lower.bases...)
+	upper.bases = append(bases, lower)
+	return upper
+}
+
+// reportConflicts logs the keys that a layer overrides with a different value,
+// so the owners of the upper layers can see which values set by the lower layers they override.
+func (w *watcher) reportConflicts(botType sarah.BotType, f *file) {
+	if len(f.bases) == 0 {
+		return
+	}
+
+	var merged interface{}
+	for _, layer := range append(f.bases, f) {
+		if !supportsSchema(layer) {
+			return
+		}
+
+		var value interface{}
+		err := w.decode(botType, layer, &value)
+		if err != nil {
+			return
+		}
+
+		keys := conflicts(merged, value, "")
+		if len(keys) > 0 {
+			w.log(botType, f.id).Warnf("%s for %s:%s overrides the values of the lower layers: %s", layer.path, botType, f.id, strings.Join(keys, ", "))
+		}
+		merged = deepMerge(merged, value)
+	}
+}
+
+// conflicts returns the sorted dot-separated keys whose values in upper differ from those in lower.
+func conflicts(lower interface{}, upper interface{}, prefix string) []string {
+	lowerMap, ok := lower.(map[string]interface{})
+	if !ok {
+		return nil
+	}
+	upperMap, ok := upper.(map[string]interface{})
+	if !ok {
+		return nil
+	}
+
+	var keys []string
+	for key, u := range upperMap {
+		l, ok := lowerMap[key]
+		if !ok {
+			continue
+		}
+
+		_, lowerIsMap := l.(map[string]interface{})
+		_, upperIsMap := u.(map[string]interface{})
+		if lowerIsMap && upperIsMap {
+			keys = append(keys, conflicts(l, u, prefix+key+".")...)
+			continue
+		}
+
+		if !reflect.DeepEqual(l, u) {
+			keys = append(keys, prefix+key)
+		}
+	}
+	sort.Strings(keys)
+	return keys
+}
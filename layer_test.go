@@ -0,0 +1,210 @@
+package githubconfig
+
+import (
+	"context"
+	"errors"
+	"github.com/shurcooL/githubv4"
+	"gopkg.in/yaml.v3"
+	"strconv"
+	"testing"
+)
+
+func TestConfig_layers(t *testing.T) {
+	input := "owner: oklahomer\nname: service\nlayers:\n  - name: org\n  - owner: team\n    name: config\n    branch: main\n"
+	config := &Config{}
+	err := yaml.Unmarshal([]byte(input), config)
+	if err != nil {
+		t.Fatalf("Unexpected error is returned: %s.", err.Error())
+	}
+
+	if len(config.Layers) != 2 || config.Layers[0].Name != "org" || config.Layers[1].Owner != "team" {
+		t.Errorf("Unexpected layers are decoded: %+v.", config.Layers)
+	}
+}
+
+func TestWatcher_layerSources(t *testing.T) {
+	w := &watcher{
+		config: &Config{
+			Owner:   "oklahomer",
+			Name:    "service",
+			BaseDir: "config",
+			Branch:  "master",
+			Layers: []*Source{
+				{Name: "org"},
+				{Owner: "team", Name: "config", BaseDir: "bots", Branch: "main"},
+			},
+		},
+	}
+
+	sources := w.layerSources()
+	if len(sources) != 2 {
+		t.Fatalf("Unexpected number of sources are returned: %d.", len(sources))
+	}
+
+	if *sources[0] != (Source{Owner: "oklahomer", Name: "org", BaseDir: "config", Branch: "master"}) {
+		t.Errorf("Unexpected source is returned: %+v.", sources[0])
+	}
+
+	if *sources[1] != (Source{Owner: "team", Name: "config", BaseDir: "bots", Branch: "main"}) {
+		t.Errorf("Unexpected source is returned: %+v.", sources[1])
+	}
+}
+
+func TestConflicts(t *testing.T) {
+	tests := []struct {
+		lower    interface{}
+		upper    interface{}
+		expected []string
+	}{
+		{
+			lower:    nil,
+			upper:    map[string]interface{}{"text": "hello"},
+			expected: nil,
+		},
+		{
+			lower:    map[string]interface{}{"text": "hello", "count": 1},
+			upper:    map[string]interface{}{"text": "hello", "other": 1},
+			expected: nil,
+		},
+		{
+			lower:    map[string]interface{}{"text": "hello", "count": 1},
+			upper:    map[string]interface{}{"text": "bye", "count": 2},
+			expected: []string{"count", "text"},
+		},
+		{
+			lower:    map[string]interface{}{"server": map[string]interface{}{"host": "example.com", "port": 80}},
+			upper:    map[string]interface{}{"server": map[string]interface{}{"host": "example.com", "port": 443}},
+			expected: []string{"server.port"},
+		},
+		{
+			lower:    map[string]interface{}{"server": map[string]interface{}{"host": "example.com"}},
+			upper:    map[string]interface{}{"server": "example.com"},
+			expected: []string{"server"},
+		},
+	}
+
+	for i, tt := range tests {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			keys := conflicts(tt.lower, tt.upper, "")
+			if len(keys) != len(tt.expected) {
+				t.Fatalf("Unexpected keys are returned: %v.", keys)
+			}
+			for i := range keys {
+				if keys[i] != tt.expected[i] {
+					t.Errorf("Unexpected keys are returned: %v.", keys)
+				}
+			}
+		})
+	}
+}
+
+func TestWatcher_get_withLayers(t *testing.T) {
+	w := &watcher{
+		client: &DummyQuerier{
+			QueryFunc: func(_ context.Context, q interface{}, v map[string]interface{}) error {
+				typed := q.(*query)
+				typed.Repository.Head.Commit.Oid = "sha"
+				switch v["name"] {
+				case githubv4.String("org"):
+					typed.Repository.Object.Tree.Entries = []entry{
+						{Name: "hello.yml", Object: entryObject{Blob: blob{Oid: "org-hello", Text: "text: hello\ncount: 1\nlimit: 10\n"}}},
+						{Name: "guess.yml", Object: entryObject{Blob: blob{Oid: "org-guess", Text: "count: 1\n"}}},
+					}
+
+				case githubv4.String("team"):
+					typed.Repository.Object.Tree.Entries = []entry{
+						{Name: "hello.yml", Object: entryObject{Blob: blob{Oid: "team-hello", Text: "count: 2\n"}}},
+					}
+
+				case githubv4.String("service"):
+					typed.Repository.Object.Tree.Entries = []entry{
+						{Name: "hello.yml", Object: entryObject{Blob: blob{Oid: "service-hello", Text: "text: bye\n"}}},
+					}
+
+				default:
+					t.Errorf("Unexpected repository is queried: %s.", v["name"])
+
+				}
+				return nil
+			},
+		},
+		config: &Config{
+			Owner:   "oklahomer",
+			Name:    "service",
+			BaseDir: "config",
+			Branch:  "master",
+			Layers: []*Source{
+				{Name: "org"},
+				{Name: "team"},
+			},
+		},
+	}
+
+	files, err := w.get(context.TODO(), "bot")
+	if err != nil {
+		t.Fatalf("Unexpected error is returned: %s.", err.Error())
+	}
+
+	hello, ok := files["hello"]
+	if !ok {
+		t.Fatal("Expected file is not returned.")
+	}
+	if len(hello.bases) != 2 || hello.bases[0].objectID != "org-hello" || hello.bases[1].objectID != "team-hello" {
+		t.Fatalf("Unexpected bases are stacked: %+v.", hello.bases)
+	}
+
+	config := &struct {
+		Text  string `yaml:"text"`
+		Count int    `yaml:"count"`
+		Limit int    `yaml:"limit"`
+	}{}
+	err = w.read(context.TODO(), "bot", hello, config)
+	if err != nil {
+		t.Fatalf("Unexpected error is returned: %s.", err.Error())
+	}
+	if config.Text != "bye" || config.Count != 2 || config.Limit != 10 {
+		t.Errorf("Unexpected value is decoded: %+v.", config)
+	}
+
+	guess, ok := files["guess"]
+	if !ok {
+		t.Fatal("File only in the lower layer is not returned.")
+	}
+	if guess.objectID != "org-guess" || guess.source == nil || guess.source.Name != "org" {
+		t.Errorf("Unexpected file is returned: %+v.", guess)
+	}
+}
+
+func TestWatcher_get_withLayers_queryError(t *testing.T) {
+	w := &watcher{
+		client: &DummyQuerier{
+			QueryFunc: func(_ context.Context, q interface{}, v map[string]interface{}) error {
+				if v["name"] == githubv4.String("org") {
+					return errors.New("API error")
+				}
+				typed := q.(*query)
+				typed.Repository.Head.Commit.Oid = "sha"
+				typed.Repository.Object.Tree.Entries = []entry{
+					{Name: "hello.yml", Object: entryObject{Blob: blob{Oid: "hello", Text: "text: hello\n"}}},
+				}
+				return nil
+			},
+		},
+		config: &Config{
+			Owner:   "oklahomer",
+			Name:    "service",
+			BaseDir: "config",
+			Branch:  "master",
+			Layers:  []*Source{{Name: "org"}},
+		},
+	}
+
+	_, err := w.get(context.TODO(), "bot")
+	var queryErr *QueryError
+	if !errors.As(err, &queryErr) {
+		t.Fatalf("Expected error is not returned: %#v.", err)
+	}
+	if queryErr.Repository != "oklahomer/org" {
+		t.Errorf("Queried layer repository is not reported: %s.", queryErr.Repository)
+	}
+}
@@ -0,0 +1,218 @@
+package githubconfig
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"github.com/oklahomer/go-sarah/v4"
+	"io/ioutil"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// defaultLFSURL is the base URL of the Git LFS server that serves repositories on github.com.
+const defaultLFSURL = "https://github.com"
+
+// lfsPointerPrefix is the first line of a Git LFS pointer file.
+const lfsPointerPrefix = "version https://git-lfs.github.com/spec/v1\n"
+
+// lfsDownloadClient downloads Git LFS objects.
+// The download action points to a storage host other than GitHub, so the authenticated client must not be used to avoid leaking the token.
+// The download action lists the headers to authenticate with instead.
+var lfsDownloadClient = &http.Client{}
+
+// WithLFSEndpoint sets the base URL of the Git LFS server such as "https://example.com" for GitHub Enterprise.
+// A configuration file stored in Git LFS is fetched from {baseURL}/{owner}/{name}.git/info/lfs with the HTTP client
+// given by WithToken or WithRESTClient, and the object is downloaded with the headers the server lists only.
+func WithLFSEndpoint(baseURL string) Option {
+	return func(w *watcher) {
+		w.lfsURL = baseURL
+	}
+}
+
+// lfsPointer represents the content of a Git LFS pointer file as below:
+//
+//	version https://git-lfs.github.com/spec/v1
+//	oid sha256:4d7a214614ab2935c943f9e0ff69d22eadbb8f32b1258daaa5e2ca24d17e2393
+//	size 12345
+type lfsPointer struct {
+	oid  string
+	size int64
+}
+
+// parseLFSPointer returns the pointer when the given content is a Git LFS pointer file.
+func parseLFSPointer(content string) (*lfsPointer, bool) {
+	if !strings.HasPrefix(content, lfsPointerPrefix) {
+		return nil, false
+	}
+
+	pointer := &lfsPointer{}
+	for _, line := range strings.Split(content, "\n") {
+		key, value, _ := strings.Cut(line, " ")
+		switch key {
+		case "oid":
+			pointer.oid = strings.TrimPrefix(value, "sha256:")
+
+		case "size":
+			size, err := strconv.ParseInt(value, 10, 64)
+			if err != nil {
+				return nil, false
+			}
+			pointer.size = size
+
+		}
+	}
+	if pointer.oid == "" {
+		return nil, false
+	}
+
+	return pointer, true
+}
+
+type lfsBatchRequest struct {
+	Operation string           `json:"operation"`
+	Transfers []string         `json:"transfers"`
+	Objects   []lfsBatchObject `json:"objects"`
+}
+
+type lfsBatchObject struct {
+	Oid     string `json:"oid"`
+	Size    int64  `json:"size"`
+	Actions struct {
+		Download *struct {
+			Href   string            `json:"href"`
+			Header map[string]string `json:"header"`
+		} `json:"download"`
+	} `json:"actions,omitempty"`
+	Error *struct {
+		Code    int    `json:"code"`
+		Message string `json:"message"`
+	} `json:"error,omitempty"`
+}
+
+type lfsBatchResponse struct {
+	Objects []lfsBatchObject `json:"objects"`
+}
+
+// lfsObject fetches the content of the Git LFS object that the pointer refers to via the Git LFS batch API.
+// The content is cached by its oid, which is the digest of the content.
+func (w *watcher) lfsObject(ctx context.Context, botType sarah.BotType, owner string, name string, pointer *lfsPointer) ([]byte, error) {
+	key := "lfs:" + pointer.oid
+	if content, ok := w.blobs.get(key); ok {
+		return content, nil
+	}
+
+	rest := w.restClient(owner, name)
+	if rest == nil {
+		return nil, fmt.Errorf("HTTP client is required to fetch Git LFS object %s: give WithToken or WithRESTClient", pointer.oid)
+	}
+
+	baseURL := w.lfsURL
+	if baseURL == "" {
+		baseURL = defaultLFSURL
+	}
+	body, err := json.Marshal(&lfsBatchRequest{
+		Operation: "download",
+		Transfers: []string{"basic"},
+		Objects:   []lfsBatchObject{{Oid: pointer.oid, Size: pointer.size}},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	url := fmt.Sprintf("%s/%s/%s.git/info/lfs/objects/batch", strings.TrimSuffix(baseURL, "/"), owner, name)
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", "application/vnd.git-lfs+json")
+	req.Header.Set("Content-Type", "application/vnd.git-lfs+json")
+
+	batch := &lfsBatchResponse{}
+	err = w.lfsDo(botType, repositoryName(owner, name), rest.httpClient, req, func(b []byte) error {
+		return json.Unmarshal(b, batch)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if len(batch.Objects) == 0 {
+		return nil, fmt.Errorf("no Git LFS object is returned for %s", pointer.oid)
+	}
+	object := batch.Objects[0]
+	if object.Error != nil {
+		return nil, fmt.Errorf("failed to fetch Git LFS object %s: %d %s", pointer.oid, object.Error.Code, object.Error.Message)
+	}
+	if object.Actions.Download == nil {
+		return nil, fmt.Errorf("no download action is given for Git LFS object %s", pointer.oid)
+	}
+
+	req, err = http.NewRequestWithContext(ctx, http.MethodGet, object.Actions.Download.Href, nil)
+	if err != nil {
+		return nil, err
+	}
+	for k, v := range object.Actions.Download.Header {
+		req.Header.Set(k, v)
+	}
+
+	var content []byte
+	err = w.lfsDo(botType, repositoryName(owner, name), lfsDownloadClient, req, func(b []byte) error {
+		content = b
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// Verify the content so a corrupted or a wrong object is never applied.
+	if fmt.Sprintf("%x", sha256.Sum256(content)) != pointer.oid {
+		return nil, fmt.Errorf("content of Git LFS object %s does not match its digest", pointer.oid)
+	}
+
+	w.blobs.put(key, content)
+	return content, nil
+}
+
+func (w *watcher) lfsDo(botType sarah.BotType, repository string, client *http.Client, req *http.Request, handle func([]byte) error) error {
+	resp, err := client.Do(req)
+	if err != nil {
+		return w.queryError(botType, repository, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return w.queryError(botType, repository, fmt.Errorf("non-200 OK status code: %s", resp.Status))
+	}
+
+	b, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+	return handle(b)
+}
+
+// blobContent returns the content of the given blob in the repository,
+// fetching the binary content via the REST API and the content stored in Git LFS via the Git LFS batch API.
+func (w *watcher) blobContent(ctx context.Context, botType sarah.BotType, owner string, name string, b blob) (string, error) {
+	if b.IsBinary {
+		content, err := w.rawBlob(ctx, botType, owner, name, string(b.Oid))
+		if err != nil {
+			return "", err
+		}
+		return string(content), nil
+	}
+
+	pointer, ok := parseLFSPointer(string(b.Text))
+	if !ok {
+		return string(b.Text), nil
+	}
+
+	content, err := w.lfsObject(ctx, botType, owner, name, pointer)
+	if err != nil {
+		return "", fmt.Errorf("failed to fetch the content stored in Git LFS: %w", err)
+	}
+	return string(content), nil
+}
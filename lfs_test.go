@@ -0,0 +1,162 @@
+package githubconfig
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"github.com/shurcooL/githubv4"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+)
+
+func TestParseLFSPointer(t *testing.T) {
+	tests := []struct {
+		content string
+		oid     string
+		size    int64
+		pointer bool
+	}{
+		{
+			content: "version https://git-lfs.github.com/spec/v1\noid sha256:abc\nsize 12\n",
+			oid:     "abc",
+			size:    12,
+			pointer: true,
+		},
+		{
+			content: "name: hello\n",
+			pointer: false,
+		},
+		{
+			content: "version https://git-lfs.github.com/spec/v1\nsize 12\n",
+			pointer: false,
+		},
+	}
+
+	for i, tt := range tests {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			pointer, ok := parseLFSPointer(tt.content)
+			if ok != tt.pointer {
+				t.Fatalf("Unexpected result is returned: %t.", ok)
+			}
+
+			if !ok {
+				return
+			}
+
+			if pointer.oid != tt.oid || pointer.size != tt.size {
+				t.Errorf("Unexpected pointer is returned: %+v.", pointer)
+			}
+		})
+	}
+}
+
+func TestWatcher_get_lfs(t *testing.T) {
+	content := "name: stored in LFS\n"
+	oid := fmt.Sprintf("%x", sha256.Sum256([]byte(content)))
+
+	downloads := 0
+	var server *httptest.Server
+	server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/oklahomer/config.git/info/lfs/objects/batch":
+			req := &lfsBatchRequest{}
+			err := json.NewDecoder(r.Body).Decode(req)
+			if err != nil || len(req.Objects) != 1 || req.Objects[0].Oid != oid || req.Operation != "download" {
+				t.Errorf("Unexpected batch request is given: %+v.", req)
+			}
+			if r.Header.Get("Authorization") != "Bearer secret" {
+				t.Errorf("Batch request is not authenticated: %s.", r.Header.Get("Authorization"))
+			}
+
+			_, _ = fmt.Fprintf(w, `{"objects": [{"oid": %q, "size": %d, "actions": {"download": {"href": "%s/objects/%s", "header": {"X-Token": "token"}}}}]}`, oid, len(content), server.URL, oid)
+
+		case "/objects/" + oid:
+			downloads++
+			if r.Header.Get("X-Token") != "token" {
+				t.Errorf("Download header is not given: %s.", r.Header.Get("X-Token"))
+			}
+			if r.Header.Get("Authorization") != "" {
+				t.Errorf("Token is sent to the storage host: %s.", r.Header.Get("Authorization"))
+			}
+			_, _ = w.Write([]byte(content))
+
+		default:
+			w.WriteHeader(http.StatusNotFound)
+
+		}
+	}))
+	defer server.Close()
+
+	pointer := fmt.Sprintf("version https://git-lfs.github.com/spec/v1\noid sha256:%s\nsize %d\n", oid, len(content))
+	w := &watcher{
+		client: &DummyQuerier{
+			QueryFunc: func(_ context.Context, q interface{}, _ map[string]interface{}) error {
+				typed := q.(*query)
+				typed.Repository.Head.Commit.Oid = "sha"
+				typed.Repository.Object.Tree.Entries = []entry{
+					{Name: "hello.yml", Type: "blob", Object: entryObject{Blob: blob{Oid: "pointer", Text: githubv4.String(pointer)}}},
+				}
+				return nil
+			},
+		},
+		config: &Config{Owner: "oklahomer", Name: "config", BaseDir: "config", Branch: "master"},
+		rest: &restClient{
+			httpClient: &http.Client{
+				Transport: &DummyRoundTripper{
+					RoundTripFunc: func(req *http.Request) (*http.Response, error) {
+						req.Header.Set("Authorization", "Bearer secret")
+						return http.DefaultTransport.RoundTrip(req)
+					},
+				},
+			},
+			baseURL: server.URL,
+		},
+		lfsURL: server.URL,
+	}
+
+	for i := 0; i < 2; i++ {
+		files, err := w.get(context.TODO(), "bot")
+		if err != nil {
+			t.Fatalf("Unexpected error is returned: %s.", err.Error())
+		}
+
+		f, ok := files["hello"]
+		if !ok {
+			t.Fatal("Expected file is not returned.")
+		}
+
+		if f.content != content {
+			t.Errorf("Unexpected content is set: %s.", f.content)
+		}
+	}
+
+	if downloads != 1 {
+		t.Errorf("Cached object is downloaded again: %d.", downloads)
+	}
+}
+
+func TestWatcher_lfsObject_error(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		_, _ = w.Write([]byte(`{"objects": [{"oid": "abc", "size": 1, "error": {"code": 404, "message": "Object does not exist"}}]}`))
+	}))
+	defer server.Close()
+
+	w := &watcher{
+		config: &Config{},
+		rest:   &restClient{httpClient: server.Client(), baseURL: server.URL},
+		lfsURL: server.URL,
+	}
+
+	_, err := w.lfsObject(context.TODO(), "bot", "oklahomer", "config", &lfsPointer{oid: "abc", size: 1})
+	if err == nil {
+		t.Fatal("Expected error is not returned.")
+	}
+
+	_, err = (&watcher{}).lfsObject(context.TODO(), "bot", "oklahomer", "config", &lfsPointer{oid: "abc", size: 1})
+	if err == nil {
+		t.Error("Expected error is not returned without HTTP client.")
+	}
+}
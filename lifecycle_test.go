@@ -0,0 +1,371 @@
+package githubconfig
+
+import (
+	"context"
+	"errors"
+	"github.com/oklahomer/go-sarah/v4"
+	"github.com/shurcooL/githubv4"
+	"strconv"
+	"testing"
+	"time"
+)
+
+func TestWatcher_Start(t *testing.T) {
+	w, err := New(&Config{Interval: time.Second, TimeOut: time.Second}, func(w *watcher) {
+		w.client = &DummyQuerier{}
+	})
+	if err != nil {
+		t.Fatalf("Unexpected error is returned: %s.", err.Error())
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	err = w.Start(ctx)
+	if err != nil {
+		t.Fatalf("Unexpected error is returned: %s.", err.Error())
+	}
+
+	err = w.Start(ctx)
+	if err == nil {
+		t.Error("Expected error is not returned on second call.")
+	}
+
+	_ = w.Stop()
+}
+
+func TestWatcher_notStarted(t *testing.T) {
+	w, err := New(&Config{Interval: time.Second, TimeOut: time.Second}, func(w *watcher) {
+		w.client = &DummyQuerier{}
+	})
+	if err != nil {
+		t.Fatalf("Unexpected error is returned: %s.", err.Error())
+	}
+
+	err = w.Read(context.Background(), "bot", "hello", &struct{}{})
+	if !errors.Is(err, WatcherNotStarted) {
+		t.Errorf("Expected error is not returned: %+v.", err)
+	}
+
+	_, err = w.WatchContext(context.Background(), "bot", "hello", func(_ context.Context, _ string) {})
+	if !errors.Is(err, WatcherNotStarted) {
+		t.Errorf("Expected error is not returned: %+v.", err)
+	}
+
+	err = w.Unwatch("bot")
+	if !errors.Is(err, WatcherNotStarted) {
+		t.Errorf("Expected error is not returned: %+v.", err)
+	}
+
+	err = w.UnwatchID("bot", "hello")
+	if !errors.Is(err, WatcherNotStarted) {
+		t.Errorf("Expected error is not returned: %+v.", err)
+	}
+}
+
+func TestWatcher_subscribe_canceled(t *testing.T) {
+	w := &watcher{
+		subscription: make(chan *subscription),
+		done:         make(chan struct{}),
+	}
+	w.started.Store(true)
+
+	// Nothing receives the subscription, so only the canceled context lets subscribe return.
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	_, err := w.subscribe(ctx, &subscription{botType: "bot", id: "hello"})
+	canceled := &CanceledError{}
+	if !errors.As(err, &canceled) {
+		t.Fatalf("Expected error is not returned: %+v.", err)
+	}
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("Unexpected wrapped error: %+v.", canceled.Err)
+	}
+}
+
+func TestWatcher_Stop(t *testing.T) {
+	t.Run("not started", func(t *testing.T) {
+		w, _ := New(&Config{Interval: time.Second}, func(w *watcher) {
+			w.client = &DummyQuerier{}
+		})
+
+		err := w.Stop()
+		if err == nil {
+			t.Error("Expected error is not returned.")
+		}
+	})
+
+	t.Run("drain", func(t *testing.T) {
+		w, _ := New(&Config{Interval: time.Second, TimeOut: time.Second}, func(w *watcher) {
+			w.client = &DummyQuerier{}
+		})
+		err := w.Start(context.Background())
+		if err != nil {
+			t.Fatalf("Unexpected error is returned: %s.", err.Error())
+		}
+
+		sub, err := w.WatchContext(context.Background(), "bot", "hello", func(_ context.Context, _ string) {})
+		if err != nil {
+			t.Fatalf("Unexpected error is returned: %s.", err.Error())
+		}
+
+		// Simulate an in-flight callback.
+		typed := w.(*watcher)
+		typed.callbacks.Add(1)
+		finished := make(chan struct{})
+		go func() {
+			time.Sleep(50 * time.Millisecond)
+			close(finished)
+			typed.callbacks.Done()
+		}()
+
+		err = w.Stop()
+		if err != nil {
+			t.Fatalf("Unexpected error is returned: %s.", err.Error())
+		}
+
+		select {
+		case <-finished:
+			// O.K.
+
+		default:
+			t.Error("Stop returned before the callback finishes.")
+
+		}
+
+		select {
+		case <-sub.Done():
+			// O.K.
+
+		default:
+			t.Error("Subscription is not ended.")
+
+		}
+
+		err = w.Read(context.Background(), "bot", "hello", &struct{}{})
+		if !errors.Is(err, WatcherStopped) {
+			t.Errorf("Expected error is not returned: %+v.", err)
+		}
+
+		_, err = w.WatchContext(context.Background(), "bot", "hello", func(_ context.Context, _ string) {})
+		if !errors.Is(err, WatcherStopped) {
+			t.Errorf("Expected error is not returned: %+v.", err)
+		}
+
+		err = w.Unwatch("bot")
+		if !errors.Is(err, WatcherStopped) {
+			t.Errorf("Expected error is not returned: %+v.", err)
+		}
+
+		err = w.UnwatchID("bot", "hello")
+		if !errors.Is(err, WatcherStopped) {
+			t.Errorf("Expected error is not returned: %+v.", err)
+		}
+	})
+}
+
+func TestWatcher_Pause(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	revision := 0
+	w := &watcher{
+		client: &DummyQuerier{
+			QueryFunc: func(_ context.Context, q interface{}, _ map[string]interface{}) error {
+				revision++
+				typed := q.(*query)
+				typed.Repository.Object.Tree.Entries = []entry{
+					{
+						Name:   "hello.json",
+						Object: entryObject{Blob: blob{Oid: githubv4.String(strconv.Itoa(revision)), Text: "{}"}},
+					},
+				}
+				return nil
+			},
+		},
+		config: &Config{
+			TimeOut:  100 * time.Millisecond,
+			Interval: 10 * time.Millisecond,
+		},
+		request:      make(chan *request),
+		subscription: make(chan *subscription),
+		pausing:      make(chan bool),
+	}
+	w.started.Store(true)
+	go w.operate(ctx)
+
+	err := w.Read(ctx, "bot", "hello", &struct{}{})
+	if err != nil {
+		t.Fatalf("Unexpected error is returned: %s.", err.Error())
+	}
+
+	err = w.Pause()
+	if err != nil {
+		t.Fatalf("Unexpected error is returned: %s.", err.Error())
+	}
+
+	called := make(chan struct{}, 10)
+	_, err = w.WatchContext(ctx, "bot", "hello", func(_ context.Context, _ string) {
+		called <- struct{}{}
+	})
+	if err != nil {
+		t.Fatalf("Unexpected error is returned: %s.", err.Error())
+	}
+
+	select {
+	case <-called:
+		t.Fatal("Callback must not be called while paused.")
+
+	case <-time.NewTimer(50 * time.Millisecond).C:
+		// O.K.
+
+	}
+
+	// The cache must remain available while paused.
+	err = w.Read(ctx, "bot", "hello", &struct{}{})
+	if err != nil {
+		t.Fatalf("Unexpected error is returned: %s.", err.Error())
+	}
+
+	err = w.Resume()
+	if err != nil {
+		t.Fatalf("Unexpected error is returned: %s.", err.Error())
+	}
+
+	select {
+	case <-called:
+		// O.K.
+
+	case <-time.NewTimer(1 * time.Second).C:
+		t.Error("Callback is not called after resume.")
+
+	}
+}
+
+func TestWatcher_SetInterval(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	queried := make(chan struct{}, 10)
+	w := &watcher{
+		client: &DummyQuerier{
+			QueryFunc: func(_ context.Context, q interface{}, _ map[string]interface{}) error {
+				queried <- struct{}{}
+				typed := q.(*query)
+				typed.Repository.Object.Tree.Entries = []entry{
+					{Name: "hello.json", Object: entryObject{Blob: blob{Oid: "oid", Text: "{}"}}},
+				}
+				return nil
+			},
+		},
+		config: &Config{
+			TimeOut:  100 * time.Millisecond,
+			Interval: time.Hour,
+		},
+		subscription:    make(chan *subscription),
+		reconfiguration: make(chan *reconfiguration),
+	}
+	w.started.Store(true)
+	go w.operate(ctx)
+
+	err := w.SetInterval(0)
+	if err == nil {
+		t.Error("Expected error is not returned.")
+	}
+
+	_, err = w.WatchContext(ctx, "bot", "hello", func(_ context.Context, _ string) {})
+	if err != nil {
+		t.Fatalf("Unexpected error is returned: %s.", err.Error())
+	}
+
+	err = w.SetInterval(10 * time.Millisecond)
+	if err != nil {
+		t.Fatalf("Unexpected error is returned: %s.", err.Error())
+	}
+
+	select {
+	case <-queried:
+		// O.K.
+
+	case <-time.NewTimer(1 * time.Second).C:
+		t.Error("Updated interval is not applied.")
+
+	}
+}
+
+func TestWatcher_SetBranch(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	w := &watcher{
+		client: &DummyQuerier{
+			QueryFunc: func(_ context.Context, q interface{}, v map[string]interface{}) error {
+				typed := q.(*query)
+				ref := string(v["ref"].(githubv4.String))
+				typed.Repository.Object.Tree.Entries = []entry{
+					{Name: "hello.json", Object: entryObject{Blob: blob{Oid: githubv4.String(ref), Text: "{}"}}},
+				}
+				return nil
+			},
+		},
+		config: &Config{
+			Branch:   "master",
+			TimeOut:  100 * time.Millisecond,
+			Interval: time.Hour,
+		},
+		request:         make(chan *request),
+		snapshot:        make(chan *snapshotRequest),
+		subscription:    make(chan *subscription),
+		reconfiguration: make(chan *reconfiguration),
+	}
+	w.started.Store(true)
+	go w.operate(ctx)
+
+	err := w.SetBranch("")
+	if err == nil {
+		t.Error("Expected error is not returned.")
+	}
+
+	for _, botType := range []sarah.BotType{"subscribed", "other"} {
+		err = w.Read(ctx, botType, "hello", &struct{}{})
+		if err != nil {
+			t.Fatalf("Unexpected error is returned: %s.", err.Error())
+		}
+	}
+
+	called := make(chan string, 1)
+	_, err = w.WatchContext(ctx, "subscribed", "hello", func(_ context.Context, id string) {
+		called <- id
+	})
+	if err != nil {
+		t.Fatalf("Unexpected error is returned: %s.", err.Error())
+	}
+
+	err = w.SetBranch("develop")
+	if err != nil {
+		t.Fatalf("Unexpected error is returned: %s.", err.Error())
+	}
+
+	select {
+	case <-called:
+		// O.K.
+
+	case <-time.NewTimer(1 * time.Second).C:
+		t.Fatal("Subscriber is not notified of the branch switch.")
+
+	}
+
+	if w.branch() != "develop" {
+		t.Errorf("Branch is not updated: %s.", w.branch())
+	}
+
+	version, err := w.Version(ctx, "other", "hello")
+	if err != nil {
+		t.Fatalf("Unexpected error is returned: %s.", err.Error())
+	}
+
+	if version.ObjectID != "develop" {
+		t.Errorf("Cache of other BotType is not invalidated: %s.", version.ObjectID)
+	}
+}
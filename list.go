@@ -0,0 +1,22 @@
+package githubconfig
+
+import (
+	"context"
+	"github.com/oklahomer/go-sarah/v4"
+	"sort"
+)
+
+func (w *watcher) List(ctx context.Context, botType sarah.BotType) ([]string, error) {
+	files, err := w.snapshotFiles(ctx, botType)
+	if err != nil {
+		return nil, err
+	}
+
+	ids := make([]string, 0, len(files))
+	for id := range files {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+
+	return ids, nil
+}
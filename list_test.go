@@ -0,0 +1,64 @@
+package githubconfig
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestWatcher_List(t *testing.T) {
+	tests := []struct {
+		files    map[string]*file
+		err      error
+		expected []string
+	}{
+		{
+			files: map[string]*file{
+				"world": newFile("world.json", "oid", "{}"),
+				"hello": newFile("hello.yml", "oid", ""),
+			},
+			expected: []string{"hello", "world"},
+		},
+		{
+			files:    map[string]*file{},
+			expected: []string{},
+		},
+		{
+			err: errors.New("dummy"),
+		},
+	}
+
+	for _, tt := range tests {
+		snapshotReq := make(chan *snapshotRequest, 1)
+		w := &watcher{
+			config: &Config{
+				TimeOut: 100 * time.Millisecond,
+			},
+			snapshot: snapshotReq,
+		}
+		w.started.Store(true)
+		go func() {
+			req := <-snapshotReq
+			req.result <- &snapshot{files: tt.files, err: tt.err}
+		}()
+
+		ids, err := w.List(context.Background(), "bot")
+
+		if tt.err != nil {
+			if !errors.Is(err, tt.err) {
+				t.Errorf("Expected error is not returned: %+v.", err)
+			}
+			continue
+		}
+
+		if err != nil {
+			t.Fatalf("Unexpected error is returned: %s.", err.Error())
+		}
+
+		if !reflect.DeepEqual(ids, tt.expected) {
+			t.Errorf("Unexpected ids are returned: %+v.", ids)
+		}
+	}
+}
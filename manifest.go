@@ -0,0 +1,72 @@
+package githubconfig
+
+import (
+	"context"
+	"fmt"
+	"github.com/oklahomer/go-sarah/v4"
+	"github.com/shurcooL/githubv4"
+	"gopkg.in/yaml.v3"
+	"path"
+	"time"
+)
+
+// manifestFileName is the name of the optional manifest file located at the base directory.
+// The manifest explicitly maps each BotType's configuration ids to arbitrary file paths in the repository as below:
+//
+//	slack:
+//	  hello: legacy/hello/config.yml
+//	  guess: legacy/games/guess.json
+//
+// This supports repositories with pre-existing structures that do not follow the {BotType}/{id}.{extension} convention.
+// A mapped file takes precedence over the convention-based file with the same id.
+const manifestFileName = "index.yml"
+
+// getMapped fetches the files mapped to the given BotType in the manifest.
+func (w *watcher) getMapped(ctx context.Context, botType sarah.BotType, src *Source, ref string, manifest string) (map[string]*file, error) {
+	mapping := map[string]map[string]string{}
+	err := yaml.Unmarshal([]byte(manifest), &mapping)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", manifestFileName, err)
+	}
+
+	files := map[string]*file{}
+	for id, p := range mapping[botType.String()] {
+		f, err := w.getFile(ctx, botType, src, ref, p)
+		if err != nil {
+			return nil, err
+		}
+		f.id = id
+		files[id] = f
+	}
+
+	return files, nil
+}
+
+// getFile fetches a single file located at the given path relative to the root of the given repository as of the given Git ref.
+func (w *watcher) getFile(ctx context.Context, botType sarah.BotType, src *Source, ref string, p string) (*file, error) {
+	q := &blobQuery{}
+	variables := map[string]interface{}{
+		"owner":      githubv4.String(src.Owner),
+		"name":       githubv4.String(src.Name),
+		"expression": githubv4.String(expression(ref, p)),
+	}
+	err := w.client.Query(ctx, q, variables)
+	if err != nil {
+		return nil, w.queryError(botType, repositoryName(src.Owner, src.Name), err)
+	}
+
+	b := q.Repository.Object.Blob
+	if b.Oid == "" {
+		return nil, fmt.Errorf("file %s is not found", p)
+	}
+	content, err := w.blobContent(ctx, botType, src.Owner, src.Name, b)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", p, err)
+	}
+	b.Text = githubv4.String(content)
+
+	f := w.newFile(path.Base(p), string(b.Oid), string(b.Text))
+	f.path = p
+	f.fetchedAt = time.Now()
+	return f, nil
+}
@@ -0,0 +1,102 @@
+package githubconfig
+
+import (
+	"context"
+	"github.com/oklahomer/go-sarah/v4"
+	"github.com/shurcooL/githubv4"
+	"testing"
+)
+
+func TestWatcher_get_withManifest(t *testing.T) {
+	var botType sarah.BotType = "slack"
+	manifest := "slack:\n  hello: legacy/hello/config.yml\nother:\n  bye: legacy/bye.json\n"
+	mappedContent := "name: mapped\n"
+	querier := &DummyQuerier{QueryFunc: func(_ context.Context, q interface{}, v map[string]interface{}) error {
+		switch typed := q.(type) {
+		case *query:
+			m := v["manifest"].(githubv4.String)
+			if string(m) != "master:config/index.yml" {
+				t.Errorf("Unexpected manifest expression is given: %s.", m)
+			}
+
+			typed.Repository.Manifest.Blob.Text = githubv4.String(manifest)
+			typed.Repository.Object.Tree.Entries = []entry{
+				{
+					Name: "hello.yml",
+					Object: entryObject{
+						Blob: blob{
+							Oid:  "conventional",
+							Text: "name: conventional\n",
+						},
+					},
+				},
+				{
+					Name: "world.yml",
+					Object: entryObject{
+						Blob: blob{
+							Oid:  "world",
+							Text: "name: world\n",
+						},
+					},
+				},
+			}
+
+		case *blobQuery:
+			e := v["expression"].(githubv4.String)
+			if string(e) != "master:legacy/hello/config.yml" {
+				t.Errorf("Unexpected expression is given: %s.", e)
+			}
+
+			typed.Repository.Object.Blob.Oid = "mapped"
+			typed.Repository.Object.Blob.Text = githubv4.String(mappedContent)
+
+		default:
+			t.Fatalf("Unexpected query is given: %T.", q)
+
+		}
+		return nil
+	}}
+	w := &watcher{
+		client: querier,
+		config: &Config{
+			BaseDir: "config",
+			Branch:  "master",
+		},
+	}
+
+	files, err := w.get(context.Background(), botType)
+	if err != nil {
+		t.Fatalf("Unexpected error is returned: %s.", err.Error())
+	}
+
+	if len(files) != 2 {
+		t.Fatalf("Unexpected number of files are returned: %d.", len(files))
+	}
+
+	hello := files["hello"]
+	if hello.content != mappedContent {
+		t.Errorf("Mapped file must take precedence: %s.", hello.content)
+	}
+
+	if hello.fileName != "config.yml" {
+		t.Errorf("Unexpected file name is set: %s.", hello.fileName)
+	}
+
+	if _, ok := files["world"]; !ok {
+		t.Error("Conventional file must be kept.")
+	}
+}
+
+func TestWatcher_getFile(t *testing.T) {
+	w := &watcher{
+		client: &DummyQuerier{QueryFunc: func(_ context.Context, _ interface{}, _ map[string]interface{}) error {
+			return nil
+		}},
+		config: &Config{Branch: "master"},
+	}
+
+	_, err := w.getFile(context.Background(), "bot", w.source("bot"), "master", "absent.yml")
+	if err == nil {
+		t.Error("Expected error is not returned for an absent file.")
+	}
+}
@@ -0,0 +1,59 @@
+package githubconfig
+
+import (
+	"github.com/oklahomer/go-sarah/v4"
+)
+
+// WithFileNameMapper sets a function that returns the candidate file names for the given id in the order of preference,
+// such as "hello.v2.yml" for the id "hello", so naming conventions such as versioned or prefixed file names can be expressed.
+// A file name is relative to the BotType's directory. When none of the candidates exists, the file is looked up by the id as usual.
+// A multi-document YAML file holds several ids, so the document declaring the requested id is picked from such a file.
+func WithFileNameMapper(mapper func(botType sarah.BotType, id string) []string) Option {
+	return func(w *watcher) {
+		w.fileNameMapper = mapper
+	}
+}
+
+// resolve returns the key of the given files that the requested id refers to.
+func (w *watcher) resolve(botType sarah.BotType, files map[string]*file, id string) string {
+	normalized := w.normalize(id)
+	if w.fileNameMapper != nil {
+		for _, name := range w.fileNameMapper(botType, id) {
+			var keys []string
+			for key, f := range files {
+				if f.fileName == name {
+					keys = append(keys, key)
+				}
+			}
+			if len(keys) == 1 {
+				return keys[0]
+			}
+
+			// The documents expanded from a multi-document file share its file name, so pick the one declaring the id.
+			for _, key := range keys {
+				if key == normalized {
+					return key
+				}
+			}
+		}
+	}
+
+	return normalized
+}
+
+// resolveSubscriptions returns the subscriptions indexed by the keys of the given files that the subscribed ids refer to.
+func (w *watcher) resolveSubscriptions(botType sarah.BotType, sub map[string][]*subscription, files map[string]*file) map[string][]*subscription {
+	if w.fileNameMapper == nil && !w.normalizeIDs {
+		return sub
+	}
+
+	resolved := map[string][]*subscription{}
+	for id, subs := range sub {
+		key := id
+		if id != allIDs {
+			key = w.resolve(botType, files, id)
+		}
+		resolved[key] = append(resolved[key], subs...)
+	}
+	return resolved
+}
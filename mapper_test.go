@@ -0,0 +1,147 @@
+package githubconfig
+
+import (
+	"context"
+	"github.com/oklahomer/go-sarah/v4"
+	"github.com/shurcooL/githubv4"
+	"strconv"
+	"testing"
+	"time"
+)
+
+func TestWatcher_resolve(t *testing.T) {
+	files := map[string]*file{
+		"hello":    newFile("hello.yml", "hello", ""),
+		"hello.v2": newFile("hello.v2.yml", "v2", ""),
+		"bot_echo": newFile("bot_echo.json", "echo", ""),
+		// Documents expanded from a multi-document file share its file name.
+		"alerts":  {id: "alerts", fileName: "tasks.yml", objectID: "tasks:alerts"},
+		"reports": {id: "reports", fileName: "tasks.yml", objectID: "tasks:reports"},
+	}
+	mapper := func(_ sarah.BotType, id string) []string {
+		switch id {
+		case "hello":
+			return []string{"hello.v3.yml", "hello.v2.yml"}
+
+		case "echo":
+			return []string{"bot_echo.json"}
+
+		case "alerts", "reports", "digest":
+			return []string{"tasks.yml"}
+
+		default:
+			return nil
+
+		}
+	}
+
+	tests := []struct {
+		id       string
+		expected string
+	}{
+		{id: "hello", expected: "hello.v2"},
+		{id: "echo", expected: "bot_echo"},
+		{id: "bot_echo", expected: "bot_echo"},
+		{id: "unknown", expected: "unknown"},
+		{id: "alerts", expected: "alerts"},
+		{id: "reports", expected: "reports"},
+		{id: "digest", expected: "digest"},
+	}
+
+	for i, tt := range tests {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			w := &watcher{}
+			WithFileNameMapper(mapper)(w)
+
+			key := w.resolve("bot", files, tt.id)
+			if key != tt.expected {
+				t.Errorf("Unexpected key is returned: %s.", key)
+			}
+		})
+	}
+}
+
+func TestWatcher_resolveSubscriptions(t *testing.T) {
+	files := map[string]*file{
+		"helloworld": newFile("helloworld.yml", "hello", ""),
+	}
+	hello := &subscription{id: "HelloWorld"}
+	all := &subscription{id: allIDs}
+	sub := map[string][]*subscription{
+		"HelloWorld": {hello},
+		allIDs:       {all},
+	}
+
+	w := &watcher{normalizeIDs: true}
+	resolved := w.resolveSubscriptions("bot", sub, files)
+
+	if len(resolved["helloworld"]) != 1 || resolved["helloworld"][0] != hello {
+		t.Errorf("Subscription is not resolved: %+v.", resolved)
+	}
+
+	if len(resolved[allIDs]) != 1 || resolved[allIDs][0] != all {
+		t.Errorf("Subscription for all ids must be kept: %+v.", resolved)
+	}
+}
+
+func TestWatcher_WatchContext_mappedFileName(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	revisions := make(chan string, 2)
+	revisions <- "first"
+	revisions <- "second"
+	revision := ""
+	w := &watcher{
+		client: &DummyQuerier{
+			QueryFunc: func(_ context.Context, q interface{}, _ map[string]interface{}) error {
+				select {
+				case revision = <-revisions:
+				default:
+				}
+
+				typed := q.(*query)
+				typed.Repository.Object.Tree.Entries = []entry{
+					{Name: "hello.v2.yml", Object: entryObject{Blob: blob{Oid: githubv4.String(revision), Text: "name: hello\n"}}},
+				}
+				return nil
+			},
+		},
+		config: &Config{
+			TimeOut:  100 * time.Millisecond,
+			Interval: 10 * time.Millisecond,
+		},
+		request:      make(chan *request),
+		subscription: make(chan *subscription),
+		fileNameMapper: func(_ sarah.BotType, id string) []string {
+			return []string{id + ".v2.yml"}
+		},
+	}
+	w.started.Store(true)
+	go w.operate(ctx)
+
+	err := w.Read(ctx, "bot", "hello", &struct{}{})
+	if err != nil {
+		t.Fatalf("Unexpected error is returned: %s.", err.Error())
+	}
+
+	called := make(chan struct{}, 1)
+	err = w.Watch(ctx, "bot", "hello", func() {
+		select {
+		case called <- struct{}{}:
+		default:
+		}
+	})
+	if err != nil {
+		t.Fatalf("Unexpected error is returned: %s.", err.Error())
+	}
+
+	select {
+	case <-called:
+		// O.K.
+
+	case <-time.NewTimer(1 * time.Second).C:
+		t.Error("Callback is not called.")
+
+	}
+}
@@ -0,0 +1,91 @@
+package githubconfig
+
+import (
+	"encoding/json"
+	"gopkg.in/yaml.v3"
+	"strconv"
+	"strings"
+)
+
+// metadataKey is the reserved top-level key that holds metadata the watcher interprets.
+//
+//	_meta:
+//	  enabled: false
+//	  min_app_version: 1.2.0
+//
+// A disabled configuration or a configuration that requires a newer application version behaves as if it does not exist,
+// so an author can park a configuration without deleting the file.
+const metadataKey = "_meta"
+
+type metadata struct {
+	Enabled       *bool  `json:"enabled" yaml:"enabled"`
+	MinAppVersion string `json:"min_app_version" yaml:"min_app_version"`
+}
+
+// parseMetadata extracts the metadata from the file content.
+// Metadata is supported in YAML and JSON families, and nil is returned when the file has none.
+func parseMetadata(f *file) *metadata {
+	holder := &struct {
+		Metadata *metadata `json:"_meta" yaml:"_meta"`
+	}{}
+
+	switch f.extension {
+	case ".yml", ".yaml":
+		_ = yaml.Unmarshal([]byte(f.content), holder)
+
+	case ".json":
+		_ = json.Unmarshal([]byte(f.content), holder)
+
+	case ".jsonc", ".json5":
+		b, err := standardizeJSON([]byte(f.content))
+		if err == nil {
+			_ = json.Unmarshal(b, holder)
+		}
+
+	}
+
+	// A malformed file is reported on decoding, so just ignore the error here.
+	return holder.Metadata
+}
+
+// active tells if the file is effective for the running application.
+func (w *watcher) active(f *file) bool {
+	meta := parseMetadata(f)
+	if meta == nil {
+		return true
+	}
+
+	if meta.Enabled != nil && !*meta.Enabled {
+		return false
+	}
+
+	if meta.MinAppVersion != "" && w.appVersion != "" {
+		return compareVersions(w.appVersion, meta.MinAppVersion) >= 0
+	}
+
+	return true
+}
+
+// compareVersions compares dot-separated numeric versions such as "1.2.0" and returns -1, 0 or 1.
+// A leading "v" is ignored, and a missing or non-numeric part is treated as 0.
+func compareVersions(a string, b string) int {
+	partsA := strings.Split(strings.TrimPrefix(a, "v"), ".")
+	partsB := strings.Split(strings.TrimPrefix(b, "v"), ".")
+	for i := 0; i < len(partsA) || i < len(partsB); i++ {
+		var x, y int
+		if i < len(partsA) {
+			x, _ = strconv.Atoi(partsA[i])
+		}
+		if i < len(partsB) {
+			y, _ = strconv.Atoi(partsB[i])
+		}
+
+		if x < y {
+			return -1
+		}
+		if x > y {
+			return 1
+		}
+	}
+	return 0
+}
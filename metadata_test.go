@@ -0,0 +1,114 @@
+package githubconfig
+
+import (
+	"context"
+	"strconv"
+	"testing"
+)
+
+func TestWithAppVersion(t *testing.T) {
+	opt := WithAppVersion("1.2.0")
+	w := &watcher{}
+
+	opt(w)
+
+	if w.appVersion != "1.2.0" {
+		t.Errorf("Unexpected version is set: %s.", w.appVersion)
+	}
+}
+
+func TestWatcher_active(t *testing.T) {
+	tests := []struct {
+		appVersion string
+		file       *file
+		expected   bool
+	}{
+		{
+			file:     newFile("hello.yml", "oid", "name: hello\n"),
+			expected: true,
+		},
+		{
+			file:     newFile("hello.yml", "oid", "_meta:\n  enabled: true\n"),
+			expected: true,
+		},
+		{
+			file:     newFile("hello.yml", "oid", "_meta:\n  enabled: false\n"),
+			expected: false,
+		},
+		{
+			file:     newFile("hello.json", "oid", `{"_meta": {"enabled": false}}`),
+			expected: false,
+		},
+		{
+			file:     newFile("hello.jsonc", "oid", "{\n  // parked\n  _meta: {enabled: false},\n}"),
+			expected: false,
+		},
+		{
+			file:     newFile("hello.yml", "oid", "_meta:\n  min_app_version: 1.2.0\n"),
+			expected: true,
+		},
+		{
+			appVersion: "1.10.0",
+			file:       newFile("hello.yml", "oid", "_meta:\n  min_app_version: 1.2.0\n"),
+			expected:   true,
+		},
+		{
+			appVersion: "v1.1.9",
+			file:       newFile("hello.yml", "oid", "_meta:\n  min_app_version: 1.2.0\n"),
+			expected:   false,
+		},
+		{
+			file:     newFile("hello.ini", "oid", "[_meta]\nenabled = false\n"),
+			expected: true,
+		},
+	}
+
+	for i, tt := range tests {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			w := &watcher{appVersion: tt.appVersion}
+
+			if w.active(tt.file) != tt.expected {
+				t.Errorf("Expected %t but was not.", tt.expected)
+			}
+		})
+	}
+}
+
+func TestCompareVersions(t *testing.T) {
+	tests := []struct {
+		a        string
+		b        string
+		expected int
+	}{
+		{a: "1.2.0", b: "1.2.0", expected: 0},
+		{a: "1.2", b: "1.2.0", expected: 0},
+		{a: "v1.10.0", b: "1.2.0", expected: 1},
+		{a: "1.2.0", b: "2", expected: -1},
+	}
+
+	for i, tt := range tests {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			actual := compareVersions(tt.a, tt.b)
+			if actual != tt.expected {
+				t.Errorf("Expected %d but was %d.", tt.expected, actual)
+			}
+		})
+	}
+}
+
+func TestWatcher_read_withMetadata(t *testing.T) {
+	cfg := &struct {
+		Name string `json:"name" yaml:"name"`
+	}{}
+
+	w := &watcher{strictDecode: true, disallowUnknownFields: true}
+	for _, f := range []*file{
+		newFile("hello.yml", "oid", "_meta:\n  enabled: true\nname: hello\n"),
+		newFile("hello.json", "oid", `{"_meta": {"enabled": true}, "name": "hello"}`),
+	} {
+		err := w.read(context.TODO(), "bot", f, cfg)
+		if err != nil {
+			t.Errorf("Metadata must be ignored on strict decoding: %s.", err.Error())
+		}
+	}
+}
@@ -0,0 +1,167 @@
+package githubconfig
+
+import (
+	"context"
+	"github.com/oklahomer/go-sarah/v4"
+	"github.com/prometheus/client_golang/prometheus"
+	"reflect"
+	"time"
+)
+
+// metricsNamespace is the prefix of the metric names. Wrap the Registerer with prometheus.WrapRegistererWithPrefix to add another one.
+const metricsNamespace = "sarah_githubconfig"
+
+// WithMetrics registers the watcher's Prometheus collectors on the given Registerer:
+// the count, latency and errors of GraphQL queries, cache hits and misses, the number of tracked files,
+// dispatched callbacks and the time of the last successful fetch of each BotType.
+// New returns the error when a collector fails to register, e.g. when another watcher already registered them on the same Registerer.
+// Wrap the Registerer of each watcher with prometheus.WrapRegistererWith to tell the watchers apart with a constant label.
+func WithMetrics(registerer prometheus.Registerer) Option {
+	return func(w *watcher) {
+		w.metricsRegisterer = registerer
+	}
+}
+
+// metrics holds the collectors registered by WithMetrics.
+type metrics struct {
+	queries       *prometheus.CounterVec
+	queryErrors   *prometheus.CounterVec
+	queryDuration *prometheus.HistogramVec
+	cacheHits     *prometheus.CounterVec
+	cacheMisses   *prometheus.CounterVec
+	filesTracked  *prometheus.GaugeVec
+	callbacks     *prometheus.CounterVec
+	lastFetch     *prometheus.GaugeVec
+}
+
+func newMetrics() *metrics {
+	return &metrics{
+		queries: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: metricsNamespace,
+			Name:      "graphql_queries_total",
+			Help:      "Number of GraphQL queries sent to GitHub.",
+		}, []string{"query"}),
+		queryErrors: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: metricsNamespace,
+			Name:      "graphql_query_errors_total",
+			Help:      "Number of GraphQL queries that failed.",
+		}, []string{"query"}),
+		queryDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: metricsNamespace,
+			Name:      "graphql_query_duration_seconds",
+			Help:      "Latency of GraphQL queries.",
+			Buckets:   prometheus.DefBuckets,
+		}, []string{"query"}),
+		cacheHits: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: metricsNamespace,
+			Name:      "cache_hits_total",
+			Help:      "Number of reads served from the cache.",
+		}, []string{"bot_type"}),
+		cacheMisses: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: metricsNamespace,
+			Name:      "cache_misses_total",
+			Help:      "Number of reads that fetched the configuration files.",
+		}, []string{"bot_type"}),
+		filesTracked: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Namespace: metricsNamespace,
+			Name:      "files_tracked",
+			Help:      "Number of configuration files tracked.",
+		}, []string{"bot_type"}),
+		callbacks: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: metricsNamespace,
+			Name:      "callbacks_dispatched_total",
+			Help:      "Number of subscriber callbacks dispatched.",
+		}, []string{"bot_type"}),
+		lastFetch: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Namespace: metricsNamespace,
+			Name:      "last_successful_fetch_timestamp_seconds",
+			Help:      "Unix time of the last successful fetch.",
+		}, []string{"bot_type"}),
+	}
+}
+
+func (m *metrics) collectors() []prometheus.Collector {
+	return []prometheus.Collector{m.queries, m.queryErrors, m.queryDuration, m.cacheHits, m.cacheMisses, m.filesTracked, m.callbacks, m.lastFetch}
+}
+
+// registerMetrics registers the collectors on the Registerer given by WithMetrics,
+// and makes the queries measured.
+func (w *watcher) registerMetrics() error {
+	if w.metricsRegisterer == nil {
+		return nil
+	}
+
+	m := newMetrics()
+	for _, c := range m.collectors() {
+		err := w.metricsRegisterer.Register(c)
+		if err != nil {
+			return err
+		}
+	}
+	w.metrics = m
+	w.client = &measuringQuerier{querier: w.client, metrics: m}
+	return nil
+}
+
+// measuringQuerier observes the latency and result of each query.
+type measuringQuerier struct {
+	querier querier
+	metrics *metrics
+}
+
+var _ querier = (*measuringQuerier)(nil)
+
+// Query sends the query with the underlying client and observes it.
+func (m *measuringQuerier) Query(ctx context.Context, q interface{}, variables map[string]interface{}) error {
+	started := time.Now()
+	err := m.querier.Query(ctx, q, variables)
+
+	name := queryName(q)
+	m.metrics.queries.WithLabelValues(name).Inc()
+	m.metrics.queryDuration.WithLabelValues(name).Observe(time.Since(started).Seconds())
+	if err != nil {
+		m.metrics.queryErrors.WithLabelValues(name).Inc()
+	}
+	return err
+}
+
+// queryName returns the name of the given query's type.
+func queryName(q interface{}) string {
+	t := reflect.TypeOf(q)
+	for t != nil && t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if t == nil || t.Name() == "" {
+		return "unknown"
+	}
+	return t.Name()
+}
+
+// observeCache observes whether the read of the given BotType is served from the cache.
+func (w *watcher) observeCache(botType sarah.BotType, hit bool) {
+	if w.metrics == nil {
+		return
+	}
+	if hit {
+		w.metrics.cacheHits.WithLabelValues(botType.String()).Inc()
+	} else {
+		w.metrics.cacheMisses.WithLabelValues(botType.String()).Inc()
+	}
+}
+
+// observeFetch observes the files of the given BotType that are successfully fetched and cached.
+func (w *watcher) observeFetch(botType sarah.BotType, files map[string]*file) {
+	if w.metrics == nil {
+		return
+	}
+	w.metrics.filesTracked.WithLabelValues(botType.String()).Set(float64(len(files)))
+	w.metrics.lastFetch.WithLabelValues(botType.String()).SetToCurrentTime()
+}
+
+// observeCallback observes the callback dispatched for the given BotType.
+func (w *watcher) observeCallback(botType sarah.BotType) {
+	if w.metrics == nil {
+		return
+	}
+	w.metrics.callbacks.WithLabelValues(botType.String()).Inc()
+}
@@ -0,0 +1,137 @@
+package githubconfig
+
+import (
+	"context"
+	"crypto/ed25519"
+	"errors"
+	"github.com/oklahomer/go-sarah/v4"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestWithMetrics(t *testing.T) {
+	registry := prometheus.NewRegistry()
+	w := &watcher{}
+	WithMetrics(registry)(w)
+
+	if w.metricsRegisterer != registry {
+		t.Errorf("Unexpected registerer is set: %#v.", w.metricsRegisterer)
+	}
+}
+
+func TestNew_withMetrics(t *testing.T) {
+	registry := prometheus.NewPedanticRegistry()
+	_, err := New(&Config{TimeOut: time.Second}, WithToken(context.TODO(), "token"), WithMetrics(registry))
+	if err != nil {
+		t.Fatalf("Unexpected error is returned: %s.", err.Error())
+	}
+
+	_, err = New(&Config{TimeOut: time.Second}, WithToken(context.TODO(), "token"), WithMetrics(registry))
+	var registered prometheus.AlreadyRegisteredError
+	if !errors.As(err, &registered) {
+		t.Errorf("Unexpected error is returned: %#v.", err)
+	}
+
+	labeled := prometheus.NewPedanticRegistry()
+	for _, name := range []string{"bot", "other"} {
+		registerer := prometheus.WrapRegistererWith(prometheus.Labels{"watcher": name}, labeled)
+		_, err = New(&Config{TimeOut: time.Second}, WithToken(context.TODO(), "token"), WithMetrics(registerer))
+		if err != nil {
+			t.Errorf("Unexpected error is returned: %s.", err.Error())
+		}
+	}
+}
+
+func TestMeasuringQuerier_Query(t *testing.T) {
+	m := newMetrics()
+	q := &measuringQuerier{
+		querier: &DummyQuerier{
+			QueryFunc: func(_ context.Context, q interface{}, _ map[string]interface{}) error {
+				if _, ok := q.(*commitQuery); ok {
+					return errors.New("ERROR")
+				}
+				return nil
+			},
+		},
+		metrics: m,
+	}
+
+	_ = q.Query(context.TODO(), &blobQuery{}, map[string]interface{}{})
+	_ = q.Query(context.TODO(), &blobQuery{}, map[string]interface{}{})
+	_ = q.Query(context.TODO(), &commitQuery{}, map[string]interface{}{})
+
+	if count := testutil.ToFloat64(m.queries.WithLabelValues("blobQuery")); count != 2 {
+		t.Errorf("Unexpected number of queries is counted: %f.", count)
+	}
+	if count := testutil.ToFloat64(m.queryErrors.WithLabelValues("commitQuery")); count != 1 {
+		t.Errorf("Unexpected number of errors is counted: %f.", count)
+	}
+	if count := testutil.CollectAndCount(m.queryDuration); count != 2 {
+		t.Errorf("Unexpected number of histograms is collected: %d.", count)
+	}
+}
+
+func TestQueryName(t *testing.T) {
+	if name := queryName(&blobQuery{}); name != "blobQuery" {
+		t.Errorf("Unexpected name is returned: %s.", name)
+	}
+	if name := queryName(&struct{}{}); name != "unknown" {
+		t.Errorf("Unexpected name is returned: %s.", name)
+	}
+}
+
+func TestWatcher_metrics(t *testing.T) {
+	public, private, _ := ed25519.GenerateKey(nil)
+	name := filepath.Join(t.TempDir(), "bundle.json")
+	exporter := &watcher{bundleExport: name, bundleSigningKey: private}
+	files := map[string]*file{
+		"hello": newFile("hello.yml", "abc", "message: hello\n"),
+		"bye":   newFile("bye.yml", "def", "message: bye\n"),
+	}
+	err := exporter.exportBundle("bot", files, nil)
+	if err != nil {
+		t.Fatalf("Unexpected error is returned: %s.", err.Error())
+	}
+
+	registry := prometheus.NewPedanticRegistry()
+	w, err := New(&Config{TimeOut: time.Second}, WithBundle(name, public), WithMetrics(registry))
+	if err != nil {
+		t.Fatalf("Unexpected error is returned: %s.", err.Error())
+	}
+	served := w.(*watcher)
+
+	cache := map[sarah.BotType]map[string]*file{}
+	for i := 0; i < 2; i++ {
+		_, err = served.cached(context.TODO(), cache, statuses{}, "bot")
+		if err != nil {
+			t.Fatalf("Unexpected error is returned: %s.", err.Error())
+		}
+	}
+	served.observeCallback("bot")
+
+	m := served.metrics
+	if hits, misses := testutil.ToFloat64(m.cacheHits.WithLabelValues("bot")), testutil.ToFloat64(m.cacheMisses.WithLabelValues("bot")); hits != 1 || misses != 1 {
+		t.Errorf("Unexpected cache hits and misses are observed: %f, %f.", hits, misses)
+	}
+	if files := testutil.ToFloat64(m.filesTracked.WithLabelValues("bot")); files != 2 {
+		t.Errorf("Unexpected number of files is observed: %f.", files)
+	}
+	if callbacks := testutil.ToFloat64(m.callbacks.WithLabelValues("bot")); callbacks != 1 {
+		t.Errorf("Unexpected number of callbacks is observed: %f.", callbacks)
+	}
+	if at := testutil.ToFloat64(m.lastFetch.WithLabelValues("bot")); at < float64(time.Now().Add(-time.Minute).Unix()) {
+		t.Errorf("Unexpected last fetch is observed: %f.", at)
+	}
+
+	_ = served.client.Query(context.TODO(), &blobQuery{}, map[string]interface{}{})
+	count, err := testutil.GatherAndCount(registry, "sarah_githubconfig_graphql_query_errors_total")
+	if err != nil {
+		t.Fatalf("Unexpected error is returned: %s.", err.Error())
+	}
+	if count != 1 {
+		t.Errorf("Offline query is not observed: %d.", count)
+	}
+}
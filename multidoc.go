@@ -0,0 +1,81 @@
+package githubconfig
+
+import (
+	"bufio"
+	"crypto/sha1"
+	"fmt"
+	"gopkg.in/yaml.v3"
+	"strings"
+)
+
+// expandDocuments expands a YAML file that consists of multiple documents into one file per document.
+// Each document must declare its id with the "id" key so closely related configurations can live in one file.
+// A file with a single document or a file of any other format is returned as-is.
+func expandDocuments(f *file) ([]*file, error) {
+	if f.extension != ".yml" && f.extension != ".yaml" {
+		return []*file{f}, nil
+	}
+
+	documents := splitDocuments(f.content)
+	if len(documents) <= 1 {
+		return []*file{f}, nil
+	}
+
+	var files []*file
+	for i, document := range documents {
+		header := &struct {
+			ID string `yaml:"id"`
+		}{}
+		err := yaml.Unmarshal([]byte(document), header)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse document %d in %s: %w", i, f.fileName, err)
+		}
+		if header.ID == "" {
+			return nil, fmt.Errorf("document %d in %s does not declare its id", i, f.fileName)
+		}
+
+		files = append(files, &file{
+			id:        header.ID,
+			fileName:  f.fileName,
+			path:      f.path,
+			commitSHA: f.commitSHA,
+			branch:    f.branch,
+			url:       f.url,
+			source:    f.source,
+			extension: f.extension,
+			// Derive the object ID from the document content so a change on one document does not notify subscribers of the others.
+			objectID:  fmt.Sprintf("%s:%x", f.objectID, sha1.Sum([]byte(document))),
+			content:   document,
+			fetchedAt: f.fetchedAt,
+			origin:    f,
+		})
+	}
+
+	return files, nil
+}
+
+// splitDocuments splits YAML content by document separators and drops empty documents.
+func splitDocuments(content string) []string {
+	var documents []string
+	current := &strings.Builder{}
+	flush := func() {
+		if strings.TrimSpace(current.String()) != "" {
+			documents = append(documents, current.String())
+		}
+		current.Reset()
+	}
+
+	scanner := bufio.NewScanner(strings.NewReader(content))
+	for scanner.Scan() {
+		line := scanner.Text()
+		if line == "---" || strings.HasPrefix(line, "--- ") || line == "..." {
+			flush()
+			continue
+		}
+		current.WriteString(line)
+		current.WriteString("\n")
+	}
+	flush()
+
+	return documents
+}
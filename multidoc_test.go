@@ -0,0 +1,77 @@
+package githubconfig
+
+import (
+	"context"
+	"strconv"
+	"testing"
+)
+
+func TestExpandDocuments(t *testing.T) {
+	t.Run("single", func(t *testing.T) {
+		tests := []*file{
+			newFile("hello.yml", "oid", "name: oklahomer\n"),
+			newFile("hello.yml", "oid", "---\nname: oklahomer\n"),
+			newFile("hello.json", "oid", `{"name": "oklahomer"}`),
+		}
+
+		for i, tt := range tests {
+			t.Run(strconv.Itoa(i), func(t *testing.T) {
+				files, err := expandDocuments(tt)
+				if err != nil {
+					t.Fatalf("Unexpected error is returned: %s.", err.Error())
+				}
+
+				if len(files) != 1 || files[0] != tt {
+					t.Errorf("The given file must be returned as-is: %+v.", files)
+				}
+			})
+		}
+	})
+
+	t.Run("multiple", func(t *testing.T) {
+		f := newFile("commands.yml", "oid", "id: hello\nname: foo\n---\nid: world\nname: bar\n")
+
+		files, err := expandDocuments(f)
+		if err != nil {
+			t.Fatalf("Unexpected error is returned: %s.", err.Error())
+		}
+
+		if len(files) != 2 {
+			t.Fatalf("Unexpected number of files are returned: %d.", len(files))
+		}
+
+		for i, expected := range []string{"hello", "world"} {
+			if files[i].id != expected {
+				t.Errorf("Unexpected id is set: %s.", files[i].id)
+			}
+
+			if files[i].origin != f {
+				t.Error("Original file is not set.")
+			}
+		}
+
+		if files[0].objectID == files[1].objectID {
+			t.Error("Each document must have its own object ID.")
+		}
+
+		cfg := &struct {
+			Name string `yaml:"name"`
+		}{}
+		err = (&watcher{}).read(context.TODO(), "bot", files[1], cfg)
+		if err != nil {
+			t.Fatalf("Unexpected error is returned: %s.", err.Error())
+		}
+		if cfg.Name != "bar" {
+			t.Errorf("Unexpected value is decoded: %s.", cfg.Name)
+		}
+	})
+
+	t.Run("without id", func(t *testing.T) {
+		f := newFile("commands.yml", "oid", "id: hello\n---\nname: bar\n")
+
+		_, err := expandDocuments(f)
+		if err == nil {
+			t.Error("Expected error is not returned.")
+		}
+	})
+}
@@ -0,0 +1,50 @@
+package githubconfig
+
+import (
+	"github.com/oklahomer/go-sarah/v4"
+	"sort"
+	"strings"
+)
+
+// WithIDNormalization normalizes configuration ids so that ids differing only in casing, dashes and underscores are equivalent.
+// e.g. a Command with the identifier "HelloWorld" reads hello_world.yml, hello-world.yml or helloworld.yml.
+// The ids passed to the subscribers and returned by List are the normalized ones.
+func WithIDNormalization() Option {
+	return func(w *watcher) {
+		w.normalizeIDs = true
+	}
+}
+
+// normalize returns the normalized form of the given id when the normalization is enabled.
+func (w *watcher) normalize(id string) string {
+	if !w.normalizeIDs {
+		return id
+	}
+	return strings.NewReplacer("-", "", "_", "").Replace(strings.ToLower(id))
+}
+
+// normalized returns the files indexed by the normalized ids.
+// When multiple files share the same normalized id, one is chosen in the same way as files sharing the same id.
+func (w *watcher) normalized(botType sarah.BotType, files map[string]*file) (map[string]*file, error) {
+	if !w.normalizeIDs {
+		return files, nil
+	}
+
+	// Iterate in order so the chosen file does not depend on the map iteration order.
+	ids := make([]string, 0, len(files))
+	for id := range files {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+
+	normalized := map[string]*file{}
+	for _, id := range ids {
+		f := files[id]
+		f.id = w.normalize(f.id)
+		err := w.put(botType, normalized, f)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return normalized, nil
+}
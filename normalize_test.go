@@ -0,0 +1,102 @@
+package githubconfig
+
+import (
+	"context"
+	"strconv"
+	"testing"
+	"time"
+)
+
+func TestWatcher_normalize(t *testing.T) {
+	tests := []struct {
+		normalizeIDs bool
+		id           string
+		expected     string
+	}{
+		{normalizeIDs: false, id: "HelloWorld", expected: "HelloWorld"},
+		{normalizeIDs: true, id: "HelloWorld", expected: "helloworld"},
+		{normalizeIDs: true, id: "hello_world", expected: "helloworld"},
+		{normalizeIDs: true, id: "hello-world", expected: "helloworld"},
+		{normalizeIDs: true, id: "alerts/Pager-Duty", expected: "alerts/pagerduty"},
+		{normalizeIDs: true, id: allIDs, expected: allIDs},
+	}
+
+	for i, tt := range tests {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			w := &watcher{normalizeIDs: tt.normalizeIDs}
+			id := w.normalize(tt.id)
+			if id != tt.expected {
+				t.Errorf("Unexpected id is returned: %s.", id)
+			}
+		})
+	}
+}
+
+func TestWatcher_normalized(t *testing.T) {
+	w := &watcher{}
+	WithIDNormalization()(w)
+
+	files := map[string]*file{
+		"hello_world": newFile("hello_world.yml", "yml", "name: yml\n"),
+		"Hello-World": newFile("Hello-World.json", "json", `{"name": "json"}`),
+		"guess":       newFile("guess.yml", "guess", "name: guess\n"),
+	}
+
+	normalized, err := w.normalized("bot", files)
+	if err != nil {
+		t.Fatalf("Unexpected error is returned: %s.", err.Error())
+	}
+
+	if len(normalized) != 2 {
+		t.Fatalf("Unexpected files are returned: %+v.", normalized)
+	}
+
+	f, ok := normalized["helloworld"]
+	if !ok {
+		t.Fatal("Expected file is not returned.")
+	}
+
+	// .yml precedes .json by default.
+	if f.objectID != "yml" {
+		t.Errorf("Unexpected file is chosen: %s.", f.fileName)
+	}
+
+	if f.id != "helloworld" {
+		t.Errorf("Unexpected id is set: %s.", f.id)
+	}
+}
+
+func TestWatcher_Read_normalizedID(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	w := &watcher{
+		client: &DummyQuerier{
+			QueryFunc: func(_ context.Context, q interface{}, _ map[string]interface{}) error {
+				typed := q.(*query)
+				typed.Repository.Head.Commit.Oid = "sha"
+				typed.Repository.Object.Tree.Entries = []entry{
+					{Name: "hello_world.yml", Object: entryObject{Blob: blob{Oid: "oid", Text: "name: hello\n"}}},
+				}
+				return nil
+			},
+		},
+		config:       &Config{Branch: "master", TimeOut: 100 * time.Millisecond, Interval: 1 * time.Minute},
+		request:      make(chan *request),
+		normalizeIDs: true,
+	}
+	w.started.Store(true)
+	go w.operate(ctx)
+
+	config := &struct {
+		Name string `yaml:"name"`
+	}{}
+	err := w.Read(ctx, "bot", "HelloWorld", config)
+	if err != nil {
+		t.Fatalf("Unexpected error is returned: %s.", err.Error())
+	}
+
+	if config.Name != "hello" {
+		t.Errorf("Unexpected value is read: %s.", config.Name)
+	}
+}
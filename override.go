@@ -0,0 +1,65 @@
+package githubconfig
+
+import (
+	"crypto/sha1"
+	"fmt"
+	"github.com/oklahomer/go-sarah/v4"
+	"io/fs"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// readLocal reads configuration files for the given BotType located under the local override directory.
+// A file in a subdirectory is identified by a slash-separated id such as "alerts/pagerduty" just like the one on GitHub.
+// A missing directory is not an error since the developer may override only some of the BotTypes.
+func (w *watcher) readLocal(botType sarah.BotType) (map[string]*file, error) {
+	dir := filepath.Join(w.localDir, botType.String())
+	_, err := os.Stat(dir)
+	if os.IsNotExist(err) {
+		return map[string]*file{}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read local override directory %s: %w", dir, err)
+	}
+
+	files := map[string]*file{}
+	err = filepath.WalkDir(dir, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return fmt.Errorf("failed to read local override directory %s: %w", p, err)
+		}
+		rel, err := filepath.Rel(dir, p)
+		if err != nil {
+			return err
+		}
+		rel = filepath.ToSlash(rel)
+
+		if d.IsDir() {
+			if rel != "." && (hidden(rel) || w.filters().excluded(rel)) {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if !d.Type().IsRegular() || !w.candidate(rel) {
+			return nil
+		}
+
+		content, err := ioutil.ReadFile(p)
+		if err != nil {
+			return fmt.Errorf("failed to read local override file %s: %w", d.Name(), err)
+		}
+
+		// The object ID is derived from the content so an edit on a local file is detected just like a new commit on GitHub.
+		objectID := fmt.Sprintf("local:%x", sha1.Sum(content))
+		f := w.newFile(rel, objectID, string(content))
+		f.id = w.kindless(f.id)
+		f.fetchedAt = time.Now()
+		return w.put(botType, files, f)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return files, nil
+}
@@ -0,0 +1,171 @@
+package githubconfig
+
+import (
+	"context"
+	"fmt"
+	"github.com/oklahomer/go-sarah/v4"
+	"github.com/shurcooL/githubv4"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestWithLocalOverride(t *testing.T) {
+	dir := "local/dir"
+	opt := WithLocalOverride(dir)
+	w := &watcher{}
+
+	opt(w)
+
+	if w.localDir != dir {
+		t.Errorf("Expected directory is not set: %s.", w.localDir)
+	}
+}
+
+func TestReadLocal(t *testing.T) {
+	t.Run("absent directory", func(t *testing.T) {
+		w := &watcher{localDir: filepath.Join(os.TempDir(), "absent", "directory")}
+		files, err := w.readLocal("botType")
+		if err != nil {
+			t.Fatalf("Unexpected error is returned: %s.", err.Error())
+		}
+
+		if len(files) != 0 {
+			t.Errorf("Unexpected files are returned: %+v.", files)
+		}
+	})
+
+	t.Run("files", func(t *testing.T) {
+		dir, err := ioutil.TempDir("", "githubconfig")
+		if err != nil {
+			t.Fatalf("Failed to create a temporary directory: %s.", err.Error())
+		}
+		defer os.RemoveAll(dir)
+
+		var botType sarah.BotType = "botType"
+		err = os.Mkdir(filepath.Join(dir, botType.String()), 0755)
+		if err != nil {
+			t.Fatalf("Failed to create a directory: %s.", err.Error())
+		}
+		content := "name: oklahomer\n"
+		err = ioutil.WriteFile(filepath.Join(dir, botType.String(), "hello.yml"), []byte(content), 0644)
+		if err != nil {
+			t.Fatalf("Failed to write a file: %s.", err.Error())
+		}
+		err = os.Mkdir(filepath.Join(dir, botType.String(), "nested"), 0755)
+		if err != nil {
+			t.Fatalf("Failed to create a directory: %s.", err.Error())
+		}
+
+		w := &watcher{localDir: dir}
+		files, err := w.readLocal(botType)
+		if err != nil {
+			t.Fatalf("Unexpected error is returned: %s.", err.Error())
+		}
+
+		if len(files) != 1 {
+			t.Fatalf("Unexpected number of files are returned: %d.", len(files))
+		}
+
+		f, ok := files["hello"]
+		if !ok {
+			t.Fatal("Expected file is not returned.")
+		}
+
+		if f.extension != ".yml" {
+			t.Errorf("Unexpected extension is set: %s.", f.extension)
+		}
+
+		if f.content != content {
+			t.Errorf("Unexpected content is set: %s.", f.content)
+		}
+
+		if !strings.HasPrefix(f.objectID, "local:") {
+			t.Errorf("Unexpected object ID is set: %s.", f.objectID)
+		}
+	})
+	t.Run("nested files", func(t *testing.T) {
+		dir, err := ioutil.TempDir("", "githubconfig")
+		if err != nil {
+			t.Fatalf("Failed to create a temporary directory: %s.", err.Error())
+		}
+		defer os.RemoveAll(dir)
+
+		var botType sarah.BotType = "botType"
+		err = os.MkdirAll(filepath.Join(dir, botType.String(), "alerts"), 0755)
+		if err != nil {
+			t.Fatalf("Failed to create a directory: %s.", err.Error())
+		}
+		err = ioutil.WriteFile(filepath.Join(dir, botType.String(), "alerts", "pagerduty.yml"), []byte("service: pagerduty\n"), 0644)
+		if err != nil {
+			t.Fatalf("Failed to write a file: %s.", err.Error())
+		}
+
+		w := &watcher{localDir: dir}
+		files, err := w.readLocal(botType)
+		if err != nil {
+			t.Fatalf("Unexpected error is returned: %s.", err.Error())
+		}
+
+		if _, ok := files["alerts/pagerduty"]; !ok {
+			t.Errorf("Expected file is not returned: %+v.", files)
+		}
+	})
+}
+
+func TestWatcher_get_withLocalOverride(t *testing.T) {
+	dir, err := ioutil.TempDir("", "githubconfig")
+	if err != nil {
+		t.Fatalf("Failed to create a temporary directory: %s.", err.Error())
+	}
+	defer os.RemoveAll(dir)
+
+	var botType sarah.BotType = "botType"
+	err = os.Mkdir(filepath.Join(dir, botType.String()), 0755)
+	if err != nil {
+		t.Fatalf("Failed to create a directory: %s.", err.Error())
+	}
+	localContent := `{"value": "local"}`
+	err = ioutil.WriteFile(filepath.Join(dir, botType.String(), "overridden.json"), []byte(localContent), 0644)
+	if err != nil {
+		t.Fatalf("Failed to write a file: %s.", err.Error())
+	}
+
+	remoteContent := `{"value": "remote"}`
+	w := &watcher{
+		client: &DummyQuerier{
+			QueryFunc: func(_ context.Context, q interface{}, _ map[string]interface{}) error {
+				typed := q.(*query)
+				for _, id := range []string{"overridden", "remote"} {
+					typed.Repository.Object.Tree.Entries = append(typed.Repository.Object.Tree.Entries, entry{
+						Name: githubv4.String(fmt.Sprintf("%s.json", id)),
+						Object: entryObject{
+							Blob: blob{
+								Oid:  "oid",
+								Text: githubv4.String(remoteContent),
+							},
+						},
+					})
+				}
+				return nil
+			},
+		},
+		config:   &Config{},
+		localDir: dir,
+	}
+
+	files, err := w.get(context.Background(), botType)
+	if err != nil {
+		t.Fatalf("Unexpected error is returned: %s.", err.Error())
+	}
+
+	if files["overridden"].content != localContent {
+		t.Errorf("Local content must take precedence: %s.", files["overridden"].content)
+	}
+
+	if files["remote"].content != remoteContent {
+		t.Errorf("Remote content must be kept when no local file is given: %s.", files["remote"].content)
+	}
+}
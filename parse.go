@@ -0,0 +1,81 @@
+package githubconfig
+
+import (
+	"errors"
+	"github.com/oklahomer/go-sarah/v4"
+	"gopkg.in/ini.v1"
+)
+
+// WithDecodeErrorHook sets a function that is called when a refreshed configuration file fails to decode.
+// The previous content that decoded successfully remains effective until a valid version lands,
+// so a typo in YAML does not break a running Command or ScheduledTask. The function is called once per broken revision.
+func WithDecodeErrorHook(hook func(err *DecodeError)) Option {
+	return func(w *watcher) {
+		w.decodeErrorHook = hook
+	}
+}
+
+// checkSyntax marks the files that fail to decode into a generic value as invalid so the previous content remains effective.
+// A file decoded by a custom Decoder is not checked since the type it expects is unknown.
+func (w *watcher) checkSyntax(botType sarah.BotType, files map[string]*file) {
+	for _, f := range files {
+		if f.invalid != nil {
+			continue
+		}
+
+		for _, layer := range f.layers() {
+			err := w.parse(botType, layer)
+			if err != nil {
+				f.invalid = &DecodeError{BotType: botType, ID: f.id, FileName: layer.fileName, Err: err}
+				break
+			}
+		}
+	}
+}
+
+func (w *watcher) parse(botType sarah.BotType, f *file) error {
+	if f.extension == "" && w.sniffing {
+		f = w.sniff(f)
+	}
+
+	if _, ok := w.decoders[f.extension]; ok {
+		return nil
+	}
+
+	switch f.extension {
+	case ".ini":
+		_, err := ini.Load([]byte(f.content))
+		return err
+
+	case ".env", ".properties":
+		var values map[string]string
+		return w.decode(botType, f, &values)
+
+	default:
+		var value interface{}
+		return w.decode(botType, f, &value)
+
+	}
+}
+
+// reportDecodeError passes the DecodeError of the given file to the hook unless the same revision is already reported.
+// This must be called from the operating goroutine.
+func (w *watcher) reportDecodeError(botType sarah.BotType, f *file) {
+	var decodeErr *DecodeError
+	if w.decodeErrorHook == nil || !errors.As(w.redactError(botType, f, f.invalid), &decodeErr) {
+		return
+	}
+
+	if w.rejected == nil {
+		w.rejected = map[sarah.BotType]map[string]string{}
+	}
+	if w.rejected[botType] == nil {
+		w.rejected[botType] = map[string]string{}
+	}
+	if w.rejected[botType][f.id] == f.revision() {
+		return
+	}
+	w.rejected[botType][f.id] = f.revision()
+
+	w.decodeErrorHook(decodeErr)
+}
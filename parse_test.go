@@ -0,0 +1,97 @@
+package githubconfig
+
+import (
+	"context"
+	"github.com/oklahomer/go-sarah/v4"
+	"strconv"
+	"testing"
+)
+
+func TestWithDecodeErrorHook(t *testing.T) {
+	w := &watcher{}
+	WithDecodeErrorHook(func(_ *DecodeError) {})(w)
+
+	if w.decodeErrorHook == nil {
+		t.Error("Hook is not set.")
+	}
+}
+
+func TestWatcher_checkSyntax(t *testing.T) {
+	tests := []struct {
+		file    *file
+		invalid bool
+	}{
+		{file: newFile("hello.yml", "oid", "name: hello\n"), invalid: false},
+		{file: newFile("hello.yml", "oid", "name: [\n"), invalid: true},
+		{file: newFile("hello.json", "oid", `{"name": "hello"}`), invalid: false},
+		{file: newFile("hello.json", "oid", `{"name": `), invalid: true},
+		{file: newFile("hello.ini", "oid", "name = hello\n"), invalid: false},
+		{file: newFile("hello.ini", "oid", "[section\n"), invalid: true},
+		{file: newFile("hello.env", "oid", "NAME=hello\n"), invalid: false},
+		{file: newFile("hello.env", "oid", "NAME\n"), invalid: true},
+	}
+
+	for i, tt := range tests {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			w := &watcher{}
+			w.checkSyntax("bot", map[string]*file{tt.file.id: tt.file})
+
+			if tt.invalid {
+				if _, ok := tt.file.invalid.(*DecodeError); !ok {
+					t.Errorf("Expected DecodeError is not set: %#v.", tt.file.invalid)
+				}
+				return
+			}
+
+			if tt.file.invalid != nil {
+				t.Errorf("Unexpected error is set: %s.", tt.file.invalid.Error())
+			}
+		})
+	}
+}
+
+func TestWatcher_refresh_keepLastGood(t *testing.T) {
+	var reported []*DecodeError
+	w := &watcher{
+		client: &DummyQuerier{
+			QueryFunc: func(_ context.Context, q interface{}, _ map[string]interface{}) error {
+				typed := q.(*query)
+				typed.Repository.Head.Commit.Oid = "sha"
+				typed.Repository.Object.Tree.Entries = []entry{
+					{Name: "hello.yml", Object: entryObject{Blob: blob{Oid: "broken", Text: "name: [\n"}}},
+				}
+				return nil
+			},
+		},
+		config: &Config{
+			Owner:   "oklahomer",
+			Name:    "config",
+			BaseDir: "config",
+			Branch:  "master",
+		},
+		decodeErrorHook: func(err *DecodeError) {
+			reported = append(reported, err)
+		},
+	}
+
+	good := newFile("hello.yml", "good", "name: hello\n")
+	cache := map[sarah.BotType]map[string]*file{
+		"bot": {"hello": good},
+	}
+	subscribed := subscriptions{"bot": {}}
+	for i := 0; i < 2; i++ {
+		w.refresh(context.TODO(), cache, subscribed, statuses{})
+	}
+
+	if cache["bot"]["hello"] != good {
+		t.Errorf("Previous content is not kept: %+v.", cache["bot"]["hello"])
+	}
+
+	if len(reported) != 1 {
+		t.Fatalf("Unexpected number of errors are reported: %d.", len(reported))
+	}
+
+	if reported[0].BotType != "bot" || reported[0].ID != "hello" || reported[0].FileName != "hello.yml" {
+		t.Errorf("Unexpected error is reported: %+v.", reported[0])
+	}
+}
@@ -0,0 +1,213 @@
+package githubconfig
+
+import (
+	"context"
+	"fmt"
+	"github.com/oklahomer/go-sarah/v4"
+	"github.com/shurcooL/githubv4"
+	"net/http"
+	"sort"
+	"strings"
+)
+
+// WithTokenPermissionCheck checks on Watcher.Start that the token can read every repository the configurations are fetched from,
+// and that it is not granted permissions broader than the watcher requires, so a misconfigured credential is caught before the first refresh silently fails.
+// The write permission is regarded as required only when WithCommitStatus, WithDeployment or WithFailureComment is given.
+// When strict is true, Start refuses to start with TokenPermissionError; otherwise the broad permissions are only logged.
+// A repository that cannot be read always prevents Start.
+func WithTokenPermissionCheck(strict bool) Option {
+	return func(w *watcher) {
+		w.tokenPermissionCheck = true
+		w.strictTokenPermission = strict
+	}
+}
+
+// TokenPermissionError is returned by Watcher.Start when the token is not granted the expected permissions on a repository.
+type TokenPermissionError struct {
+	// Repository is the queried repository in the form of "owner/name".
+	Repository string
+	// Readable tells whether the token can read the repository.
+	Readable bool
+	// Problems describes each unexpected permission.
+	Problems []string
+}
+
+// Error returns stringified representation of the error.
+func (err *TokenPermissionError) Error() string {
+	return fmt.Sprintf("token is not granted the expected permissions on %s: %s", err.Repository, strings.Join(err.Problems, ", "))
+}
+
+var _ error = (*TokenPermissionError)(nil)
+
+// broadScopes are the OAuth scopes of a classic token that the watcher never requires.
+// A scope that starts with any of the "admin:", "write:" and "delete:" prefixes is also regarded as broad.
+var broadScopes = map[string]bool{
+	"delete_repo": true,
+	"workflow":    true,
+	"user":        true,
+}
+
+// viewerPermissionQuery represents a Graphql query to fetch the permission of the token on a repository.
+// Formatted query is as below:
+//
+// 	query ($owner: String!, $name: String!) {
+//    repository(owner: $owner, name: $name) {
+//      viewerPermission
+//    }
+// 	}
+type viewerPermissionQuery struct {
+	Repository *struct {
+		ViewerPermission githubv4.RepositoryPermission
+	} `graphql:"repository(owner: $owner, name: $name)"`
+}
+
+// repositories returns the distinct repositories the configurations are fetched from.
+func (w *watcher) repositories() []*Source {
+	return distinctSources(w.sources(), func(src *Source) string {
+		return strings.ToLower(fmt.Sprintf("%s/%s", src.Owner, src.Name))
+	})
+}
+
+// sources returns the sources the configurations are fetched from: the default one, the ones of Config.Sources in the order of BotType, and the ones of Config.Layers.
+func (w *watcher) sources() []*Source {
+	sources := []*Source{w.source("")}
+	w.configMutex.RLock()
+	var botTypes []string
+	for botType := range w.config.Sources {
+		botTypes = append(botTypes, botType.String())
+	}
+	w.configMutex.RUnlock()
+	sort.Strings(botTypes)
+	for _, botType := range botTypes {
+		sources = append(sources, w.source(sarah.BotType(botType)))
+	}
+	return append(sources, w.layerSources()...)
+}
+
+// distinctSources returns the given sources without the ones whose key is already seen.
+func distinctSources(sources []*Source, key func(*Source) string) []*Source {
+	seen := map[string]bool{}
+	var distinct []*Source
+	for _, src := range sources {
+		k := key(src)
+		if seen[k] {
+			continue
+		}
+		seen[k] = true
+		distinct = append(distinct, src)
+	}
+	return distinct
+}
+
+// checkTokenPermission checks the permission of the token on each repository.
+// A *TokenPermissionError is returned when a repository cannot be read, or when the token is granted broad permissions and the check is strict.
+func (w *watcher) checkTokenPermission(ctx context.Context) error {
+	if !w.tokenPermissionCheck {
+		return nil
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, w.config.TimeOut)
+	defer cancel()
+
+	for _, src := range w.repositories() {
+		err := w.checkRepositoryPermission(ctx, src)
+		if err == nil {
+			continue
+		}
+
+		permissionErr, ok := err.(*TokenPermissionError)
+		if !ok || !permissionErr.Readable || w.strictTokenPermission {
+			return err
+		}
+		w.log("", "").Warnf("Token is granted broader permissions than required: %s", err.Error())
+	}
+
+	return nil
+}
+
+// checkRepositoryPermission checks the permission of the token on the given repository.
+func (w *watcher) checkRepositoryPermission(ctx context.Context, src *Source) error {
+	q := &viewerPermissionQuery{}
+	variables := map[string]interface{}{
+		"owner": githubv4.String(src.Owner),
+		"name":  githubv4.String(src.Name),
+	}
+	err := w.client.Query(ctx, q, variables)
+	if err != nil {
+		return w.queryError("", repositoryName(src.Owner, src.Name), err)
+	}
+
+	repository := fmt.Sprintf("%s/%s", src.Owner, src.Name)
+	if q.Repository == nil || q.Repository.ViewerPermission == "" {
+		return &TokenPermissionError{Repository: repository, Problems: []string{"repository cannot be read"}}
+	}
+
+	problems := w.permissionProblems(q.Repository.ViewerPermission)
+
+	scopes, err := w.tokenScopes(ctx, src)
+	if err != nil {
+		return err
+	}
+	var broad []string
+	for _, scope := range scopes {
+		if broadScopes[scope] || strings.HasPrefix(scope, "admin:") || strings.HasPrefix(scope, "write:") || strings.HasPrefix(scope, "delete:") {
+			broad = append(broad, scope)
+		}
+	}
+	if len(broad) > 0 {
+		problems = append(problems, fmt.Sprintf("broad scopes are granted: %s", strings.Join(broad, ", ")))
+	}
+
+	if len(problems) == 0 {
+		return nil
+	}
+	return &TokenPermissionError{Repository: repository, Readable: true, Problems: problems}
+}
+
+// permissionProblems returns the problems of the given permission on a readable repository.
+func (w *watcher) permissionProblems(permission githubv4.RepositoryPermission) []string {
+	switch permission {
+	case githubv4.RepositoryPermissionAdmin, githubv4.RepositoryPermissionMaintain:
+		return []string{fmt.Sprintf("%s permission is granted", strings.ToLower(string(permission)))}
+
+	case githubv4.RepositoryPermissionWrite:
+		if w.commitStatusName != "" || w.deploymentEnvironment != "" || w.failureComment {
+			return nil
+		}
+		return []string{"write permission is granted while only read is required"}
+
+	default:
+		return nil
+	}
+}
+
+// tokenScopes returns the OAuth scopes of a classic token that authenticates the REST API requests to the given repository.
+// Nil is returned when no REST client is given, or when the token has no scope such as a fine-grained token or a GitHub App token.
+func (w *watcher) tokenScopes(ctx context.Context, src *Source) ([]string, error) {
+	rest := w.restClient(src.Owner, src.Name)
+	if rest == nil {
+		return nil, nil
+	}
+
+	url := fmt.Sprintf("%s/repos/%s/%s", strings.TrimSuffix(rest.baseURL, "/"), src.Owner, src.Name)
+	req, err := http.NewRequestWithContext(ctx, http.MethodHead, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", "application/vnd.github+json")
+
+	resp, err := rest.httpClient.Do(req)
+	if err != nil {
+		return nil, w.queryError("", repositoryName(src.Owner, src.Name), err)
+	}
+	defer resp.Body.Close()
+
+	var scopes []string
+	for _, scope := range strings.Split(resp.Header.Get("X-OAuth-Scopes"), ",") {
+		scope = strings.TrimSpace(scope)
+		if scope != "" {
+			scopes = append(scopes, scope)
+		}
+	}
+	return scopes, nil
+}
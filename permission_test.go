@@ -0,0 +1,157 @@
+package githubconfig
+
+import (
+	"context"
+	"errors"
+	"github.com/oklahomer/go-sarah/v4"
+	"github.com/shurcooL/githubv4"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strconv"
+	"testing"
+	"time"
+)
+
+func TestWithTokenPermissionCheck(t *testing.T) {
+	w := &watcher{}
+	WithTokenPermissionCheck(true)(w)
+
+	if !w.tokenPermissionCheck {
+		t.Error("Token permission check is not enabled.")
+	}
+
+	if !w.strictTokenPermission {
+		t.Error("Token permission check is not strict.")
+	}
+}
+
+func TestWatcher_repositories(t *testing.T) {
+	w := &watcher{
+		config: &Config{
+			Owner: "oklahomer",
+			Name:  "config",
+			Sources: map[sarah.BotType]*Source{
+				"slack": {Name: "slack-config"},
+				"line":  {Owner: "Oklahomer", Name: "Config"},
+			},
+			Layers: []*Source{{Owner: "org", Name: "shared"}},
+		},
+	}
+
+	var repositories []string
+	for _, src := range w.repositories() {
+		repositories = append(repositories, src.Owner+"/"+src.Name)
+	}
+
+	expected := []string{"oklahomer/config", "oklahomer/slack-config", "org/shared"}
+	if !reflect.DeepEqual(repositories, expected) {
+		t.Errorf("Unexpected repositories are returned: %v.", repositories)
+	}
+}
+
+func TestWatcher_permissionProblems(t *testing.T) {
+	tests := []struct {
+		permission githubv4.RepositoryPermission
+		write      bool
+		expected   []string
+	}{
+		{permission: githubv4.RepositoryPermissionRead, expected: nil},
+		{permission: githubv4.RepositoryPermissionTriage, expected: nil},
+		{permission: githubv4.RepositoryPermissionWrite, write: true, expected: nil},
+		{permission: githubv4.RepositoryPermissionWrite, expected: []string{"write permission is granted while only read is required"}},
+		{permission: githubv4.RepositoryPermissionMaintain, write: true, expected: []string{"maintain permission is granted"}},
+		{permission: githubv4.RepositoryPermissionAdmin, write: true, expected: []string{"admin permission is granted"}},
+	}
+
+	for i, tt := range tests {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			w := &watcher{failureComment: tt.write}
+			problems := w.permissionProblems(tt.permission)
+			if !reflect.DeepEqual(problems, tt.expected) {
+				t.Errorf("Unexpected problems are returned: %v.", problems)
+			}
+		})
+	}
+}
+
+func TestWatcher_tokenScopes(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodHead || r.URL.Path != "/repos/oklahomer/config" {
+			t.Errorf("Unexpected request is made: %s %s.", r.Method, r.URL.Path)
+		}
+		w.Header().Set("X-OAuth-Scopes", "repo, admin:org, delete_repo")
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	w := &watcher{
+		rest: &restClient{httpClient: server.Client(), baseURL: server.URL},
+	}
+
+	scopes, err := w.tokenScopes(context.TODO(), &Source{Owner: "oklahomer", Name: "config"})
+	if err != nil {
+		t.Fatalf("Unexpected error is returned: %s.", err.Error())
+	}
+
+	expected := []string{"repo", "admin:org", "delete_repo"}
+	if !reflect.DeepEqual(scopes, expected) {
+		t.Errorf("Unexpected scopes are returned: %v.", scopes)
+	}
+}
+
+func TestWatcher_checkTokenPermission(t *testing.T) {
+	tests := []struct {
+		permission githubv4.RepositoryPermission
+		strict     bool
+		err        bool
+	}{
+		{permission: githubv4.RepositoryPermissionRead, strict: true, err: false},
+		{permission: githubv4.RepositoryPermissionAdmin, strict: true, err: true},
+		{permission: githubv4.RepositoryPermissionAdmin, strict: false, err: false},
+		{permission: "", strict: false, err: true},
+	}
+
+	for i, tt := range tests {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			w := &watcher{
+				client: &DummyQuerier{
+					QueryFunc: func(_ context.Context, q interface{}, v map[string]interface{}) error {
+						if v["owner"] != githubv4.String("oklahomer") || v["name"] != githubv4.String("config") {
+							t.Errorf("Unexpected repository is given: %s/%s.", v["owner"], v["name"])
+						}
+						if tt.permission != "" {
+							q.(*viewerPermissionQuery).Repository = &struct {
+								ViewerPermission githubv4.RepositoryPermission
+							}{ViewerPermission: tt.permission}
+						}
+						return nil
+					},
+				},
+				config: &Config{
+					Owner:   "oklahomer",
+					Name:    "config",
+					TimeOut: 100 * time.Millisecond,
+				},
+				tokenPermissionCheck:  true,
+				strictTokenPermission: tt.strict,
+			}
+
+			err := w.checkTokenPermission(context.TODO())
+			if !tt.err {
+				if err != nil {
+					t.Errorf("Unexpected error is returned: %s.", err.Error())
+				}
+				return
+			}
+
+			var permissionErr *TokenPermissionError
+			if !errors.As(err, &permissionErr) {
+				t.Fatalf("Expected error is not returned: %#v.", err)
+			}
+			if permissionErr.Repository != "oklahomer/config" {
+				t.Errorf("Unexpected error is returned: %+v.", permissionErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,75 @@
+package githubconfig
+
+import (
+	"fmt"
+	"github.com/oklahomer/go-sarah/v4"
+	"strings"
+)
+
+// DuplicateConfigError is returned when multiple files map to the same id while WithDuplicateDetection is set.
+type DuplicateConfigError struct {
+	BotType   sarah.BotType
+	ID        string
+	FileNames []string
+}
+
+// Error returns stringified representation of the error.
+func (err *DuplicateConfigError) Error() string {
+	return fmt.Sprintf("multiple files map to %s:%s: %s", err.BotType, err.ID, strings.Join(err.FileNames, ", "))
+}
+
+var _ error = (*DuplicateConfigError)(nil)
+
+// defaultExtensionPrecedence is the order of file extensions to choose a file from when multiple files share the same id.
+var defaultExtensionPrecedence = []string{".yaml", ".yml", ".json"}
+
+// put stores the file unless another file with the same id and a higher precedence is already stored.
+// This way, the chosen file does not depend on the order of files.
+// When duplicate detection is enabled, a DuplicateConfigError is returned instead.
+func (w *watcher) put(botType sarah.BotType, files map[string]*file, f *file) error {
+	current, ok := files[f.id]
+	if ok && w.detectDuplicate {
+		return &DuplicateConfigError{
+			BotType:   botType,
+			ID:        f.id,
+			FileNames: []string{current.fileName, f.fileName},
+		}
+	}
+
+	if ok && w.precedes(current, f) {
+		return nil
+	}
+	files[f.id] = f
+	return nil
+}
+
+// precedes tells if the file a takes precedence over the file b.
+// Files with the same extension, such as "Hello.yml" and "hello.yml" with WithIDNormalization, are ordered by their paths and then by their names.
+func (w *watcher) precedes(a *file, b *file) bool {
+	rankA := w.rank(a.extension)
+	rankB := w.rank(b.extension)
+	if rankA != rankB {
+		return rankA < rankB
+	}
+	if a.extension != b.extension {
+		return a.extension < b.extension
+	}
+	if a.path != b.path {
+		return a.path < b.path
+	}
+	return a.fileName <= b.fileName
+}
+
+func (w *watcher) rank(extension string) int {
+	precedence := w.extensionPrecedence
+	if precedence == nil {
+		precedence = defaultExtensionPrecedence
+	}
+
+	for i, e := range precedence {
+		if e == extension {
+			return i
+		}
+	}
+	return len(precedence)
+}
@@ -0,0 +1,139 @@
+package githubconfig
+
+import (
+	"errors"
+	"reflect"
+	"strconv"
+	"testing"
+)
+
+func TestWithExtensionPrecedence(t *testing.T) {
+	extensions := []string{".json", ".yml"}
+	opt := WithExtensionPrecedence(extensions...)
+	w := &watcher{}
+
+	opt(w)
+
+	if !reflect.DeepEqual(w.extensionPrecedence, extensions) {
+		t.Errorf("Unexpected precedence is set: %+v.", w.extensionPrecedence)
+	}
+}
+
+func TestWatcher_put(t *testing.T) {
+	tests := []struct {
+		precedence []string
+		fileNames  []string
+		expected   string
+	}{
+		{
+			fileNames: []string{"hello.json", "hello.yml", "hello.yaml"},
+			expected:  "hello.yaml",
+		},
+		{
+			fileNames: []string{"hello.yaml", "hello.yml", "hello.json"},
+			expected:  "hello.yaml",
+		},
+		{
+			fileNames: []string{"hello.toml", "hello.json"},
+			expected:  "hello.json",
+		},
+		{
+			fileNames: []string{"hello.toml", "hello.ini"},
+			expected:  "hello.ini",
+		},
+		{
+			precedence: []string{".json", ".yml"},
+			fileNames:  []string{"hello.yml", "hello.json"},
+			expected:   "hello.json",
+		},
+	}
+
+	for i, tt := range tests {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			w := &watcher{extensionPrecedence: tt.precedence}
+			files := map[string]*file{}
+			for _, name := range tt.fileNames {
+				err := w.put("bot", files, newFile(name, "oid", ""))
+				if err != nil {
+					t.Fatalf("Unexpected error is returned: %s.", err.Error())
+				}
+			}
+
+			if files["hello"].fileName != tt.expected {
+				t.Errorf("Unexpected file is chosen: %s.", files["hello"].fileName)
+			}
+		})
+	}
+}
+
+func TestWatcher_put_sameExtension(t *testing.T) {
+	commands := newFile("hello.yml", "commands", "")
+	commands.path = "config/bot/commands/hello.yml"
+	tasks := newFile("hello.yml", "tasks", "")
+	tasks.path = "config/bot/tasks/hello.yml"
+
+	for i, order := range [][]*file{{commands, tasks}, {tasks, commands}} {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			w := &watcher{}
+			files := map[string]*file{}
+			for _, f := range order {
+				err := w.put("bot", files, f)
+				if err != nil {
+					t.Fatalf("Unexpected error is returned: %s.", err.Error())
+				}
+			}
+
+			if files["hello"] != commands {
+				t.Errorf("Unexpected file is chosen: %s.", files["hello"].path)
+			}
+		})
+	}
+}
+
+func TestWithDuplicateDetection(t *testing.T) {
+	opt := WithDuplicateDetection()
+	w := &watcher{}
+
+	opt(w)
+
+	if !w.detectDuplicate {
+		t.Error("Duplicate detection is not enabled.")
+	}
+}
+
+func TestWatcher_put_withDuplicateDetection(t *testing.T) {
+	w := &watcher{detectDuplicate: true}
+	files := map[string]*file{}
+
+	err := w.put("bot", files, newFile("hello.yml", "oid", ""))
+	if err != nil {
+		t.Fatalf("Unexpected error is returned: %s.", err.Error())
+	}
+
+	err = w.put("bot", files, newFile("hello.json", "oid", ""))
+
+	var dup *DuplicateConfigError
+	if !errors.As(err, &dup) {
+		t.Fatalf("Expected error is not returned: %+v.", err)
+	}
+
+	if dup.BotType != "bot" || dup.ID != "hello" {
+		t.Errorf("Unexpected error values are set: %+v.", dup)
+	}
+
+	if !reflect.DeepEqual(dup.FileNames, []string{"hello.yml", "hello.json"}) {
+		t.Errorf("Unexpected file names are set: %+v.", dup.FileNames)
+	}
+}
+
+func TestDuplicateConfigError_Error(t *testing.T) {
+	err := &DuplicateConfigError{
+		BotType:   "bot",
+		ID:        "hello",
+		FileNames: []string{"hello.yml", "hello.json"},
+	}
+
+	if err.Error() != "multiple files map to bot:hello: hello.yml, hello.json" {
+		t.Errorf("Unexpected message is returned: %s.", err.Error())
+	}
+}
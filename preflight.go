@@ -0,0 +1,122 @@
+package githubconfig
+
+import (
+	"context"
+	"fmt"
+	"github.com/oklahomer/go-sarah/v4"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// WithPrototype registers the value that Verify decodes the given id's configuration into, such as the config struct of a Command.
+// The prototype itself is left intact since a fresh copy is decoded into on each verification.
+// Without a prototype, Verify only checks that the configuration file is decodable.
+func WithPrototype(botType sarah.BotType, id string, prototype interface{}) Option {
+	return func(w *watcher) {
+		if w.prototypes == nil {
+			w.prototypes = map[sarah.BotType]map[string]interface{}{}
+		}
+		if w.prototypes[botType] == nil {
+			w.prototypes[botType] = map[string]interface{}{}
+		}
+		w.prototypes[botType][id] = prototype
+	}
+}
+
+// VerificationError is returned by Verify when any of the given configurations fails to be fetched or decoded.
+type VerificationError struct {
+	// Failures holds the error of each failed configuration by BotType and id.
+	Failures map[sarah.BotType]map[string]error
+}
+
+// Error returns stringified representation of the error.
+func (err *VerificationError) Error() string {
+	var failures []string
+	for botType, errs := range err.Failures {
+		for id, e := range errs {
+			failures = append(failures, fmt.Sprintf("%s:%s: %s", botType, id, e.Error()))
+		}
+	}
+	sort.Strings(failures)
+	return fmt.Sprintf("failed to verify %d configurations: %s", len(failures), strings.Join(failures, "; "))
+}
+
+// Unwrap returns the underlying errors.
+func (err *VerificationError) Unwrap() []error {
+	var errs []error
+	for _, e := range err.Failures {
+		for _, failure := range e {
+			errs = append(errs, failure)
+		}
+	}
+	return errs
+}
+
+var _ error = (*VerificationError)(nil)
+
+func (w *watcher) Verify(ctx context.Context, configs map[sarah.BotType][]string) error {
+	failures := map[sarah.BotType]map[string]error{}
+	fail := func(botType sarah.BotType, id string, err error) {
+		if failures[botType] == nil {
+			failures[botType] = map[string]error{}
+		}
+		failures[botType][id] = err
+	}
+
+	for botType, ids := range configs {
+		files, err := w.verificationFiles(ctx, botType)
+		for _, id := range ids {
+			if err != nil {
+				fail(botType, id, err)
+				continue
+			}
+
+			f := files[w.resolve(botType, files, id)]
+			if f == nil {
+				fail(botType, id, &sarah.ConfigNotFoundError{BotType: botType, ID: id})
+				continue
+			}
+
+			if f.invalid != nil {
+				fail(botType, id, w.redactError(botType, f, f.invalid))
+				continue
+			}
+
+			prototype, ok := w.prototypes[botType][id]
+			if !ok {
+				continue
+			}
+			err := w.read(ctx, botType, f, copyPrototype(prototype))
+			if err != nil {
+				fail(botType, id, w.redactError(botType, f, err))
+			}
+		}
+	}
+
+	if len(failures) == 0 {
+		return nil
+	}
+	return &VerificationError{Failures: failures}
+}
+
+// verificationFiles fetches the given BotType's configuration files without touching the cache,
+// so Verify works in CI without starting the watcher.
+func (w *watcher) verificationFiles(ctx context.Context, botType sarah.BotType) (map[string]*file, error) {
+	ctx, cancel := context.WithTimeout(ctx, w.config.TimeOut)
+	defer cancel()
+
+	return w.get(ctx, botType)
+}
+
+// copyPrototype returns a pointer to a copy of the given prototype so decoding does not modify the prototype.
+func copyPrototype(prototype interface{}) interface{} {
+	value := reflect.ValueOf(prototype)
+	if value.Kind() == reflect.Ptr {
+		value = value.Elem()
+	}
+
+	copied := reflect.New(value.Type())
+	copied.Elem().Set(value)
+	return copied.Interface()
+}
@@ -0,0 +1,107 @@
+package githubconfig
+
+import (
+	"context"
+	"errors"
+	"github.com/oklahomer/go-sarah/v4"
+	"testing"
+	"time"
+)
+
+func TestWithPrototype(t *testing.T) {
+	prototype := &struct{}{}
+	w := &watcher{}
+	WithPrototype("bot", "hello", prototype)(w)
+
+	if w.prototypes["bot"]["hello"] != prototype {
+		t.Errorf("Unexpected prototype is set: %#v.", w.prototypes)
+	}
+}
+
+func TestCopyPrototype(t *testing.T) {
+	type config struct {
+		Text string
+	}
+
+	for _, prototype := range []interface{}{&config{Text: "default"}, config{Text: "default"}} {
+		copied, ok := copyPrototype(prototype).(*config)
+		if !ok {
+			t.Fatalf("Unexpected type is returned: %T.", copied)
+		}
+
+		if copied.Text != "default" {
+			t.Errorf("Value is not copied: %+v.", copied)
+		}
+
+		copied.Text = "modified"
+		if p, ok := prototype.(*config); ok && p.Text != "default" {
+			t.Error("Prototype is modified.")
+		}
+	}
+}
+
+func TestWatcher_Verify(t *testing.T) {
+	type config struct {
+		Count int `json:"count"`
+	}
+	prototype := &config{Count: 1}
+
+	w := &watcher{
+		client: &DummyQuerier{
+			QueryFunc: func(_ context.Context, q interface{}, _ map[string]interface{}) error {
+				typed := q.(*query)
+				typed.Repository.Head.Commit.Oid = "sha"
+				typed.Repository.Object.Tree.Entries = []entry{
+					{Name: "hello.json", Object: entryObject{Blob: blob{Oid: "hello", Text: `{"count": 10}`}}},
+					{Name: "count.json", Object: entryObject{Blob: blob{Oid: "count", Text: `{"count": "ten"}`}}},
+					{Name: "broken.json", Object: entryObject{Blob: blob{Oid: "broken", Text: `{"count": `}}},
+				}
+				return nil
+			},
+		},
+		config: &Config{
+			Branch:  "master",
+			TimeOut: 100 * time.Millisecond,
+		},
+		prototypes: map[sarah.BotType]map[string]interface{}{
+			"bot": {"hello": prototype, "count": prototype},
+		},
+	}
+
+	err := w.Verify(context.TODO(), map[sarah.BotType][]string{
+		"bot": {"hello", "count", "broken", "absent"},
+	})
+
+	var verificationErr *VerificationError
+	if !errors.As(err, &verificationErr) {
+		t.Fatalf("Expected error is not returned: %#v.", err)
+	}
+
+	failures := verificationErr.Failures["bot"]
+	if len(failures) != 3 {
+		t.Fatalf("Unexpected failures are returned: %+v.", failures)
+	}
+
+	var decodeErr *DecodeError
+	if !errors.As(failures["count"], &decodeErr) {
+		t.Errorf("Unexpected error is returned for count: %#v.", failures["count"])
+	}
+	if !errors.As(failures["broken"], &decodeErr) {
+		t.Errorf("Unexpected error is returned for broken: %#v.", failures["broken"])
+	}
+	var notFoundErr *sarah.ConfigNotFoundError
+	if !errors.As(failures["absent"], &notFoundErr) {
+		t.Errorf("Unexpected error is returned for absent: %#v.", failures["absent"])
+	}
+
+	if prototype.Count != 1 {
+		t.Errorf("Prototype is modified: %+v.", prototype)
+	}
+
+	err = w.Verify(context.TODO(), map[sarah.BotType][]string{
+		"bot": {"hello"},
+	})
+	if err != nil {
+		t.Errorf("Unexpected error is returned: %s.", err.Error())
+	}
+}
@@ -0,0 +1,138 @@
+package githubconfig
+
+import (
+	"context"
+	"fmt"
+	"github.com/shurcooL/githubv4"
+	"strings"
+)
+
+// WithBranchProtectionCheck checks on Watcher.Start that the branch of each source the configurations are fetched from is protected,
+// so that a change requires an approving review and the history cannot be rewritten by a force push.
+// The sources are the default repository, the ones of Config.Sources and the ones of Config.Layers, each checked at its own branch.
+// When strict is true, Start refuses to start with BranchProtectionError; otherwise the missing protections are only logged.
+func WithBranchProtectionCheck(strict bool) Option {
+	return func(w *watcher) {
+		w.branchProtectionCheck = true
+		w.strictBranchProtection = strict
+	}
+}
+
+// BranchProtectionError is returned by Watcher.Start when the configured branch is not protected as expected.
+type BranchProtectionError struct {
+	// Repository is the queried repository in the form of "owner/name".
+	Repository string
+	Branch     string
+	// Problems describes each missing protection.
+	Problems []string
+}
+
+// Error returns stringified representation of the error.
+func (err *BranchProtectionError) Error() string {
+	return fmt.Sprintf("branch %s on %s is not protected: %s", err.Branch, err.Repository, strings.Join(err.Problems, ", "))
+}
+
+var _ error = (*BranchProtectionError)(nil)
+
+// branchProtectionQuery represents a Graphql query to fetch the protection rule of a branch.
+// Formatted query is as below:
+//
+// 	query ($owner: String!, $name: String!, $branch: String!) {
+//    repository(owner: $owner, name: $name) {
+//      ref(qualifiedName: $branch) {
+//        branchProtectionRule {
+//          requiresApprovingReviews
+//          requiredApprovingReviewCount
+//          allowsForcePushes
+//        }
+//      }
+//    }
+// 	}
+type branchProtectionQuery struct {
+	Repository struct {
+		Ref *struct {
+			BranchProtectionRule *branchProtectionRule
+		} `graphql:"ref(qualifiedName: $branch)"`
+	} `graphql:"repository(owner: $owner, name: $name)"`
+}
+
+type branchProtectionRule struct {
+	RequiresApprovingReviews     githubv4.Boolean
+	RequiredApprovingReviewCount githubv4.Int
+	AllowsForcePushes            githubv4.Boolean
+}
+
+// checkBranchProtection checks the protection rule of the branch of each source.
+// A *BranchProtectionError is returned only when the check is strict.
+func (w *watcher) checkBranchProtection(ctx context.Context) error {
+	if !w.branchProtectionCheck {
+		return nil
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, w.config.TimeOut)
+	defer cancel()
+
+	sources := distinctSources(w.sources(), func(src *Source) string {
+		return strings.ToLower(fmt.Sprintf("%s/%s", src.Owner, src.Name)) + "@" + src.Branch
+	})
+	for _, src := range sources {
+		err := w.checkSourceBranchProtection(ctx, src)
+		if err == nil {
+			continue
+		}
+
+		_, ok := err.(*BranchProtectionError)
+		if !ok || w.strictBranchProtection {
+			return err
+		}
+		w.log("", "").Warnf("Configuration branch is not protected: %s", err.Error())
+	}
+
+	return nil
+}
+
+// checkSourceBranchProtection checks the protection rule of the given source's branch.
+func (w *watcher) checkSourceBranchProtection(ctx context.Context, src *Source) error {
+	q := &branchProtectionQuery{}
+	variables := map[string]interface{}{
+		"owner":  githubv4.String(src.Owner),
+		"name":   githubv4.String(src.Name),
+		"branch": githubv4.String("refs/heads/" + src.Branch),
+	}
+	err := w.client.Query(ctx, q, variables)
+	if err != nil {
+		return w.queryError("", repositoryName(src.Owner, src.Name), err)
+	}
+
+	problems := branchProtectionProblems(q)
+	if len(problems) == 0 {
+		return nil
+	}
+
+	return &BranchProtectionError{
+		Repository: fmt.Sprintf("%s/%s", src.Owner, src.Name),
+		Branch:     src.Branch,
+		Problems:   problems,
+	}
+}
+
+// branchProtectionProblems returns the missing protections of the queried branch.
+func branchProtectionProblems(q *branchProtectionQuery) []string {
+	if q.Repository.Ref == nil {
+		return []string{"branch is not found"}
+	}
+
+	rule := q.Repository.Ref.BranchProtectionRule
+	if rule == nil {
+		return []string{"no branch protection rule is applied"}
+	}
+
+	var problems []string
+	if !rule.RequiresApprovingReviews || rule.RequiredApprovingReviewCount < 1 {
+		problems = append(problems, "approving reviews are not required")
+	}
+	if rule.AllowsForcePushes {
+		problems = append(problems, "force pushes are allowed")
+	}
+	return problems
+}
@@ -0,0 +1,187 @@
+package githubconfig
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/oklahomer/go-sarah/v4"
+	"github.com/shurcooL/githubv4"
+	"reflect"
+	"strconv"
+	"testing"
+	"time"
+)
+
+func TestWithBranchProtectionCheck(t *testing.T) {
+	w := &watcher{}
+	WithBranchProtectionCheck(true)(w)
+
+	if !w.branchProtectionCheck {
+		t.Error("Branch protection check is not enabled.")
+	}
+
+	if !w.strictBranchProtection {
+		t.Error("Branch protection check is not strict.")
+	}
+}
+
+func TestBranchProtectionProblems(t *testing.T) {
+	tests := []struct {
+		found    bool
+		rule     *branchProtectionRule
+		expected []string
+	}{
+		{found: false, expected: []string{"branch is not found"}},
+		{found: true, rule: nil, expected: []string{"no branch protection rule is applied"}},
+		{found: true, rule: &branchProtectionRule{RequiresApprovingReviews: true, RequiredApprovingReviewCount: 1}, expected: nil},
+		{found: true, rule: &branchProtectionRule{RequiresApprovingReviews: true, RequiredApprovingReviewCount: 0}, expected: []string{"approving reviews are not required"}},
+		{
+			found:    true,
+			rule:     &branchProtectionRule{AllowsForcePushes: true},
+			expected: []string{"approving reviews are not required", "force pushes are allowed"},
+		},
+	}
+
+	for i, tt := range tests {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			q := &branchProtectionQuery{}
+			if tt.found {
+				q.Repository.Ref = &struct {
+					BranchProtectionRule *branchProtectionRule
+				}{BranchProtectionRule: tt.rule}
+			}
+
+			problems := branchProtectionProblems(q)
+			if !reflect.DeepEqual(problems, tt.expected) {
+				t.Errorf("Unexpected problems are returned: %v.", problems)
+			}
+		})
+	}
+}
+
+func TestWatcher_checkBranchProtection(t *testing.T) {
+	tests := []struct {
+		strict bool
+		err    bool
+	}{
+		{strict: true, err: true},
+		{strict: false, err: false},
+	}
+
+	for i, tt := range tests {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			w := &watcher{
+				client: &DummyQuerier{
+					QueryFunc: func(_ context.Context, _ interface{}, v map[string]interface{}) error {
+						if v["branch"] != githubv4.String("refs/heads/master") {
+							t.Errorf("Unexpected branch is given: %s.", v["branch"])
+						}
+						// Branch without protection
+						return nil
+					},
+				},
+				config: &Config{
+					Owner:   "oklahomer",
+					Name:    "config",
+					Branch:  "master",
+					TimeOut: 100 * time.Millisecond,
+				},
+				branchProtectionCheck:  true,
+				strictBranchProtection: tt.strict,
+			}
+
+			err := w.checkBranchProtection(context.TODO())
+			if !tt.err {
+				if err != nil {
+					t.Errorf("Unexpected error is returned: %s.", err.Error())
+				}
+				return
+			}
+
+			var protectionErr *BranchProtectionError
+			if !errors.As(err, &protectionErr) {
+				t.Fatalf("Expected error is not returned: %#v.", err)
+			}
+			if protectionErr.Repository != "oklahomer/config" || protectionErr.Branch != "master" {
+				t.Errorf("Unexpected error is returned: %+v.", protectionErr)
+			}
+		})
+	}
+}
+
+func TestWatcher_checkBranchProtection_sources(t *testing.T) {
+	queried := map[string]bool{}
+	w := &watcher{
+		client: &DummyQuerier{
+			QueryFunc: func(_ context.Context, q interface{}, v map[string]interface{}) error {
+				queried[fmt.Sprintf("%s/%s@%s", v["owner"], v["name"], v["branch"])] = true
+				if v["name"] == githubv4.String("team") {
+					// Branch without protection
+					return nil
+				}
+				rule := &branchProtectionRule{RequiresApprovingReviews: true, RequiredApprovingReviewCount: 1}
+				q.(*branchProtectionQuery).Repository.Ref = &struct {
+					BranchProtectionRule *branchProtectionRule
+				}{BranchProtectionRule: rule}
+				return nil
+			},
+		},
+		config: &Config{
+			Owner:   "oklahomer",
+			Name:    "config",
+			Branch:  "master",
+			TimeOut: 100 * time.Millisecond,
+			Sources: map[sarah.BotType]*Source{
+				"slack": {Name: "team", Branch: "main"},
+				"line":  {Branch: "line"},
+			},
+			Layers: []*Source{{Name: "shared"}},
+		},
+		branchProtectionCheck: true,
+	}
+
+	err := w.checkBranchProtection(context.TODO())
+	if err != nil {
+		t.Fatalf("Unexpected error is returned: %s.", err.Error())
+	}
+	for _, expected := range []string{"oklahomer/config@refs/heads/master", "oklahomer/config@refs/heads/line", "oklahomer/shared@refs/heads/master", "oklahomer/team@refs/heads/main"} {
+		if !queried[expected] {
+			t.Errorf("%s is not checked: %v.", expected, queried)
+		}
+	}
+
+	w.strictBranchProtection = true
+	err = w.checkBranchProtection(context.TODO())
+	var protectionErr *BranchProtectionError
+	if !errors.As(err, &protectionErr) {
+		t.Fatalf("Expected error is not returned: %#v.", err)
+	}
+	if protectionErr.Repository != "oklahomer/team" || protectionErr.Branch != "main" {
+		t.Errorf("Unexpected error is returned: %+v.", protectionErr)
+	}
+}
+
+func TestWatcher_Start_unprotectedBranch(t *testing.T) {
+	w := &watcher{
+		client: &DummyQuerier{
+			QueryFunc: func(_ context.Context, _ interface{}, _ map[string]interface{}) error {
+				return nil
+			},
+		},
+		config: &Config{
+			Branch:  "master",
+			TimeOut: 100 * time.Millisecond,
+		},
+		branchProtectionCheck:  true,
+		strictBranchProtection: true,
+	}
+
+	err := w.Start(context.TODO())
+	if err == nil {
+		t.Fatal("Expected error is not returned.")
+	}
+
+	if w.cancel != nil {
+		t.Error("Watcher is started.")
+	}
+}
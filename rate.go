@@ -0,0 +1,71 @@
+package githubconfig
+
+import (
+	"fmt"
+	"github.com/oklahomer/go-sarah/v4"
+	"time"
+)
+
+// WithChangeRateAlert emits EventChangeRateExceeded when a configuration changes more than the given number of times within the given window,
+// e.g. five changes in ten minutes, which usually indicates a fight between an automation and humans editing the repository.
+// The alert is emitted once until the rate falls back within the limit.
+func WithChangeRateAlert(limit int, window time.Duration) Option {
+	return func(w *watcher) {
+		w.changeRateLimit = limit
+		w.changeRateWindow = window
+	}
+}
+
+// ChangeRateError describes a configuration that changes more often than the limit given by WithChangeRateAlert.
+type ChangeRateError struct {
+	BotType sarah.BotType
+	ID      string
+	// Changes is the number of changes within the window.
+	Changes int
+	Window  time.Duration
+}
+
+// Error returns stringified representation of the error.
+func (err *ChangeRateError) Error() string {
+	return fmt.Sprintf("configuration for %s:%s changed %d times in %s", err.BotType, err.ID, err.Changes, err.Window)
+}
+
+var _ error = (*ChangeRateError)(nil)
+
+// trackChangeRate records the change of the given configuration at the given time,
+// and emits EventChangeRateExceeded when the configuration changes more often than the limit.
+func (w *watcher) trackChangeRate(botType sarah.BotType, id string, now time.Time) {
+	if w.changeRateLimit <= 0 {
+		return
+	}
+
+	key := fmt.Sprintf("%s:%s", botType, id)
+	threshold := now.Add(-w.changeRateWindow)
+	var recent []time.Time
+	for _, t := range w.changes[key] {
+		if t.After(threshold) {
+			recent = append(recent, t)
+		}
+	}
+	recent = append(recent, now)
+	if w.changes == nil {
+		w.changes = map[string][]time.Time{}
+	}
+	w.changes[key] = recent
+
+	if len(recent) <= w.changeRateLimit {
+		delete(w.rateAlerted, key)
+		return
+	}
+	if w.rateAlerted[key] {
+		return
+	}
+	if w.rateAlerted == nil {
+		w.rateAlerted = map[string]bool{}
+	}
+	w.rateAlerted[key] = true
+
+	err := &ChangeRateError{BotType: botType, ID: id, Changes: len(recent), Window: w.changeRateWindow}
+	w.log(botType, id).Warnf("Configuration changes too often: %s", err.Error())
+	w.emit(Event{Type: EventChangeRateExceeded, BotType: botType, ID: id, Err: err})
+}
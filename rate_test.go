@@ -0,0 +1,67 @@
+package githubconfig
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestWithChangeRateAlert(t *testing.T) {
+	w := &watcher{}
+	WithChangeRateAlert(5, 10*time.Minute)(w)
+
+	if w.changeRateLimit != 5 {
+		t.Errorf("Unexpected limit is set: %d.", w.changeRateLimit)
+	}
+	if w.changeRateWindow != 10*time.Minute {
+		t.Errorf("Unexpected window is set: %s.", w.changeRateWindow)
+	}
+}
+
+func TestWatcher_trackChangeRate(t *testing.T) {
+	w := &watcher{
+		changeRateLimit:  2,
+		changeRateWindow: 10 * time.Minute,
+		events:           make(chan Event, 10),
+	}
+
+	start := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	for i := 0; i < 4; i++ {
+		w.trackChangeRate("bot", "hello", start.Add(time.Duration(i)*time.Minute))
+	}
+	w.trackChangeRate("bot", "other", start)
+
+	if len(w.events) != 1 {
+		t.Fatalf("Unexpected number of events are emitted: %d.", len(w.events))
+	}
+	event := <-w.events
+	var rateErr *ChangeRateError
+	if event.Type != EventChangeRateExceeded || event.ID != "hello" || !errors.As(event.Err, &rateErr) {
+		t.Fatalf("Unexpected event is emitted: %+v.", event)
+	}
+	if rateErr.Changes != 3 {
+		t.Errorf("Unexpected number of changes is reported: %d.", rateErr.Changes)
+	}
+
+	// The rate falls back within the limit, and then exceeds it again.
+	w.trackChangeRate("bot", "hello", start.Add(time.Hour))
+	if len(w.events) != 0 {
+		t.Fatalf("Unexpected event is emitted: %+v.", <-w.events)
+	}
+	w.trackChangeRate("bot", "hello", start.Add(time.Hour+time.Minute))
+	w.trackChangeRate("bot", "hello", start.Add(time.Hour+2*time.Minute))
+	if len(w.events) != 1 {
+		t.Errorf("Unexpected number of events are emitted: %d.", len(w.events))
+	}
+}
+
+func TestWatcher_trackChangeRate_disabled(t *testing.T) {
+	w := &watcher{events: make(chan Event, 10)}
+	for i := 0; i < 10; i++ {
+		w.trackChangeRate("bot", "hello", time.Now())
+	}
+
+	if len(w.events) != 0 || w.changes != nil {
+		t.Error("Change rate is tracked without the limit.")
+	}
+}
@@ -0,0 +1,56 @@
+package githubconfig
+
+import (
+	"context"
+	"github.com/oklahomer/go-sarah/v4"
+	"time"
+)
+
+// FileInfo describes a fetched configuration file.
+type FileInfo struct {
+	// FileName is the name of the file including its extension.
+	FileName string
+	// Extension is the extension of the file with a leading dot such as ".yml".
+	Extension string
+	// ObjectID is the Git blob object ID of the file content.
+	ObjectID string
+	// FetchedAt is the time the file content was fetched.
+	FetchedAt time.Time
+	// Branch is the branch the file is fetched from. This is empty for a local override file.
+	Branch string
+	// Path is the path of the file relative to the repository root. This is empty for a local override file.
+	Path string
+	// URL is the URL to view the file on GitHub as of the commit it is fetched from,
+	// so admin commands can link maintainers straight to the file. This is empty for a local override file.
+	URL string
+}
+
+// newFileInfo returns the metadata of the given file.
+func newFileInfo(f *file) *FileInfo {
+	return &FileInfo{
+		FileName:  f.fileName,
+		Extension: f.extension,
+		ObjectID:  f.objectID,
+		FetchedAt: f.fetchedAt,
+		Branch:    f.branch,
+		Path:      f.path,
+		URL:       f.url,
+	}
+}
+
+func (w *watcher) ReadRaw(ctx context.Context, botType sarah.BotType, id string) ([]byte, *FileInfo, error) {
+	files, err := w.snapshotFiles(ctx, botType)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	f, ok := files[w.resolve(botType, files, id)]
+	if !ok {
+		return nil, nil, &sarah.ConfigNotFoundError{
+			BotType: botType,
+			ID:      id,
+		}
+	}
+
+	return []byte(f.content), newFileInfo(f), nil
+}
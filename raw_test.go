@@ -0,0 +1,78 @@
+package githubconfig
+
+import (
+	"context"
+	"errors"
+	"github.com/oklahomer/go-sarah/v4"
+	"testing"
+	"time"
+)
+
+func TestWatcher_ReadRaw(t *testing.T) {
+	fetchedAt := time.Now()
+	f := newFile("hello.yml", "oid", "name: oklahomer\n")
+	f.fetchedAt = fetchedAt
+	f.path = "config/bot/hello.yml"
+	f.url = "https://github.com/oklahomer/config/blob/sha/config/bot/hello.yml"
+	snapshotReq := make(chan *snapshotRequest, 1)
+	w := &watcher{
+		config: &Config{
+			TimeOut: 100 * time.Millisecond,
+		},
+		snapshot: snapshotReq,
+	}
+	w.started.Store(true)
+	go func() {
+		for {
+			select {
+			case req := <-snapshotReq:
+				req.result <- &snapshot{files: map[string]*file{f.id: f}}
+
+			case <-time.NewTimer(1 * time.Second).C:
+				// Just to be sure goroutine does not leak
+				return
+
+			}
+		}
+	}()
+
+	t.Run("existing", func(t *testing.T) {
+		b, info, err := w.ReadRaw(context.Background(), "bot", "hello")
+		if err != nil {
+			t.Fatalf("Unexpected error is returned: %s.", err.Error())
+		}
+
+		if string(b) != f.content {
+			t.Errorf("Unexpected content is returned: %s.", string(b))
+		}
+
+		if info.FileName != "hello.yml" {
+			t.Errorf("Unexpected file name is returned: %s.", info.FileName)
+		}
+
+		if info.Extension != ".yml" {
+			t.Errorf("Unexpected extension is returned: %s.", info.Extension)
+		}
+
+		if info.ObjectID != "oid" {
+			t.Errorf("Unexpected object ID is returned: %s.", info.ObjectID)
+		}
+
+		if !info.FetchedAt.Equal(fetchedAt) {
+			t.Errorf("Unexpected fetch time is returned: %s.", info.FetchedAt)
+		}
+
+		if info.Path != f.path || info.URL != f.url {
+			t.Errorf("Unexpected location is returned: %s, %s.", info.Path, info.URL)
+		}
+	})
+
+	t.Run("absent", func(t *testing.T) {
+		_, _, err := w.ReadRaw(context.Background(), "bot", "absent")
+
+		var notFound *sarah.ConfigNotFoundError
+		if !errors.As(err, &notFound) {
+			t.Errorf("Expected error is not returned: %+v.", err)
+		}
+	})
+}
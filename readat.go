@@ -0,0 +1,64 @@
+package githubconfig
+
+import (
+	"context"
+	"github.com/oklahomer/go-sarah/v4"
+)
+
+func (w *watcher) ReadAt(ctx context.Context, botType sarah.BotType, id string, ref string, out interface{}) error {
+	ctx, cancel := context.WithTimeout(ctx, w.config.TimeOut)
+	defer cancel()
+
+	fetched, schemas, found, err := w.fetch(ctx, botType, ref)
+	if err != nil {
+		return err
+	}
+	if len(fetched) == 0 && !found {
+		return w.directoryNotFound(botType)
+	}
+
+	files, err := w.assemble(botType, fetched, schemas)
+	if err != nil {
+		return err
+	}
+
+	f := files[w.resolve(botType, files, id)]
+	if f == nil {
+		return &sarah.ConfigNotFoundError{
+			BotType: botType,
+			ID:      id,
+		}
+	}
+
+	if f.invalid != nil {
+		return w.redactError(botType, f, f.invalid)
+	}
+
+	return w.redactError(botType, f, w.read(ctx, botType, f, out))
+}
+
+// ReadOption customizes a single ReadWith call.
+type ReadOption func(*readOptions)
+
+type readOptions struct {
+	ref string
+}
+
+// AtRef makes ReadWith read the configuration as of the given Git ref such as a branch or a tag instead of the cached one.
+func AtRef(ref string) ReadOption {
+	return func(o *readOptions) {
+		o.ref = ref
+	}
+}
+
+func (w *watcher) ReadWith(ctx context.Context, botType sarah.BotType, id string, out interface{}, options ...ReadOption) error {
+	o := &readOptions{}
+	for _, option := range options {
+		option(o)
+	}
+
+	if o.ref != "" {
+		return w.ReadAt(ctx, botType, id, o.ref, out)
+	}
+	return w.Read(ctx, botType, id, out)
+}
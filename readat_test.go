@@ -0,0 +1,112 @@
+package githubconfig
+
+import (
+	"context"
+	"errors"
+	"github.com/oklahomer/go-sarah/v4"
+	"github.com/shurcooL/githubv4"
+	"testing"
+	"time"
+)
+
+func TestWatcher_ReadAt(t *testing.T) {
+	w := &watcher{
+		client: &DummyQuerier{
+			QueryFunc: func(_ context.Context, q interface{}, v map[string]interface{}) error {
+				if v["ref"] != githubv4.String("v1.0.0") {
+					t.Errorf("Unexpected ref is given: %s.", v["ref"])
+				}
+
+				if v["expression"] != githubv4.String("v1.0.0:config/bot") {
+					t.Errorf("Unexpected expression is given: %s.", v["expression"])
+				}
+
+				typed := q.(*query)
+				typed.Repository.Object.Tree.Entries = []entry{
+					{
+						Name:   "hello.yml",
+						Object: entryObject{Blob: blob{Oid: "old", Text: "name: old\n"}},
+					},
+				}
+				return nil
+			},
+		},
+		config: &Config{
+			BaseDir: "config",
+			Branch:  "master",
+			TimeOut: 100 * time.Millisecond,
+		},
+	}
+
+	t.Run("existing", func(t *testing.T) {
+		out := &struct {
+			Name string `yaml:"name"`
+		}{}
+		err := w.ReadAt(context.Background(), "bot", "hello", "v1.0.0", out)
+		if err != nil {
+			t.Fatalf("Unexpected error is returned: %s.", err.Error())
+		}
+
+		if out.Name != "old" {
+			t.Errorf("Unexpected value is set: %s.", out.Name)
+		}
+	})
+
+	t.Run("absent", func(t *testing.T) {
+		err := w.ReadAt(context.Background(), "bot", "absent", "v1.0.0", &struct{}{})
+
+		var notFound *sarah.ConfigNotFoundError
+		if !errors.As(err, &notFound) {
+			t.Errorf("Expected error is not returned: %+v.", err)
+		}
+	})
+}
+
+func TestWatcher_ReadWith(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	w := &watcher{
+		client: &DummyQuerier{
+			QueryFunc: func(_ context.Context, q interface{}, v map[string]interface{}) error {
+				typed := q.(*query)
+				typed.Repository.Head.Commit.Oid = "sha"
+				typed.Repository.Object.Tree.Entries = []entry{
+					{
+						Name:   "hello.yml",
+						Object: entryObject{Blob: blob{Oid: githubv4.String(v["ref"].(githubv4.String)), Text: githubv4.String("name: " + v["ref"].(githubv4.String) + "\n")}},
+					},
+				}
+				return nil
+			},
+		},
+		config: &Config{
+			BaseDir:  "config",
+			Branch:   "master",
+			TimeOut:  100 * time.Millisecond,
+			Interval: time.Hour,
+		},
+		request: make(chan *request),
+	}
+	w.started.Store(true)
+	go w.operate(ctx)
+
+	config := &struct {
+		Name string `yaml:"name"`
+	}{}
+	err := w.ReadWith(ctx, "bot", "hello", config, AtRef("feature"))
+	if err != nil {
+		t.Fatalf("Unexpected error is returned: %s.", err.Error())
+	}
+	if config.Name != "feature" {
+		t.Errorf("Unexpected value is decoded: %s.", config.Name)
+	}
+
+	err = w.ReadWith(ctx, "bot", "hello", config)
+	if err != nil {
+		t.Fatalf("Unexpected error is returned: %s.", err.Error())
+	}
+	if config.Name != "master" {
+		t.Errorf("Unexpected value is decoded: %s.", config.Name)
+	}
+}
@@ -0,0 +1,221 @@
+package githubconfig
+
+import (
+	"github.com/oklahomer/go-sarah/v4"
+	"reflect"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+const (
+	// sensitiveTag is the struct tag that marks a configuration field as sensitive, e.g. `sensitive:"true"`.
+	sensitiveTag = "sensitive"
+	// redacted replaces a sensitive value.
+	redacted = "[REDACTED]"
+)
+
+// meaningful matches a value that may be a secret, which excludes YAML indicators such as "|" and "{".
+var meaningful = regexp.MustCompile(`\w`)
+
+// flatEntry matches a "key: value" or "key = value" line of YAML, JSON, INI, dotenv and properties files.
+var flatEntry = regexp.MustCompile(`^\s*["']?([\w.-]+)["']?\s*[:=]\s*(.*?)\s*,?\s*$`)
+
+// WithRedaction applies the given function to the diffs and the error messages the watcher exposes,
+// in addition to masking the values of the fields tagged with `sensitive:"true"`.
+// This is useful to mask secrets that a struct tag cannot describe, such as tokens in a free-form text.
+func WithRedaction(redact func(text string) string) Option {
+	return func(w *watcher) {
+		w.redaction = redact
+	}
+}
+
+// redactedError masks the message of the underlying error while keeping it available to errors.Is and errors.As.
+type redactedError struct {
+	message string
+	err     error
+}
+
+// Error returns stringified representation of the error.
+func (err *redactedError) Error() string {
+	return err.message
+}
+
+// Unwrap returns the underlying error.
+func (err *redactedError) Unwrap() error {
+	return err.err
+}
+
+var _ error = (*redactedError)(nil)
+
+// learnSensitiveKeys memorizes the keys of the fields tagged as sensitive in the type of the given value,
+// so the values at those keys are masked for the id afterwards.
+func (w *watcher) learnSensitiveKeys(botType sarah.BotType, id string, out interface{}) {
+	keys := sensitiveKeys(reflect.TypeOf(out))
+	if len(keys) == 0 {
+		return
+	}
+
+	w.sensitiveMutex.Lock()
+	defer w.sensitiveMutex.Unlock()
+	if w.sensitive == nil {
+		w.sensitive = map[sarah.BotType]map[string]map[string]bool{}
+	}
+	if w.sensitive[botType] == nil {
+		w.sensitive[botType] = map[string]map[string]bool{}
+	}
+	w.sensitive[botType][id] = keys
+}
+
+// sensitiveKeysOf returns the learned sensitive keys of the given id, including the ones of its prototype.
+func (w *watcher) sensitiveKeysOf(botType sarah.BotType, id string) map[string]bool {
+	w.sensitiveMutex.RLock()
+	keys := w.sensitive[botType][id]
+	w.sensitiveMutex.RUnlock()
+
+	prototype, ok := w.prototypes[botType][id]
+	if !ok {
+		return keys
+	}
+
+	merged := sensitiveKeys(reflect.TypeOf(prototype))
+	for key := range keys {
+		merged[key] = true
+	}
+	return merged
+}
+
+// sensitiveKeys returns the lower-cased keys of the sensitive fields in the given type and its nested types.
+// The field name, and the names given by the yaml and json tags are all treated as keys.
+func sensitiveKeys(t reflect.Type) map[string]bool {
+	keys := map[string]bool{}
+	visited := map[reflect.Type]bool{}
+
+	var walk func(reflect.Type)
+	walk = func(t reflect.Type) {
+		for t != nil && (t.Kind() == reflect.Ptr || t.Kind() == reflect.Slice || t.Kind() == reflect.Array || t.Kind() == reflect.Map) {
+			t = t.Elem()
+		}
+		if t == nil || t.Kind() != reflect.Struct || visited[t] {
+			return
+		}
+		visited[t] = true
+
+		for i := 0; i < t.NumField(); i++ {
+			field := t.Field(i)
+			if field.Tag.Get(sensitiveTag) != "true" {
+				walk(field.Type)
+				continue
+			}
+
+			keys[strings.ToLower(field.Name)] = true
+			for _, tag := range []string{"yaml", "json"} {
+				name, _, _ := strings.Cut(field.Tag.Get(tag), ",")
+				if name != "" && name != "-" {
+					keys[strings.ToLower(name)] = true
+				}
+			}
+		}
+	}
+	walk(t)
+
+	return keys
+}
+
+// sensitiveValues returns the values stored at the sensitive keys in the given files, longest first.
+func (w *watcher) sensitiveValues(botType sarah.BotType, keys map[string]bool, files ...*file) []string {
+	found := map[string]bool{}
+	for _, f := range files {
+		if f == nil {
+			continue
+		}
+
+		for _, layer := range f.layers() {
+			for _, line := range splitLines(layer.content) {
+				match := flatEntry.FindStringSubmatch(line)
+				if match != nil && keys[strings.ToLower(match[1])] {
+					found[strings.Trim(match[2], `"'`)] = true
+				}
+			}
+		}
+
+		if !supportsSchema(f) {
+			continue
+		}
+		value, err := w.generic(botType, f)
+		if err == nil {
+			collectSensitiveValues(value, keys, false, found)
+		}
+	}
+
+	var values []string
+	for value := range found {
+		if meaningful.MatchString(value) {
+			values = append(values, value)
+		}
+	}
+	sort.Slice(values, func(i, j int) bool {
+		return len(values[i]) > len(values[j])
+	})
+	return values
+}
+
+func collectSensitiveValues(value interface{}, keys map[string]bool, sensitive bool, found map[string]bool) {
+	switch typed := value.(type) {
+	case map[string]interface{}:
+		for k, v := range typed {
+			collectSensitiveValues(v, keys, sensitive || keys[strings.ToLower(k)], found)
+		}
+
+	case []interface{}:
+		for _, v := range typed {
+			collectSensitiveValues(v, keys, sensitive, found)
+		}
+
+	case string:
+		if sensitive {
+			// A block scalar ends with a line break that does not appear in a diff or an error message.
+			found[strings.TrimSpace(typed)] = true
+		}
+
+	}
+}
+
+// redact masks the sensitive values of the given files in the text, and then applies the function given by WithRedaction.
+func (w *watcher) redact(botType sarah.BotType, id string, text string, files ...*file) string {
+	keys := w.sensitiveKeysOf(botType, id)
+	if len(keys) > 0 {
+		for _, value := range w.sensitiveValues(botType, keys, files...) {
+			text = strings.ReplaceAll(text, value, redacted)
+		}
+	}
+
+	if w.redaction != nil {
+		text = w.redaction(text)
+	}
+	return text
+}
+
+// redactError returns the error with its message redacted as the redact method does.
+// A *DecodeError is kept as is with the underlying error redacted, so the callers can still inspect its fields.
+func (w *watcher) redactError(botType sarah.BotType, f *file, err error) error {
+	if err == nil {
+		return nil
+	}
+
+	if decodeErr, ok := err.(*DecodeError); ok {
+		message := w.redact(botType, f.id, decodeErr.Err.Error(), f)
+		if message == decodeErr.Err.Error() {
+			return err
+		}
+		copied := *decodeErr
+		copied.Err = &redactedError{message: message, err: decodeErr.Err}
+		return &copied
+	}
+
+	message := w.redact(botType, f.id, err.Error(), f)
+	if message == err.Error() {
+		return err
+	}
+	return &redactedError{message: message, err: err}
+}
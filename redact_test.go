@@ -0,0 +1,140 @@
+package githubconfig
+
+import (
+	"errors"
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+type sensitiveConfig struct {
+	Token    string `yaml:"api_token" json:"apiToken" sensitive:"true"`
+	Endpoint string `yaml:"endpoint"`
+	Nested   []*struct {
+		Password string `sensitive:"true"`
+	}
+}
+
+func TestWithRedaction(t *testing.T) {
+	w := &watcher{}
+	WithRedaction(func(text string) string {
+		return "redacted"
+	})(w)
+
+	if w.redaction == nil || w.redaction("text") != "redacted" {
+		t.Error("Redaction is not set.")
+	}
+}
+
+func TestSensitiveKeys(t *testing.T) {
+	keys := sensitiveKeys(reflect.TypeOf(&sensitiveConfig{}))
+
+	expected := map[string]bool{"token": true, "api_token": true, "apitoken": true, "password": true}
+	if !reflect.DeepEqual(keys, expected) {
+		t.Errorf("Unexpected keys are returned: %v.", keys)
+	}
+}
+
+func TestWatcher_redact(t *testing.T) {
+	tests := []struct {
+		file     *file
+		text     string
+		expected string
+	}{
+		{
+			file:     newFile("hello.yml", "1", "api_token: s3cr3t\nendpoint: https://example.com\nnested:\n  - password: |\n      p@ss\n"),
+			text:     "token s3cr3t is sent to https://example.com with p@ss",
+			expected: "token [REDACTED] is sent to https://example.com with [REDACTED]",
+		},
+		{
+			file:     newFile("hello.json", "1", `{"apiToken": "s3cr3t", "endpoint": "https://example.com"}`),
+			text:     "+  \"apiToken\": \"s3cr3t\",",
+			expected: "+  \"apiToken\": \"[REDACTED]\",",
+		},
+		{
+			file:     newFile("hello.env", "1", "API_TOKEN=s3cr3t\n"),
+			text:     "invalid line: API_TOKEN=s3cr3t",
+			expected: "invalid line: API_TOKEN=[REDACTED]",
+		},
+		{
+			file:     newFile("hello.yml", "1", "endpoint: https://example.com\n"),
+			text:     "sent to https://example.com",
+			expected: "sent to https://example.com",
+		},
+	}
+
+	for i, tt := range tests {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			w := &watcher{}
+			w.learnSensitiveKeys("bot", "hello", &sensitiveConfig{})
+
+			text := w.redact("bot", "hello", tt.text, tt.file)
+			if text != tt.expected {
+				t.Errorf("Unexpected text is returned: %s.", text)
+			}
+		})
+	}
+}
+
+func TestWatcher_redact_redaction(t *testing.T) {
+	w := &watcher{
+		redaction: func(text string) string {
+			return strings.ReplaceAll(text, "xoxb-123", "xoxb-***")
+		},
+	}
+
+	text := w.redact("bot", "hello", "token is xoxb-123", newFile("hello.yml", "1", "message: xoxb-123\n"))
+	if text != "token is xoxb-***" {
+		t.Errorf("Unexpected text is returned: %s.", text)
+	}
+}
+
+func TestWatcher_redactError(t *testing.T) {
+	f := newFile("hello.yml", "1", "api_token: s3cr3t\n")
+	w := &watcher{}
+	w.learnSensitiveKeys("bot", "hello", &sensitiveConfig{})
+
+	cause := errors.New("cannot unmarshal s3cr3t")
+	err := w.redactError("bot", f, &DecodeError{BotType: "bot", ID: "hello", FileName: "hello.yml", Err: cause})
+
+	var decodeErr *DecodeError
+	if !errors.As(err, &decodeErr) {
+		t.Fatalf("Unexpected error is returned: %#v.", err)
+	}
+	if strings.Contains(err.Error(), "s3cr3t") {
+		t.Errorf("Sensitive value is not redacted: %s.", err.Error())
+	}
+	if !errors.Is(err, cause) {
+		t.Error("Underlying error is not kept.")
+	}
+
+	err = w.redactError("bot", f, cause)
+	if err.Error() != "cannot unmarshal [REDACTED]" {
+		t.Errorf("Unexpected error is returned: %s.", err.Error())
+	}
+	if !errors.Is(err, cause) {
+		t.Error("Underlying error is not kept.")
+	}
+
+	plain := errors.New("plain")
+	if w.redactError("bot", f, plain) != plain {
+		t.Error("Error without sensitive value must be returned as is.")
+	}
+}
+
+func TestWatcher_newChange_redacted(t *testing.T) {
+	w := &watcher{}
+	w.learnSensitiveKeys("bot", "hello", &sensitiveConfig{})
+
+	old := newFile("hello.yml", "old", "api_token: old-token\n")
+	current := newFile("hello.yml", "new", "api_token: new-token\n")
+	change := w.newChange(nil, "bot", "hello", old, current, false)
+
+	if strings.Contains(change.Diff, "old-token") || strings.Contains(change.Diff, "new-token") {
+		t.Errorf("Sensitive values are not redacted: %s.", change.Diff)
+	}
+	if !strings.Contains(change.Diff, "+api_token: [REDACTED]") {
+		t.Errorf("Unexpected diff is returned: %s.", change.Diff)
+	}
+}
@@ -0,0 +1,50 @@
+package githubconfig
+
+import (
+	"context"
+	"github.com/oklahomer/go-sarah/v4"
+)
+
+// rollback holds the commit to pin a BotType's configuration files to. An empty SHA releases the pin.
+type rollback struct {
+	botType sarah.BotType
+	sha     string
+}
+
+func (w *watcher) Rollback(ctx context.Context, botType sarah.BotType, sha string) error {
+	if sha != "" {
+		// Fetch once so a mistyped SHA is reported to the caller instead of breaking the following refreshes.
+		ctx, cancel := context.WithTimeout(ctx, w.config.TimeOut)
+		defer cancel()
+
+		_, _, _, err := w.fetch(ctx, botType, sha)
+		if err != nil {
+			return err
+		}
+	}
+
+	return w.reconfigure(&reconfiguration{rollback: &rollback{botType: botType, sha: sha}})
+}
+
+// pin pins the given BotType to the given commit, or releases the pin with an empty SHA.
+// The caller must hold configMutex.
+func (w *watcher) pin(botType sarah.BotType, sha string) {
+	if sha == "" {
+		delete(w.pins, botType)
+		return
+	}
+
+	if w.pins == nil {
+		w.pins = map[sarah.BotType]string{}
+	}
+	w.pins[botType] = sha
+}
+
+// pinned returns the commit the given BotType is rolled back to, if any.
+func (w *watcher) pinned(botType sarah.BotType) (string, bool) {
+	w.configMutex.RLock()
+	defer w.configMutex.RUnlock()
+
+	sha, ok := w.pins[botType]
+	return sha, ok
+}
@@ -0,0 +1,195 @@
+package githubconfig
+
+import (
+	"context"
+	"errors"
+	"github.com/oklahomer/go-sarah/v4"
+	"github.com/shurcooL/githubv4"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestWatcher_Rollback(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	w := &watcher{
+		client: &DummyQuerier{
+			QueryFunc: func(_ context.Context, q interface{}, v map[string]interface{}) error {
+				typed := q.(*query)
+				ref := string(v["ref"].(githubv4.String))
+				if ref == "unknown" {
+					return nil
+				}
+				typed.Repository.Head.Commit.Oid = githubv4.String(ref)
+				typed.Repository.Object.Tree.Entries = []entry{
+					{Name: "hello.json", Object: entryObject{Blob: blob{Oid: githubv4.String(ref), Text: "{}"}}},
+				}
+				return nil
+			},
+		},
+		config: &Config{
+			Branch:   "master",
+			TimeOut:  100 * time.Millisecond,
+			Interval: time.Hour,
+		},
+		request:         make(chan *request),
+		snapshot:        make(chan *snapshotRequest),
+		subscription:    make(chan *subscription),
+		reconfiguration: make(chan *reconfiguration),
+	}
+	w.started.Store(true)
+	go w.operate(ctx)
+
+	called := make(chan string, 1)
+	_, err := w.WatchContext(ctx, "bot", "hello", func(_ context.Context, id string) {
+		called <- id
+	})
+	if err != nil {
+		t.Fatalf("Unexpected error is returned: %s.", err.Error())
+	}
+	err = w.Read(ctx, "bot", "hello", &struct{}{})
+	if err != nil {
+		t.Fatalf("Unexpected error is returned: %s.", err.Error())
+	}
+
+	err = w.Rollback(ctx, "bot", "unknown")
+	var notFound *RefOrPathNotFoundError
+	if !errors.As(err, &notFound) {
+		t.Fatalf("Unexpected error is returned: %#v.", err)
+	}
+	if _, ok := w.pinned("bot"); ok {
+		t.Fatal("BotType is pinned to unknown commit.")
+	}
+
+	for _, sha := range []string{"abc", ""} {
+		err = w.Rollback(ctx, "bot", sha)
+		if err != nil {
+			t.Fatalf("Unexpected error is returned: %s.", err.Error())
+		}
+
+		expected := sha
+		if expected == "" {
+			expected = "master"
+		}
+		select {
+		case <-called:
+			// O.K.
+
+		case <-time.NewTimer(1 * time.Second).C:
+			t.Fatal("Subscriber is not notified of the rollback.")
+
+		}
+
+		version, err := w.Version(ctx, "bot", "hello")
+		if err != nil {
+			t.Fatalf("Unexpected error is returned: %s.", err.Error())
+		}
+		if version.ObjectID != expected {
+			t.Errorf("Unexpected content is applied: %s.", version.ObjectID)
+		}
+	}
+
+	if !reflect.DeepEqual(w.branches("bot"), []string{"master"}) {
+		t.Errorf("Pin is not released: %v.", w.branches("bot"))
+	}
+}
+
+func TestWatcher_branches_pinned(t *testing.T) {
+	w := &watcher{
+		config: &Config{Branch: "master", Branches: []string{"hotfix"}},
+		pins:   map[sarah.BotType]string{"bot": "abc"},
+	}
+
+	if !reflect.DeepEqual(w.branches("bot"), []string{"abc"}) {
+		t.Errorf("Unexpected branches are returned: %v.", w.branches("bot"))
+	}
+	if !reflect.DeepEqual(w.branches("other"), []string{"hotfix", "master"}) {
+		t.Errorf("Unexpected branches are returned: %v.", w.branches("other"))
+	}
+}
+
+func TestWatcher_Rollback_approvalPolicy(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	approved := pullRequest{Merged: true}
+	approved.Reviews.Nodes = make([]struct {
+		Author struct{ Login githubv4.String }
+	}, 1)
+	approved.Reviews.Nodes[0].Author.Login = "reviewer"
+	w := &watcher{
+		client: &DummyQuerier{
+			QueryFunc: func(_ context.Context, q interface{}, v map[string]interface{}) error {
+				switch typed := q.(type) {
+				case *query:
+					ref := string(v["ref"].(githubv4.String))
+					typed.Repository.Head.Commit.Oid = githubv4.String(ref)
+					typed.Repository.Object.Tree.Entries = []entry{
+						{Name: "hello.json", Object: entryObject{Blob: blob{Oid: githubv4.String(ref), Text: "{}"}}},
+					}
+
+				case *commitQuery:
+					// A commit SHA is not a qualified name of a ref.
+					if v["branch"] == githubv4.String("master") {
+						typed.Repository.Ref.Target.Commit.History.Nodes = []commit{{Oid: "head"}}
+					}
+
+				case *pinnedCommitQuery:
+					typed.Repository.Object.Commit.History.Nodes = []commit{{Oid: githubv4.String(v["sha"].(githubv4.String))}}
+
+				case *pullRequestQuery:
+					typed.Repository.Object.Commit.AssociatedPullRequests.Nodes = []pullRequest{approved}
+
+				}
+				return nil
+			},
+		},
+		config: &Config{
+			Branch:   "master",
+			TimeOut:  100 * time.Millisecond,
+			Interval: time.Hour,
+		},
+		requiredApprovals: 1,
+		request:           make(chan *request),
+		snapshot:          make(chan *snapshotRequest),
+		subscription:      make(chan *subscription),
+		reconfiguration:   make(chan *reconfiguration),
+	}
+	w.started.Store(true)
+	go w.operate(ctx)
+
+	called := make(chan string, 1)
+	_, err := w.WatchContext(ctx, "bot", "hello", func(_ context.Context, id string) {
+		called <- id
+	})
+	if err != nil {
+		t.Fatalf("Unexpected error is returned: %s.", err.Error())
+	}
+	err = w.Read(ctx, "bot", "hello", &struct{}{})
+	if err != nil {
+		t.Fatalf("Unexpected error is returned: %s.", err.Error())
+	}
+
+	err = w.Rollback(ctx, "bot", "abc")
+	if err != nil {
+		t.Fatalf("Unexpected error is returned: %s.", err.Error())
+	}
+	select {
+	case <-called:
+		// O.K.
+
+	case <-time.NewTimer(1 * time.Second).C:
+		t.Fatal("Subscriber is not notified of the rollback.")
+
+	}
+
+	version, err := w.Version(ctx, "bot", "hello")
+	if err != nil {
+		t.Fatalf("Unexpected error is returned: %s.", err.Error())
+	}
+	if version.ObjectID != "abc" {
+		t.Errorf("Approved rollback is not applied: %s.", version.ObjectID)
+	}
+}
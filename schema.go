@@ -0,0 +1,134 @@
+package githubconfig
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"github.com/oklahomer/go-sarah/v4"
+	"github.com/santhosh-tekuri/jsonschema/v5"
+	"strings"
+)
+
+// schemasDir is the directory under the base directory that holds JSON Schemas.
+// A schema located at {BaseDir}/schemas/{BotType}/{id}.json validates the configuration file with the same BotType and id.
+const schemasDir = "schemas"
+
+// SchemaValidationError is returned when a configuration file does not conform to its JSON Schema.
+type SchemaValidationError struct {
+	BotType sarah.BotType
+	ID      string
+	Err     error
+}
+
+// Error returns stringified representation of the error.
+func (err *SchemaValidationError) Error() string {
+	return fmt.Sprintf("configuration for %s:%s does not conform to its schema: %s", err.BotType, err.ID, err.Err.Error())
+}
+
+// Unwrap returns the underlying error.
+func (err *SchemaValidationError) Unwrap() error {
+	return err.Err
+}
+
+var _ error = (*SchemaValidationError)(nil)
+
+// validate validates the files against the schemas with the same ids.
+// A file that does not conform to its schema is marked as invalid so the previous content remains effective.
+func (w *watcher) validate(botType sarah.BotType, files map[string]*file, schemas []entry) {
+	for _, schema := range schemas {
+		name := string(schema.Name)
+		if !strings.HasSuffix(name, ".json") {
+			continue
+		}
+
+		f, ok := files[strings.TrimSuffix(name, ".json")]
+		if !ok || !supportsSchema(f) {
+			continue
+		}
+
+		err := w.validateFile(botType, f, name, string(schema.Object.Blob.Text))
+		if err != nil {
+			f.invalid = &SchemaValidationError{
+				BotType: botType,
+				ID:      f.id,
+				Err:     err,
+			}
+		}
+	}
+}
+
+func (w *watcher) validateFile(botType sarah.BotType, f *file, schemaName string, schemaContent string) error {
+	compiler := jsonschema.NewCompiler()
+	err := compiler.AddResource(schemaName, strings.NewReader(schemaContent))
+	if err != nil {
+		return fmt.Errorf("failed to load schema %s: %w", schemaName, err)
+	}
+	schema, err := compiler.Compile(schemaName)
+	if err != nil {
+		return fmt.Errorf("failed to compile schema %s: %w", schemaName, err)
+	}
+
+	value, err := w.generic(botType, f)
+	if err != nil {
+		return err
+	}
+
+	return schema.Validate(value)
+}
+
+// generic decodes the file along with the files merged with it into a JSON-compatible value.
+func (w *watcher) generic(botType sarah.BotType, f *file) (interface{}, error) {
+	var merged interface{}
+	for _, layer := range f.layers() {
+		var value interface{}
+		err := w.decode(botType, layer, &value)
+		if err != nil {
+			return nil, err
+		}
+		merged = deepMerge(merged, value)
+	}
+
+	// Encode and then decode to normalize the value as the validator expects.
+	b, err := json.Marshal(merged)
+	if err != nil {
+		return nil, fmt.Errorf("failed to convert %s to JSON: %w", f.fileName, err)
+	}
+	decoder := json.NewDecoder(bytes.NewReader(b))
+	decoder.UseNumber()
+	var value interface{}
+	err = decoder.Decode(&value)
+	if err != nil {
+		return nil, err
+	}
+	return value, nil
+}
+
+// deepMerge merges src over dst.
+// Maps are merged recursively while any other value in src replaces the one in dst.
+func deepMerge(dst interface{}, src interface{}) interface{} {
+	dstMap, ok := dst.(map[string]interface{})
+	if !ok {
+		return src
+	}
+	srcMap, ok := src.(map[string]interface{})
+	if !ok {
+		return src
+	}
+
+	for k, v := range srcMap {
+		dstMap[k] = deepMerge(dstMap[k], v)
+	}
+	return dstMap
+}
+
+// supportsSchema tells if the file can be decoded into a JSON-compatible value to be validated.
+func supportsSchema(f *file) bool {
+	switch f.extension {
+	case ".yml", ".yaml", ".json", ".jsonc", ".json5", ".cue":
+		return true
+
+	default:
+		return false
+
+	}
+}
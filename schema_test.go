@@ -0,0 +1,195 @@
+package githubconfig
+
+import (
+	"context"
+	"errors"
+	"github.com/shurcooL/githubv4"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestWatcher_get_withSchemas(t *testing.T) {
+	schema := `{"type": "object", "required": ["name"], "properties": {"port": {"type": "integer", "maximum": 65535}}}`
+	w := &watcher{
+		client: &DummyQuerier{
+			QueryFunc: func(_ context.Context, q interface{}, v map[string]interface{}) error {
+				s := v["schemas"].(githubv4.String)
+				if string(s) != "master:config/schemas/bot" {
+					t.Errorf("Unexpected schemas expression is given: %s.", s)
+				}
+
+				typed := q.(*query)
+				for name, content := range map[string]string{
+					"_default.yml": "port: 80\n",
+					"valid.yml":    "name: valid\n",
+					"invalid.yml":  "name: invalid\nport: 100000\n",
+					"missing.json": `{"port": 80}`,
+					"free.yml":     "port: 100000\n",
+				} {
+					typed.Repository.Object.Tree.Entries = append(typed.Repository.Object.Tree.Entries, entry{
+						Name:   githubv4.String(name),
+						Object: entryObject{Blob: blob{Oid: "oid", Text: githubv4.String(content)}},
+					})
+				}
+				for _, name := range []string{"valid.json", "invalid.json", "missing.json"} {
+					typed.Repository.Schemas.Tree.Entries = append(typed.Repository.Schemas.Tree.Entries, entry{
+						Name:   githubv4.String(name),
+						Object: entryObject{Blob: blob{Oid: "oid", Text: githubv4.String(schema)}},
+					})
+				}
+				return nil
+			},
+		},
+		config: &Config{
+			BaseDir: "config",
+			Branch:  "master",
+		},
+	}
+
+	files, err := w.get(context.Background(), "bot")
+	if err != nil {
+		t.Fatalf("Unexpected error is returned: %s.", err.Error())
+	}
+
+	for id, valid := range map[string]bool{"valid": true, "invalid": false, "missing": false, "free": true} {
+		f := files[id]
+		if valid && f.invalid != nil {
+			t.Errorf("%s must be valid: %s.", id, f.invalid.Error())
+		}
+
+		if !valid {
+			var schemaErr *SchemaValidationError
+			if !errors.As(f.invalid, &schemaErr) {
+				t.Errorf("%s must be invalid: %+v.", id, f.invalid)
+			}
+		}
+	}
+}
+
+func TestDeepMerge(t *testing.T) {
+	dst := map[string]interface{}{
+		"name": "default",
+		"server": map[string]interface{}{
+			"host": "localhost",
+			"port": 80,
+		},
+		"tags": []interface{}{"a"},
+	}
+	src := map[string]interface{}{
+		"server": map[string]interface{}{
+			"port": 8080,
+		},
+		"tags": []interface{}{"b"},
+	}
+
+	merged := deepMerge(dst, src)
+
+	expected := map[string]interface{}{
+		"name": "default",
+		"server": map[string]interface{}{
+			"host": "localhost",
+			"port": 8080,
+		},
+		"tags": []interface{}{"b"},
+	}
+	if !reflect.DeepEqual(merged, expected) {
+		t.Errorf("Unexpected value is returned: %+v.", merged)
+	}
+}
+
+func TestSchemaValidationError(t *testing.T) {
+	cause := errors.New("cause")
+	err := &SchemaValidationError{
+		BotType: "bot",
+		ID:      "hello",
+		Err:     cause,
+	}
+
+	if err.Error() != "configuration for bot:hello does not conform to its schema: cause" {
+		t.Errorf("Unexpected message is returned: %s.", err.Error())
+	}
+
+	if !errors.Is(err, cause) {
+		t.Error("Underlying error is not unwrapped.")
+	}
+}
+
+func TestWatcher_operate_withInvalidUpdate(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	contents := make(chan string, 2)
+	contents <- `{"port": 80}`
+	contents <- `{"port": 100000}`
+	current := ""
+	w := &watcher{
+		client: &DummyQuerier{
+			QueryFunc: func(_ context.Context, q interface{}, _ map[string]interface{}) error {
+				select {
+				case current = <-contents:
+				default:
+				}
+
+				typed := q.(*query)
+				typed.Repository.Object.Tree.Entries = []entry{
+					{
+						Name:   "hello.json",
+						Object: entryObject{Blob: blob{Oid: githubv4.String(current), Text: githubv4.String(current)}},
+					},
+				}
+				typed.Repository.Schemas.Tree.Entries = []entry{
+					{
+						Name:   "hello.json",
+						Object: entryObject{Blob: blob{Oid: "schema", Text: `{"properties": {"port": {"maximum": 65535}}}`}},
+					},
+				}
+				return nil
+			},
+		},
+		config: &Config{
+			TimeOut:  100 * time.Millisecond,
+			Interval: 10 * time.Millisecond,
+		},
+		request:      make(chan *request),
+		subscription: make(chan *subscription),
+	}
+	w.started.Store(true)
+	go w.operate(ctx)
+
+	type config struct {
+		Port int `json:"port"`
+	}
+	cfg := &config{}
+	err := w.Read(ctx, "bot", "hello", cfg)
+	if err != nil {
+		t.Fatalf("Unexpected error is returned: %s.", err.Error())
+	}
+
+	called := make(chan struct{}, 1)
+	err = w.Watch(ctx, "bot", "hello", func() {
+		called <- struct{}{}
+	})
+	if err != nil {
+		t.Fatalf("Unexpected error is returned: %s.", err.Error())
+	}
+
+	select {
+	case <-called:
+		t.Error("Callback must not be called for an invalid update.")
+
+	case <-time.NewTimer(100 * time.Millisecond).C:
+		// O.K.
+
+	}
+
+	cfg = &config{}
+	err = w.Read(ctx, "bot", "hello", cfg)
+	if err != nil {
+		t.Fatalf("Unexpected error is returned: %s.", err.Error())
+	}
+
+	if cfg.Port != 80 {
+		t.Errorf("Previous value must remain effective: %d.", cfg.Port)
+	}
+}
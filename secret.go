@@ -0,0 +1,186 @@
+package githubconfig
+
+import (
+	"context"
+	"fmt"
+	"github.com/oklahomer/go-sarah/v4"
+	"reflect"
+	"regexp"
+)
+
+var secretPattern = regexp.MustCompile(`\$\{secret:([^}]+)}`)
+
+// SecretResolver resolves a secret reference such as "slack/token" in ${secret:slack/token} into the real value.
+// Implement this with environment variables, Vault or a cloud secret manager of the user's choice.
+type SecretResolver interface {
+	ResolveSecret(ctx context.Context, reference string) (string, error)
+}
+
+// WithSecretResolver replaces each ${secret:...} reference in the decoded string values of the configuration with the value the given SecretResolver returns.
+// The references are resolved after the file is decoded, so a resolved value is never parsed as part of the file and can not inject a key.
+// The resolved values are cached until the configuration file or any file merged with it changes.
+func WithSecretResolver(resolver SecretResolver) Option {
+	return func(w *watcher) {
+		w.secretResolver = resolver
+	}
+}
+
+// resolvedSecrets holds the values resolved for a revision of a configuration.
+type resolvedSecrets struct {
+	revision string
+	values   map[string]string
+}
+
+// resolveSecrets replaces the secret references in the string values of the decoded configuration with the resolved values.
+func (w *watcher) resolveSecrets(ctx context.Context, botType sarah.BotType, f *file, out interface{}) error {
+	if w.secretResolver == nil {
+		return nil
+	}
+
+	resolve := func(s string) (string, error) {
+		var err error
+		replaced := secretPattern.ReplaceAllStringFunc(s, func(ref string) string {
+			if err != nil {
+				return ref
+			}
+			reference := secretPattern.FindStringSubmatch(ref)[1]
+			var value string
+			value, err = w.resolveSecret(ctx, botType, f, reference)
+			if err != nil {
+				err = fmt.Errorf("failed to resolve secret %s in %s: %w", reference, f.fileName, err)
+				return ref
+			}
+			return value
+		})
+		return replaced, err
+	}
+
+	_, err := replaceStrings(reflect.ValueOf(out), resolve)
+	return err
+}
+
+// resolveSecret returns the value of the given reference, which is cached for the current revision of the file.
+func (w *watcher) resolveSecret(ctx context.Context, botType sarah.BotType, f *file, reference string) (string, error) {
+	key := fmt.Sprintf("%s:%s", botType, f.id)
+	revision := f.revision()
+
+	w.secretMutex.Lock()
+	cached, ok := w.secrets[key]
+	if ok && cached.revision == revision {
+		if value, ok := cached.values[reference]; ok {
+			w.secretMutex.Unlock()
+			return value, nil
+		}
+	}
+	w.secretMutex.Unlock()
+
+	value, err := w.secretResolver.ResolveSecret(ctx, reference)
+	if err != nil {
+		return "", err
+	}
+
+	w.secretMutex.Lock()
+	defer w.secretMutex.Unlock()
+	if w.secrets == nil {
+		w.secrets = map[string]*resolvedSecrets{}
+	}
+	cached, ok = w.secrets[key]
+	if !ok || cached.revision != revision {
+		// Only the latest revision is kept, so the values of a replaced revision are dropped.
+		cached = &resolvedSecrets{revision: revision, values: map[string]string{}}
+		w.secrets[key] = cached
+	}
+	cached.values[reference] = value
+	return value, nil
+}
+
+// replaceStrings walks the given value and replaces each string with the one the given function returns.
+// The returned value replaces the given one where the given one is not settable, e.g. a map value.
+func replaceStrings(v reflect.Value, replace func(string) (string, error)) (reflect.Value, error) {
+	switch v.Kind() {
+	case reflect.String:
+		s, err := replace(v.String())
+		if err != nil || s == v.String() {
+			return v, err
+		}
+		return reflect.ValueOf(s).Convert(v.Type()), nil
+
+	case reflect.Ptr:
+		if v.IsNil() {
+			return v, nil
+		}
+		elem := v.Elem()
+		replaced, err := replaceStrings(elem, replace)
+		if err != nil {
+			return v, err
+		}
+		if elem.CanSet() {
+			elem.Set(replaced)
+		}
+		return v, nil
+
+	case reflect.Interface:
+		if v.IsNil() {
+			return v, nil
+		}
+		replaced, err := replaceStrings(v.Elem(), replace)
+		if err != nil {
+			return v, err
+		}
+		value := reflect.New(v.Type()).Elem()
+		value.Set(replaced)
+		return value, nil
+
+	case reflect.Struct:
+		value := reflect.New(v.Type()).Elem()
+		value.Set(v)
+		for i := 0; i < value.NumField(); i++ {
+			field := value.Field(i)
+			if !field.CanSet() {
+				continue
+			}
+			replaced, err := replaceStrings(field, replace)
+			if err != nil {
+				return v, err
+			}
+			field.Set(replaced)
+		}
+		return value, nil
+
+	case reflect.Map:
+		for _, key := range v.MapKeys() {
+			replaced, err := replaceStrings(v.MapIndex(key), replace)
+			if err != nil {
+				return v, err
+			}
+			v.SetMapIndex(key, replaced)
+		}
+		return v, nil
+
+	case reflect.Slice:
+		for i := 0; i < v.Len(); i++ {
+			replaced, err := replaceStrings(v.Index(i), replace)
+			if err != nil {
+				return v, err
+			}
+			v.Index(i).Set(replaced)
+		}
+		return v, nil
+
+	case reflect.Array:
+		value := reflect.New(v.Type()).Elem()
+		value.Set(v)
+		for i := 0; i < value.Len(); i++ {
+			replaced, err := replaceStrings(value.Index(i), replace)
+			if err != nil {
+				return v, err
+			}
+			value.Index(i).Set(replaced)
+		}
+		return value, nil
+
+	default:
+		return v, nil
+
+	}
+}
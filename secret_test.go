@@ -0,0 +1,171 @@
+package githubconfig
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"testing"
+)
+
+type DummySecretResolver struct {
+	ResolveSecretFunc func(ctx context.Context, reference string) (string, error)
+}
+
+func (r *DummySecretResolver) ResolveSecret(ctx context.Context, reference string) (string, error) {
+	return r.ResolveSecretFunc(ctx, reference)
+}
+
+func TestWithSecretResolver(t *testing.T) {
+	resolver := &DummySecretResolver{}
+	w := &watcher{}
+	WithSecretResolver(resolver)(w)
+
+	if w.secretResolver != resolver {
+		t.Error("Resolver is not set.")
+	}
+}
+
+func TestWatcher_read_withSecretResolver(t *testing.T) {
+	secrets := map[string]string{
+		"slack/token": "xoxb-secret",
+		"quoted":      `pass"word`,
+		"injection":   "x\nadmin: true",
+	}
+	w := &watcher{
+		secretResolver: &DummySecretResolver{
+			ResolveSecretFunc: func(_ context.Context, reference string) (string, error) {
+				value, ok := secrets[reference]
+				if !ok {
+					return "", errors.New("not found")
+				}
+				return value, nil
+			},
+		},
+	}
+
+	type config struct {
+		Token    string `yaml:"token" json:"token"`
+		Password string `yaml:"password" json:"password"`
+		Admin    bool   `yaml:"admin" json:"admin"`
+		Name     string `yaml:"name" json:"name"`
+	}
+	tests := []struct {
+		file     *file
+		expected config
+		err      bool
+	}{
+		{file: newFile("hello.yml", "oid", "token: Bearer ${secret:slack/token}\n"), expected: config{Token: "Bearer xoxb-secret"}},
+		{file: newFile("hello.json", "oid", `{"password": "${secret:quoted}"}`), expected: config{Password: `pass"word`}},
+		{file: newFile("hello.yml", "oid", "password: ${secret:injection}\n"), expected: config{Password: "x\nadmin: true"}},
+		{file: newFile("hello.yml", "oid", "name: ${NAME}\n"), expected: config{Name: "${NAME}"}},
+		{file: newFile("hello.yml", "oid", "token: ${secret:absent}\n"), err: true},
+	}
+
+	for i, tt := range tests {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			cfg := &config{}
+			err := w.read(context.TODO(), "bot", tt.file, cfg)
+			if tt.err {
+				if err == nil {
+					t.Error("Expected error is not returned.")
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("Unexpected error is returned: %s.", err.Error())
+			}
+
+			if *cfg != tt.expected {
+				t.Errorf("Unexpected value is decoded: %+v.", cfg)
+			}
+		})
+	}
+}
+
+func TestWatcher_read_withSecretResolverGeneric(t *testing.T) {
+	w := &watcher{
+		secretResolver: &DummySecretResolver{
+			ResolveSecretFunc: func(_ context.Context, _ string) (string, error) {
+				return "x\nadmin: true", nil
+			},
+		},
+	}
+	f := newFile("hello.yml", "oid", "password: ${secret:injection}\nlist:\n  - ${secret:injection}\n")
+
+	var value map[string]interface{}
+	err := w.read(context.TODO(), "bot", f, &value)
+	if err != nil {
+		t.Fatalf("Unexpected error is returned: %s.", err.Error())
+	}
+
+	if _, ok := value["admin"]; ok || value["password"] != "x\nadmin: true" {
+		t.Errorf("Unexpected value is decoded: %#v.", value)
+	}
+	if list, ok := value["list"].([]interface{}); !ok || list[0] != "x\nadmin: true" {
+		t.Errorf("Unexpected list is decoded: %#v.", value["list"])
+	}
+}
+
+func TestWatcher_resolveSecret_cache(t *testing.T) {
+	type key struct{}
+	calls := 0
+	w := &watcher{
+		secretResolver: &DummySecretResolver{
+			ResolveSecretFunc: func(ctx context.Context, _ string) (string, error) {
+				if ctx.Value(key{}) == nil {
+					t.Error("The caller's context is not passed.")
+				}
+				calls++
+				return "xoxb-secret", nil
+			},
+		},
+	}
+	ctx := context.WithValue(context.TODO(), key{}, true)
+
+	config := &struct {
+		Token string `yaml:"token"`
+	}{}
+	f := newFile("hello.yml", "oid", "token: ${secret:slack/token}\n")
+	for i := 0; i < 3; i++ {
+		err := w.read(ctx, "bot", f, config)
+		if err != nil {
+			t.Fatalf("Unexpected error is returned: %s.", err.Error())
+		}
+	}
+	if calls != 1 {
+		t.Errorf("Secret is resolved for every read: %d.", calls)
+	}
+
+	err := w.read(ctx, "bot", newFile("hello.yml", "new", "token: ${secret:slack/token}\n"), config)
+	if err != nil {
+		t.Fatalf("Unexpected error is returned: %s.", err.Error())
+	}
+	if calls != 2 {
+		t.Errorf("Secret is not resolved for the new revision: %d.", calls)
+	}
+}
+
+func TestWatcher_decode_withSecretResolver(t *testing.T) {
+	w := &watcher{
+		secretResolver: &DummySecretResolver{
+			ResolveSecretFunc: func(_ context.Context, _ string) (string, error) {
+				t.Error("Secret must not be resolved on decode.")
+				return "xoxb-secret", nil
+			},
+		},
+	}
+	f := newFile("hello.yml", "oid", "token: ${secret:slack/token}\n")
+
+	config := &struct {
+		Token string `yaml:"token"`
+	}{}
+	err := w.decode("bot", f, config)
+	if err != nil {
+		t.Fatalf("Unexpected error is returned: %s.", err.Error())
+	}
+
+	if config.Token != "${secret:slack/token}" {
+		t.Errorf("Unexpected value is decoded: %s.", config.Token)
+	}
+}
@@ -0,0 +1,140 @@
+package githubconfig
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/shurcooL/githubv4"
+	"gopkg.in/yaml.v3"
+	"io"
+	"path"
+	"strings"
+	"time"
+)
+
+// selfConfigFileName is the name of the optional file located at the base directory that configures the watcher itself.
+// This is read only when WithSelfConfiguration is given, and its content is as below:
+//
+//	interval: 5m
+//	branch: release
+//	paused: false
+//	include:
+//	  - "*.yml"
+//	exclude:
+//	  - "archive/**"
+//	required_approvals: 2
+//	code_owner_approval: true
+//
+// The include and exclude patterns replace the ones given by WithInclude and WithExclude,
+// and the approval settings replace the ones given by WithRequiredApprovals and WithCodeOwnerApproval.
+// Any omitted setting is left as-is.
+// A file declaring an unknown setting or a malformed value is rejected instead of partially applied.
+const selfConfigFileName = "_watcher.yml"
+
+type selfConfig struct {
+	Interval          time.Duration `yaml:"interval"`
+	Branch            string        `yaml:"branch"`
+	Paused            *bool         `yaml:"paused"`
+	Include           []string      `yaml:"include"`
+	Exclude           []string      `yaml:"exclude"`
+	RequiredApprovals *int          `yaml:"required_approvals"`
+	CodeOwnerApproval *bool         `yaml:"code_owner_approval"`
+}
+
+// WithSelfConfiguration enables the watcher to read _watcher.yml located at the base directory
+// and to hot-reload the interval, the branch, the paused state, the filters, and the approval policy declared in that file
+// whenever the file changes, so operators can tune the watcher without redeploying the bot.
+// Start reads the file before the first refresh, and returns an error when the file is malformed.
+// The file is always read from the branch given by Config so the declared branch does not hide the file itself.
+// New returns an error when this is given along with WithBundle, which serves the configurations without reading the repository.
+func WithSelfConfiguration() Option {
+	return func(w *watcher) {
+		w.selfConfiguration = true
+	}
+}
+
+// validateSelfConfiguration returns an error when WithSelfConfiguration is given along with an option that never reads the file.
+func (w *watcher) validateSelfConfiguration() error {
+	if w.selfConfiguration && w.bundlePath != "" {
+		return fmt.Errorf("%s can not be read while serving a bundle: give either WithSelfConfiguration or WithBundle", selfConfigFileName)
+	}
+	return nil
+}
+
+// getSelfConfig fetches the self-configuration file as of the given ref.
+// This returns nil when the file does not exist or when its object ID equals to the given one, which means no change.
+func (w *watcher) getSelfConfig(ctx context.Context, ref string, objectID string) (*reconfiguration, string, error) {
+	q := &blobQuery{}
+	variables := map[string]interface{}{
+		"owner":      githubv4.String(w.config.Owner),
+		"name":       githubv4.String(w.config.Name),
+		"expression": githubv4.String(expression(ref, path.Join(w.config.BaseDir, selfConfigFileName))),
+	}
+	err := w.client.Query(ctx, q, variables)
+	if err != nil {
+		return nil, objectID, w.queryError("", repositoryName(w.config.Owner, w.config.Name), err)
+	}
+
+	b := q.Repository.Object.Blob
+	if b.Oid == "" || string(b.Oid) == objectID {
+		return nil, objectID, nil
+	}
+
+	cfg := &selfConfig{}
+	decoder := yaml.NewDecoder(strings.NewReader(string(b.Text)))
+	decoder.KnownFields(true)
+	err = decoder.Decode(cfg)
+	if err != nil && !errors.Is(err, io.EOF) {
+		return nil, objectID, fmt.Errorf("failed to parse %s: %w", selfConfigFileName, err)
+	}
+
+	r := &reconfiguration{
+		interval:          cfg.Interval,
+		branch:            cfg.Branch,
+		paused:            cfg.Paused,
+		requiredApprovals: cfg.RequiredApprovals,
+		codeOwnerApproval: cfg.CodeOwnerApproval,
+	}
+	if cfg.Include != nil || cfg.Exclude != nil {
+		current := w.filters()
+		r.globs = &globs{include: current.include, exclude: current.exclude}
+		if cfg.Include != nil {
+			r.globs.include = cfg.Include
+		}
+		if cfg.Exclude != nil {
+			r.globs.exclude = cfg.Exclude
+		}
+		err = r.globs.validate()
+		if err != nil {
+			return nil, objectID, fmt.Errorf("invalid filter in %s: %w", selfConfigFileName, err)
+		}
+	}
+	if cfg.RequiredApprovals != nil && *cfg.RequiredApprovals < 0 {
+		return nil, objectID, fmt.Errorf("invalid required_approvals in %s: %d", selfConfigFileName, *cfg.RequiredApprovals)
+	}
+
+	return r, string(b.Oid), nil
+}
+
+// loadSelfConfig reads the self-configuration file and applies it before the first refresh.
+func (w *watcher) loadSelfConfig(ctx context.Context) error {
+	if !w.selfConfiguration {
+		return nil
+	}
+
+	ref := w.branch()
+	r, id, err := w.getSelfConfig(ctx, ref, "")
+	if err != nil {
+		return err
+	}
+
+	w.selfConfigRef = ref
+	w.selfConfigID = id
+	if r != nil {
+		w.apply(r)
+		if r.paused != nil {
+			w.startPaused = *r.paused
+		}
+	}
+	return nil
+}
@@ -0,0 +1,305 @@
+package githubconfig
+
+import (
+	"context"
+	"crypto/ed25519"
+	"github.com/shurcooL/githubv4"
+	"path/filepath"
+	"reflect"
+	"strconv"
+	"testing"
+	"time"
+)
+
+func TestWithSelfConfiguration(t *testing.T) {
+	w := &watcher{}
+	WithSelfConfiguration()(w)
+
+	if !w.selfConfiguration {
+		t.Error("Self-configuration is not enabled.")
+	}
+}
+
+func TestWatcher_getSelfConfig(t *testing.T) {
+	tests := []struct {
+		objectID string
+		content  string
+		current  string
+		paused   bool
+		changed  bool
+		error    bool
+	}{
+		{
+			objectID: "",
+			changed:  false,
+		},
+		{
+			objectID: "oid",
+			content:  "interval: 5m\n",
+			current:  "oid",
+			changed:  false,
+		},
+		{
+			objectID: "new",
+			content:  "interval: 5m\nbranch: release\npaused: true\n",
+			current:  "old",
+			changed:  true,
+			paused:   true,
+		},
+		{
+			objectID: "new",
+			content:  "interval: [\n",
+			current:  "old",
+			error:    true,
+		},
+		{
+			objectID: "new",
+			content:  "interval: 5m\nunknown: true\n",
+			current:  "old",
+			error:    true,
+		},
+		{
+			objectID: "new",
+			content:  "include:\n  - \"[.yml\"\n",
+			current:  "old",
+			error:    true,
+		},
+		{
+			objectID: "new",
+			content:  "required_approvals: -1\n",
+			current:  "old",
+			error:    true,
+		},
+	}
+
+	for i, tt := range tests {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			w := &watcher{
+				client: &DummyQuerier{
+					QueryFunc: func(_ context.Context, q interface{}, v map[string]interface{}) error {
+						if v["expression"] != githubv4.String("master:config/_watcher.yml") {
+							t.Errorf("Unexpected expression is given: %s.", v["expression"])
+						}
+
+						typed := q.(*blobQuery)
+						typed.Repository.Object.Blob.Oid = githubv4.String(tt.objectID)
+						typed.Repository.Object.Blob.Text = githubv4.String(tt.content)
+						return nil
+					},
+				},
+				config: &Config{BaseDir: "config"},
+			}
+
+			r, id, err := w.getSelfConfig(context.Background(), "master", tt.current)
+			if tt.error {
+				if err == nil {
+					t.Fatal("Expected error is not returned.")
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("Unexpected error is returned: %s.", err.Error())
+			}
+
+			if !tt.changed {
+				if r != nil {
+					t.Errorf("Unexpected reconfiguration is returned: %+v.", r)
+				}
+				if id != tt.current {
+					t.Errorf("Unexpected object ID is returned: %s.", id)
+				}
+				return
+			}
+
+			if id != tt.objectID {
+				t.Errorf("Unexpected object ID is returned: %s.", id)
+			}
+
+			if r.interval != 5*time.Minute || r.branch != "release" {
+				t.Errorf("Unexpected reconfiguration is returned: %+v.", r)
+			}
+
+			if r.paused == nil || *r.paused != tt.paused {
+				t.Errorf("Unexpected paused state is returned: %v.", r.paused)
+			}
+		})
+	}
+}
+
+func TestWatcher_getSelfConfig_policies(t *testing.T) {
+	w := &watcher{
+		client: &DummyQuerier{
+			QueryFunc: func(_ context.Context, q interface{}, _ map[string]interface{}) error {
+				typed := q.(*blobQuery)
+				typed.Repository.Object.Blob.Oid = "oid"
+				typed.Repository.Object.Blob.Text = "include:\n  - \"*.yml\"\nrequired_approvals: 2\ncode_owner_approval: true\n"
+				return nil
+			},
+		},
+		config: &Config{BaseDir: "config", Branch: "master"},
+		globs:  globs{include: []string{"*.json"}, exclude: []string{"archive/**"}},
+	}
+
+	r, _, err := w.getSelfConfig(context.Background(), "master", "")
+	if err != nil {
+		t.Fatalf("Unexpected error is returned: %s.", err.Error())
+	}
+
+	// The omitted exclude patterns are left as-is.
+	expected := globs{include: []string{"*.yml"}, exclude: []string{"archive/**"}}
+	if r.globs == nil || !reflect.DeepEqual(*r.globs, expected) {
+		t.Errorf("Unexpected filters are returned: %+v.", r.globs)
+	}
+
+	w.approvals = map[string]error{"memorized": nil}
+	changed := w.apply(r)
+	if !changed {
+		t.Error("Filter change is not reported.")
+	}
+	if !w.candidate("hello.yml") || w.candidate("hello.json") || w.candidate("archive/hello.yml") {
+		t.Errorf("Filters are not applied: %+v.", w.globs)
+	}
+	if w.requiredApprovals != 2 || !w.codeOwnerApproval {
+		t.Errorf("Approval policy is not applied: %d, %t.", w.requiredApprovals, w.codeOwnerApproval)
+	}
+	if w.approvals != nil {
+		t.Errorf("Memorized approvals are kept over a policy change: %+v.", w.approvals)
+	}
+
+	if w.apply(r) {
+		t.Error("Unchanged filters are reported as changed.")
+	}
+}
+
+func TestWatcher_Start_withSelfConfiguration(t *testing.T) {
+	w, err := New(&Config{Branch: "master", Interval: time.Hour, TimeOut: time.Second}, func(w *watcher) {
+		w.client = &DummyQuerier{
+			QueryFunc: func(_ context.Context, q interface{}, _ map[string]interface{}) error {
+				switch typed := q.(type) {
+				case *blobQuery:
+					typed.Repository.Object.Blob.Oid = "oid"
+					typed.Repository.Object.Blob.Text = "branch: release\npaused: true\nexclude:\n  - \"*.json\"\n"
+
+				}
+				return nil
+			},
+		}
+	}, WithSelfConfiguration())
+	if err != nil {
+		t.Fatalf("Unexpected error is returned: %s.", err.Error())
+	}
+
+	err = w.Start(context.Background())
+	if err != nil {
+		t.Fatalf("Unexpected error is returned: %s.", err.Error())
+	}
+	defer func() {
+		_ = w.Stop()
+	}()
+
+	// Settings are applied before the first refresh.
+	typed := w.(*watcher)
+	if typed.branch() != "release" {
+		t.Errorf("Branch declared in %s is not applied: %s.", selfConfigFileName, typed.branch())
+	}
+	if typed.candidate("hello.json") {
+		t.Errorf("Filters declared in %s are not applied: %+v.", selfConfigFileName, typed.filters())
+	}
+
+	status, err := w.Status(context.Background())
+	if err != nil {
+		t.Fatalf("Unexpected error is returned: %s.", err.Error())
+	}
+	if !status.Paused {
+		t.Error("Paused state declared in the file is not applied.")
+	}
+}
+
+func TestWatcher_Start_withMalformedSelfConfiguration(t *testing.T) {
+	w, err := New(&Config{Interval: time.Hour, TimeOut: time.Second}, func(w *watcher) {
+		w.client = &DummyQuerier{
+			QueryFunc: func(_ context.Context, q interface{}, _ map[string]interface{}) error {
+				typed := q.(*blobQuery)
+				typed.Repository.Object.Blob.Oid = "oid"
+				typed.Repository.Object.Blob.Text = "interval: ["
+				return nil
+			},
+		}
+	}, WithSelfConfiguration())
+	if err != nil {
+		t.Fatalf("Unexpected error is returned: %s.", err.Error())
+	}
+
+	err = w.Start(context.Background())
+	if err == nil {
+		_ = w.Stop()
+		t.Error("Expected error is not returned.")
+	}
+}
+
+func TestNew_selfConfigurationWithBundle(t *testing.T) {
+	public, private, _ := ed25519.GenerateKey(nil)
+	name := filepath.Join(t.TempDir(), "bundle.json")
+	exporter := &watcher{bundleExport: name, bundleSigningKey: private}
+	err := exporter.exportBundle("bot", map[string]*file{"hello": newFile("hello.yml", "abc", "message: hello\n")}, nil)
+	if err != nil {
+		t.Fatalf("Unexpected error is returned: %s.", err.Error())
+	}
+
+	_, err = New(&Config{TimeOut: time.Second}, WithBundle(name, public), WithSelfConfiguration())
+	if err == nil {
+		t.Error("Expected error is not returned.")
+	}
+}
+
+func TestWatcher_operate_withSelfConfiguration(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	refs := make(chan string, 10)
+	w := &watcher{
+		client: &DummyQuerier{
+			QueryFunc: func(_ context.Context, q interface{}, v map[string]interface{}) error {
+				switch typed := q.(type) {
+				case *blobQuery:
+					typed.Repository.Object.Blob.Oid = "oid"
+					typed.Repository.Object.Blob.Text = "branch: release\n"
+
+				case *query:
+					refs <- string(v["ref"].(githubv4.String))
+					typed.Repository.Object.Tree.Entries = []entry{
+						{Name: "hello.json", Object: entryObject{Blob: blob{Oid: "oid", Text: "{}"}}},
+					}
+
+				}
+				return nil
+			},
+		},
+		config: &Config{
+			Branch:   "master",
+			TimeOut:  100 * time.Millisecond,
+			Interval: 10 * time.Millisecond,
+		},
+		selfConfiguration: true,
+		subscription:      make(chan *subscription),
+	}
+	w.started.Store(true)
+	go w.operate(ctx)
+
+	_, err := w.WatchContext(ctx, "bot", "hello", func(_ context.Context, _ string) {})
+	if err != nil {
+		t.Fatalf("Unexpected error is returned: %s.", err.Error())
+	}
+
+	select {
+	case ref := <-refs:
+		if ref != "release" {
+			t.Errorf("Branch declared in %s is not applied: %s.", selfConfigFileName, ref)
+		}
+
+	case <-time.NewTimer(1 * time.Second).C:
+		t.Error("Configuration files are not fetched.")
+
+	}
+}
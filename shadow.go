@@ -0,0 +1,91 @@
+package githubconfig
+
+import (
+	"context"
+	"fmt"
+	"github.com/oklahomer/go-sarah/v4"
+)
+
+// WithShadowHook registers a function that evaluates each newly detected configuration without affecting whether it is applied,
+// so a team can trial a stricter validation such as a tighter schema before enforcing it with WithPostDecodeHook.
+// The function receives the value decoded into a copy of the prototype given by WithPrototype,
+// or the JSON-compatible value of a YAML, JSON or CUE file without a prototype.
+// The result is emitted as EventShadowEvaluated, whose Err is a *ShadowEvaluationError when the configuration fails the evaluation.
+// The evaluation runs asynchronously so a slow function never delays the changes from being applied.
+func WithShadowHook(hook func(botType sarah.BotType, id string, out interface{}) error) Option {
+	return func(w *watcher) {
+		w.shadowHooks = append(w.shadowHooks, hook)
+	}
+}
+
+// ShadowEvaluationError describes a configuration that fails the evaluation of the functions given by WithShadowHook.
+// The configuration is applied regardless.
+type ShadowEvaluationError struct {
+	BotType sarah.BotType
+	ID      string
+	Err     error
+}
+
+// Error returns stringified representation of the error.
+func (err *ShadowEvaluationError) Error() string {
+	return fmt.Sprintf("configuration for %s:%s fails shadow evaluation: %s", err.BotType, err.ID, err.Err.Error())
+}
+
+// Unwrap returns the underlying error.
+func (err *ShadowEvaluationError) Unwrap() error {
+	return err.Err
+}
+
+var _ error = (*ShadowEvaluationError)(nil)
+
+// shadowEvaluate runs the given file through the functions given by WithShadowHook and emits the result.
+func (w *watcher) shadowEvaluate(ctx context.Context, botType sarah.BotType, f *file) {
+	if len(w.shadowHooks) == 0 {
+		return
+	}
+
+	prototype, ok := w.prototypes[botType][f.id]
+	if !ok && !supportsSchema(f) {
+		return
+	}
+
+	w.callbacks.Add(1)
+	go func() {
+		defer w.callbacks.Done()
+
+		err := w.evaluate(ctx, botType, f, prototype, ok)
+		if err != nil {
+			err = &ShadowEvaluationError{BotType: botType, ID: f.id, Err: w.redactError(botType, f, err)}
+		}
+		if ctx.Err() != nil {
+			return
+		}
+		w.emit(Event{Type: EventShadowEvaluated, BotType: botType, ID: f.id, Err: err})
+	}()
+}
+
+// evaluate decodes the file and runs the value through each function, and returns the first error.
+func (w *watcher) evaluate(ctx context.Context, botType sarah.BotType, f *file, prototype interface{}, ok bool) error {
+	var out interface{}
+	if ok {
+		out = copyPrototype(prototype)
+		err := w.read(ctx, botType, f, out)
+		if err != nil {
+			return err
+		}
+	} else {
+		value, err := w.generic(botType, f)
+		if err != nil {
+			return err
+		}
+		out = value
+	}
+
+	for _, hook := range w.shadowHooks {
+		err := hook(botType, f.id, out)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,106 @@
+package githubconfig
+
+import (
+	"context"
+	"errors"
+	"github.com/oklahomer/go-sarah/v4"
+	"testing"
+)
+
+func TestWithShadowHook(t *testing.T) {
+	w := &watcher{}
+	hook := func(_ sarah.BotType, _ string, _ interface{}) error {
+		return nil
+	}
+	WithShadowHook(hook)(w)
+	WithShadowHook(hook)(w)
+
+	if len(w.shadowHooks) != 2 {
+		t.Errorf("Unexpected number of hooks are set: %d.", len(w.shadowHooks))
+	}
+}
+
+func TestWatcher_shadowEvaluate(t *testing.T) {
+	type config struct {
+		Message string `yaml:"message"`
+	}
+	rejected := errors.New("message must not be empty")
+
+	tests := []struct {
+		prototype interface{}
+		content   string
+		err       error
+	}{
+		{prototype: &config{}, content: "message: hello\n", err: nil},
+		{prototype: &config{}, content: "message: \"\"\n", err: rejected},
+		{prototype: nil, content: "message: hello\n", err: nil},
+		{prototype: nil, content: "other: value\n", err: rejected},
+	}
+
+	for i, tt := range tests {
+		w := &watcher{
+			events: make(chan Event, 10),
+		}
+		if tt.prototype != nil {
+			WithPrototype("bot", "hello", tt.prototype)(w)
+		}
+		WithShadowHook(func(botType sarah.BotType, id string, out interface{}) error {
+			if botType != "bot" || id != "hello" {
+				t.Errorf("Unexpected configuration is given: %s:%s.", botType, id)
+			}
+
+			message := ""
+			switch v := out.(type) {
+			case *config:
+				message = v.Message
+
+			case map[string]interface{}:
+				message, _ = v["message"].(string)
+
+			default:
+				t.Errorf("Unexpected value is given: %#v.", out)
+
+			}
+			if message == "" {
+				return rejected
+			}
+			return nil
+		})(w)
+
+		w.shadowEvaluate(context.TODO(), "bot", newFile("hello.yml", "abc", tt.content))
+		w.callbacks.Wait()
+
+		if len(w.events) != 1 {
+			t.Fatalf("Unexpected number of events are emitted on %d: %d.", i, len(w.events))
+		}
+		event := <-w.events
+		if event.Type != EventShadowEvaluated || event.ID != "hello" {
+			t.Errorf("Unexpected event is emitted on %d: %+v.", i, event)
+		}
+		if tt.err == nil {
+			if event.Err != nil {
+				t.Errorf("Unexpected error is returned on %d: %s.", i, event.Err.Error())
+			}
+			continue
+		}
+		var shadowErr *ShadowEvaluationError
+		if !errors.As(event.Err, &shadowErr) || !errors.Is(event.Err, tt.err) {
+			t.Errorf("Unexpected error is returned on %d: %#v.", i, event.Err)
+		}
+	}
+}
+
+func TestWatcher_shadowEvaluate_skipped(t *testing.T) {
+	w := &watcher{events: make(chan Event, 10)}
+	w.shadowEvaluate(context.TODO(), "bot", newFile("hello.yml", "abc", "message: hello\n"))
+
+	WithShadowHook(func(_ sarah.BotType, _ string, _ interface{}) error {
+		return nil
+	})(w)
+	w.shadowEvaluate(context.TODO(), "bot", newFile("hello.txt", "abc", "hello"))
+	w.callbacks.Wait()
+
+	if len(w.events) != 0 {
+		t.Errorf("Unexpected event is emitted: %+v.", <-w.events)
+	}
+}
@@ -0,0 +1,109 @@
+package githubconfig
+
+import (
+	"context"
+	"fmt"
+	"github.com/oklahomer/go-kasumi/logger"
+	"github.com/oklahomer/go-sarah/v4"
+	"log/slog"
+	"runtime"
+	"time"
+)
+
+// WithSlog emits the watcher's logs as log/slog records through the given logger instead of go-kasumi/logger.
+// Each record carries the structured attributes that apply such as bot_type, id, commit and duration,
+// so the logs can be filtered and aggregated without parsing the messages.
+// Give a logger derived with slog.Logger.WithGroup to group the attributes, or slog.Logger.With to add the caller's own ones.
+func WithSlog(l *slog.Logger) Option {
+	return func(w *watcher) {
+		w.slogger = l
+	}
+}
+
+// WithSlogHandler is similar to WithSlog, but emits the records to the given handler.
+func WithSlogHandler(handler slog.Handler) Option {
+	return WithSlog(slog.New(handler))
+}
+
+// logEntry emits a log with the attributes either to the logger given by WithSlog or to go-kasumi/logger.
+// Its logging methods must be called directly where the log is emitted, so the log reports the caller's source location.
+type logEntry struct {
+	slogger *slog.Logger
+	attrs   []slog.Attr
+}
+
+// log returns a logEntry with the given BotType, id and other attributes. An empty BotType or id is omitted.
+func (w *watcher) log(botType sarah.BotType, id string, attrs ...slog.Attr) *logEntry {
+	var all []slog.Attr
+	if botType != "" {
+		all = append(all, slog.String("bot_type", botType.String()))
+	}
+	if id != "" {
+		all = append(all, slog.String("id", id))
+	}
+	return &logEntry{
+		slogger: w.slogger,
+		attrs:   append(all, attrs...),
+	}
+}
+
+// commitAttr returns the attribute of the given commit SHA.
+func commitAttr(sha string) slog.Attr {
+	return slog.String("commit", sha)
+}
+
+// durationAttr returns the attribute of the time an operation took.
+func durationAttr(d time.Duration) slog.Attr {
+	return slog.Duration("duration", d)
+}
+
+// Debugf emits a debug log.
+func (e *logEntry) Debugf(format string, args ...interface{}) {
+	if e.slogger == nil {
+		// Call the logger's method, not the package function, so the source location is reported at the same depth.
+		logger.GetLogger().Debugf(format, args...)
+		return
+	}
+	e.emit(slog.LevelDebug, format, args)
+}
+
+// Infof emits an informational log.
+func (e *logEntry) Infof(format string, args ...interface{}) {
+	if e.slogger == nil {
+		logger.GetLogger().Infof(format, args...)
+		return
+	}
+	e.emit(slog.LevelInfo, format, args)
+}
+
+// Warnf emits a warning log.
+func (e *logEntry) Warnf(format string, args ...interface{}) {
+	if e.slogger == nil {
+		logger.GetLogger().Warnf(format, args...)
+		return
+	}
+	e.emit(slog.LevelWarn, format, args)
+}
+
+// Errorf emits an error log.
+func (e *logEntry) Errorf(format string, args ...interface{}) {
+	if e.slogger == nil {
+		logger.GetLogger().Errorf(format, args...)
+		return
+	}
+	e.emit(slog.LevelError, format, args)
+}
+
+func (e *logEntry) emit(level slog.Level, format string, args []interface{}) {
+	ctx := context.Background()
+	if !e.slogger.Enabled(ctx, level) {
+		return
+	}
+
+	// Skip runtime.Callers, emit and the logging method to point at the caller.
+	var pcs [1]uintptr
+	runtime.Callers(3, pcs[:])
+	record := slog.NewRecord(time.Now(), level, fmt.Sprintf(format, args...), pcs[0])
+	record.AddAttrs(e.attrs...)
+	_ = e.slogger.Handler().Handle(ctx, record)
+}
@@ -0,0 +1,78 @@
+package githubconfig
+
+import (
+	"bytes"
+	"encoding/json"
+	"log/slog"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestWithSlog(t *testing.T) {
+	l := slog.Default()
+	w := &watcher{}
+	WithSlog(l)(w)
+
+	if w.slogger != l {
+		t.Error("Unexpected logger is set.")
+	}
+}
+
+func TestWithSlogHandler(t *testing.T) {
+	handler := slog.NewTextHandler(&bytes.Buffer{}, nil)
+	w := &watcher{}
+	WithSlogHandler(handler)(w)
+
+	if w.slogger == nil || w.slogger.Handler() != handler {
+		t.Error("Unexpected handler is set.")
+	}
+}
+
+func TestLogEntry(t *testing.T) {
+	buf := &bytes.Buffer{}
+	w := &watcher{
+		slogger: slog.New(slog.NewJSONHandler(buf, &slog.HandlerOptions{AddSource: true, Level: slog.LevelInfo})),
+	}
+
+	w.log("bot", "hello", commitAttr("sha"), durationAttr(time.Second)).Warnf("Refusing to apply %s", "hello.yml")
+	w.log("", "").Debugf("Filtered out")
+
+	var record struct {
+		Level    string `json:"level"`
+		Message  string `json:"msg"`
+		BotType  string `json:"bot_type"`
+		ID       string `json:"id"`
+		Commit   string `json:"commit"`
+		Duration int64  `json:"duration"`
+		Source   struct {
+			File string `json:"file"`
+		} `json:"source"`
+	}
+	decoder := json.NewDecoder(buf)
+	err := decoder.Decode(&record)
+	if err != nil {
+		t.Fatalf("Unexpected error is returned: %s.", err.Error())
+	}
+
+	if record.Level != "WARN" || record.Message != "Refusing to apply hello.yml" {
+		t.Errorf("Unexpected record is emitted: %+v.", record)
+	}
+	if record.BotType != "bot" || record.ID != "hello" || record.Commit != "sha" || record.Duration != int64(time.Second) {
+		t.Errorf("Unexpected attributes are emitted: %+v.", record)
+	}
+	if filepath.Base(record.Source.File) != "slog_test.go" {
+		t.Errorf("Unexpected source is reported: %s.", record.Source.File)
+	}
+
+	if decoder.More() {
+		t.Error("Record below the level is emitted.")
+	}
+}
+
+func TestWatcher_log_omitted(t *testing.T) {
+	entry := (&watcher{}).log("", "")
+	if len(entry.attrs) != 0 {
+		t.Errorf("Unexpected attributes are set: %v.", entry.attrs)
+	}
+}
@@ -0,0 +1,21 @@
+package githubconfig
+
+import (
+	"encoding/json"
+)
+
+// sniff returns a copy of the extension-less file with the extension to decode the content with.
+func (w *watcher) sniff(f *file) *file {
+	extension := w.defaultExtension
+	if extension == "" {
+		if json.Valid([]byte(f.content)) {
+			extension = ".json"
+		} else {
+			extension = ".yml"
+		}
+	}
+
+	sniffed := *f
+	sniffed.extension = extension
+	return &sniffed
+}
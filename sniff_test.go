@@ -0,0 +1,81 @@
+package githubconfig
+
+import (
+	"context"
+	"strconv"
+	"testing"
+)
+
+func TestWithContentSniffing(t *testing.T) {
+	opt := WithContentSniffing(".yml")
+	w := &watcher{}
+
+	opt(w)
+
+	if !w.sniffing {
+		t.Error("Sniffing is not enabled.")
+	}
+
+	if w.defaultExtension != ".yml" {
+		t.Errorf("Unexpected default extension is set: %s.", w.defaultExtension)
+	}
+}
+
+func TestWatcher_sniff(t *testing.T) {
+	tests := []struct {
+		defaultExtension string
+		content          string
+		expected         string
+	}{
+		{
+			content:  `{"name": "oklahomer"}`,
+			expected: ".json",
+		},
+		{
+			content:  "name: oklahomer\n",
+			expected: ".yml",
+		},
+		{
+			defaultExtension: ".toml",
+			content:          `{"name": "oklahomer"}`,
+			expected:         ".toml",
+		},
+	}
+
+	for i, tt := range tests {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			w := &watcher{sniffing: true, defaultExtension: tt.defaultExtension}
+			f := newFile("hello", "oid", tt.content)
+
+			sniffed := w.sniff(f)
+
+			if sniffed.extension != tt.expected {
+				t.Errorf("Unexpected extension is set: %s.", sniffed.extension)
+			}
+
+			if f.extension != "" {
+				t.Error("The original file must not be modified.")
+			}
+		})
+	}
+}
+
+func TestWatcher_read_withSniffing(t *testing.T) {
+	cfg := &struct {
+		Name string `json:"name" yaml:"name"`
+	}{}
+	f := newFile("hello", "oid", "name: oklahomer\n")
+
+	err := (&watcher{}).read(context.TODO(), "bot", f, cfg)
+	if err == nil {
+		t.Error("Extension-less file must not be decoded without sniffing.")
+	}
+
+	err = (&watcher{sniffing: true}).read(context.TODO(), "bot", f, cfg)
+	if err != nil {
+		t.Fatalf("Unexpected error is returned: %s.", err.Error())
+	}
+	if cfg.Name != "oklahomer" {
+		t.Errorf("Unexpected value is decoded: %s.", cfg.Name)
+	}
+}
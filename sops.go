@@ -0,0 +1,94 @@
+package githubconfig
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"gopkg.in/yaml.v3"
+	"strings"
+)
+
+// SOPSDecrypter decrypts the content of a SOPS-encrypted file in the given format: "yaml", "json", "dotenv" or "ini".
+// The decrypted content must not contain the sops metadata.
+type SOPSDecrypter func(content []byte, format string) ([]byte, error)
+
+// WithSOPS sets the function that decrypts SOPS-encrypted configuration files before decoding,
+// so the configurations that bear secrets can live in the same repository.
+// The key material is up to the function, e.g. wrap decrypt.DataWithFormat of github.com/getsops/sops/v3 that reads
+// the age or PGP keys and the cloud KMS credentials from the environment.
+// Without this option, a SOPS-encrypted file fails to decode instead of serving the encrypted values.
+func WithSOPS(decrypter SOPSDecrypter) Option {
+	return func(w *watcher) {
+		w.sopsDecrypter = decrypter
+	}
+}
+
+// errSOPSDecrypterMissing is returned when a SOPS-encrypted file is decoded without WithSOPS.
+var errSOPSDecrypterMissing = errors.New("the file is encrypted with SOPS but no decrypter is given with WithSOPS")
+
+// sopsFormat returns the format of the given file as SOPS calls it when the file is encrypted with SOPS.
+func sopsFormat(f *file) (string, bool) {
+	switch f.extension {
+	case ".yml", ".yaml", ".json":
+		// The YAML parser reads JSON as well.
+		document := struct {
+			SOPS struct {
+				MAC string `yaml:"mac"`
+			} `yaml:"sops"`
+		}{}
+		if !strings.Contains(f.content, "sops") || yaml.Unmarshal([]byte(f.content), &document) != nil || document.SOPS.MAC == "" {
+			return "", false
+		}
+		if f.extension == ".json" {
+			return "json", true
+		}
+		return "yaml", true
+
+	case ".env":
+		if hasLine(f.content, func(line string) bool { return strings.HasPrefix(line, "sops_mac=") }) {
+			return "dotenv", true
+		}
+		return "", false
+
+	case ".ini":
+		if hasLine(f.content, func(line string) bool { return line == "[sops]" }) {
+			return "ini", true
+		}
+		return "", false
+
+	default:
+		return "", false
+
+	}
+}
+
+func hasLine(content string, match func(line string) bool) bool {
+	scanner := bufio.NewScanner(strings.NewReader(content))
+	for scanner.Scan() {
+		if match(strings.TrimSpace(scanner.Text())) {
+			return true
+		}
+	}
+	return false
+}
+
+// decrypt returns the file with its content decrypted when it is encrypted with SOPS, or the file as is otherwise.
+func (w *watcher) decrypt(f *file) (*file, error) {
+	format, ok := sopsFormat(f)
+	if !ok {
+		return f, nil
+	}
+
+	if w.sopsDecrypter == nil {
+		return nil, errSOPSDecrypterMissing
+	}
+
+	content, err := w.sopsDecrypter([]byte(f.content), format)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decrypt %s: %w", f.fileName, err)
+	}
+
+	decrypted := *f
+	decrypted.content = string(content)
+	return &decrypted, nil
+}
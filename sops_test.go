@@ -0,0 +1,104 @@
+package githubconfig
+
+import (
+	"errors"
+	"strconv"
+	"testing"
+)
+
+const encryptedYAML = `token: ENC[AES256_GCM,data:abc,iv:def,tag:ghi,type:str]
+sops:
+    mac: ENC[AES256_GCM,data:mac,iv:iv,tag:tag,type:str]
+    version: 3.8.1
+`
+
+func TestWithSOPS(t *testing.T) {
+	w := &watcher{}
+	WithSOPS(func(content []byte, _ string) ([]byte, error) {
+		return content, nil
+	})(w)
+
+	if w.sopsDecrypter == nil {
+		t.Error("Decrypter is not set.")
+	}
+}
+
+func TestSOPSFormat(t *testing.T) {
+	tests := []struct {
+		file      *file
+		format    string
+		encrypted bool
+	}{
+		{file: newFile("hello.yml", "oid", encryptedYAML), format: "yaml", encrypted: true},
+		{file: newFile("hello.json", "oid", `{"token": "ENC[...]", "sops": {"mac": "ENC[...]"}}`), format: "json", encrypted: true},
+		{file: newFile("hello.env", "oid", "TOKEN=ENC[...]\nsops_mac=ENC[...]\n"), format: "dotenv", encrypted: true},
+		{file: newFile("hello.ini", "oid", "token = ENC[...]\n\n[sops]\nmac = ENC[...]\n"), format: "ini", encrypted: true},
+		{file: newFile("hello.yml", "oid", "sops: not a metadata\n"), encrypted: false},
+		{file: newFile("hello.yml", "oid", "name: hello\n"), encrypted: false},
+		{file: newFile("hello.env", "oid", "NAME=hello\n"), encrypted: false},
+		{file: newFile("hello.cue", "oid", "sops: mac: \"x\"\n"), encrypted: false},
+	}
+
+	for i, tt := range tests {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			format, encrypted := sopsFormat(tt.file)
+			if encrypted != tt.encrypted {
+				t.Fatalf("Unexpected result is returned: %t.", encrypted)
+			}
+
+			if format != tt.format {
+				t.Errorf("Unexpected format is returned: %s.", format)
+			}
+		})
+	}
+}
+
+func TestWatcher_decode_withSOPS(t *testing.T) {
+	f := newFile("hello.yml", "oid", encryptedYAML)
+
+	t.Run("decrypted", func(t *testing.T) {
+		w := &watcher{
+			sopsDecrypter: func(content []byte, format string) ([]byte, error) {
+				if string(content) != encryptedYAML || format != "yaml" {
+					t.Errorf("Unexpected content is given: %s in %s.", string(content), format)
+				}
+				return []byte("token: secret\n"), nil
+			},
+		}
+
+		config := &struct {
+			Token string `yaml:"token"`
+		}{}
+		err := w.decode("bot", f, config)
+		if err != nil {
+			t.Fatalf("Unexpected error is returned: %s.", err.Error())
+		}
+
+		if config.Token != "secret" {
+			t.Errorf("Unexpected value is decoded: %s.", config.Token)
+		}
+	})
+
+	t.Run("failed", func(t *testing.T) {
+		expected := errors.New("no key")
+		w := &watcher{
+			sopsDecrypter: func(_ []byte, _ string) ([]byte, error) {
+				return nil, expected
+			},
+		}
+
+		err := w.decode("bot", f, &struct{}{})
+		if !errors.Is(err, expected) {
+			t.Errorf("Expected error is not returned: %#v.", err)
+		}
+	})
+
+	t.Run("without decrypter", func(t *testing.T) {
+		w := &watcher{}
+
+		err := w.decode("bot", f, &struct{}{})
+		if !errors.Is(err, errSOPSDecrypterMissing) {
+			t.Errorf("Expected error is not returned: %#v.", err)
+		}
+	})
+}
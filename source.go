@@ -0,0 +1,125 @@
+package githubconfig
+
+import (
+	"context"
+	"errors"
+	"github.com/oklahomer/go-sarah/v4"
+	"strings"
+)
+
+// Source overrides the repository that a BotType's configuration files are fetched from.
+// This allows one watcher to serve BotTypes whose configuration files live in different repositories,
+// e.g. Slack's configuration files in one repository and a custom adapter's in another.
+// An empty field inherits the corresponding value of Config.
+type Source struct {
+	Owner   string `json:"owner" yaml:"owner"`
+	Name    string `json:"name" yaml:"name"`
+	BaseDir string `json:"base_dir" yaml:"base_dir"`
+	Branch  string `json:"branch" yaml:"branch"`
+	// PathTemplate is the template of the directory that contains the BotType's configuration files.
+	// See Config.PathTemplate for the supported placeholders.
+	PathTemplate string `json:"path_template" yaml:"path_template"`
+}
+
+// branches returns the branches to look up the given BotType's configuration files in, in the order of precedence.
+func (w *watcher) branches(botType sarah.BotType) []string {
+	if sha, ok := w.pinned(botType); ok {
+		return []string{sha}
+	}
+
+	var candidates []string
+	if w.config.Environment != "" && w.config.EnvironmentBranch != "" {
+		candidates = append(candidates, strings.ReplaceAll(w.config.EnvironmentBranch, "{environment}", w.config.Environment))
+	}
+	candidates = append(candidates, w.config.Branches...)
+	candidates = append(candidates, w.source(botType).Branch)
+
+	var branches []string
+	seen := map[string]struct{}{}
+	for _, branch := range candidates {
+		if _, ok := seen[branch]; ok {
+			continue
+		}
+		seen[branch] = struct{}{}
+		branches = append(branches, branch)
+	}
+	return branches
+}
+
+// fetchBranches fetches the configuration files of the given BotType from the branches and merges them.
+// A file found in an earlier branch wins, and a branch that does not exist is skipped unless it is the last one
+// so a deleted feature branch does not stop the bot. This also tells if the BotType's directory exists in any of the branches.
+func (w *watcher) fetchBranches(ctx context.Context, botType sarah.BotType) (map[string]*file, []entry, bool, error) {
+	branches := w.branches(botType)
+	files := map[string]*file{}
+	var schemas []entry
+	found := false
+	for i, branch := range branches {
+		ref, err := w.verifiedRef(ctx, botType, branch)
+		if err != nil {
+			return nil, nil, false, err
+		}
+
+		fetched, s, dirFound, err := w.fetch(ctx, botType, ref)
+		var notFound *RefOrPathNotFoundError
+		if errors.As(err, &notFound) && i < len(branches)-1 {
+			w.log(botType, "").Warnf("Skipping branch %s for %s: %+v", branch, botType, err)
+			continue
+		}
+		if err != nil {
+			return nil, nil, false, err
+		}
+
+		found = found || dirFound
+		if schemas == nil {
+			schemas = s
+		}
+		for id, f := range fetched {
+			if _, ok := files[id]; ok {
+				continue
+			}
+			f.branch = branch
+			files[id] = f
+		}
+	}
+
+	return files, schemas, found, nil
+}
+
+// source returns the repository to fetch the given BotType's configuration files from.
+// Config's values are returned for a BotType without any override, or for an empty BotType.
+func (w *watcher) source(botType sarah.BotType) *Source {
+	w.configMutex.RLock()
+	defer w.configMutex.RUnlock()
+
+	s := &Source{
+		Owner:        w.config.Owner,
+		Name:         w.config.Name,
+		BaseDir:      w.config.BaseDir,
+		Branch:       w.config.Branch,
+		PathTemplate: w.config.PathTemplate,
+	}
+
+	override, ok := w.config.Sources[botType]
+	if !ok || override == nil {
+		return s
+	}
+
+	if override.Owner != "" {
+		s.Owner = override.Owner
+	}
+	if override.Name != "" {
+		s.Name = override.Name
+	}
+	if override.BaseDir != "" {
+		s.BaseDir = override.BaseDir
+	}
+	if override.Branch != "" {
+		s.Branch = override.Branch
+	}
+	if override.PathTemplate != "" {
+		s.PathTemplate = override.PathTemplate
+	}
+
+	return s
+}
@@ -0,0 +1,224 @@
+package githubconfig
+
+import (
+	"context"
+	"github.com/oklahomer/go-sarah/v4"
+	"github.com/shurcooL/githubv4"
+	"gopkg.in/yaml.v3"
+	"strconv"
+	"testing"
+)
+
+func TestWatcher_source(t *testing.T) {
+	config := &Config{
+		Owner:   "oklahomer",
+		Name:    "config",
+		BaseDir: "config",
+		Branch:  "master",
+		Sources: map[sarah.BotType]*Source{
+			"custom": {
+				Owner:   "another",
+				Name:    "adapter",
+				BaseDir: "bots",
+			},
+			"staging": {
+				Branch: "staging",
+			},
+		},
+	}
+
+	tests := []struct {
+		botType  sarah.BotType
+		expected Source
+	}{
+		{
+			botType:  "slack",
+			expected: Source{Owner: "oklahomer", Name: "config", BaseDir: "config", Branch: "master"},
+		},
+		{
+			botType:  "custom",
+			expected: Source{Owner: "another", Name: "adapter", BaseDir: "bots", Branch: "master"},
+		},
+		{
+			botType:  "staging",
+			expected: Source{Owner: "oklahomer", Name: "config", BaseDir: "config", Branch: "staging"},
+		},
+		{
+			botType:  "",
+			expected: Source{Owner: "oklahomer", Name: "config", BaseDir: "config", Branch: "master"},
+		},
+	}
+
+	for i, tt := range tests {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			w := &watcher{config: config}
+			src := w.source(tt.botType)
+			if *src != tt.expected {
+				t.Errorf("Unexpected source is returned: %+v.", src)
+			}
+		})
+	}
+}
+
+func TestConfig_sources(t *testing.T) {
+	input := "owner: oklahomer\nname: config\nsources:\n  custom:\n    name: adapter\n    branch: main\n"
+	config := &Config{}
+	err := yaml.Unmarshal([]byte(input), config)
+	if err != nil {
+		t.Fatalf("Unexpected error is returned: %s.", err.Error())
+	}
+
+	src, ok := config.Sources["custom"]
+	if !ok {
+		t.Fatal("Expected source is not decoded.")
+	}
+
+	if src.Name != "adapter" || src.Branch != "main" {
+		t.Errorf("Unexpected source is decoded: %+v.", src)
+	}
+}
+
+func TestWatcher_get_withSource(t *testing.T) {
+	w := &watcher{
+		client: &DummyQuerier{
+			QueryFunc: func(_ context.Context, q interface{}, v map[string]interface{}) error {
+				if v["owner"] != githubv4.String("another") || v["name"] != githubv4.String("adapter") {
+					t.Errorf("Unexpected repository is given: %s/%s.", v["owner"], v["name"])
+				}
+
+				if v["expression"] != githubv4.String("main:bots/custom") {
+					t.Errorf("Unexpected expression is given: %s.", v["expression"])
+				}
+
+				typed := q.(*query)
+				typed.Repository.Head.Commit.Oid = "sha"
+				typed.Repository.Object.Tree.Entries = []entry{
+					{
+						Name:   "hello.yml",
+						Object: entryObject{Blob: blob{Oid: "oid", Text: "name: hello\n"}},
+					},
+				}
+				return nil
+			},
+		},
+		config: &Config{
+			Owner:   "oklahomer",
+			Name:    "config",
+			BaseDir: "config",
+			Branch:  "master",
+			Sources: map[sarah.BotType]*Source{
+				"custom": {
+					Owner:   "another",
+					Name:    "adapter",
+					BaseDir: "bots",
+					Branch:  "main",
+				},
+			},
+		},
+	}
+
+	files, err := w.get(context.TODO(), "custom")
+	if err != nil {
+		t.Fatalf("Unexpected error is returned: %s.", err.Error())
+	}
+
+	f, ok := files["hello"]
+	if !ok {
+		t.Fatal("Expected file is not returned.")
+	}
+
+	if f.path != "bots/custom/hello.yml" {
+		t.Errorf("Unexpected path is set: %s.", f.path)
+	}
+}
+
+func TestWatcher_branches(t *testing.T) {
+	tests := []struct {
+		config   *Config
+		expected []string
+	}{
+		{
+			config:   &Config{Branch: "main"},
+			expected: []string{"main"},
+		},
+		{
+			config:   &Config{Branch: "main", Branches: []string{"feature", "staging"}},
+			expected: []string{"feature", "staging", "main"},
+		},
+		{
+			config:   &Config{Branch: "main", Branches: []string{"feature", "main"}},
+			expected: []string{"feature", "main"},
+		},
+		{
+			config:   &Config{Branch: "main", Environment: "staging", EnvironmentBranch: "env/{environment}"},
+			expected: []string{"env/staging", "main"},
+		},
+		{
+			config:   &Config{Branch: "main", EnvironmentBranch: "env/{environment}"},
+			expected: []string{"main"},
+		},
+	}
+
+	for i, tt := range tests {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			w := &watcher{config: tt.config}
+			branches := w.branches("bot")
+			if len(branches) != len(tt.expected) {
+				t.Fatalf("Unexpected branches are returned: %v.", branches)
+			}
+			for i := range branches {
+				if branches[i] != tt.expected[i] {
+					t.Errorf("Unexpected branches are returned: %v.", branches)
+				}
+			}
+		})
+	}
+}
+
+func TestWatcher_get_branches(t *testing.T) {
+	w := &watcher{
+		client: &DummyQuerier{
+			QueryFunc: func(_ context.Context, q interface{}, v map[string]interface{}) error {
+				typed := q.(*query)
+				switch v["ref"] {
+				case githubv4.String("feature"):
+					typed.Repository.Head.Commit.Oid = "feature"
+					typed.Repository.Object.Tree.Entries = []entry{
+						{Name: "hello.yml", Object: entryObject{Blob: blob{Oid: "preview", Text: "name: preview\n"}}},
+					}
+
+				case githubv4.String("main"):
+					typed.Repository.Head.Commit.Oid = "main"
+					typed.Repository.Object.Tree.Entries = []entry{
+						{Name: "hello.yml", Object: entryObject{Blob: blob{Oid: "hello", Text: "name: hello\n"}}},
+						{Name: "guess.yml", Object: entryObject{Blob: blob{Oid: "guess", Text: "name: guess\n"}}},
+					}
+
+				default:
+					// Deleted branch
+					return nil
+
+				}
+				return nil
+			},
+		},
+		config: &Config{BaseDir: "config", Branch: "main", Branches: []string{"deleted", "feature"}},
+	}
+
+	files, err := w.get(context.TODO(), "bot")
+	if err != nil {
+		t.Fatalf("Unexpected error is returned: %s.", err.Error())
+	}
+
+	if len(files) != 2 {
+		t.Fatalf("Unexpected files are returned: %+v.", files)
+	}
+
+	if f := files["hello"]; f.objectID != "preview" || f.branch != "feature" {
+		t.Errorf("File on the earlier branch must win: %+v.", f)
+	}
+
+	if f := files["guess"]; f.objectID != "guess" || f.branch != "main" {
+		t.Errorf("File must fall back to the later branch: %+v.", f)
+	}
+}
@@ -0,0 +1,146 @@
+package githubconfig
+
+import (
+	"context"
+	"github.com/oklahomer/go-sarah/v4"
+	"time"
+)
+
+// Status describes the current state of the watcher.
+type Status struct {
+	// Branch is the branch configuration files are fetched from.
+	Branch string
+	// Paused tells if applying configuration changes is paused.
+	Paused bool
+	// BotTypes holds the state of each BotType that is read or subscribed.
+	BotTypes map[sarah.BotType]*BotTypeStatus
+}
+
+// BotTypeStatus describes the current state of a BotType.
+type BotTypeStatus struct {
+	// LastFetchedAt is the time of the last successful fetch. This is zero when no fetch has succeeded yet.
+	LastFetchedAt time.Time
+	// LastError is the error of the last failed fetch. This is nil when the last fetch succeeded.
+	LastError error
+	// LastErrorAt is the time of the last failed fetch.
+	LastErrorAt time.Time
+	// CommitSHA is the SHA of the commit the cached configuration files are fetched from.
+	CommitSHA string
+	// CachedFiles is the number of the cached configuration files.
+	CachedFiles int
+	// Subscriptions is the number of the active subscriptions.
+	Subscriptions int
+	// Files holds the metadata of the cached configuration files by id.
+	Files map[string]*FileInfo
+}
+
+type statusRequest struct {
+	result chan<- *Status
+}
+
+// statuses holds the fetch results by BotType. This is only accessed by the operating goroutine.
+type statuses map[sarah.BotType]*BotTypeStatus
+
+// record records the result of a fetch.
+func (s statuses) record(botType sarah.BotType, files map[string]*file, err error) {
+	status, ok := s[botType]
+	if !ok {
+		status = &BotTypeStatus{}
+		s[botType] = status
+	}
+
+	if err != nil {
+		status.LastError = err
+		status.LastErrorAt = time.Now()
+		return
+	}
+
+	status.LastError = nil
+	status.LastFetchedAt = time.Now()
+	status.CommitSHA = ""
+	for _, f := range files {
+		if f.commitSHA != "" {
+			status.CommitSHA = f.commitSHA
+			break
+		}
+	}
+}
+
+// build returns a copy of the current state so the caller can read it without synchronization.
+func (s statuses) build(branch string, paused bool, cache map[sarah.BotType]map[string]*file, subscribed subscriptions) *Status {
+	status := &Status{
+		Branch:   branch,
+		Paused:   paused,
+		BotTypes: map[sarah.BotType]*BotTypeStatus{},
+	}
+
+	for botType, recorded := range s {
+		copied := *recorded
+		status.BotTypes[botType] = &copied
+	}
+
+	for botType, sub := range subscribed {
+		botTypeStatus, ok := status.BotTypes[botType]
+		if !ok {
+			botTypeStatus = &BotTypeStatus{}
+			status.BotTypes[botType] = botTypeStatus
+		}
+		for _, subs := range sub {
+			botTypeStatus.Subscriptions += len(subs)
+		}
+	}
+
+	for botType, files := range cache {
+		if botTypeStatus, ok := status.BotTypes[botType]; ok {
+			botTypeStatus.CachedFiles = len(files)
+			botTypeStatus.Files = map[string]*FileInfo{}
+			for id, f := range files {
+				botTypeStatus.Files[id] = newFileInfo(f)
+			}
+		}
+	}
+
+	return status
+}
+
+func (w *watcher) Status(ctx context.Context) (*Status, error) {
+	result := make(chan *Status, 1)
+	req := &statusRequest{
+		result: result,
+	}
+
+	err := w.running()
+	if err != nil {
+		return nil, err
+	}
+
+	timer := time.NewTimer(w.config.TimeOut)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return nil, &CanceledError{Err: ctx.Err()}
+
+	case <-timer.C:
+		return nil, SubscriptionTimeout
+
+	case <-w.done:
+		return nil, WatcherStopped
+
+	case w.status <- req:
+		// Successfully enqueued
+
+	}
+
+	select {
+	case <-ctx.Done():
+		return nil, &CanceledError{Err: ctx.Err()}
+
+	case <-timer.C:
+		return nil, SubscriptionTimeout
+
+	case s := <-result:
+		return s, nil
+
+	}
+}
@@ -0,0 +1,150 @@
+package githubconfig
+
+import (
+	"context"
+	"errors"
+	"github.com/oklahomer/go-sarah/v4"
+	"github.com/shurcooL/githubv4"
+	"net/url"
+	"testing"
+	"time"
+)
+
+func TestStatuses_record(t *testing.T) {
+	s := statuses{}
+
+	s.record("bot", map[string]*file{"hello": {commitSHA: "sha"}}, nil)
+	status := s["bot"]
+	if status.LastFetchedAt.IsZero() || status.CommitSHA != "sha" || status.LastError != nil {
+		t.Errorf("Unexpected status is recorded: %+v.", status)
+	}
+
+	err := errors.New("API error")
+	s.record("bot", nil, err)
+	if status.LastError != err || status.LastErrorAt.IsZero() {
+		t.Errorf("Error is not recorded: %+v.", status)
+	}
+	if status.CommitSHA != "sha" {
+		t.Errorf("Previous commit must remain: %s.", status.CommitSHA)
+	}
+
+	s.record("bot", map[string]*file{"hello": {commitSHA: "new"}}, nil)
+	if status.LastError != nil || status.CommitSHA != "new" {
+		t.Errorf("Unexpected status is recorded: %+v.", status)
+	}
+}
+
+func TestStatuses_build(t *testing.T) {
+	s := statuses{
+		"bot": &BotTypeStatus{CommitSHA: "sha"},
+	}
+	cache := map[sarah.BotType]map[string]*file{
+		"bot": {"hello": {}, "bye": {}},
+	}
+	subscribed := subscriptions{}
+	subscribed.add(&subscription{botType: "bot", id: "hello"})
+	subscribed.add(&subscription{botType: "bot", id: "hello"})
+	subscribed.add(&subscription{botType: "other", id: allIDs})
+
+	status := s.build("master", true, cache, subscribed)
+
+	if status.Branch != "master" || !status.Paused {
+		t.Errorf("Unexpected status is built: %+v.", status)
+	}
+
+	bot := status.BotTypes["bot"]
+	if bot.CommitSHA != "sha" || bot.CachedFiles != 2 || bot.Subscriptions != 2 {
+		t.Errorf("Unexpected status is built: %+v.", bot)
+	}
+
+	other := status.BotTypes["other"]
+	if other == nil || other.Subscriptions != 1 {
+		t.Errorf("Unexpected status is built: %+v.", other)
+	}
+
+	// The returned status must be a copy.
+	bot.CommitSHA = "modified"
+	if s["bot"].CommitSHA != "sha" {
+		t.Error("Recorded status is modified.")
+	}
+}
+
+func TestWatcher_Status(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	w := &watcher{
+		client: &DummyQuerier{
+			QueryFunc: func(_ context.Context, q interface{}, _ map[string]interface{}) error {
+				typed := q.(*query)
+				typed.Repository.URL = githubv4.URI{URL: &url.URL{Scheme: "https", Host: "github.com", Path: "/oklahomer/config"}}
+				typed.Repository.Head.Commit.Oid = "sha"
+				typed.Repository.Object.Tree.Entries = []entry{
+					{Name: "hello.json", Object: entryObject{Blob: blob{Oid: "oid", Text: "{}"}}},
+				}
+				return nil
+			},
+		},
+		config: &Config{
+			BaseDir:  "config",
+			Branch:   "master",
+			TimeOut:  100 * time.Millisecond,
+			Interval: time.Hour,
+		},
+		request: make(chan *request),
+		status:  make(chan *statusRequest),
+	}
+	w.started.Store(true)
+	go w.operate(ctx)
+
+	err := w.Read(ctx, "bot", "hello", &struct{}{})
+	if err != nil {
+		t.Fatalf("Unexpected error is returned: %s.", err.Error())
+	}
+
+	status, err := w.Status(ctx)
+	if err != nil {
+		t.Fatalf("Unexpected error is returned: %s.", err.Error())
+	}
+
+	bot, ok := status.BotTypes["bot"]
+	if !ok {
+		t.Fatalf("Status for BotType is not returned: %+v.", status)
+	}
+
+	if bot.CommitSHA != "sha" || bot.CachedFiles != 1 || bot.LastFetchedAt.IsZero() {
+		t.Errorf("Unexpected status is returned: %+v.", bot)
+	}
+
+	hello, ok := bot.Files["hello"]
+	if !ok {
+		t.Fatalf("Metadata of the file is not returned: %+v.", bot.Files)
+	}
+
+	if hello.ObjectID != "oid" || hello.Path != "config/bot/hello.json" || hello.URL != "https://github.com/oklahomer/config/blob/sha/config/bot/hello.json" {
+		t.Errorf("Unexpected metadata is returned: %+v.", hello)
+	}
+}
+
+func TestWatcher_Status_withCanceledContext(t *testing.T) {
+	w := &watcher{
+		config: &Config{
+			TimeOut: time.Second,
+		},
+		status: make(chan *statusRequest),
+	}
+	w.started.Store(true)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err := w.Status(ctx)
+
+	var canceled *CanceledError
+	if !errors.As(err, &canceled) {
+		t.Fatalf("Expected error is not returned: %#v.", err)
+	}
+
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("Cause is not wrapped: %#v.", err)
+	}
+}
@@ -0,0 +1,69 @@
+package githubconfig
+
+import (
+	"context"
+	"fmt"
+	"github.com/shurcooL/githubv4"
+	"path"
+	"strings"
+)
+
+// WithSubmodules descends into the Git submodules under a BotType's directory instead of skipping them.
+// A submodule is read as of the commit the parent repository records, and its files are identified in the same way as
+// the files in a subdirectory. The credential must be permitted to read the submodule's repository on the same GitHub host;
+// otherwise, the submodule is skipped with a log.
+func WithSubmodules() Option {
+	return func(w *watcher) {
+		w.submodules = true
+	}
+}
+
+type submodule struct {
+	GitURL              githubv4.String `graphql:"gitUrl"`
+	SubprojectCommitOid githubv4.String
+}
+
+// walkSubmodule returns the file entries in the given submodule located at the name relative to the BotType's directory.
+func (w *watcher) walkSubmodule(ctx context.Context, tw *treeWalk, loc treeLocation, name string, s submodule, depth int) ([]entry, error) {
+	owner, repo, err := parseRepository(string(s.GitURL), loc.owner)
+	if err != nil {
+		if tw.verbose {
+			w.log(tw.botType, "").Warnf("Skipping submodule %s: %+v", name, err)
+		}
+		return nil, nil
+	}
+
+	sub := treeLocation{
+		owner:  owner,
+		name:   repo,
+		ref:    string(s.SubprojectCommitOid),
+		prefix: name,
+	}
+	nodes, err := w.tree(ctx, tw, sub, "")
+	if err != nil {
+		if tw.verbose {
+			w.log(tw.botType, "").Warnf("Skipping submodule %s: %+v", name, err)
+		}
+		return nil, nil
+	}
+
+	return w.walk(ctx, tw, sub, "", nodes, depth+1)
+}
+
+// parseRepository returns the owner and the name of the repository from the URL of a submodule such as
+// "https://github.com/oklahomer/config.git", "git@github.com:oklahomer/config.git" or "../config.git".
+// A relative URL refers to a repository of the given owner.
+func parseRepository(url string, owner string) (string, string, error) {
+	trimmed := strings.TrimSuffix(strings.TrimSuffix(url, "/"), ".git")
+	if strings.HasPrefix(trimmed, "../") {
+		return owner, path.Base(trimmed), nil
+	}
+
+	segments := strings.FieldsFunc(trimmed, func(r rune) bool {
+		return r == '/' || r == ':'
+	})
+	if len(segments) < 3 {
+		return "", "", fmt.Errorf("unsupported submodule URL: %s", url)
+	}
+	return segments[len(segments)-2], segments[len(segments)-1], nil
+}
@@ -0,0 +1,108 @@
+package githubconfig
+
+import (
+	"context"
+	"github.com/shurcooL/githubv4"
+	"strconv"
+	"testing"
+)
+
+func TestParseRepository(t *testing.T) {
+	tests := []struct {
+		url    string
+		owner  string
+		name   string
+		hasErr bool
+	}{
+		{url: "https://github.com/oklahomer/shared-config.git", owner: "oklahomer", name: "shared-config"},
+		{url: "https://github.com/oklahomer/shared-config", owner: "oklahomer", name: "shared-config"},
+		{url: "git@github.com:oklahomer/shared-config.git", owner: "oklahomer", name: "shared-config"},
+		{url: "../shared-config.git", owner: "parent", name: "shared-config"},
+		{url: "shared-config", hasErr: true},
+	}
+
+	for i, tt := range tests {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			owner, name, err := parseRepository(tt.url, "parent")
+			if tt.hasErr {
+				if err == nil {
+					t.Error("Expected error is not returned.")
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("Unexpected error is returned: %s.", err.Error())
+			}
+
+			if owner != tt.owner || name != tt.name {
+				t.Errorf("Unexpected repository is returned: %s/%s.", owner, name)
+			}
+		})
+	}
+}
+
+func TestWatcher_get_submodule(t *testing.T) {
+	newWatcher := func(t *testing.T) *watcher {
+		return &watcher{
+			client: &DummyQuerier{
+				QueryFunc: func(_ context.Context, q interface{}, v map[string]interface{}) error {
+					switch typed := q.(type) {
+					case *query:
+						typed.Repository.Head.Commit.Oid = "sha"
+						typed.Repository.Object.Tree.Entries = []entry{
+							{Name: "hello.yml", Type: "blob", Object: entryObject{Blob: blob{Oid: "hello", Text: "name: hello\n"}}},
+							{
+								Name: "shared",
+								Type: "commit",
+								Submodule: submodule{
+									GitURL:              "https://github.com/oklahomer/shared-config.git",
+									SubprojectCommitOid: "abc",
+								},
+							},
+						}
+
+					case *treeQuery:
+						if v["owner"] != githubv4.String("oklahomer") || v["name"] != githubv4.String("shared-config") {
+							t.Errorf("Unexpected repository is given: %s/%s.", v["owner"], v["name"])
+						}
+						if v["expression"] != githubv4.String("abc:") {
+							t.Errorf("Unexpected expression is given: %s.", v["expression"])
+						}
+						typed.Repository.Object.Tree.Entries = []entry{
+							{Name: "guess.yml", Type: "blob", Object: entryObject{Blob: blob{Oid: "guess", Text: "name: guess\n"}}},
+						}
+
+					}
+					return nil
+				},
+			},
+			config: &Config{Owner: "oklahomer", Name: "config", BaseDir: "config", Branch: "master"},
+		}
+	}
+
+	t.Run("skip", func(t *testing.T) {
+		w := newWatcher(t)
+		files, err := w.get(context.TODO(), "bot")
+		if err != nil {
+			t.Fatalf("Unexpected error is returned: %s.", err.Error())
+		}
+
+		if len(files) != 1 {
+			t.Errorf("Submodule must be skipped: %+v.", files)
+		}
+	})
+
+	t.Run("descend", func(t *testing.T) {
+		w := newWatcher(t)
+		WithSubmodules()(w)
+		files, err := w.get(context.TODO(), "bot")
+		if err != nil {
+			t.Fatalf("Unexpected error is returned: %s.", err.Error())
+		}
+
+		if _, ok := files["shared/guess"]; !ok {
+			t.Errorf("File in submodule is not returned: %+v.", files)
+		}
+	})
+}
@@ -0,0 +1,120 @@
+package githubconfig
+
+import (
+	"context"
+	"github.com/oklahomer/go-sarah/v4"
+)
+
+// allIDs is the pseudo id to subscribe to all configuration files of a BotType.
+const allIDs = "*"
+
+// Subscription represents an individual subscription made by Watcher.WatchContext.
+type Subscription struct {
+	watcher      *watcher
+	subscription *subscription
+}
+
+// Cancel stops the subscription.
+// This is safe to call multiple times, and the call returns immediately when the subscription is already ended.
+func (s *Subscription) Cancel() {
+	select {
+	case s.watcher.cancellation <- s.subscription:
+		// Successfully enqueued
+
+	case <-s.subscription.done:
+		// Already ended
+
+	case <-s.watcher.done:
+		// Ended along with the watcher
+
+	}
+}
+
+// Done returns a channel that is closed when the subscription ends.
+// A subscription ends when it is canceled, when Unwatch or UnwatchID is called for it, or when the watcher stops.
+func (s *Subscription) Done() <-chan struct{} {
+	return s.subscription.done
+}
+
+type subscription struct {
+	botType  sarah.BotType
+	id       string
+	callback func(context.Context, *Change)
+	// keys are the dot-separated keys to filter changes with. The callback is called on any change when this is empty.
+	keys []string
+	// withCommit tells if the subscriber needs the commit that made the change.
+	// The commit is fetched only when any of the subscribers needs it so plain subscriptions do not consume extra API calls.
+	withCommit bool
+	done       chan struct{}
+}
+
+func withCommit(subs []*subscription) bool {
+	for _, s := range subs {
+		if s.withCommit {
+			return true
+		}
+	}
+	return false
+}
+
+// subscriptions holds the active subscriptions by BotType and id.
+// Multiple subscriptions can be made for the same id since several components may care about the same configuration.
+// This is only accessed by the operating goroutine.
+type subscriptions map[sarah.BotType]map[string][]*subscription
+
+func (s subscriptions) add(sub *subscription) {
+	_, ok := s[sub.botType]
+	if !ok {
+		s[sub.botType] = map[string][]*subscription{}
+	}
+	s[sub.botType][sub.id] = append(s[sub.botType][sub.id], sub)
+}
+
+// remove removes all subscriptions for the given id.
+func (s subscriptions) remove(botType sarah.BotType, id string) {
+	for _, sub := range s[botType][id] {
+		sub.end()
+	}
+
+	delete(s[botType], id)
+	if len(s[botType]) == 0 {
+		delete(s, botType)
+	}
+}
+
+// cancel removes the given subscription while other subscriptions for the same id remain intact.
+func (s subscriptions) cancel(sub *subscription) {
+	subs := s[sub.botType][sub.id]
+	for i, registered := range subs {
+		if registered != sub {
+			continue
+		}
+
+		if len(subs) == 1 {
+			s.remove(sub.botType, sub.id)
+			return
+		}
+
+		sub.end()
+		s[sub.botType][sub.id] = append(subs[:i:i], subs[i+1:]...)
+		return
+	}
+}
+
+func (s subscriptions) removeBotType(botType sarah.BotType) {
+	for id := range s[botType] {
+		s.remove(botType, id)
+	}
+}
+
+func (s subscriptions) removeAll() {
+	for botType := range s {
+		s.removeBotType(botType)
+	}
+}
+
+func (s *subscription) end() {
+	if s.done != nil {
+		close(s.done)
+	}
+}
@@ -0,0 +1,202 @@
+package githubconfig
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestSubscription(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	w := &watcher{
+		config: &Config{
+			Interval: 10 * time.Second,
+		},
+		subscription:     make(chan *subscription),
+		idUnsubscription: make(chan *subscription),
+		cancellation:     make(chan *subscription),
+	}
+	w.started.Store(true)
+	go w.operate(ctx)
+
+	noop := func(_ context.Context, _ string) {}
+
+	t.Run("cancel", func(t *testing.T) {
+		sub, err := w.WatchContext(ctx, "bot", "cancel", noop)
+		if err != nil {
+			t.Fatalf("Unexpected error is returned: %s.", err.Error())
+		}
+
+		sub.Cancel()
+		assertDone(t, sub)
+
+		// Must not block
+		sub.Cancel()
+	})
+
+	t.Run("multiple", func(t *testing.T) {
+		first, _ := w.WatchContext(ctx, "bot", "multiple", noop)
+		second, _ := w.WatchContext(ctx, "bot", "multiple", noop)
+
+		// Canceling one subscription must not affect the other.
+		first.Cancel()
+		assertDone(t, first)
+		select {
+		case <-second.Done():
+			t.Error("The other subscription must remain active.")
+
+		case <-time.NewTimer(10 * time.Millisecond).C:
+			// O.K.
+
+		}
+
+		second.Cancel()
+		assertDone(t, second)
+	})
+
+	t.Run("unwatch id", func(t *testing.T) {
+		sub, _ := w.WatchContext(ctx, "bot", "unwatch", noop)
+
+		err := w.UnwatchID("bot", "unwatch")
+		if err != nil {
+			t.Fatalf("Unexpected error is returned: %s.", err.Error())
+		}
+
+		assertDone(t, sub)
+	})
+
+	t.Run("stop", func(t *testing.T) {
+		sub, _ := w.WatchContext(ctx, "bot", "stop", noop)
+
+		cancel()
+
+		assertDone(t, sub)
+	})
+}
+
+func assertDone(t *testing.T, sub *Subscription) {
+	t.Helper()
+
+	select {
+	case <-sub.Done():
+		// O.K.
+
+	case <-time.NewTimer(1 * time.Second).C:
+		t.Error("Subscription is not ended.")
+
+	}
+}
+
+func TestWatcher_WatchAll(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	entries := make(chan []entry, 2)
+	entries <- []entry{
+		{Name: "hello.json", Object: entryObject{Blob: blob{Oid: "hello", Text: "{}"}}},
+		{Name: "removed.json", Object: entryObject{Blob: blob{Oid: "removed", Text: "{}"}}},
+	}
+	entries <- []entry{
+		{Name: "hello.json", Object: entryObject{Blob: blob{Oid: "updated", Text: "{}"}}},
+		{Name: "added.json", Object: entryObject{Blob: blob{Oid: "added", Text: "{}"}}},
+	}
+	var current []entry
+	w := &watcher{
+		client: &DummyQuerier{
+			QueryFunc: func(_ context.Context, q interface{}, _ map[string]interface{}) error {
+				select {
+				case current = <-entries:
+				default:
+				}
+
+				q.(*query).Repository.Object.Tree.Entries = current
+				return nil
+			},
+		},
+		config: &Config{
+			TimeOut:  100 * time.Millisecond,
+			Interval: 10 * time.Millisecond,
+		},
+		request:      make(chan *request),
+		subscription: make(chan *subscription),
+	}
+	w.started.Store(true)
+	go w.operate(ctx)
+
+	err := w.Read(ctx, "bot", "hello", &struct{}{})
+	if err != nil {
+		t.Fatalf("Unexpected error is returned: %s.", err.Error())
+	}
+
+	called := make(chan string, 10)
+	_, err = w.WatchAll(ctx, "bot", func(_ context.Context, id string) {
+		called <- id
+	})
+	if err != nil {
+		t.Fatalf("Unexpected error is returned: %s.", err.Error())
+	}
+
+	ids := map[string]bool{}
+	timeout := time.NewTimer(1 * time.Second)
+	for len(ids) < 3 {
+		select {
+		case id := <-called:
+			ids[id] = true
+
+		case <-timeout.C:
+			t.Fatalf("Callback is not called for all changed files: %+v.", ids)
+
+		}
+	}
+
+	for _, id := range []string{"hello", "added", "removed"} {
+		if !ids[id] {
+			t.Errorf("Callback is not called for %s.", id)
+		}
+	}
+}
+
+func TestSubscriptions(t *testing.T) {
+	s := subscriptions{}
+	first := &subscription{botType: "bot", id: "hello", done: make(chan struct{})}
+	second := &subscription{botType: "bot", id: "hello", done: make(chan struct{})}
+	other := &subscription{botType: "bot", id: "other", done: make(chan struct{})}
+	s.add(first)
+	s.add(second)
+	s.add(other)
+
+	if len(s["bot"]["hello"]) != 2 {
+		t.Fatalf("Both subscriptions must be registered: %d.", len(s["bot"]["hello"]))
+	}
+
+	s.cancel(first)
+	if len(s["bot"]["hello"]) != 1 || s["bot"]["hello"][0] != second {
+		t.Errorf("Only the canceled subscription must be removed: %+v.", s["bot"]["hello"])
+	}
+
+	s.remove("bot", "hello")
+	if _, ok := s["bot"]["hello"]; ok {
+		t.Error("Subscriptions must be removed.")
+	}
+	if _, ok := s["bot"]["other"]; !ok {
+		t.Error("Subscription for other id must remain.")
+	}
+
+	s.removeAll()
+	if len(s) != 0 {
+		t.Errorf("All subscriptions must be removed: %+v.", s)
+	}
+
+	for _, sub := range []*subscription{first, second, other} {
+		select {
+		case <-sub.done:
+			// O.K.
+
+		default:
+			t.Errorf("Subscription is not ended: %s.", sub.id)
+
+		}
+	}
+}
@@ -0,0 +1,139 @@
+package githubconfig
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/oklahomer/go-sarah/v4"
+	"github.com/shurcooL/githubv4"
+)
+
+// TagVerifier verifies the signature of an annotated Git tag.
+// The payload is the raw tag object without the signature, and the signature is ASCII-armored,
+// so an implementation can check them against a GPG keyring with openpgp.CheckArmoredDetachedSignature.
+type TagVerifier interface {
+	VerifyTag(ctx context.Context, payload []byte, signature []byte) error
+}
+
+// WithTagVerifier verifies the signature of the tag with the given TagVerifier before serving its contents
+// when the watcher tracks a tag instead of a branch, so production bots are protected from unsigned or forged release tags.
+// The contents are then read as of the commit the verified tag points to.
+func WithTagVerifier(verifier TagVerifier) Option {
+	return func(w *watcher) {
+		w.tagVerifier = verifier
+	}
+}
+
+// TagVerificationError is returned when the tracked tag is not signed or its signature is not trusted.
+type TagVerificationError struct {
+	// Repository is the queried repository in the form of "owner/name".
+	Repository string
+	Tag        string
+	Err        error
+}
+
+// Error returns stringified representation of the error.
+func (err *TagVerificationError) Error() string {
+	return fmt.Sprintf("failed to verify tag %s on %s: %s", err.Tag, err.Repository, err.Err.Error())
+}
+
+// Unwrap returns the underlying error.
+func (err *TagVerificationError) Unwrap() error {
+	return err.Err
+}
+
+var _ error = (*TagVerificationError)(nil)
+
+// tagQuery represents a Graphql query to fetch a tag and its signature.
+// Formatted query is as below:
+//
+// 	query ($owner: String!, $name: String!, $tag: String!) {
+//    repository(owner: $owner, name: $name) {
+//      ref(qualifiedName: $tag) {
+//        target {
+//          __typename
+//          ... on Tag {
+//            target {
+//              oid
+//            }
+//            signature {
+//              payload
+//              signature
+//            }
+//          }
+//        }
+//      }
+//    }
+// 	}
+type tagQuery struct {
+	Repository struct {
+		Ref *tagRef `graphql:"ref(qualifiedName: $tag)"`
+	} `graphql:"repository(owner: $owner, name: $name)"`
+}
+
+type tagRef struct {
+	Target struct {
+		Typename githubv4.String `graphql:"__typename"`
+		Tag      struct {
+			Target struct {
+				Oid githubv4.String
+			}
+			Signature *tagSignature
+		} `graphql:"... on Tag"`
+	}
+}
+
+type tagSignature struct {
+	Payload   githubv4.String
+	Signature githubv4.String
+}
+
+// verifiedRef returns the commit that the given ref points to when it is a tag with a trusted signature,
+// or the ref as is when it is not a tag or no TagVerifier is given.
+func (w *watcher) verifiedRef(ctx context.Context, botType sarah.BotType, ref string) (string, error) {
+	if w.tagVerifier == nil {
+		return ref, nil
+	}
+
+	q := &tagQuery{}
+	src := w.source(botType)
+	variables := map[string]interface{}{
+		"owner": githubv4.String(src.Owner),
+		"name":  githubv4.String(src.Name),
+		"tag":   githubv4.String("refs/tags/" + ref),
+	}
+	err := w.client.Query(ctx, q, variables)
+	if err != nil {
+		return "", w.queryError(botType, repositoryName(src.Owner, src.Name), err)
+	}
+
+	if q.Repository.Ref == nil {
+		// Not a tag
+		return ref, nil
+	}
+
+	verificationError := func(err error) error {
+		return &TagVerificationError{
+			Repository: w.repository(botType),
+			Tag:        ref,
+			Err:        err,
+		}
+	}
+
+	target := q.Repository.Ref.Target
+	if target.Typename != "Tag" {
+		return "", verificationError(errors.New("lightweight tag cannot be signed"))
+	}
+
+	signature := target.Tag.Signature
+	if signature == nil {
+		return "", verificationError(errors.New("tag is not signed"))
+	}
+
+	err = w.tagVerifier.VerifyTag(ctx, []byte(signature.Payload), []byte(signature.Signature))
+	if err != nil {
+		return "", verificationError(err)
+	}
+
+	return string(target.Tag.Target.Oid), nil
+}
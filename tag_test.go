@@ -0,0 +1,143 @@
+package githubconfig
+
+import (
+	"context"
+	"errors"
+	"github.com/shurcooL/githubv4"
+	"strconv"
+	"testing"
+)
+
+type DummyTagVerifier struct {
+	VerifyTagFunc func(ctx context.Context, payload []byte, signature []byte) error
+}
+
+func (v *DummyTagVerifier) VerifyTag(ctx context.Context, payload []byte, signature []byte) error {
+	return v.VerifyTagFunc(ctx, payload, signature)
+}
+
+func TestWithTagVerifier(t *testing.T) {
+	verifier := &DummyTagVerifier{}
+	w := &watcher{}
+	WithTagVerifier(verifier)(w)
+
+	if w.tagVerifier != verifier {
+		t.Error("Verifier is not set.")
+	}
+}
+
+func TestWatcher_verifiedRef(t *testing.T) {
+	signed := func() *tagRef {
+		ref := &tagRef{}
+		ref.Target.Typename = "Tag"
+		ref.Target.Tag.Target.Oid = "commit"
+		ref.Target.Tag.Signature = &tagSignature{Payload: "payload", Signature: "signature"}
+		return ref
+	}
+	lightweight := &tagRef{}
+	lightweight.Target.Typename = "Commit"
+	unsigned := signed()
+	unsigned.Target.Tag.Signature = nil
+
+	forged := errors.New("forged")
+	tests := []struct {
+		ref      *tagRef
+		verified error
+		expected string
+		err      bool
+	}{
+		{ref: nil, expected: "master"},
+		{ref: signed(), expected: "commit"},
+		{ref: signed(), verified: forged, err: true},
+		{ref: unsigned, err: true},
+		{ref: lightweight, err: true},
+	}
+
+	for i, tt := range tests {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			w := &watcher{
+				client: &DummyQuerier{
+					QueryFunc: func(_ context.Context, q interface{}, v map[string]interface{}) error {
+						if v["tag"] != githubv4.String("refs/tags/master") {
+							t.Errorf("Unexpected tag is given: %s.", v["tag"])
+						}
+						q.(*tagQuery).Repository.Ref = tt.ref
+						return nil
+					},
+				},
+				config: &Config{Owner: "oklahomer", Name: "config"},
+				tagVerifier: &DummyTagVerifier{
+					VerifyTagFunc: func(_ context.Context, payload []byte, signature []byte) error {
+						if string(payload) != "payload" || string(signature) != "signature" {
+							t.Errorf("Unexpected signature is given: %s, %s.", string(payload), string(signature))
+						}
+						return tt.verified
+					},
+				},
+			}
+
+			ref, err := w.verifiedRef(context.TODO(), "bot", "master")
+			if tt.err {
+				var verificationErr *TagVerificationError
+				if !errors.As(err, &verificationErr) {
+					t.Errorf("Expected error is not returned: %#v.", err)
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("Unexpected error is returned: %s.", err.Error())
+			}
+
+			if ref != tt.expected {
+				t.Errorf("Unexpected ref is returned: %s.", ref)
+			}
+		})
+	}
+}
+
+func TestWatcher_get_withTagVerifier(t *testing.T) {
+	w := &watcher{
+		client: &DummyQuerier{
+			QueryFunc: func(_ context.Context, q interface{}, v map[string]interface{}) error {
+				switch typed := q.(type) {
+				case *tagQuery:
+					ref := &tagRef{}
+					ref.Target.Typename = "Tag"
+					ref.Target.Tag.Target.Oid = "commit"
+					ref.Target.Tag.Signature = &tagSignature{Payload: "payload", Signature: "signature"}
+					typed.Repository.Ref = ref
+
+				case *query:
+					if v["expression"] != githubv4.String("commit:config/bot") {
+						t.Errorf("Unexpected expression is given: %s.", v["expression"])
+					}
+					typed.Repository.Head.Commit.Oid = "commit"
+					typed.Repository.Object.Tree.Entries = []entry{
+						{Name: "hello.yml", Object: entryObject{Blob: blob{Oid: "oid", Text: "name: hello\n"}}},
+					}
+
+				}
+				return nil
+			},
+		},
+		config: &Config{
+			BaseDir: "config",
+			Branch:  "v1.0.0",
+		},
+		tagVerifier: &DummyTagVerifier{
+			VerifyTagFunc: func(_ context.Context, _ []byte, _ []byte) error {
+				return nil
+			},
+		},
+	}
+
+	files, err := w.get(context.TODO(), "bot")
+	if err != nil {
+		t.Fatalf("Unexpected error is returned: %s.", err.Error())
+	}
+
+	if files["hello"] == nil || files["hello"].branch != "v1.0.0" {
+		t.Errorf("Unexpected files are returned: %+v.", files)
+	}
+}
@@ -0,0 +1,97 @@
+package githubconfig
+
+import (
+	"fmt"
+	"github.com/oklahomer/go-sarah/v4"
+	"path"
+	"regexp"
+	"strings"
+)
+
+// placeholderPattern matches a placeholder in a path template such as {botType}.
+var placeholderPattern = regexp.MustCompile(`\{[^{}]*}`)
+
+// placeholders are the supported placeholders in a path template.
+var placeholders = []string{"{baseDir}", "{botType}", "{environment}"}
+
+// validatePathTemplate returns an error when the given path template contains an unsupported placeholder.
+func validatePathTemplate(template string) error {
+	for _, p := range placeholderPattern.FindAllString(template, -1) {
+		supported := false
+		for _, s := range placeholders {
+			if p == s {
+				supported = true
+				break
+			}
+		}
+		if !supported {
+			return fmt.Errorf("unsupported placeholder %s in path template %s", p, template)
+		}
+	}
+	return nil
+}
+
+// WithPathResolver sets a function that returns the directory containing the given BotType's configuration files
+// relative to the repository root. This gives full control over the layouts too irregular for a path template,
+// and takes precedence over Config.PathTemplate and Config.BaseDir.
+func WithPathResolver(resolver func(botType sarah.BotType) string) Option {
+	return func(w *watcher) {
+		w.pathResolver = resolver
+	}
+}
+
+// WithFlatLayout looks up the configuration files directly under the base directory regardless of the BotType,
+// so a small single-bot deployment can keep its files such as config/hello.yml in one directory.
+// The manifest, the watcher's own settings file and the schemas directory are not treated as configuration files.
+func WithFlatLayout() Option {
+	return func(w *watcher) {
+		w.flatLayout = true
+	}
+}
+
+// reserved tells if the given entry under the base directory is not a configuration file in the flat layout.
+func reserved(name string) bool {
+	return name == manifestFileName || name == selfConfigFileName || name == schemasDir
+}
+
+// directory returns the directory that contains the given BotType's configuration files relative to the repository root.
+// The directory is {baseDir}/{botType} unless a path resolver, the flat layout or a path template is configured.
+func (w *watcher) directory(botType sarah.BotType) string {
+	if w.pathResolver != nil {
+		return strings.TrimPrefix(path.Clean(w.pathResolver(botType)), "/")
+	}
+
+	src := w.source(botType)
+	if w.flatLayout {
+		return src.BaseDir
+	}
+
+	if src.PathTemplate == "" {
+		return path.Join(src.BaseDir, w.config.Environment, botType.String())
+	}
+
+	environment := w.config.Environment
+	if environment == "" {
+		environment = w.environment
+	}
+	dir := strings.NewReplacer(
+		"{baseDir}", src.BaseDir,
+		"{botType}", botType.String(),
+		"{environment}", environment,
+	).Replace(src.PathTemplate)
+	return strings.TrimPrefix(path.Clean(dir), "/")
+}
+
+// fallbackDirectory returns the directory to look up the configuration files missing in the environment's directory.
+// This is available only with the default layout since a custom layout explicitly declares where the files are.
+func (w *watcher) fallbackDirectory(botType sarah.BotType) (string, bool) {
+	if w.config.Environment == "" || w.pathResolver != nil || w.flatLayout {
+		return "", false
+	}
+
+	src := w.source(botType)
+	if src.PathTemplate != "" {
+		return "", false
+	}
+	return path.Join(src.BaseDir, botType.String()), true
+}
@@ -0,0 +1,257 @@
+package githubconfig
+
+import (
+	"context"
+	"github.com/oklahomer/go-sarah/v4"
+	"github.com/shurcooL/githubv4"
+	"strconv"
+	"testing"
+)
+
+func TestValidatePathTemplate(t *testing.T) {
+	tests := []struct {
+		template string
+		hasErr   bool
+	}{
+		{template: "", hasErr: false},
+		{template: "configs/{botType}/{environment}", hasErr: false},
+		{template: "{baseDir}/bots/{botType}", hasErr: false},
+		{template: "configs/{bot}", hasErr: true},
+	}
+
+	for i, tt := range tests {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			err := validatePathTemplate(tt.template)
+			if tt.hasErr && err == nil {
+				t.Error("Expected error is not returned.")
+			}
+
+			if !tt.hasErr && err != nil {
+				t.Errorf("Unexpected error is returned: %s.", err.Error())
+			}
+		})
+	}
+}
+
+func TestWatcher_directory(t *testing.T) {
+	tests := []struct {
+		config      *Config
+		environment string
+		expected    string
+	}{
+		{
+			config:   &Config{BaseDir: "config"},
+			expected: "config/slack",
+		},
+		{
+			config:   &Config{BaseDir: ""},
+			expected: "slack",
+		},
+		{
+			config:      &Config{BaseDir: "config", PathTemplate: "configs/{botType}/{environment}"},
+			environment: "production",
+			expected:    "configs/slack/production",
+		},
+		{
+			config:   &Config{BaseDir: "config", PathTemplate: "/{baseDir}/{botType}/{environment}"},
+			expected: "config/slack",
+		},
+		{
+			config: &Config{
+				BaseDir:      "config",
+				PathTemplate: "configs/{botType}",
+				Sources: map[sarah.BotType]*Source{
+					"slack": {PathTemplate: "{baseDir}/bots/{botType}"},
+				},
+			},
+			expected: "config/bots/slack",
+		},
+	}
+
+	for i, tt := range tests {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			w := &watcher{
+				config:      tt.config,
+				environment: tt.environment,
+			}
+
+			dir := w.directory("slack")
+			if dir != tt.expected {
+				t.Errorf("Unexpected directory is returned: %s.", dir)
+			}
+		})
+	}
+}
+
+func TestNew_invalidPathTemplate(t *testing.T) {
+	config := &Config{PathTemplate: "configs/{unknown}"}
+	_, err := New(config, func(w *watcher) {
+		w.client = &DummyQuerier{}
+	})
+	if err == nil {
+		t.Error("Expected error is not returned.")
+	}
+}
+
+func TestWithPathResolver(t *testing.T) {
+	w := &watcher{config: &Config{BaseDir: "config", PathTemplate: "configs/{botType}"}}
+	WithPathResolver(func(botType sarah.BotType) string {
+		if botType == "slack" {
+			return "/legacy/chat/"
+		}
+		return "bots/" + botType.String()
+	})(w)
+
+	tests := []struct {
+		botType  sarah.BotType
+		expected string
+	}{
+		{botType: "slack", expected: "legacy/chat"},
+		{botType: "custom", expected: "bots/custom"},
+	}
+
+	for i, tt := range tests {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			dir := w.directory(tt.botType)
+			if dir != tt.expected {
+				t.Errorf("Unexpected directory is returned: %s.", dir)
+			}
+		})
+	}
+}
+
+func TestWithFlatLayout(t *testing.T) {
+	w := &watcher{config: &Config{BaseDir: "config", PathTemplate: "configs/{botType}"}}
+	WithFlatLayout()(w)
+
+	dir := w.directory("slack")
+	if dir != "config" {
+		t.Errorf("Unexpected directory is returned: %s.", dir)
+	}
+}
+
+func TestWatcher_get_flatLayout(t *testing.T) {
+	w := &watcher{
+		client: &DummyQuerier{
+			QueryFunc: func(_ context.Context, q interface{}, v map[string]interface{}) error {
+				if v["expression"] != githubv4.String("master:config") {
+					t.Errorf("Unexpected expression is given: %s.", v["expression"])
+				}
+
+				typed := q.(*query)
+				typed.Repository.Head.Commit.Oid = "sha"
+				typed.Repository.Object.Tree.Entries = []entry{
+					{Name: "hello.yml", Object: entryObject{Blob: blob{Oid: "hello", Text: "name: hello\n"}}},
+					{Name: "index.yml", Object: entryObject{Blob: blob{Oid: "index", Text: "slack: {}\n"}}},
+					{Name: "_watcher.yml", Object: entryObject{Blob: blob{Oid: "watcher", Text: "interval: 1m\n"}}},
+					{Name: "schemas"},
+				}
+				return nil
+			},
+		},
+		config:     &Config{BaseDir: "config", Branch: "master"},
+		flatLayout: true,
+	}
+
+	files, err := w.get(context.TODO(), "slack")
+	if err != nil {
+		t.Fatalf("Unexpected error is returned: %s.", err.Error())
+	}
+
+	if len(files) != 1 {
+		t.Fatalf("Unexpected files are returned: %+v.", files)
+	}
+
+	if _, ok := files["hello"]; !ok {
+		t.Error("Expected file is not returned.")
+	}
+}
+
+func TestWatcher_directory_environment(t *testing.T) {
+	tests := []struct {
+		config   *Config
+		expected string
+		fallback string
+	}{
+		{
+			config:   &Config{BaseDir: "config", Environment: "production"},
+			expected: "config/production/slack",
+			fallback: "config/slack",
+		},
+		{
+			config:   &Config{BaseDir: "config", Environment: "production", PathTemplate: "configs/{environment}/{botType}"},
+			expected: "configs/production/slack",
+		},
+		{
+			config:   &Config{BaseDir: "config"},
+			expected: "config/slack",
+		},
+	}
+
+	for i, tt := range tests {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			w := &watcher{config: tt.config}
+
+			dir := w.directory("slack")
+			if dir != tt.expected {
+				t.Errorf("Unexpected directory is returned: %s.", dir)
+			}
+
+			fallback, ok := w.fallbackDirectory("slack")
+			if ok != (tt.fallback != "") {
+				t.Fatalf("Unexpected result is returned: %t.", ok)
+			}
+
+			if fallback != tt.fallback {
+				t.Errorf("Unexpected fallback directory is returned: %s.", fallback)
+			}
+		})
+	}
+}
+
+func TestWatcher_get_environment(t *testing.T) {
+	w := &watcher{
+		client: &DummyQuerier{
+			QueryFunc: func(_ context.Context, q interface{}, v map[string]interface{}) error {
+				typed := q.(*query)
+				typed.Repository.Head.Commit.Oid = "sha"
+
+				switch v["expression"] {
+				case githubv4.String("master:config/production/slack"):
+					typed.Repository.Object.Tree.Entries = []entry{
+						{Name: "hello.yml", Object: entryObject{Blob: blob{Oid: "production", Text: "name: production\n"}}},
+					}
+
+				case githubv4.String("master:config/slack"):
+					typed.Repository.Object.Tree.Entries = []entry{
+						{Name: "hello.yml", Object: entryObject{Blob: blob{Oid: "base", Text: "name: base\n"}}},
+						{Name: "guess.yml", Object: entryObject{Blob: blob{Oid: "guess", Text: "name: guess\n"}}},
+					}
+
+				default:
+					t.Errorf("Unexpected expression is given: %s.", v["expression"])
+
+				}
+				return nil
+			},
+		},
+		config: &Config{BaseDir: "config", Branch: "master", Environment: "production"},
+	}
+
+	files, err := w.get(context.TODO(), "slack")
+	if err != nil {
+		t.Fatalf("Unexpected error is returned: %s.", err.Error())
+	}
+
+	if len(files) != 2 {
+		t.Fatalf("Unexpected files are returned: %+v.", files)
+	}
+
+	if files["hello"].objectID != "production" {
+		t.Errorf("Environment-specific file must take precedence: %+v.", files["hello"])
+	}
+
+	if files["guess"].objectID != "guess" {
+		t.Errorf("Missing file must fall back to the base directory: %+v.", files["guess"])
+	}
+}
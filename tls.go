@@ -0,0 +1,157 @@
+package githubconfig
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"github.com/shurcooL/githubv4"
+	"golang.org/x/oauth2"
+	"net/http"
+	"os"
+)
+
+// WithRootCAs verifies the server certificate of GitHub Enterprise Server, or of the gateway in front of it, with the CA certificates in the given PEM files
+// instead of the system's certificate pool.
+// See WithClientCertificate for the HTTP clients this applies to.
+func WithRootCAs(pemFiles ...string) Option {
+	return func(w *watcher) {
+		w.rootCAFiles = append(w.rootCAFiles, pemFiles...)
+	}
+}
+
+// WithClientCertificate presents the client certificate and the private key in the given PEM files on the TLS handshake,
+// so the requests pass through a gateway that enforces mutual TLS.
+// This applies to copies of the HTTP clients given by WithToken, WithRepositoryToken, WithRESTClient and WithRepositoryHTTPClient,
+// so the given clients and their other users are left intact.
+// The client wrapped by the githubv4.Client given by WithClient is not reachable, so New refuses the combination;
+// give WithToken along with WithGraphQLURL instead.
+// New returns an error when the files cannot be loaded, or when a client has a transport that the TLS settings cannot be applied to.
+func WithClientCertificate(certFile string, keyFile string) Option {
+	return func(w *watcher) {
+		w.clientCertFile = certFile
+		w.clientKeyFile = keyFile
+	}
+}
+
+// tlsConfig returns the TLS settings given by WithRootCAs and WithClientCertificate, or nil when none is given.
+func (w *watcher) tlsConfig() (*tls.Config, error) {
+	if len(w.rootCAFiles) == 0 && w.clientCertFile == "" {
+		return nil, nil
+	}
+
+	cfg := &tls.Config{MinVersion: tls.VersionTLS12}
+	if len(w.rootCAFiles) > 0 {
+		pool := x509.NewCertPool()
+		for _, name := range w.rootCAFiles {
+			b, err := os.ReadFile(name)
+			if err != nil {
+				return nil, fmt.Errorf("failed to read CA certificate: %w", err)
+			}
+			if !pool.AppendCertsFromPEM(b) {
+				return nil, fmt.Errorf("no CA certificate is found in %s", name)
+			}
+		}
+		cfg.RootCAs = pool
+	}
+
+	if w.clientCertFile != "" {
+		cert, err := tls.LoadX509KeyPair(w.clientCertFile, w.clientKeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load client certificate: %w", err)
+		}
+		cfg.Certificates = []tls.Certificate{cert}
+	}
+
+	return cfg, nil
+}
+
+// configureTLS replaces the HTTP clients with their copies that apply the TLS settings.
+// The HTTP client given by WithToken is shared with the REST API client, so both are replaced with the same copy.
+// The GraphQL API clients must be built with the replaced clients afterwards.
+func (w *watcher) configureTLS() error {
+	cfg, err := w.tlsConfig()
+	if err != nil || cfg == nil {
+		return err
+	}
+
+	if _, ok := w.client.(*githubv4.Client); ok && w.tokenClient == nil {
+		return errors.New("TLS settings cannot be applied to the client given by WithClient: give WithToken along with WithGraphQLURL instead")
+	}
+
+	configured := map[*http.Client]*http.Client{}
+	configure := func(httpClient *http.Client) (*http.Client, error) {
+		if httpClient == nil {
+			return nil, nil
+		}
+		if c, ok := configured[httpClient]; ok {
+			return c, nil
+		}
+
+		transport, err := withTLSConfig(httpClient.Transport, cfg)
+		if err != nil {
+			return nil, err
+		}
+		c := *httpClient
+		c.Transport = transport
+		configured[httpClient] = &c
+		return &c, nil
+	}
+
+	w.tokenClient, err = configure(w.tokenClient)
+	if err != nil {
+		return err
+	}
+	if w.rest != nil {
+		httpClient, err := configure(w.rest.httpClient)
+		if err != nil {
+			return err
+		}
+		w.rest = &restClient{httpClient: httpClient, baseURL: w.rest.baseURL}
+	}
+	repositoryClients := map[string]*http.Client{}
+	for repository, httpClient := range w.repositoryClients {
+		c, err := configure(httpClient)
+		if err != nil {
+			return err
+		}
+		repositoryClients[repository] = c
+	}
+	if w.repositoryClients != nil {
+		w.repositoryClients = repositoryClients
+	}
+
+	return nil
+}
+
+// withTLSConfig returns the transport that establishes TLS connections with the given settings
+// in place of the innermost *http.Transport of the given transport.
+func withTLSConfig(rt http.RoundTripper, cfg *tls.Config) (http.RoundTripper, error) {
+	switch t := rt.(type) {
+	case nil:
+		return withTLSConfig(http.DefaultTransport, cfg)
+
+	case *http.Transport:
+		// Clone so http.DefaultTransport or a transport shared with others is left intact.
+		t = t.Clone()
+		t.TLSClientConfig = cfg.Clone()
+		return t, nil
+
+	case *scrubbingTransport:
+		base, err := withTLSConfig(t.base, cfg)
+		if err != nil {
+			return nil, err
+		}
+		return &scrubbingTransport{base: base, secrets: t.secrets}, nil
+
+	case *oauth2.Transport:
+		base, err := withTLSConfig(t.Base, cfg)
+		if err != nil {
+			return nil, err
+		}
+		return &oauth2.Transport{Base: base, Source: t.Source}, nil
+
+	default:
+		return nil, fmt.Errorf("TLS settings cannot be applied to transport %T: configure the HTTP client's transport instead", rt)
+	}
+}
@@ -0,0 +1,174 @@
+package githubconfig
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/pem"
+	"github.com/shurcooL/githubv4"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// writeClientCertificate writes a self-signed client certificate and its private key, and returns the file names.
+func writeClientCertificate(t *testing.T) (string, string) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("Unexpected error is returned: %s.", err.Error())
+	}
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatalf("Unexpected error is returned: %s.", err.Error())
+	}
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatalf("Unexpected error is returned: %s.", err.Error())
+	}
+
+	dir := t.TempDir()
+	certFile := filepath.Join(dir, "client.crt")
+	keyFile := filepath.Join(dir, "client.key")
+	_ = os.WriteFile(certFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0o600)
+	_ = os.WriteFile(keyFile, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}), 0o600)
+	return certFile, keyFile
+}
+
+func TestWithRootCAs(t *testing.T) {
+	w := &watcher{}
+	WithRootCAs("a.pem", "b.pem")(w)
+
+	if len(w.rootCAFiles) != 2 {
+		t.Errorf("Unexpected files are set: %v.", w.rootCAFiles)
+	}
+}
+
+func TestWithClientCertificate(t *testing.T) {
+	w := &watcher{}
+	WithClientCertificate("client.crt", "client.key")(w)
+
+	if w.clientCertFile != "client.crt" || w.clientKeyFile != "client.key" {
+		t.Errorf("Unexpected files are set: %s, %s.", w.clientCertFile, w.clientKeyFile)
+	}
+}
+
+func TestWatcher_configureTLS(t *testing.T) {
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if len(r.TLS.PeerCertificates) == 0 {
+			t.Error("Client certificate is not presented.")
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	server.TLS = &tls.Config{ClientAuth: tls.RequireAnyClientCert}
+	server.StartTLS()
+	defer server.Close()
+
+	caFile := filepath.Join(t.TempDir(), "ca.pem")
+	_ = os.WriteFile(caFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: server.Certificate().Raw}), 0o600)
+	certFile, keyFile := writeClientCertificate(t)
+
+	w := &watcher{}
+	WithToken(context.TODO(), "token")(w)
+	WithRootCAs(caFile)(w)
+	WithClientCertificate(certFile, keyFile)(w)
+
+	err := w.configureTLS()
+	if err != nil {
+		t.Fatalf("Unexpected error is returned: %s.", err.Error())
+	}
+
+	resp, err := w.rest.httpClient.Get(server.URL)
+	if err != nil {
+		t.Fatalf("Unexpected error is returned: %s.", err.Error())
+	}
+	_ = resp.Body.Close()
+
+	if cfg := http.DefaultTransport.(*http.Transport).TLSClientConfig; cfg != nil && len(cfg.Certificates) > 0 {
+		t.Error("Default transport is modified.")
+	}
+}
+
+func TestWatcher_configureTLS_error(t *testing.T) {
+	certFile, keyFile := writeClientCertificate(t)
+
+	tests := []struct {
+		watcher *watcher
+	}{
+		{watcher: &watcher{rootCAFiles: []string{filepath.Join(t.TempDir(), "missing.pem")}}},
+		{watcher: &watcher{rootCAFiles: []string{keyFile}}},
+		{watcher: &watcher{clientCertFile: certFile, clientKeyFile: certFile}},
+		{
+			watcher: &watcher{
+				clientCertFile: certFile,
+				clientKeyFile:  keyFile,
+				rest:           &restClient{httpClient: &http.Client{Transport: &DummyRoundTripper{}}},
+			},
+		},
+	}
+
+	for i, tt := range tests {
+		err := tt.watcher.configureTLS()
+		if err == nil {
+			t.Errorf("Expected error is not returned on %d.", i)
+		}
+	}
+}
+
+func TestWatcher_configureTLS_copy(t *testing.T) {
+	certFile, keyFile := writeClientCertificate(t)
+	transport := &http.Transport{}
+	given := &http.Client{Transport: transport}
+
+	w := &watcher{
+		clientCertFile: certFile,
+		clientKeyFile:  keyFile,
+	}
+	WithRESTClient(given, "https://example.com/api/v3")(w)
+	WithRepositoryHTTPClient("oklahomer/team", given)(w)
+
+	err := w.configureTLS()
+	if err != nil {
+		t.Fatalf("Unexpected error is returned: %s.", err.Error())
+	}
+
+	// Cloning a transport may set up HTTP/2 on the original, so check that the certificate is not given to it.
+	if given.Transport != transport || (transport.TLSClientConfig != nil && len(transport.TLSClientConfig.Certificates) > 0) {
+		t.Error("Given client is modified.")
+	}
+	if w.rest.httpClient == given || w.rest.httpClient != w.repositoryClients["oklahomer/team"] {
+		t.Errorf("Unexpected clients are set: %p, %p.", w.rest.httpClient, w.repositoryClients["oklahomer/team"])
+	}
+	if len(w.rest.httpClient.Transport.(*http.Transport).TLSClientConfig.Certificates) == 0 {
+		t.Error("TLS settings are not applied.")
+	}
+}
+
+func TestNew_withClientAndTLS(t *testing.T) {
+	certFile, keyFile := writeClientCertificate(t)
+
+	_, err := New(&Config{TimeOut: time.Second}, WithClient(githubv4.NewClient(&http.Client{})), WithClientCertificate(certFile, keyFile))
+	if err == nil {
+		t.Error("Expected error is not returned.")
+	}
+
+	w, err := New(&Config{TimeOut: time.Second}, WithToken(context.TODO(), "token"), WithClientCertificate(certFile, keyFile))
+	if err != nil {
+		t.Fatalf("Unexpected error is returned: %s.", err.Error())
+	}
+	if w.(*watcher).tokenClient != w.(*watcher).rest.httpClient {
+		t.Error("GraphQL and REST API clients do not share the HTTP client.")
+	}
+}
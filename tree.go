@@ -0,0 +1,344 @@
+package githubconfig
+
+import (
+	"context"
+	"fmt"
+	"github.com/oklahomer/go-sarah/v4"
+	"github.com/shurcooL/githubv4"
+	"path"
+	"strings"
+)
+
+// maxTreeDepth limits how deep the subdirectories under a BotType's directory are scanned.
+const maxTreeDepth = 8
+
+// treePrefetchDepth is how many levels of subdirectories are fetched along with their parent directory in a single query.
+// A deeper subdirectory is fetched with another query.
+const treePrefetchDepth = 3
+
+// treeQuery represents a Graphql query to fetch the entries of a subdirectory.
+// Formatted query is as below:
+//
+// 	query ($owner: String!, $name: String!, $expression:String!) {
+//    repository(owner: $owner, name: $name) {
+//      object(expression: $expression) {
+//        ... on Tree {
+//          oid
+//          entries {
+//            name
+//            type
+//            mode
+//            submodule {
+//              gitUrl
+//              subprojectCommitOid
+//            }
+//            object {
+//              ... on Blob {
+//                oid
+//                text
+//                isBinary
+//              }
+//            }
+//            subtree: object {
+//              ... on Tree {
+//                entries {
+//                  # The same fields as above, nested up to treePrefetchDepth levels.
+//                }
+//              }
+//            }
+//          }
+//        }
+//      }
+//    }
+// 	}
+type treeQuery struct {
+	Repository treeRepository `graphql:"repository(owner: $owner, name: $name)"`
+}
+
+type treeRepository struct {
+	Object repositoryObject `graphql:"object(expression: $expression)"`
+}
+
+// prefetchedTree holds the entries of a subdirectory fetched along with its parent directory.
+type prefetchedTree = subtree[nestedEntry[nestedEntry[leafEntry]]]
+
+// prefetchedEntry is an entry whose subdirectory entries may be fetched along with it.
+type prefetchedEntry interface {
+	node() treeNode
+}
+
+type subtree[T prefetchedEntry] struct {
+	Tree struct {
+		Entries []T
+	} `graphql:"... on Tree"`
+}
+
+// nestedEntry is an entry of a subdirectory, whose own subdirectory entries are fetched along with it as T.
+type nestedEntry[T prefetchedEntry] struct {
+	Name      githubv4.String
+	Type      githubv4.String
+	Mode      githubv4.Int
+	Submodule submodule
+	Object    entryObject
+	Subtree   subtree[T] `graphql:"subtree: object"`
+}
+
+func (e nestedEntry[T]) node() treeNode {
+	n := treeNode{entry: entry{Name: e.Name, Type: e.Type, Mode: e.Mode, Submodule: e.Submodule, Object: e.Object}}
+	for _, child := range e.Subtree.Tree.Entries {
+		n.children = append(n.children, child.node())
+	}
+	return n
+}
+
+// leafEntry is an entry at treePrefetchDepth, whose subdirectory entries are not fetched along with it.
+type leafEntry struct {
+	Name      githubv4.String
+	Type      githubv4.String
+	Mode      githubv4.Int
+	Submodule submodule
+	Object    entryObject
+}
+
+func (e leafEntry) node() treeNode {
+	return treeNode{entry: entry{Name: e.Name, Type: e.Type, Mode: e.Mode, Submodule: e.Submodule, Object: e.Object}}
+}
+
+// treeNode is an entry along with the entries of its subdirectory when they are fetched in the same query.
+type treeNode struct {
+	entry
+	children []treeNode
+}
+
+func (e entry) node() treeNode {
+	n := treeNode{entry: e}
+	n.Subtree = prefetchedTree{}
+	for _, child := range e.Subtree.Tree.Entries {
+		n.children = append(n.children, child.node())
+	}
+	return n
+}
+
+// treeNodes returns the given entries along with the entries of their subdirectories fetched in the same query.
+func treeNodes(entries []entry) []treeNode {
+	nodes := make([]treeNode, 0, len(entries))
+	for _, e := range entries {
+		nodes = append(nodes, e.node())
+	}
+	return nodes
+}
+
+// treeWalk holds the state of walking the directory tree of a BotType.
+type treeWalk struct {
+	botType sarah.BotType
+	// verbose tells if the skipped entries are logged.
+	// This is set only when the tree has changed since the previous walk, so the same entry is not reported on every refresh.
+	verbose bool
+	// dirs holds the entries of the fetched directories keyed by their location,
+	// so a symbolic link pointing into any of them is resolved without another query.
+	dirs map[string][]treeNode
+}
+
+// newTreeWalk starts walking the tree with the given object ID located at the given location,
+// and tells the walk to log the skipped entries only when the object ID differs from the one previously walked.
+func (w *watcher) newTreeWalk(botType sarah.BotType, loc treeLocation, objectID string) *treeWalk {
+	key := fmt.Sprintf("%s:%s/%s@%s:%s", botType, loc.owner, loc.name, loc.ref, loc.dir)
+
+	w.treeMutex.Lock()
+	defer w.treeMutex.Unlock()
+	if w.walkedTrees == nil {
+		w.walkedTrees = map[string]string{}
+	}
+	previous, ok := w.walkedTrees[key]
+	w.walkedTrees[key] = objectID
+
+	return &treeWalk{
+		botType: botType,
+		verbose: !ok || previous != objectID,
+		dirs:    map[string][]treeNode{},
+	}
+}
+
+// dirKey returns the key of the directory located at the given path relative to the repository root of the location.
+func dirKey(loc treeLocation, p string) string {
+	return fmt.Sprintf("%s/%s@%s:%s", loc.owner, loc.name, loc.ref, path.Clean(p))
+}
+
+// remember holds the given entries of the directory located at the given path relative to the repository root,
+// along with the entries of their subdirectories fetched in the same query.
+func (tw *treeWalk) remember(loc treeLocation, p string, nodes []treeNode) {
+	tw.dirs[dirKey(loc, p)] = nodes
+	for _, n := range nodes {
+		if len(n.children) > 0 {
+			tw.remember(loc, path.Join(p, string(n.Name)), n.children)
+		}
+	}
+}
+
+// symlinkMode is the Git file mode of a symbolic link.
+const symlinkMode = 0120000
+
+// maxSymlinkHops limits how many symbolic links are followed to resolve one so a loop does not hang the watcher.
+const maxSymlinkHops = 8
+
+// treeLocation points to the directory in a repository that the entries being walked are relative to.
+type treeLocation struct {
+	owner string
+	name  string
+	ref   string
+	dir   string
+	// prefix is prepended to the names of the entries so they are relative to the BotType's directory.
+	// This is set for the entries in a submodule.
+	prefix string
+}
+
+// tree fetches the entries of the directory located at the given path relative to the location,
+// along with the entries of its subdirectories up to treePrefetchDepth levels.
+func (w *watcher) tree(ctx context.Context, tw *treeWalk, loc treeLocation, p string) ([]treeNode, error) {
+	key := dirKey(loc, path.Join(loc.dir, p))
+	if nodes, ok := tw.dirs[key]; ok {
+		return nodes, nil
+	}
+
+	q := &treeQuery{}
+	variables := map[string]interface{}{
+		"owner":      githubv4.String(loc.owner),
+		"name":       githubv4.String(loc.name),
+		"expression": githubv4.String(expression(loc.ref, path.Join(loc.dir, p))),
+	}
+	err := w.client.Query(ctx, q, variables)
+	if err != nil {
+		return nil, w.queryError(tw.botType, repositoryName(loc.owner, loc.name), err)
+	}
+
+	nodes := treeNodes(q.Repository.Object.Tree.Entries)
+	tw.remember(loc, path.Join(loc.dir, p), nodes)
+	return nodes, nil
+}
+
+// resolveSymlink returns the entry that the symbolic link located at the given path relative to the location points to.
+// A link is resolved within the repository, and a link pointing to another link is followed up to maxSymlinkHops times.
+// A link pointing into a directory that is already fetched is resolved without another query.
+func (w *watcher) resolveSymlink(ctx context.Context, tw *treeWalk, loc treeLocation, p string, target string) (*treeNode, error) {
+	for hops := 0; hops < maxSymlinkHops; hops++ {
+		if path.IsAbs(target) {
+			return nil, fmt.Errorf("absolute link target %s is not supported", target)
+		}
+		p = path.Join(path.Dir(path.Join(loc.dir, p)), target)
+		if p == ".." || strings.HasPrefix(p, "../") {
+			return nil, fmt.Errorf("link target %s is outside of the repository", target)
+		}
+
+		nodes, err := w.tree(ctx, tw, treeLocation{owner: loc.owner, name: loc.name, ref: loc.ref}, path.Dir(p))
+		if err != nil {
+			return nil, err
+		}
+
+		var resolved *treeNode
+		for _, n := range nodes {
+			if string(n.Name) == path.Base(p) {
+				n := n
+				resolved = &n
+				break
+			}
+		}
+		if resolved == nil {
+			return nil, fmt.Errorf("link target %s is not found", p)
+		}
+
+		if resolved.Mode != symlinkMode {
+			if resolved.Type != "blob" {
+				return nil, fmt.Errorf("link target %s is not a file", p)
+			}
+			return resolved, nil
+		}
+		target = string(resolved.Object.Blob.Text)
+		loc.dir = ""
+	}
+
+	return nil, fmt.Errorf("too many levels of symbolic links to resolve %s", p)
+}
+
+// walk returns the file entries under the location, descending into its subdirectories.
+// The given nodes are located at rel, which is the path relative to the location.
+// The name of each returned entry is the path relative to the BotType's directory such as "alerts/pagerduty.yml",
+// so the file is identified by a slash-separated id such as "alerts/pagerduty".
+// A file that is not a configuration file, a hidden directory and a directory excluded by the glob patterns are skipped.
+// A submodule is skipped unless WithSubmodules is given.
+// The entries of a subdirectory are fetched with another query only when they are not fetched along with its parent directory.
+func (w *watcher) walk(ctx context.Context, tw *treeWalk, loc treeLocation, rel string, nodes []treeNode, depth int) ([]entry, error) {
+	tw.remember(loc, path.Join(loc.dir, rel), nodes)
+
+	var files []entry
+	for _, n := range nodes {
+		p := path.Join(rel, string(n.Name))
+		name := path.Join(loc.prefix, p)
+		if n.Mode == symlinkMode {
+			resolved, err := w.resolveSymlink(ctx, tw, loc, p, string(n.Object.Blob.Text))
+			if err != nil {
+				if tw.verbose {
+					w.log(tw.botType, "").Warnf("Skipping symbolic link %s: %+v", path.Join(loc.dir, p), err)
+				}
+				continue
+			}
+			n.Type = resolved.Type
+			n.Object = resolved.Object
+		}
+
+		switch n.Type {
+		case "tree":
+			if depth >= maxTreeDepth || hidden(name) || w.filters().excluded(name) {
+				continue
+			}
+
+			children := n.children
+			if len(children) == 0 {
+				// A Git tree is never empty, so the entries are not fetched along with the parent directory.
+				var err error
+				children, err = w.tree(ctx, tw, loc, p)
+				if err != nil {
+					return nil, err
+				}
+			}
+			entries, err := w.walk(ctx, tw, loc, p, children, depth+1)
+			if err != nil {
+				return nil, err
+			}
+			files = append(files, entries...)
+
+		case "commit":
+			if !w.submodules {
+				if tw.verbose {
+					w.log(tw.botType, "").Infof("Skipping submodule %s", path.Join(loc.dir, p))
+				}
+				continue
+			}
+			if depth >= maxTreeDepth || hidden(name) || w.filters().excluded(name) {
+				continue
+			}
+
+			entries, err := w.walkSubmodule(ctx, tw, loc, name, n.Submodule, depth)
+			if err != nil {
+				return nil, err
+			}
+			files = append(files, entries...)
+
+		default:
+			if !w.candidate(name) {
+				continue
+			}
+
+			e := n.entry
+			content, err := w.blobContent(ctx, tw.botType, loc.owner, loc.name, e.Object.Blob)
+			if err != nil {
+				return nil, fmt.Errorf("failed to read %s: %w", path.Join(loc.dir, p), err)
+			}
+			e.Object.Blob.Text = githubv4.String(content)
+			e.Name = githubv4.String(name)
+			files = append(files, e)
+
+		}
+	}
+
+	return files, nil
+}
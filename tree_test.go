@@ -0,0 +1,213 @@
+package githubconfig
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"github.com/shurcooL/githubv4"
+	"log/slog"
+	"strings"
+	"testing"
+)
+
+func TestWatcher_get_nested(t *testing.T) {
+	w := &watcher{
+		client: &DummyQuerier{
+			QueryFunc: func(_ context.Context, q interface{}, v map[string]interface{}) error {
+				switch typed := q.(type) {
+				case *query:
+					typed.Repository.Head.Commit.Oid = "sha"
+					typed.Repository.Object.Tree.Entries = []entry{
+						{Name: "hello.yml", Type: "blob", Object: entryObject{Blob: blob{Oid: "hello", Text: "name: hello\n"}}},
+						{Name: "alerts", Type: "tree"},
+					}
+
+				case *treeQuery:
+					switch v["expression"] {
+					case githubv4.String("master:config/bot/alerts"):
+						typed.Repository.Object.Tree.Entries = []entry{
+							{Name: "pagerduty.yml", Type: "blob", Object: entryObject{Blob: blob{Oid: "pagerduty", Text: "service: pagerduty\n"}}},
+							{Name: "team", Type: "tree"},
+						}
+
+					case githubv4.String("master:config/bot/alerts/team"):
+						typed.Repository.Object.Tree.Entries = []entry{
+							{Name: "oncall.json", Type: "blob", Object: entryObject{Blob: blob{Oid: "oncall", Text: "{}"}}},
+						}
+
+					default:
+						t.Errorf("Unexpected expression is given: %s.", v["expression"])
+
+					}
+
+				}
+				return nil
+			},
+		},
+		config: &Config{BaseDir: "config", Branch: "master"},
+	}
+
+	files, err := w.get(context.TODO(), "bot")
+	if err != nil {
+		t.Fatalf("Unexpected error is returned: %s.", err.Error())
+	}
+
+	expected := map[string]string{
+		"hello":              "config/bot/hello.yml",
+		"alerts/pagerduty":   "config/bot/alerts/pagerduty.yml",
+		"alerts/team/oncall": "config/bot/alerts/team/oncall.json",
+	}
+	if len(files) != len(expected) {
+		t.Fatalf("Unexpected files are returned: %+v.", files)
+	}
+	for id, p := range expected {
+		f, ok := files[id]
+		if !ok {
+			t.Errorf("Expected file is not returned: %s.", id)
+			continue
+		}
+
+		if f.path != p {
+			t.Errorf("Unexpected path is set for %s: %s.", id, f.path)
+		}
+	}
+}
+
+func TestWatcher_walk_error(t *testing.T) {
+	w := &watcher{
+		client: &DummyQuerier{
+			QueryFunc: func(_ context.Context, _ interface{}, _ map[string]interface{}) error {
+				return errors.New("API error")
+			},
+		},
+		config: &Config{},
+	}
+
+	loc := treeLocation{ref: "master", dir: "config/bot"}
+	_, err := w.walk(context.TODO(), w.newTreeWalk("bot", loc, "tree"), loc, "", treeNodes([]entry{{Name: "alerts", Type: "tree"}}), 0)
+	if err == nil {
+		t.Fatal("Expected error is not returned.")
+	}
+
+	var queryErr *QueryError
+	if !errors.As(err, &queryErr) {
+		t.Errorf("Unexpected error is returned: %#v.", err)
+	}
+}
+
+func TestWatcher_get_symlink(t *testing.T) {
+	w := &watcher{
+		client: &DummyQuerier{
+			QueryFunc: func(_ context.Context, q interface{}, v map[string]interface{}) error {
+				switch typed := q.(type) {
+				case *query:
+					typed.Repository.Head.Commit.Oid = "sha"
+					typed.Repository.Object.Tree.Entries = []entry{
+						{Name: "hello.yml", Type: "blob", Mode: symlinkMode, Object: entryObject{Blob: blob{Oid: "link", Text: "../shared/hello.yml"}}},
+						{Name: "loop.yml", Type: "blob", Mode: symlinkMode, Object: entryObject{Blob: blob{Oid: "loop", Text: "loop.yml"}}},
+						{Name: "outside.yml", Type: "blob", Mode: symlinkMode, Object: entryObject{Blob: blob{Oid: "outside", Text: "../../../outside.yml"}}},
+						{Name: "guess.yml", Type: "blob", Mode: 0100644, Object: entryObject{Blob: blob{Oid: "guess", Text: "name: guess\n"}}},
+					}
+
+				case *treeQuery:
+					switch v["expression"] {
+					case githubv4.String("master:config/shared"):
+						typed.Repository.Object.Tree.Entries = []entry{
+							{Name: "hello.yml", Type: "blob", Mode: symlinkMode, Object: entryObject{Blob: blob{Oid: "nested", Text: "actual.yml"}}},
+							{Name: "actual.yml", Type: "blob", Mode: 0100644, Object: entryObject{Blob: blob{Oid: "actual", Text: "name: actual\n"}}},
+						}
+
+					case githubv4.String("master:config/bot"):
+						typed.Repository.Object.Tree.Entries = []entry{
+							{Name: "loop.yml", Type: "blob", Mode: symlinkMode, Object: entryObject{Blob: blob{Oid: "loop", Text: "loop.yml"}}},
+						}
+
+					default:
+						t.Errorf("Unexpected expression is given: %s.", v["expression"])
+
+					}
+
+				}
+				return nil
+			},
+		},
+		config: &Config{BaseDir: "config", Branch: "master"},
+	}
+
+	files, err := w.get(context.TODO(), "bot")
+	if err != nil {
+		t.Fatalf("Unexpected error is returned: %s.", err.Error())
+	}
+
+	if len(files) != 2 {
+		t.Fatalf("Unexpected files are returned: %+v.", files)
+	}
+
+	hello, ok := files["hello"]
+	if !ok {
+		t.Fatal("Expected file is not returned.")
+	}
+
+	if hello.objectID != "actual" || hello.content != "name: actual\n" {
+		t.Errorf("Symbolic link is not resolved: %+v.", hello)
+	}
+
+	if _, ok := files["guess"]; !ok {
+		t.Error("Regular file must be returned.")
+	}
+}
+
+func TestWatcher_get_prefetched(t *testing.T) {
+	queries := 0
+	w := &watcher{
+		client: &DummyQuerier{
+			QueryFunc: func(_ context.Context, q interface{}, v map[string]interface{}) error {
+				queries++
+				switch typed := q.(type) {
+				case *query:
+					typed.Repository.Head.Commit.Oid = "sha"
+					typed.Repository.Object.Tree.Oid = "root"
+					alerts := entry{Name: "alerts", Type: "tree"}
+					alerts.Subtree.Tree.Entries = []nestedEntry[nestedEntry[leafEntry]]{
+						{Name: "pagerduty.yml", Type: "blob", Object: entryObject{Blob: blob{Oid: "pagerduty", Text: "service: pagerduty\n"}}},
+						{Name: "link.yml", Type: "blob", Mode: symlinkMode, Object: entryObject{Blob: blob{Oid: "link", Text: "../hello.yml"}}},
+					}
+					typed.Repository.Object.Tree.Entries = []entry{
+						{Name: "hello.yml", Type: "blob", Object: entryObject{Blob: blob{Oid: "hello", Text: "name: hello\n"}}},
+						{Name: "shared", Type: "commit", Submodule: submodule{GitURL: "invalid", SubprojectCommitOid: "abc"}},
+						alerts,
+					}
+
+				default:
+					t.Errorf("Unexpected query is given: %#v.", v)
+
+				}
+				return nil
+			},
+		},
+		config:     &Config{BaseDir: "config", Branch: "master"},
+		submodules: true,
+	}
+	buf := &bytes.Buffer{}
+	w.slogger = slog.New(slog.NewTextHandler(buf, nil))
+
+	for i := 0; i < 2; i++ {
+		files, err := w.get(context.TODO(), "bot")
+		if err != nil {
+			t.Fatalf("Unexpected error is returned: %s.", err.Error())
+		}
+
+		for _, id := range []string{"hello", "alerts/pagerduty", "alerts/link"} {
+			if _, ok := files[id]; !ok {
+				t.Errorf("Expected file is not returned: %s.", id)
+			}
+		}
+	}
+
+	if queries != 2 {
+		t.Errorf("Unexpected number of queries are sent: %d.", queries)
+	}
+	if count := strings.Count(buf.String(), "Skipping submodule"); count != 1 {
+		t.Errorf("Skipped submodule must be logged only once for the unchanged tree: %d.", count)
+	}
+}
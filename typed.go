@@ -0,0 +1,22 @@
+package githubconfig
+
+import (
+	"context"
+	"github.com/oklahomer/go-sarah/v4"
+)
+
+// Read reads the given id's configuration into a value of type T and returns it.
+// This is a typed shorthand for sarah.ConfigWatcher.Read so the caller does not have to prepare an out parameter:
+//
+//	cfg, err := githubconfig.Read[HelloConfig](ctx, watcher, "slack", "hello")
+//
+// Any sarah.ConfigWatcher implementation including the one returned by Compose can be given.
+func Read[T any](ctx context.Context, w sarah.ConfigWatcher, botType sarah.BotType, id string) (T, error) {
+	var out T
+	err := w.Read(ctx, botType, id, &out)
+	if err != nil {
+		var zero T
+		return zero, err
+	}
+	return out, nil
+}
@@ -0,0 +1,55 @@
+package githubconfig
+
+import (
+	"context"
+	"errors"
+	"github.com/oklahomer/go-sarah/v4"
+	"testing"
+)
+
+func TestRead_typed(t *testing.T) {
+	type config struct {
+		Name string `yaml:"name"`
+	}
+
+	t.Run("success", func(t *testing.T) {
+		w := &DummyConfigWatcher{
+			ReadFunc: func(_ context.Context, _ sarah.BotType, _ string, out interface{}) error {
+				cfg, ok := out.(*config)
+				if !ok {
+					t.Fatalf("Unexpected type is given: %T.", out)
+				}
+				cfg.Name = "oklahomer"
+				return nil
+			},
+		}
+
+		cfg, err := Read[config](context.Background(), w, "bot", "hello")
+		if err != nil {
+			t.Fatalf("Unexpected error is returned: %s.", err.Error())
+		}
+
+		if cfg.Name != "oklahomer" {
+			t.Errorf("Unexpected value is returned: %+v.", cfg)
+		}
+	})
+
+	t.Run("error", func(t *testing.T) {
+		expected := errors.New("read error")
+		w := &DummyConfigWatcher{
+			ReadFunc: func(_ context.Context, _ sarah.BotType, _ string, out interface{}) error {
+				out.(*config).Name = "partial"
+				return expected
+			},
+		}
+
+		cfg, err := Read[config](context.Background(), w, "bot", "hello")
+		if !errors.Is(err, expected) {
+			t.Errorf("Expected error is not returned: %+v.", err)
+		}
+
+		if cfg.Name != "" {
+			t.Errorf("Zero value must be returned on error: %+v.", cfg)
+		}
+	})
+}
@@ -0,0 +1,119 @@
+package githubconfig
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/oklahomer/go-sarah/v4"
+	"sort"
+	"strings"
+	"time"
+)
+
+// WithStrictStartup makes VerifyWatched fail with MissingConfigError when any watched id has no configuration file.
+// Without this option, VerifyWatched only logs the missing configurations.
+func WithStrictStartup() Option {
+	return func(w *watcher) {
+		w.strictStartup = true
+	}
+}
+
+// MissingConfigError is returned by VerifyWatched when the watched ids have no corresponding configuration files.
+type MissingConfigError struct {
+	// Missing holds the sorted ids of the missing configurations by BotType.
+	Missing map[sarah.BotType][]string
+}
+
+// Error returns stringified representation of the error.
+func (err *MissingConfigError) Error() string {
+	var missing []string
+	for botType, ids := range err.Missing {
+		for _, id := range ids {
+			missing = append(missing, fmt.Sprintf("%s:%s", botType, id))
+		}
+	}
+	sort.Strings(missing)
+	return fmt.Sprintf("configuration files are missing for %s", strings.Join(missing, ", "))
+}
+
+var _ error = (*MissingConfigError)(nil)
+
+type verificationRequest struct {
+	result chan<- error
+}
+
+func (w *watcher) VerifyWatched(ctx context.Context) error {
+	result := make(chan error, 1)
+	req := &verificationRequest{
+		result: result,
+	}
+
+	err := w.running()
+	if err != nil {
+		return err
+	}
+
+	timer := time.NewTimer(w.config.TimeOut)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+
+	case <-timer.C:
+		return SubscriptionTimeout
+
+	case <-w.done:
+		return WatcherStopped
+
+	case w.verification <- req:
+		// Successfully enqueued
+
+	}
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+
+	case <-timer.C:
+		return SubscriptionTimeout
+
+	case err := <-result:
+		return err
+
+	}
+}
+
+// verify checks the subscribed ids against the cached files, fetching the files of a BotType that is not cached yet.
+// This must be called from the operating goroutine.
+func (w *watcher) verify(ctx context.Context, cache map[sarah.BotType]map[string]*file, fetches statuses, subscribed subscriptions) error {
+	missing := map[sarah.BotType][]string{}
+	for botType, sub := range subscribed {
+		files, err := w.cached(ctx, cache, fetches, botType)
+		var notFound *DirectoryNotFoundError
+		if err != nil && !errors.As(err, &notFound) {
+			return err
+		}
+
+		for id := range sub {
+			if id == allIDs {
+				continue
+			}
+			if files[w.resolve(botType, files, id)] == nil {
+				missing[botType] = append(missing[botType], id)
+			}
+		}
+		sort.Strings(missing[botType])
+	}
+
+	if len(missing) == 0 {
+		return nil
+	}
+
+	err := &MissingConfigError{Missing: missing}
+	if !w.strictStartup {
+		w.log("", "").Warnf("%s", err.Error())
+		return nil
+	}
+	return err
+}
@@ -0,0 +1,107 @@
+package githubconfig
+
+import (
+	"context"
+	"errors"
+	"github.com/oklahomer/go-sarah/v4"
+	"github.com/shurcooL/githubv4"
+	"testing"
+	"time"
+)
+
+func TestWithStrictStartup(t *testing.T) {
+	w := &watcher{}
+	WithStrictStartup()(w)
+
+	if !w.strictStartup {
+		t.Error("Strict startup is not enabled.")
+	}
+}
+
+func TestMissingConfigError_Error(t *testing.T) {
+	err := &MissingConfigError{
+		Missing: map[sarah.BotType][]string{
+			"slack": {"guess", "hello"},
+			"bot":   {"hello"},
+		},
+	}
+
+	expected := "configuration files are missing for bot:hello, slack:guess, slack:hello"
+	if err.Error() != expected {
+		t.Errorf("Unexpected error message is returned: %s.", err.Error())
+	}
+}
+
+func TestWatcher_VerifyWatched(t *testing.T) {
+	tests := []struct {
+		strict  bool
+		missing bool
+	}{
+		{strict: true, missing: true},
+		{strict: false, missing: false},
+	}
+
+	for _, tt := range tests {
+		ctx, cancel := context.WithCancel(context.Background())
+		w := &watcher{
+			client: &DummyQuerier{
+				QueryFunc: func(_ context.Context, q interface{}, v map[string]interface{}) error {
+					typed := q.(*query)
+					typed.Repository.Head.Commit.Oid = "sha"
+					if v["expression"] == githubv4.String("master:config/bot") {
+						typed.Repository.Object.Tree.Entries = []entry{
+							{Name: "hello.json", Object: entryObject{Blob: blob{Oid: "oid", Text: "{}"}}},
+						}
+					}
+					return nil
+				},
+			},
+			config: &Config{
+				BaseDir:  "config",
+				Branch:   "master",
+				TimeOut:  100 * time.Millisecond,
+				Interval: time.Hour,
+			},
+			strictStartup: tt.strict,
+			subscription:  make(chan *subscription),
+			verification:  make(chan *verificationRequest),
+		}
+		w.started.Store(true)
+		go w.operate(ctx)
+
+		for _, s := range []*subscription{
+			{botType: "bot", id: "hello"},
+			{botType: "bot", id: "guess"},
+			{botType: "bot", id: allIDs},
+			{botType: "other", id: "weather"},
+		} {
+			_, err := w.subscribe(ctx, s)
+			if err != nil {
+				t.Fatalf("Unexpected error is returned: %s.", err.Error())
+			}
+		}
+
+		err := w.VerifyWatched(ctx)
+		cancel()
+
+		if !tt.missing {
+			if err != nil {
+				t.Errorf("Unexpected error is returned: %s.", err.Error())
+			}
+			continue
+		}
+
+		var missingErr *MissingConfigError
+		if !errors.As(err, &missingErr) {
+			t.Fatalf("Expected error is not returned: %#v.", err)
+		}
+
+		if len(missingErr.Missing["bot"]) != 1 || missingErr.Missing["bot"][0] != "guess" {
+			t.Errorf("Unexpected ids are returned for bot: %v.", missingErr.Missing["bot"])
+		}
+
+		if len(missingErr.Missing["other"]) != 1 || missingErr.Missing["other"][0] != "weather" {
+			t.Errorf("Unexpected ids are returned for other: %v.", missingErr.Missing["other"])
+		}
+	}
+}
@@ -0,0 +1,37 @@
+package githubconfig
+
+import (
+	"context"
+	"github.com/oklahomer/go-sarah/v4"
+)
+
+// Version describes the version of a configuration file.
+type Version struct {
+	// ObjectID is the Git blob object ID of the file content.
+	ObjectID string
+	// CommitSHA is the SHA of the commit the file is fetched from. This is empty for a local override file.
+	CommitSHA string
+	// Branch is the branch the file is fetched from. This is empty for a local override file.
+	Branch string
+}
+
+func (w *watcher) Version(ctx context.Context, botType sarah.BotType, id string) (*Version, error) {
+	files, err := w.snapshotFiles(ctx, botType)
+	if err != nil {
+		return nil, err
+	}
+
+	f, ok := files[w.resolve(botType, files, id)]
+	if !ok {
+		return nil, &sarah.ConfigNotFoundError{
+			BotType: botType,
+			ID:      id,
+		}
+	}
+
+	return &Version{
+		ObjectID:  f.objectID,
+		CommitSHA: f.commitSHA,
+		Branch:    f.branch,
+	}, nil
+}
@@ -0,0 +1,59 @@
+package githubconfig
+
+import (
+	"context"
+	"errors"
+	"github.com/oklahomer/go-sarah/v4"
+	"testing"
+	"time"
+)
+
+func TestWatcher_Version(t *testing.T) {
+	f := newFile("hello.yml", "oid", "name: oklahomer\n")
+	f.commitSHA = "sha"
+	snapshotReq := make(chan *snapshotRequest, 1)
+	w := &watcher{
+		config: &Config{
+			TimeOut: 100 * time.Millisecond,
+		},
+		snapshot: snapshotReq,
+	}
+	w.started.Store(true)
+	go func() {
+		for {
+			select {
+			case req := <-snapshotReq:
+				req.result <- &snapshot{files: map[string]*file{f.id: f}}
+
+			case <-time.NewTimer(1 * time.Second).C:
+				// Just to be sure goroutine does not leak
+				return
+
+			}
+		}
+	}()
+
+	t.Run("existing", func(t *testing.T) {
+		version, err := w.Version(context.Background(), "bot", "hello")
+		if err != nil {
+			t.Fatalf("Unexpected error is returned: %s.", err.Error())
+		}
+
+		if version.ObjectID != "oid" {
+			t.Errorf("Unexpected object ID is returned: %s.", version.ObjectID)
+		}
+
+		if version.CommitSHA != "sha" {
+			t.Errorf("Unexpected commit SHA is returned: %s.", version.CommitSHA)
+		}
+	})
+
+	t.Run("absent", func(t *testing.T) {
+		_, err := w.Version(context.Background(), "bot", "absent")
+
+		var notFound *sarah.ConfigNotFoundError
+		if !errors.As(err, &notFound) {
+			t.Errorf("Expected error is not returned: %+v.", err)
+		}
+	})
+}
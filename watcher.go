@@ -2,21 +2,61 @@ package githubconfig
 
 import (
 	"context"
-	"encoding/json"
+	"crypto"
 	"errors"
 	"fmt"
 	"github.com/oklahomer/go-sarah/v4"
+	"github.com/prometheus/client_golang/prometheus"
 	"github.com/shurcooL/githubv4"
 	"golang.org/x/oauth2"
-	"gopkg.in/yaml.v2"
+	"gopkg.in/ini.v1"
+	"io/fs"
+	"log/slog"
+	"net/http"
 	"path"
 	"path/filepath"
+	"reflect"
 	"strings"
+	"sync"
+	"sync/atomic"
 	"time"
 )
 
 var SubscriptionTimeout = errors.New("timeout")
 
+// WatcherStopped is returned when an operation is requested after the watcher is stopped.
+var WatcherStopped = errors.New("watcher is stopped")
+
+// WatcherNotStarted is returned when an operation that requires the running watcher is requested before Watcher.Start is called.
+var WatcherNotStarted = errors.New("watcher is not started")
+
+// CanceledError is returned when the caller's context is canceled or its deadline is exceeded before the operation completes.
+// Use errors.Is with context.Canceled or context.DeadlineExceeded to see the cause.
+type CanceledError struct {
+	// BotType is empty for an operation that is not bound to any BotType such as Status.
+	BotType sarah.BotType
+	ID      string
+	Err     error
+}
+
+// Error returns stringified representation of the error.
+func (err *CanceledError) Error() string {
+	if err.BotType == "" {
+		return fmt.Sprintf("operation is canceled: %s", err.Err.Error())
+	}
+	if err.ID == "" {
+		return fmt.Sprintf("operation for %s is canceled: %s", err.BotType, err.Err.Error())
+	}
+	return fmt.Sprintf("operation for %s:%s is canceled: %s", err.BotType, err.ID, err.Err.Error())
+}
+
+// Unwrap returns the underlying context error.
+func (err *CanceledError) Unwrap() error {
+	return err.Err
+}
+
+var _ error = (*CanceledError)(nil)
+
 type Config struct {
 	Owner    string        `json:"owner" yaml:"owner"`
 	Name     string        `json:"name" yaml:"name"`
@@ -24,211 +64,1502 @@ type Config struct {
 	Branch   string        `json:"branch" yaml:"branch"`
 	Interval time.Duration `json:"interval" yaml:"interval"`
 	TimeOut  time.Duration `json:"timeout" yaml:"timeout"`
+	// Branches is the ordered list of branches to look up the configuration files in before Branch.
+	// A file found in an earlier branch wins, so a preview bot can layer a feature branch over the main branch.
+	Branches []string `json:"branches" yaml:"branches"`
+	// Environment is the name of the environment such as "production" to look up the configuration files for.
+	// With the default layout, the files are looked up in {BaseDir}/{Environment}/{BotType}/ and then in {BaseDir}/{BotType}/ as a fallback,
+	// so one repository can serve the bots in multiple environments. This also replaces the {environment} placeholder of PathTemplate.
+	Environment string `json:"environment" yaml:"environment"`
+	// EnvironmentBranch is the template of the branch that holds the environment-specific files such as "env/{environment}".
+	// When this and Environment are set, the files are looked up on the environment's branch first and then on Branches and Branch,
+	// so only the overridden files need to live on the environment's branch.
+	EnvironmentBranch string `json:"environment_branch" yaml:"environment_branch"`
+	// PathTemplate is the template of the directory that contains each BotType's configuration files relative to the repository root,
+	// such as "configs/{botType}/{environment}". {baseDir}, {botType} and {environment} are replaced with BaseDir, the BotType
+	// and Environment, or the environment name given by WithEnvironmentOverlay when Environment is empty.
+	// The directory defaults to "{baseDir}/{botType}" when this is empty.
+	PathTemplate string `json:"path_template" yaml:"path_template"`
+	// Sources overrides the repository, the branch and the base directory per BotType.
+	Sources map[sarah.BotType]*Source `json:"sources" yaml:"sources"`
+	// Layers is the ordered stack of repositories beneath the BotType's repository, from the lowest precedence such as
+	// the organization's defaults to the highest such as the team's overrides. Each layer is read at {base_dir}/{botType}/,
+	// and the files with the same id are deep-merged over each other with the BotType's repository on top.
+	// An omitted field of a layer inherits the corresponding value of Config.
+	Layers []*Source `json:"layers" yaml:"layers"`
+}
+
+func NewConfig(owner string, name string, baseDir string) *Config {
+	return &Config{
+		Owner:    owner,
+		Name:     name,
+		BaseDir:  baseDir,
+		Branch:   "master",
+		Interval: 1 * time.Minute,
+		TimeOut:  5 * time.Second,
+	}
+}
+
+type watcher struct {
+	client                querier
+	config                *Config
+	localDir              string
+	strictDecode          bool
+	disallowUnknownFields bool
+	sniffing              bool
+	extensionPrecedence   []string
+	detectDuplicate       bool
+	interpolate           bool
+	environment           string
+	preDecodeHook         func(sarah.BotType, string, []byte) ([]byte, error)
+	postDecodeHook        func(sarah.BotType, string, interface{}) error
+	shadowHooks           []func(sarah.BotType, string, interface{}) error
+	slogger               *slog.Logger
+	decodeErrorHook       func(*DecodeError)
+	strictStartup         bool
+	prototypes            map[sarah.BotType]map[string]interface{}
+	sopsDecrypter         SOPSDecrypter
+	decrypters            []*patternDecrypter
+	secretResolver        SecretResolver
+	// secrets holds the values resolved for each configuration, keyed by "botType:id".
+	secrets     map[string]*resolvedSecrets
+	secretMutex sync.Mutex
+	// blobs holds the contents fetched via the REST API and the Git LFS batch API, and the plaintext decrypted by WithDecrypter.
+	blobs blobCache
+	// walkedTrees holds the object ID of the tree previously walked at each location, so an entry skipped in an unchanged tree is not logged again.
+	walkedTrees       map[string]string
+	treeMutex         sync.Mutex
+	tagVerifier       TagVerifier
+	codeOwnerApproval bool
+	requiredApprovals int
+	allowlistPath     string
+	// credentials are the secrets given to authenticate, which must never appear in an error message.
+	credentials []string
+	// repositoryClients are the HTTP clients to send the requests to each repository with, keyed by lower-cased "owner/name".
+	repositoryClients map[string]*http.Client
+	// tokenClient is the HTTP client given by WithToken to build the GraphQL API client with.
+	tokenClient        *http.Client
+	graphqlURL         string
+	rootCAFiles        []string
+	clientCertFile     string
+	clientKeyFile      string
+	bundleExport       string
+	bundleSigningKey   crypto.Signer
+	bundlePath         string
+	bundleVerifyingKey crypto.PublicKey
+	// bundle is the bundle given by WithBundle, which is served instead of the files on GitHub.
+	bundle *bundle
+	// bundled holds each BotType's files to write to the bundle given by WithBundleExport.
+	bundled          map[sarah.BotType]*bundleEntry
+	bundleMutex      sync.Mutex
+	changeRateLimit  int
+	changeRateWindow time.Duration
+	// changes holds the recent change times of each configuration. This is only accessed by the operating goroutine.
+	changes map[string][]time.Time
+	// rateAlerted holds the configurations whose excessive change rates are already reported. This is only accessed by the operating goroutine.
+	rateAlerted    map[string]bool
+	localAllowlist string
+	// disallowed holds the contents already reported as not in the allowlist. This is only accessed by the operating goroutine.
+	// The entries for the contents no longer fetched are dropped by pruneChecks.
+	disallowed             map[string]bool
+	branchProtectionCheck  bool
+	strictBranchProtection bool
+	tokenPermissionCheck   bool
+	strictTokenPermission  bool
+	redaction              func(string) string
+	auditSink              AuditSink
+	metricsRegisterer      prometheus.Registerer
+	metrics                *metrics
+	commitStatusName       string
+	deploymentEnvironment  string
+	failureComment         bool
+	// commented holds the contents of the files whose failures are already commented. This is only accessed by the operating goroutine.
+	commented map[string]bool
+	// reportedCommits holds the commits whose statuses are already set. This is only accessed by the operating goroutine.
+	reportedCommits map[string]bool
+	// sensitive holds the lower-cased keys of the sensitive fields by BotType and id.
+	sensitive      map[sarah.BotType]map[string]map[string]bool
+	sensitiveMutex sync.RWMutex
+	// approvals holds the result of the code owner approval check of each file content. This is only accessed by the operating goroutine.
+	// The entries for the contents no longer fetched are dropped by pruneChecks.
+	approvals map[string]error
+	// rejected holds the revision of each file whose DecodeError is already reported. This is only accessed by the operating goroutine.
+	rejected          map[sarah.BotType]map[string]string
+	appVersion        string
+	defaultExtension  string
+	exportDir         string
+	selfConfiguration bool
+	// selfConfigRef and selfConfigID are the branch the self-configuration file is read from and the object ID of the file Start applies.
+	selfConfigRef string
+	selfConfigID  string
+	// startPaused is the paused state the self-configuration file declares when Start applies it.
+	startPaused      bool
+	request          chan *request
+	snapshot         chan *snapshotRequest
+	subscription     chan *subscription
+	unsubscription   chan sarah.BotType
+	idUnsubscription chan *subscription
+	cancellation     chan *subscription
+	pausing          chan bool
+	reconfiguration  chan *reconfiguration
+	status           chan *statusRequest
+	verification     chan *verificationRequest
+	// events is created on the first call to Events, so no event is built or buffered without a consumer.
+	events      chan Event
+	eventsMutex sync.Mutex
+	maxDiffSize int
+	// mutex guards cancel.
+	mutex  sync.Mutex
+	cancel context.CancelFunc
+	// configMutex guards the fields of config that can be updated at runtime.
+	configMutex sync.RWMutex
+	// pins holds the commit each BotType is rolled back to. This is guarded by configMutex.
+	pins map[sarah.BotType]string
+	// started is set when the operating goroutine is started.
+	started atomic.Bool
+	// done is closed when the operating goroutine returns.
+	done chan struct{}
+	// callbacks tracks the running subscriber callbacks so Stop can wait for them.
+	callbacks sync.WaitGroup
+	health    health
+	// pathResolver returns the directory of each BotType's configuration files when set.
+	pathResolver func(botType sarah.BotType) string
+	flatLayout   bool
+	// globs is guarded by configMutex since the self-configuration file may replace it at runtime.
+	globs        globs
+	extensions   []string
+	normalizeIDs bool
+	// fileNameMapper returns the candidate file names for an id when set.
+	fileNameMapper  func(botType sarah.BotType, id string) []string
+	kindDirectories bool
+	submodules      bool
+	decoders        map[string]Decoder
+	rest            *restClient
+	lfsURL          string
+	// discovery finds the repositories whose configuration files are aggregated when set.
+	discovery *discovery
+}
+
+// Watcher is a sarah.ConfigWatcher implementation that subscribes to configuration files on a GitHub repository.
+// In addition to the methods sarah.ConfigWatcher provides, this gives access to the fetched configuration files.
+type Watcher interface {
+	sarah.ConfigWatcher
+
+	// Start starts polling the repository.
+	// The watcher stops when the given context is canceled or Stop is called.
+	// With WithBranchProtectionCheck, this first checks the protection of the configured branch.
+	// Reading and watching before Start return WatcherNotStarted instead of blocking.
+	Start(ctx context.Context) error
+
+	// Stop stops polling the repository and ends all subscriptions.
+	// This blocks until the running subscriber callbacks return, so in-flight reloads are not cut off.
+	Stop() error
+
+	// Pause temporarily stops applying configuration changes, e.g. during an incident.
+	// Subscriptions and the cache remain intact, and Read keeps returning the cached configuration.
+	Pause() error
+
+	// Resume resumes applying configuration changes paused by Pause.
+	// Changes made while paused are applied on the next refresh.
+	Resume() error
+
+	// SetInterval updates the polling interval of the running watcher.
+	SetInterval(interval time.Duration) error
+
+	// Rollback pins the given BotType's configuration files to the given commit SHA, or any other Git ref, until it is called again,
+	// so a bad configuration push can be reverted from chat without waiting for a git revert to land.
+	// The subscribers are notified of the differences immediately unless the watcher is paused.
+	// An empty SHA releases the pin and the BotType follows its branch again.
+	// This fails without pinning when the configuration files cannot be fetched at the given commit.
+	Rollback(ctx context.Context, botType sarah.BotType, sha string) error
+
+	// SetBranch updates the branch to fetch configuration files from without a restart.
+	// A BotType with its own branch in Config.Sources keeps fetching from that branch.
+	// The subscribed BotTypes are refreshed immediately so the subscribers are notified of the differences between the branches,
+	// and the cache of the other BotTypes is invalidated.
+	SetBranch(branch string) error
+
+	// FS returns an fs.FS view of the configuration files for the given BotType.
+	// Each file is located at its path relative to the BotType's directory, so a subdirectory is served as a directory.
+	FS(botType sarah.BotType) fs.FS
+
+	// ReadRaw returns the undecoded content of the configuration file along with its metadata.
+	// This is useful when the caller needs custom parsing or wants to display the raw file.
+	ReadRaw(ctx context.Context, botType sarah.BotType, id string) ([]byte, *FileInfo, error)
+
+	// WatchContext subscribes to the given id's configuration just like Watch does.
+	// The callback receives a context that is canceled when the watcher stops, and the id of the changed configuration,
+	// so the subscriber can bound its own reload work and cancel it on shutdown.
+	// The returned Subscription can be used to manage this individual subscription.
+	WatchContext(ctx context.Context, botType sarah.BotType, id string, callback func(ctx context.Context, id string)) (*Subscription, error)
+
+	// WatchAll subscribes to all configuration files of the given BotType.
+	// The callback is called with the id of any added, updated or removed file,
+	// so generic plumbing such as metrics or configuration auditors does not have to enumerate every id up front.
+	WatchAll(ctx context.Context, botType sarah.BotType, callback func(ctx context.Context, id string)) (*Subscription, error)
+
+	// WatchChange subscribes to the given id's configuration just like WatchContext does.
+	// The callback receives a Change that describes the old and new object IDs and the commit that made the change,
+	// so a reload log can state who changed what.
+	WatchChange(ctx context.Context, botType sarah.BotType, id string, callback func(ctx context.Context, change *Change)) (*Subscription, error)
+
+	// WatchKeys subscribes to the given id's configuration just like WatchChange does,
+	// but the callback is called only when the value at any of the given keys differs.
+	// Keys are dot-separated paths such as "message" or "thresholds.max",
+	// so cosmetic edits elsewhere in the file do not trigger reloads.
+	// This filtering applies to the formats that decode into nested maps: YAML, JSON and CUE.
+	WatchKeys(ctx context.Context, botType sarah.BotType, id string, keys []string, callback func(ctx context.Context, change *Change)) (*Subscription, error)
+
+	// ReadAt reads the given id's configuration as of the given Git ref such as a commit SHA, a tag, or a branch.
+	// This does not touch the cached configuration, so it can be used for investigations
+	// such as "what was the configuration when that incident happened?"
+	ReadAt(ctx context.Context, botType sarah.BotType, id string, ref string, out interface{}) error
+
+	// ReadWith reads the given id's configuration just like Read does, with the given ReadOptions applied to this call only.
+	// e.g. AtRef previews the configuration on another branch without touching the cached configuration.
+	ReadWith(ctx context.Context, botType sarah.BotType, id string, out interface{}, options ...ReadOption) error
+
+	// History returns up to limit recent commits that touched the given id's configuration file, newest first.
+	// This returns no commit for a local override file since it has no history on the repository.
+	History(ctx context.Context, botType sarah.BotType, id string, limit int) ([]*Commit, error)
+
+	// Version returns the version of the cached configuration file so the caller can log or display
+	// exactly which version of the configuration is in effect.
+	Version(ctx context.Context, botType sarah.BotType, id string) (*Version, error)
+
+	// Status returns the current state of the watcher such as the last fetch results and the active subscriptions of each BotType,
+	// so health dashboards and admin commands have structured data to show.
+	Status(ctx context.Context) (*Status, error)
+
+	// Healthy tells if the watcher is healthy, which means the recent fetches of no BotType failed
+	// more times in a row than the tolerance given by WithHealthTolerance.
+	// This does not block, so it can be wired into liveness and readiness probes.
+	Healthy() bool
+
+	// LastError returns the error of the latest failed fetch among the BotTypes whose last fetch failed.
+	// This returns nil when the last fetch of every BotType succeeded.
+	LastError() error
+
+	// Events returns a channel that emits events on fetches, changes, deletions and fetch failures.
+	// This is an alternative to callbacks that composes well with select-based consumers.
+	// Changes and deletions are detected only for the BotTypes with at least one subscription.
+	// The channel is created on the first call, and the events before that are not delivered.
+	// An event is dropped when the consumer does not keep up with the buffered channel.
+	Events() <-chan Event
+
+	// UnwatchID stops the subscription to the given id's configuration.
+	// Unlike Unwatch, subscriptions to other ids of the same BotType remain intact.
+	UnwatchID(botType sarah.BotType, id string) error
+
+	// List returns the sorted ids of the configuration files currently available for the given BotType.
+	List(ctx context.Context, botType sarah.BotType) ([]string, error)
+
+	// VerifyWatched checks that every watched id has its configuration file, fetching the BotTypes that are not fetched yet.
+	// Call this after go-sarah registers the Commands and ScheduledTasks to fail fast at startup
+	// instead of letting them silently run on their compiled-in defaults.
+	// A missing configuration is only logged unless WithStrictStartup is given.
+	VerifyWatched(ctx context.Context) error
+
+	// Verify fetches and decodes the configurations of the given ids by BotType, and returns a VerificationError
+	// that reports every configuration that is missing or fails to decode.
+	// Each configuration is decoded into a copy of the prototype given by WithPrototype, if any.
+	// This does not require the watcher to be started, so it suits CI and boot-time sanity checks.
+	Verify(ctx context.Context, configs map[sarah.BotType][]string) error
+}
+
+var _ Watcher = (*watcher)(nil)
+
+func (w *watcher) Read(ctx context.Context, botType sarah.BotType, id string, out interface{}) error {
+	err := make(chan error, 1)
+	req := &request{
+		ctx:     ctx,
+		botType: botType,
+		id:      id,
+		err:     err,
+		out:     out,
+	}
+
+	running := w.running()
+	if running != nil {
+		return running
+	}
+
+	timer := time.NewTimer(w.config.TimeOut)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return &CanceledError{BotType: botType, ID: id, Err: ctx.Err()}
+
+	case <-timer.C:
+		return SubscriptionTimeout
+
+	case <-w.done:
+		return WatcherStopped
+
+	case w.request <- req:
+		// Successfully enqueued
+
+	}
+
+	select {
+	case <-ctx.Done():
+		return &CanceledError{BotType: botType, ID: id, Err: ctx.Err()}
+
+	case <-timer.C:
+		return SubscriptionTimeout
+
+	case <-w.done:
+		return WatcherStopped
+
+	case e := <-err:
+		return e
+
+	}
+}
+
+func (w *watcher) Watch(ctx context.Context, botType sarah.BotType, id string, callback func()) error {
+	_, err := w.WatchContext(ctx, botType, id, func(_ context.Context, _ string) {
+		callback()
+	})
+	return err
+}
+
+func (w *watcher) WatchContext(ctx context.Context, botType sarah.BotType, id string, callback func(ctx context.Context, id string)) (*Subscription, error) {
+	return w.subscribe(ctx, &subscription{
+		botType: botType,
+		id:      id,
+		callback: func(ctx context.Context, change *Change) {
+			callback(ctx, change.ID)
+		},
+		done: make(chan struct{}),
+	})
+}
+
+func (w *watcher) WatchChange(ctx context.Context, botType sarah.BotType, id string, callback func(ctx context.Context, change *Change)) (*Subscription, error) {
+	return w.subscribe(ctx, &subscription{
+		botType:    botType,
+		id:         id,
+		callback:   callback,
+		withCommit: true,
+		done:       make(chan struct{}),
+	})
+}
+
+func (w *watcher) subscribe(ctx context.Context, s *subscription) (*Subscription, error) {
+	err := w.running()
+	if err != nil {
+		return nil, err
+	}
+
+	select {
+	case <-ctx.Done():
+		return nil, &CanceledError{BotType: s.botType, ID: s.id, Err: ctx.Err()}
+
+	case <-w.done:
+		return nil, WatcherStopped
+
+	case w.subscription <- s:
+		return &Subscription{
+			watcher:      w,
+			subscription: s,
+		}, nil
+
+	}
+}
+
+func (w *watcher) WatchAll(ctx context.Context, botType sarah.BotType, callback func(ctx context.Context, id string)) (*Subscription, error) {
+	return w.WatchContext(ctx, botType, allIDs, callback)
+}
+
+func (w *watcher) Unwatch(botType sarah.BotType) error {
+	err := w.running()
+	if err != nil {
+		return err
+	}
+
+	select {
+	case <-w.done:
+		return WatcherStopped
+
+	case w.unsubscription <- botType:
+		return nil
+
+	}
+}
+
+func (w *watcher) UnwatchID(botType sarah.BotType, id string) error {
+	s := &subscription{
+		botType: botType,
+		id:      id,
+	}
+
+	err := w.running()
+	if err != nil {
+		return err
+	}
+
+	select {
+	case <-w.done:
+		return WatcherStopped
+
+	case w.idUnsubscription <- s:
+		return nil
+
+	}
+}
+
+func (w *watcher) Pause() error {
+	return w.setPaused(true)
+}
+
+func (w *watcher) Resume() error {
+	return w.setPaused(false)
+}
+
+func (w *watcher) setPaused(paused bool) error {
+	err := w.running()
+	if err != nil {
+		return err
+	}
+
+	select {
+	case <-w.done:
+		return WatcherStopped
+
+	case w.pausing <- paused:
+		return nil
+
+	}
+}
+
+func (w *watcher) SetInterval(interval time.Duration) error {
+	if interval <= 0 {
+		return fmt.Errorf("interval must be positive: %s", interval)
+	}
+	return w.reconfigure(&reconfiguration{interval: interval})
+}
+
+func (w *watcher) SetBranch(branch string) error {
+	if branch == "" {
+		return errors.New("branch must not be empty")
+	}
+	return w.reconfigure(&reconfiguration{branch: branch})
+}
+
+func (w *watcher) reconfigure(r *reconfiguration) error {
+	err := w.running()
+	if err != nil {
+		return err
+	}
+
+	select {
+	case <-w.done:
+		return WatcherStopped
+
+	case w.reconfiguration <- r:
+		return nil
+
+	}
+}
+
+// running returns WatcherNotStarted before Start is called, and WatcherStopped after the watcher stops,
+// so a request to the loop that owns the cache and the subscriptions never blocks while the loop is not running.
+func (w *watcher) running() error {
+	select {
+	case <-w.done:
+		return WatcherStopped
+
+	default:
+		if !w.started.Load() {
+			return WatcherNotStarted
+		}
+		return nil
+
+	}
+}
+
+// branch returns the branch to fetch configuration files from.
+func (w *watcher) branch() string {
+	w.configMutex.RLock()
+	defer w.configMutex.RUnlock()
+	return w.config.Branch
+}
+
+func (w *watcher) Start(ctx context.Context) error {
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+
+	if w.cancel != nil {
+		return errors.New("watcher is already started")
+	}
+
+	err := w.loadSelfConfig(ctx)
+	if err != nil {
+		return err
+	}
+
+	err = w.checkBranchProtection(ctx)
+	if err != nil {
+		return err
+	}
+
+	err = w.checkTokenPermission(ctx)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	w.cancel = cancel
+	w.started.Store(true)
+	go func() {
+		defer close(w.done)
+		w.operate(ctx)
+	}()
+
+	return nil
+}
+
+func (w *watcher) Stop() error {
+	w.mutex.Lock()
+	cancel := w.cancel
+	w.mutex.Unlock()
+
+	if cancel == nil {
+		return errors.New("watcher is not started")
+	}
+
+	cancel()
+	<-w.done
+	w.callbacks.Wait()
+
+	return nil
+}
+
+// apply applies the given settings except the paused state, and tells if the fetched files may change
+// for a branch switch, a rollback, or a filter change.
+func (w *watcher) apply(r *reconfiguration) bool {
+	w.configMutex.Lock()
+	defer w.configMutex.Unlock()
+
+	if r.interval > 0 {
+		w.config.Interval = r.interval
+	}
+	changed := r.branch != "" && r.branch != w.config.Branch
+	if changed {
+		w.config.Branch = r.branch
+	}
+	if r.rollback != nil {
+		w.pin(r.rollback.botType, r.rollback.sha)
+		changed = true
+	}
+	if r.globs != nil && !reflect.DeepEqual(*r.globs, w.globs) {
+		w.globs = *r.globs
+		changed = true
+	}
+
+	policyChanged := false
+	if r.requiredApprovals != nil && *r.requiredApprovals != w.requiredApprovals {
+		w.requiredApprovals = *r.requiredApprovals
+		policyChanged = true
+	}
+	if r.codeOwnerApproval != nil && *r.codeOwnerApproval != w.codeOwnerApproval {
+		w.codeOwnerApproval = *r.codeOwnerApproval
+		policyChanged = true
+	}
+	if policyChanged {
+		// The memorized results no longer tell if the changes satisfy the policy.
+		w.approvals = nil
+	}
+
+	return changed
 }
 
-func NewConfig(owner string, name string, baseDir string) *Config {
-	return &Config{
-		Owner:    owner,
-		Name:     name,
-		BaseDir:  baseDir,
-		Branch:   "master",
-		Interval: 1 * time.Minute,
-		TimeOut:  5 * time.Second,
+func (w *watcher) operate(ctx context.Context) {
+	cache := map[sarah.BotType]map[string]*file{}
+	subscribed := subscriptions{}
+	fetches := statuses{}
+	paused := w.startPaused
+
+	ticker := time.NewTicker(w.config.Interval)
+	defer ticker.Stop()
+
+	// Read the self-configuration file from the initial branch even after the branch is switched.
+	selfConfigRef := w.selfConfigRef
+	if selfConfigRef == "" {
+		selfConfigRef = w.branch()
+	}
+	selfConfigID := w.selfConfigID
+
+	// reconfigure applies the given settings and tells if the subscribed BotTypes are refreshed
+	// for a branch switch, a rollback, or a filter change.
+	reconfigure := func(r *reconfiguration) bool {
+		changed := w.apply(r)
+		if r.interval > 0 {
+			ticker.Reset(r.interval)
+		}
+
+		if r.paused != nil {
+			paused = *r.paused
+		}
+
+		if !changed {
+			return false
+		}
+
+		for botType := range cache {
+			if _, ok := subscribed[botType]; !ok {
+				delete(cache, botType)
+			}
+		}
+		if paused {
+			return false
+		}
+		w.refresh(ctx, cache, subscribed, fetches)
+		return true
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			subscribed.removeAll()
+			return
+
+		case s := <-w.subscription:
+			subscribed.add(s)
+
+		case botType := <-w.unsubscription:
+			delete(cache, botType)
+			subscribed.removeBotType(botType)
+			w.health.forget(botType)
+			w.pruneChecks(botType, nil)
+
+		case s := <-w.idUnsubscription:
+			// Keep the cache since other subscribers of the same BotType may still read it.
+			subscribed.remove(s.botType, s.id)
+
+		case s := <-w.cancellation:
+			subscribed.cancel(s)
+
+		case p := <-w.pausing:
+			paused = p
+
+		case r := <-w.reconfiguration:
+			reconfigure(r)
+
+		case req := <-w.request:
+			files, err := w.cached(ctx, cache, fetches, req.botType)
+			if err != nil {
+				req.err <- err
+				continue
+			}
+
+			f := files[w.resolve(req.botType, files, req.id)]
+			if f == nil {
+				req.err <- &sarah.ConfigNotFoundError{
+					BotType: req.botType,
+					ID:      req.id,
+				}
+				continue
+			}
+
+			w.learnSensitiveKeys(req.botType, f.id, req.out)
+			if f.invalid != nil {
+				req.err <- w.redactError(req.botType, f, f.invalid)
+				continue
+			}
+
+			req.err <- w.redactError(req.botType, f, w.read(req.ctx, req.botType, f, req.out))
+
+		case req := <-w.status:
+			req.result <- fetches.build(w.branch(), paused, cache, subscribed)
+
+		case req := <-w.verification:
+			req.result <- w.verify(ctx, cache, fetches, subscribed)
+
+		case req := <-w.snapshot:
+			files, err := w.cached(ctx, cache, fetches, req.botType)
+			req.result <- &snapshot{
+				files: files,
+				err:   err,
+			}
+
+		case <-ticker.C:
+			if w.selfConfiguration {
+				r, id, err := w.getSelfConfig(ctx, selfConfigRef, selfConfigID)
+				if err != nil {
+					w.log("", "").Errorf("Failed to fetch %s: %+v", selfConfigFileName, err)
+				}
+				selfConfigID = id
+				if r != nil && reconfigure(r) {
+					// Already refreshed on the branch switch.
+					continue
+				}
+			}
+
+			if paused {
+				continue
+			}
+
+			w.refresh(ctx, cache, subscribed, fetches)
+
+		}
+	}
+}
+
+// refresh fetches the configuration files of the subscribed BotTypes and notifies the subscribers of the changes.
+func (w *watcher) refresh(ctx context.Context, cache map[sarah.BotType]map[string]*file, subscribed subscriptions, fetches statuses) {
+	for botType, sub := range subscribed {
+		started := time.Now()
+		files, err := w.get(ctx, botType)
+		fetches.record(botType, files, err)
+		w.health.record(botType, err)
+		if err != nil {
+			w.log(botType, "", durationAttr(time.Since(started))).Errorf("Failed to fetch configuration files for %s: %+v", botType, err)
+			w.emit(Event{Type: EventError, BotType: botType, Err: err})
+			continue
+		}
+		w.emit(Event{Type: EventFetched, BotType: botType})
+		w.pruneChecks(botType, files)
+
+		for id, f := range files {
+			if f.invalid == nil {
+				continue
+			}
+
+			// Refuse to apply the invalid content and keep the previous one effective.
+			w.log(botType, id, commitAttr(f.commitSHA)).Warnf("Refusing to apply invalid configuration: %+v", w.redactError(botType, f, f.invalid))
+			w.reportDecodeError(botType, f)
+			w.commentFailure(ctx, botType, f, f.invalid)
+			if old, ok := cache[botType][id]; ok {
+				files[id] = old
+			}
+		}
+
+		err = w.enforceAllowlist(ctx, botType, cache[botType], files)
+		if err != nil {
+			w.log(botType, "").Errorf("Failed to check configuration files for %s against the allowlist: %+v", botType, err)
+			w.emit(Event{Type: EventError, BotType: botType, Err: err})
+			continue
+		}
+
+		old, ok := cache[botType]
+		w.enforceApprovalPolicy(ctx, botType, old, files)
+
+		resolved := w.resolveSubscriptions(botType, sub, files)
+		if !ok {
+			// No configuration has been served for the BotType, e.g. its directory did not exist when the subscriber read it.
+			// Treat the watched files as added so the subscribers that started with defaults pick them up.
+			old = map[string]*file{}
+			for id, f := range files {
+				if _, watched := resolved[id]; !watched {
+					old[id] = f
+				}
+			}
+		}
+		w.audit(ctx, botType, cache[botType], files)
+		cache[botType] = files
+		w.observeFetch(botType, files)
+
+		// Dispatch a goroutine to let the subscriber read the configuration.
+		// In this way, a developer may call watcher.Read() in the callback.
+		// A case with "<-w.request" blocks in watcher.Read() call, otherwise.
+		ids := changedIDs(old, files)
+		now := time.Now()
+		for _, id := range ids {
+			w.trackChangeRate(botType, id, now)
+			var subs []*subscription
+			if files[id] != nil {
+				w.reportConflicts(botType, files[id])
+				w.shadowEvaluate(ctx, botType, files[id])
+				subs = append(subs, resolved[id]...)
+			}
+			subs = append(subs, sub[allIDs]...)
+			if len(subs) == 0 && w.eventChannel() == nil {
+				continue
+			}
+
+			change := w.newChange(ctx, botType, id, old[id], files[id], withCommit(subs))
+			for _, s := range subs {
+				if len(s.keys) > 0 && !w.keysChanged(botType, old[id], files[id], s.keys) {
+					continue
+				}
+				w.observeCallback(botType)
+				w.callbacks.Add(1)
+				go func(s *subscription) {
+					defer w.callbacks.Done()
+					s.callback(ctx, change)
+				}(s)
+			}
+			w.emit(changeEvent(change))
+		}
+		w.reportApplied(ctx, botType, files, ids)
+	}
+	w.blobs.rotate()
+}
+
+// pruneChecks drops the allowlist and approval check results of the given BotType except the ones for the given files' contents,
+// so the results for the contents no longer in the repository do not pile up.
+func (w *watcher) pruneChecks(botType sarah.BotType, files map[string]*file) {
+	current := map[string]bool{}
+	for id, f := range files {
+		current[disallowedKey(botType, id, f)] = true
+		for _, layer := range f.inputs() {
+			current[approvalKey(botType, layer)] = true
+		}
+	}
+
+	prefix := string(botType) + ":"
+	for key := range w.disallowed {
+		if strings.HasPrefix(key, prefix) && !current[key] {
+			delete(w.disallowed, key)
+		}
+	}
+	for key := range w.approvals {
+		if strings.HasPrefix(key, prefix) && !current[key] {
+			delete(w.approvals, key)
+		}
+	}
+}
+
+// changedIDs returns the ids of the files that are added, updated, or removed.
+func changedIDs(old map[string]*file, files map[string]*file) []string {
+	var ids []string
+	for id, f := range files {
+		o, ok := old[id]
+		if !ok || o.revision() != f.revision() {
+			ids = append(ids, id)
+		}
+	}
+
+	for id := range old {
+		if _, ok := files[id]; !ok {
+			ids = append(ids, id)
+		}
+	}
+
+	return ids
+}
+
+// cached returns the cached files for the given BotType or fetches them when those are not cached yet.
+func (w *watcher) cached(ctx context.Context, cache map[sarah.BotType]map[string]*file, fetches statuses, botType sarah.BotType) (map[string]*file, error) {
+	files, ok := cache[botType]
+	w.observeCache(botType, ok)
+	if ok {
+		return files, nil
+	}
+
+	files, err := w.get(ctx, botType)
+	if err == nil {
+		err = w.enforceAllowlist(ctx, botType, nil, files)
+	}
+	if err == nil {
+		// Refuse the unapproved files on the first fetch as well, e.g. one merged while the bot was down.
+		w.enforceApprovalPolicy(ctx, botType, nil, files)
+	}
+	fetches.record(botType, files, err)
+	w.health.record(botType, err)
+	if err != nil {
+		w.emit(Event{Type: EventError, BotType: botType, Err: err})
+		return nil, err
+	}
+	cache[botType] = files
+	w.observeFetch(botType, files)
+	w.audit(ctx, botType, nil, files)
+	w.emit(Event{Type: EventFetched, BotType: botType})
+	for _, f := range files {
+		w.reportConflicts(botType, f)
+	}
+	return files, nil
+}
+
+// snapshotFiles returns the cached files for the given BotType.
+// The returned map must be treated as read-only since it is shared with the operating goroutine.
+func (w *watcher) snapshotFiles(ctx context.Context, botType sarah.BotType) (map[string]*file, error) {
+	result := make(chan *snapshot, 1)
+	req := &snapshotRequest{
+		botType: botType,
+		result:  result,
+	}
+
+	err := w.running()
+	if err != nil {
+		return nil, err
+	}
+
+	timer := time.NewTimer(w.config.TimeOut)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return nil, &CanceledError{BotType: botType, Err: ctx.Err()}
+
+	case <-timer.C:
+		return nil, SubscriptionTimeout
+
+	case <-w.done:
+		return nil, WatcherStopped
+
+	case w.snapshot <- req:
+		// Successfully enqueued
+
+	}
+
+	select {
+	case <-ctx.Done():
+		return nil, &CanceledError{BotType: botType, Err: ctx.Err()}
+
+	case <-timer.C:
+		return nil, SubscriptionTimeout
+
+	case <-w.done:
+		return nil, WatcherStopped
+
+	case s := <-result:
+		return s.files, s.err
+
+	}
+}
+
+func (w *watcher) read(ctx context.Context, botType sarah.BotType, f *file, out interface{}) error {
+	// Decoding the defaults and then the file into the same value deep-merges the file over the defaults.
+	// The fragments and the overlay are merged over the file in the same way.
+	for _, layer := range f.layers() {
+		err := w.decode(botType, layer, out)
+		if err != nil {
+			return &DecodeError{BotType: botType, ID: f.id, FileName: layer.fileName, Err: err}
+		}
+	}
+
+	err := w.resolveSecrets(ctx, botType, f, out)
+	if err != nil {
+		return err
+	}
+
+	if w.postDecodeHook != nil {
+		err := w.postDecodeHook(botType, f.id, out)
+		if err != nil {
+			return fmt.Errorf("invalid configuration for %s:%s: %w", botType, f.id, err)
+		}
+	}
+
+	return nil
+}
+
+func (w *watcher) decode(botType sarah.BotType, f *file, out interface{}) error {
+	f, err := w.decryptContent(botType, f)
+	if err != nil {
+		return err
+	}
+
+	f, err = w.decrypt(f)
+	if err != nil {
+		return err
+	}
+
+	if w.preDecodeHook != nil {
+		content, err := w.preDecodeHook(botType, f.id, []byte(f.content))
+		if err != nil {
+			return fmt.Errorf("failed to pre-process %s: %w", f.fileName, err)
+		}
+		transformed := *f
+		transformed.content = string(content)
+		f = &transformed
+	}
+
+	if w.interpolate {
+		f = interpolate(f)
+	}
+
+	if decoder, ok := w.decoders[f.extension]; ok {
+		return decoder([]byte(f.content), out)
+	}
+
+	switch f.extension {
+	case ".yml", ".yaml":
+		return w.decodeYAML(f, out)
+
+	case ".json":
+		return w.decodeJSON([]byte(f.content), out)
+
+	case ".jsonc", ".json5":
+		b, err := standardizeJSON([]byte(f.content))
+		if err != nil {
+			return fmt.Errorf("failed to parse %s: %w", f.fileName, err)
+		}
+		return w.decodeJSON(b, out)
+
+	case ".cue":
+		return decodeCUE(f, out)
+
+	case ".ini":
+		return ini.MapTo(out, []byte(f.content))
+
+	case ".env":
+		values, err := parseEnv(f.content)
+		if err != nil {
+			return fmt.Errorf("failed to parse %s: %w", f.fileName, err)
+		}
+		return decodeFlat(values, out, "env")
+
+	case ".properties":
+		values, err := parseProperties(f.content)
+		if err != nil {
+			return fmt.Errorf("failed to parse %s: %w", f.fileName, err)
+		}
+		return decodeFlat(values, out, "properties")
+
+	case "":
+		if w.sniffing {
+			return w.decode(botType, w.sniff(f), out)
+		}
+		return fmt.Errorf("unsupported file extension for %s: %s", f.id, f.extension)
+
+	default:
+		return fmt.Errorf("unsupported file extension for %s: %s", f.id, f.extension)
+
+	}
+}
+
+func (w *watcher) get(ctx context.Context, botType sarah.BotType) (map[string]*file, error) {
+	var files map[string]*file
+	var schemas []entry
+	var found bool
+	if w.bundle != nil {
+		files, schemas, found = w.bundle.files(botType)
+	} else {
+		var err error
+		files, schemas, found, err = w.fetchAll(ctx, botType)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if w.localDir != "" {
+		local, err := w.readLocal(botType)
+		if err != nil {
+			return nil, err
+		}
+		for id, f := range local {
+			files[id] = f
+		}
+	}
+
+	// A directory whose files are all skipped, e.g. by WithExclude, exists with no configuration file.
+	if len(files) == 0 && !found {
+		return nil, w.directoryNotFound(botType)
+	}
+
+	if w.exportDir != "" {
+		err := export(filepath.Join(w.exportDir, botType.String()), files)
+		if err != nil {
+			return nil, err
+		}
 	}
-}
 
-type watcher struct {
-	client         querier
-	config         *Config
-	request        chan *request
-	subscription   chan *subscription
-	unsubscription chan sarah.BotType
+	return w.assemble(botType, files, schemas)
 }
 
-var _ sarah.ConfigWatcher = (*watcher)(nil)
-
-func (w *watcher) Read(_ context.Context, botType sarah.BotType, id string, out interface{}) error {
-	err := make(chan error)
-	req := &request{
-		botType: botType,
-		id:      id,
-		err:     err,
-		out:     out,
+// fetchAll fetches the configuration files of the given BotType from every repository they are aggregated from, along with their JSON Schemas.
+// This also tells if the BotType's directory exists in its own repository.
+func (w *watcher) fetchAll(ctx context.Context, botType sarah.BotType) (map[string]*file, []entry, bool, error) {
+	files, schemas, found, err := w.fetchBranches(ctx, botType)
+	if err != nil {
+		return nil, nil, false, err
 	}
-	w.request <- req
 
-	select {
-	case <-time.NewTimer(w.config.TimeOut).C:
-		return SubscriptionTimeout
+	if w.discovery != nil {
+		err := w.fetchDiscovered(ctx, botType, files)
+		if err != nil {
+			return nil, nil, false, err
+		}
+	}
 
-	case e := <-err:
-		return e
+	if len(w.config.Layers) > 0 {
+		err := w.fetchLayers(ctx, botType, files)
+		if err != nil {
+			return nil, nil, false, err
+		}
+	}
 
+	err = w.exportBundle(botType, files, schemas)
+	if err != nil {
+		return nil, nil, false, err
 	}
+
+	return files, schemas, found, nil
 }
 
-func (w *watcher) Watch(_ context.Context, botType sarah.BotType, id string, callback func()) error {
-	s := &subscription{
-		botType:  botType,
-		id:       id,
-		callback: callback,
+// fetch fetches the configuration files of the given BotType and their JSON Schemas as of the given Git ref,
+// and tells if the BotType's directory exists.
+// When Config.Environment is set, a file missing in the environment's directory falls back to the one in the BotType's directory.
+func (w *watcher) fetch(ctx context.Context, botType sarah.BotType, ref string) (map[string]*file, []entry, bool, error) {
+	src := w.source(botType)
+	files, schemas, found, err := w.fetchDir(ctx, botType, src, ref, w.directory(botType))
+	if err != nil {
+		return nil, nil, false, err
 	}
-	w.subscription <- s
-	return nil
-}
 
-func (w *watcher) Unwatch(botType sarah.BotType) error {
-	w.unsubscription <- botType
-	return nil
-}
+	fallback, ok := w.fallbackDirectory(botType)
+	if !ok {
+		return files, schemas, found, nil
+	}
 
-func (w *watcher) operate(ctx context.Context) {
-	cache := map[sarah.BotType]map[string]*file{}
-	subscription := map[sarah.BotType]map[string]func(){}
+	base, _, baseFound, err := w.fetchDir(ctx, botType, src, ref, fallback)
+	if err != nil {
+		return nil, nil, false, err
+	}
+	for id, f := range base {
+		if _, ok := files[id]; !ok {
+			files[id] = f
+		}
+	}
 
-	ticker := time.NewTicker(w.config.Interval)
-	defer ticker.Stop()
+	return files, schemas, found || baseFound, nil
+}
 
-	for {
-		select {
-		case <-ctx.Done():
-			return
+// fetchDir fetches the configuration files located in the given directory of the given repository
+// and the JSON Schemas of the given BotType as of the given Git ref, and tells if the directory exists.
+func (w *watcher) fetchDir(ctx context.Context, botType sarah.BotType, src *Source, ref string, dir string) (map[string]*file, []entry, bool, error) {
+	q := &query{}
+	variables := map[string]interface{}{
+		"owner":      githubv4.String(src.Owner),
+		"name":       githubv4.String(src.Name),
+		"ref":        githubv4.String(ref),
+		"expression": githubv4.String(expression(ref, dir)),
+		"manifest":   githubv4.String(expression(ref, path.Join(src.BaseDir, manifestFileName))),
+		"schemas":    githubv4.String(expression(ref, path.Join(src.BaseDir, schemasDir, botType.String()))),
+	}
+	err := w.client.Query(ctx, q, variables)
+	if err != nil {
+		return nil, nil, false, w.queryError(botType, repositoryName(src.Owner, src.Name), err)
+	}
 
-		case s := <-w.subscription:
-			_, ok := subscription[s.botType]
-			if !ok {
-				subscription[s.botType] = map[string]func(){}
-			}
-			subscription[s.botType][s.id] = s.callback
+	now := time.Now()
+	commitSHA := string(q.Repository.Head.Commit.Oid)
+	files := map[string]*file{}
+	var entries []entry
+	for _, entry := range q.Repository.Object.Tree.Entries {
+		if w.flatLayout && reserved(string(entry.Name)) {
+			continue
+		}
+		entries = append(entries, entry)
+	}
+	root := treeLocation{owner: src.Owner, name: src.Name, ref: ref, dir: dir}
+	tw := w.newTreeWalk(botType, root, string(q.Repository.Object.Tree.Oid))
+	entries, err = w.walk(ctx, tw, root, "", treeNodes(entries), 0)
+	if err != nil {
+		return nil, nil, false, err
+	}
 
-		case botType := <-w.unsubscription:
-			delete(cache, botType)
-			delete(subscription, botType)
+	for _, entry := range entries {
+		f := w.newFile(string(entry.Name), string(entry.Object.Blob.Oid), string(entry.Object.Blob.Text))
+		f.id = w.kindless(f.id)
+		f.path = path.Join(dir, string(entry.Name))
+		f.commitSHA = commitSHA
+		f.url = blobURL(q.Repository.URL, commitSHA, f.path)
+		f.fetchedAt = now
+		err := w.put(botType, files, f)
+		if err != nil {
+			return nil, nil, false, err
+		}
+	}
 
-		case req := <-w.request:
-			files, ok := cache[req.botType]
-			if !ok {
-				cache[req.botType] = map[string]*file{}
+	if manifest := string(q.Repository.Manifest.Blob.Text); manifest != "" {
+		mapped, err := w.getMapped(ctx, botType, src, ref, manifest)
+		if err != nil {
+			return nil, nil, false, err
+		}
+		for id, f := range mapped {
+			f.commitSHA = commitSHA
+			f.url = blobURL(q.Repository.URL, commitSHA, f.path)
+			files[id] = f
+		}
+	}
 
-				var err error
-				files, err = w.get(ctx, req.botType)
-				if err != nil {
-					req.err <- err
-					continue
-				}
-				cache[req.botType] = files
-			}
+	// Both the directory and the ref resolve to null when the ref is wrong.
+	// Otherwise, a missing directory is reported after the local override is applied.
+	if len(files) == 0 && commitSHA == "" {
+		return nil, nil, false, &RefOrPathNotFoundError{
+			BotType:    botType,
+			Repository: fmt.Sprintf("%s/%s", src.Owner, src.Name),
+			Expression: expression(ref, dir),
+		}
+	}
 
-			f := files[req.id]
-			if f == nil {
-				req.err <- &sarah.ConfigNotFoundError{
-					BotType: req.botType,
-					ID:      req.id,
-				}
+	return files, q.Repository.Schemas.Tree.Entries, q.Repository.Object.Tree.Oid != "", nil
+}
+
+// assemble expands the fetched files into configurations and wires the files each configuration depends on.
+func (w *watcher) assemble(botType sarah.BotType, files map[string]*file, schemas []entry) (map[string]*file, error) {
+	expanded := map[string]*file{}
+	for _, f := range files {
+		documents, err := expandDocuments(f)
+		if err != nil {
+			return nil, err
+		}
+		for _, document := range documents {
+			if !w.active(document) {
 				continue
 			}
 
-			req.err <- read(f, req.out)
+			err := w.put(botType, expanded, document)
+			if err != nil {
+				return nil, err
+			}
+		}
+	}
 
-		case <-ticker.C:
-			for botType, sub := range subscription {
-				files, err := w.get(ctx, botType)
-				if err != nil {
-					// TODO logging
-					continue
-				}
+	if anchors, ok := expanded[anchorsID]; ok {
+		delete(expanded, anchorsID)
+		for _, f := range expanded {
+			f.anchors = anchors
+		}
+	}
 
-				if _, ok := cache[botType]; !ok {
-					cache[botType] = files
-				}
+	mergeFragments(expanded)
 
-				for id, callback := range sub {
-					if f, ok := files[id]; ok {
-						old, ok := cache[botType][f.id]
-						if !ok || old.objectID != f.objectID {
-							// Dispatch a goroutine to let the subscriber read the configuration.
-							// In this way, a developer may call watcher.Read() in the callback.
-							// A case with "<-w.request" blocks in watcher.Read() call, otherwise.
-							go callback()
-						}
-					}
-				}
-				cache[botType] = files
+	if w.environment != "" {
+		suffix := "." + w.environment
+		for id, f := range expanded {
+			base, ok := expanded[strings.TrimSuffix(id, suffix)]
+			if !ok || !strings.HasSuffix(id, suffix) {
+				continue
 			}
-
+			base.overlay = f
+			delete(expanded, id)
 		}
 	}
-}
 
-func read(f *file, out interface{}) error {
-	switch f.extension {
-	case ".yml", ".yaml":
-		return yaml.Unmarshal([]byte(f.content), out)
-
-	case ".json":
-		return json.Unmarshal([]byte(f.content), out)
+	if defaults, ok := expanded[defaultsID]; ok {
+		delete(expanded, defaultsID)
+		for _, f := range expanded {
+			f.defaults = defaults
+		}
+	}
 
-	default:
-		return fmt.Errorf("unsupported file extension for %s: %s", f.id, f.extension)
+	w.validate(botType, expanded, schemas)
+	w.checkSyntax(botType, expanded)
 
-	}
+	return w.normalized(botType, expanded)
 }
 
-func (w *watcher) get(ctx context.Context, botType sarah.BotType) (map[string]*file, error) {
-	q := &query{}
-	dir := path.Join(w.config.BaseDir, botType.String())
-	expression := fmt.Sprintf("%s:%s", w.config.Branch, strings.TrimPrefix(dir, "/"))
-	variables := map[string]interface{}{
-		"owner":      githubv4.String(w.config.Owner),
-		"name":       githubv4.String(w.config.Name),
-		"expression": githubv4.String(expression),
-	}
-	err := w.client.Query(ctx, q, variables)
-	if err != nil {
-		return nil, fmt.Errorf("failed to query Github API: %w", err)
+// blobURL returns the URL of the file located at the given path of the repository as of the given commit.
+// An empty string is returned when the URL of the repository is unknown.
+func blobURL(repository githubv4.URI, commitSHA string, p string) string {
+	if repository.URL == nil || commitSHA == "" {
+		return ""
 	}
+	return fmt.Sprintf("%s/blob/%s/%s", strings.TrimSuffix(repository.String(), "/"), commitSHA, strings.TrimPrefix(p, "/"))
+}
 
-	files := map[string]*file{}
-	for _, entry := range q.Repository.Object.Tree.Entries {
-		name := string(entry.Name)
-		extension := filepath.Ext(name)
-		id := strings.TrimSuffix(name, extension)
-		cfg := &file{
-			id:        id,
-			fileName:  name,
-			extension: extension,
-			objectID:  string(entry.Object.Blob.Oid),
-			content:   string(entry.Object.Blob.Text),
-		}
-		files[id] = cfg
-	}
-	return files, nil
+// expression returns a Git object expression that points to the given path as of the given Git ref.
+func expression(ref string, p string) string {
+	return fmt.Sprintf("%s:%s", ref, strings.TrimPrefix(p, "/"))
 }
 
-func New(ctx context.Context, cfg *Config, opts ...Option) (sarah.ConfigWatcher, error) {
+// New constructs a Watcher with the given configuration and options.
+// Call Watcher.Start to start polling the repository before handing the watcher to sarah.RegisterConfigWatcher.
+func New(cfg *Config, opts ...Option) (Watcher, error) {
 	w := &watcher{
-		config:         cfg,
-		request:        make(chan *request),
-		subscription:   make(chan *subscription),
-		unsubscription: make(chan sarah.BotType),
+		config:           cfg,
+		request:          make(chan *request),
+		snapshot:         make(chan *snapshotRequest),
+		subscription:     make(chan *subscription),
+		unsubscription:   make(chan sarah.BotType),
+		idUnsubscription: make(chan *subscription),
+		cancellation:     make(chan *subscription),
+		pausing:          make(chan bool),
+		reconfiguration:  make(chan *reconfiguration),
+		status:           make(chan *statusRequest),
+		verification:     make(chan *verificationRequest),
+		maxDiffSize:      defaultMaxDiffSize,
+		done:             make(chan struct{}),
 	}
 	for _, opt := range opts {
 		opt(w)
 	}
+
+	err := w.loadBundle()
+	if err != nil {
+		return nil, err
+	}
+	err = w.validateBundleExport()
+	if err != nil {
+		return nil, err
+	}
+	err = w.validateSelfConfiguration()
+	if err != nil {
+		return nil, err
+	}
 	if w.client == nil {
 		return nil, errors.New("githubv4.Client must be derived from WithClient or WithToken option")
 	}
 
-	go w.operate(ctx)
+	err = w.configureTLS()
+	if err != nil {
+		return nil, err
+	}
+	if w.tokenClient != nil {
+		// Build again with the endpoint and the copy of the HTTP client that applies the TLS settings, regardless of the order of the options.
+		w.client = w.newGraphQLClient(w.tokenClient)
+	}
+	w.routeQueries()
+	err = w.registerMetrics()
+	if err != nil {
+		return nil, err
+	}
+
+	err = w.filters().validate()
+	if err != nil {
+		return nil, err
+	}
+
+	err = w.validateDecrypters()
+	if err != nil {
+		return nil, err
+	}
+
+	err = validatePathTemplate(cfg.PathTemplate)
+	if err != nil {
+		return nil, err
+	}
+	for _, src := range cfg.Sources {
+		if src == nil {
+			continue
+		}
+		err := validatePathTemplate(src.PathTemplate)
+		if err != nil {
+			return nil, err
+		}
+	}
 
 	return w, nil
 }
 
 type Option func(*watcher)
 
+// WithLocalOverride sets a local directory whose files take precedence over those on GitHub.
+// A file located at {dir}/{BotType}/{id}.{extension} overrides the GitHub-hosted configuration with the same BotType and id,
+// so a developer can iterate on configuration changes without pushing every tweak to the repository.
+func WithLocalOverride(dir string) Option {
+	return func(w *watcher) {
+		w.localDir = dir
+	}
+}
+
+// WithStrictDecode enables strict decoding so an unknown key in a YAML configuration file is rejected with an error.
+// Without this option, a typo in a key silently leaves the corresponding field with its zero value.
+func WithStrictDecode() Option {
+	return func(w *watcher) {
+		w.strictDecode = true
+	}
+}
+
+// WithDisallowUnknownFields rejects a JSON configuration file that contains a key with no corresponding struct field.
+// This way, a refactoring that renames a struct field surfaces as an error rather than silently dropping the value.
+func WithDisallowUnknownFields() Option {
+	return func(w *watcher) {
+		w.disallowUnknownFields = true
+	}
+}
+
+// WithContentSniffing enables decoding of files without extensions such as "hello".
+// Such a file is decoded as if it has the given extension, e.g. ".yml".
+// When an empty string is given, the format is detected from the content: JSON when the content is valid JSON, and YAML otherwise.
+func WithContentSniffing(defaultExtension string) Option {
+	return func(w *watcher) {
+		w.sniffing = true
+		w.defaultExtension = defaultExtension
+	}
+}
+
+// WithExtensionPrecedence sets the order of file extensions to choose a file from when multiple files share the same id.
+// An extension that comes first takes precedence, and an extension not listed comes after the listed ones in alphabetical order.
+// By default, ".yaml" precedes ".yml", and ".yml" precedes ".json".
+// Among the files with the same extension, the one with the lexically smallest path is chosen.
+// Use ReadRaw to see which file is chosen.
+func WithExtensionPrecedence(extensions ...string) Option {
+	return func(w *watcher) {
+		w.extensionPrecedence = extensions
+	}
+}
+
+// WithDuplicateDetection enables strict mode that fails a refresh with DuplicateConfigError when multiple files map to the same id.
+// Without this option, one file is chosen as described in WithExtensionPrecedence.
+func WithDuplicateDetection() Option {
+	return func(w *watcher) {
+		w.detectDuplicate = true
+	}
+}
+
+// WithEnvInterpolation enables expansion of environment variables in the file content before decoding.
+// ${VAR} is replaced with the value of the environment variable VAR, and ${VAR:-default} falls back to "default" when VAR is unset or empty.
+// This way, a single repository can serve multiple environments that differ only in endpoints or channel names.
+func WithEnvInterpolation() Option {
+	return func(w *watcher) {
+		w.interpolate = true
+	}
+}
+
+// WithEnvironmentOverlay sets the environment name to select overlay files with.
+// When "production" is given, hello.production.yml is deep-merged over hello.yml,
+// so environment-specific differences are reviewable side by side in the same directory.
+func WithEnvironmentOverlay(env string) Option {
+	return func(w *watcher) {
+		w.environment = env
+	}
+}
+
+// WithPreDecodeHook sets a function that transforms the raw content of each configuration file right before decoding.
+// The function receives the BotType, the id, and the raw content, and returns the content to decode.
+// This can be used to strip comments, decrypt, or rewrite the content with the user's own logic.
+func WithPreDecodeHook(hook func(botType sarah.BotType, id string, raw []byte) ([]byte, error)) Option {
+	return func(w *watcher) {
+		w.preDecodeHook = hook
+	}
+}
+
+// WithPostDecodeHook sets a function that validates each decoded configuration value.
+// The function receives the BotType, the id, and the decoded value, and returns an error to reject the value.
+// Because go-sarah does not apply a configuration value when Read returns an error,
+// integrations such as go-playground/validator can reject an invalid configuration before it ever reaches a Command or ScheduledTask.
+func WithPostDecodeHook(hook func(botType sarah.BotType, id string, out interface{}) error) Option {
+	return func(w *watcher) {
+		w.postDecodeHook = hook
+	}
+}
+
+// WithAppVersion sets the version of the running application such as "1.2.0".
+// A configuration file that declares a greater min_app_version in its metadata behaves as if it does not exist.
+func WithAppVersion(version string) Option {
+	return func(w *watcher) {
+		w.appVersion = version
+	}
+}
+
+// WithExport sets a local directory to which every fetched configuration file is mirrored.
+// Files are written to {dir}/{BotType}/{id}.{extension} and are kept in sync on each refresh,
+// so sidecar tools and sarah's file-based watcher can consume the same data.
+// The files are written as they are stored in the repository, so the files encrypted with SOPS or decrypted by WithDecrypter stay encrypted.
+func WithExport(dir string) Option {
+	return func(w *watcher) {
+		w.exportDir = dir
+	}
+}
+
+// WithMaxDiffSize sets the maximum size of Change.Diff in bytes. A longer diff is truncated.
+// The default is 16KiB, which fits in a chat message, and zero disables the limit.
+func WithMaxDiffSize(size int) Option {
+	return func(w *watcher) {
+		w.maxDiffSize = size
+	}
+}
+
 func WithClient(client *githubv4.Client) Option {
 	return func(w *watcher) {
 		w.client = client
+		w.tokenClient = nil
 	}
 }
 
@@ -238,23 +1569,43 @@ func WithToken(ctx context.Context, token string) Option {
 			&oauth2.Token{AccessToken: token},
 		)
 		httpClient := oauth2.NewClient(ctx, src)
+		httpClient.Transport = &scrubbingTransport{base: httpClient.Transport, secrets: []string{token}}
+		w.credentials = append(w.credentials, token)
 		w.client = githubv4.NewClient(httpClient)
+		w.tokenClient = httpClient
+		w.rest = &restClient{httpClient: httpClient, baseURL: defaultRESTURL}
 	}
 }
 
-type subscription struct {
-	botType  sarah.BotType
-	id       string
-	callback func()
-}
-
 type request struct {
+	ctx     context.Context
 	botType sarah.BotType
 	id      string
 	out     interface{}
 	err     chan<- error
 }
 
+// reconfiguration holds the values to update the running watcher with. A zero value leaves the corresponding setting as-is.
+type reconfiguration struct {
+	interval          time.Duration
+	branch            string
+	paused            *bool
+	rollback          *rollback
+	globs             *globs
+	requiredApprovals *int
+	codeOwnerApproval *bool
+}
+
+type snapshotRequest struct {
+	botType sarah.BotType
+	result  chan<- *snapshot
+}
+
+type snapshot struct {
+	files map[string]*file
+	err   error
+}
+
 type querier interface {
 	Query(ctx context.Context, q interface{}, variables map[string]interface{}) error
 }
@@ -262,16 +1613,72 @@ type querier interface {
 // query represents a Graphql query to fetch configuration files.
 // Formatted query is as below:
 //
-// 	query ($owner: String!, $name: String!, $expression:String!) {
+// 	query ($owner: String!, $name: String!, $ref:String!, $expression:String!, $manifest:String!, $schemas:String!) {
 //    repository(owner: $owner, name: $name) {
+//      url
+//      head: object(expression: $ref) {
+//        ... on Commit {
+//          oid
+//        }
+//      }
 //      object(expression: $expression) {
 //        ... on Tree {
+//          oid
+//          entries {
+//            name
+//            type
+//            mode
+//            submodule {
+//              gitUrl
+//              subprojectCommitOid
+//            }
+//            object {
+//              ... on Blob {
+//                oid
+//                text
+//                isBinary
+//              }
+//            }
+//            subtree: object {
+//              ... on Tree {
+//                entries {
+//                  # The same fields as above, nested up to treePrefetchDepth levels.
+//                }
+//              }
+//            }
+//          }
+//        }
+//      }
+//      manifest: object(expression: $manifest) {
+//        ... on Blob {
+//          oid
+//          text
+//          isBinary
+//        }
+//      }
+//      schemas: object(expression: $schemas) {
+//        ... on Tree {
+//          oid
 //          entries {
 //            name
+//            type
+//            mode
+//            submodule {
+//              gitUrl
+//              subprojectCommitOid
+//            }
 //            object {
 //              ... on Blob {
 //                oid
 //                text
+//                isBinary
+//              }
+//            }
+//            subtree: object {
+//              ... on Tree {
+//                entries {
+//                  # The same fields as above, nested up to treePrefetchDepth levels.
+//                }
 //              }
 //            }
 //          }
@@ -284,7 +1691,33 @@ type query struct {
 }
 
 type repository struct {
-	Object repositoryObject `graphql:"object(expression: $expression)"`
+	URL      githubv4.URI
+	Head     commitObject     `graphql:"head: object(expression: $ref)"`
+	Object   repositoryObject `graphql:"object(expression: $expression)"`
+	Manifest entryObject      `graphql:"manifest: object(expression: $manifest)"`
+	Schemas  repositoryObject `graphql:"schemas: object(expression: $schemas)"`
+}
+
+// blobQuery represents a Graphql query to fetch a single file.
+// Formatted query is as below:
+//
+// 	query ($owner: String!, $name: String!, $expression:String!) {
+//    repository(owner: $owner, name: $name) {
+//      object(expression: $expression) {
+//        ... on Blob {
+//          oid
+//          text
+//          isBinary
+//        }
+//      }
+//    }
+// 	}
+type blobQuery struct {
+	Repository blobRepository `graphql:"repository(owner: $owner, name: $name)"`
+}
+
+type blobRepository struct {
+	Object entryObject `graphql:"object(expression: $expression)"`
 }
 
 type repositoryObject struct {
@@ -292,9 +1725,16 @@ type repositoryObject struct {
 }
 
 type tree struct {
+	Oid     githubv4.String
 	Entries []entry
 }
 
+type commitObject struct {
+	Commit struct {
+		Oid githubv4.String
+	} `graphql:"... on Commit"`
+}
+
 type entryObject struct {
 	Blob blob `graphql:"... on Blob"`
 }
@@ -302,17 +1742,89 @@ type entryObject struct {
 type blob struct {
 	Oid  githubv4.String
 	Text githubv4.String
+	// IsBinary is set when the content is binary, in which case Text is null.
+	IsBinary githubv4.Boolean
 }
 
 type entry struct {
-	Name   githubv4.String
-	Object entryObject
+	Name githubv4.String
+	// Type is either "blob" or "tree".
+	Type githubv4.String
+	// Mode is the file mode, which tells if the entry is a symbolic link.
+	Mode githubv4.Int
+	// Submodule is set when the entry is a Git submodule, whose Type is "commit".
+	Submodule submodule
+	Object    entryObject
+	// Subtree holds the entries of the subdirectory when Type is "tree".
+	Subtree prefetchedTree `graphql:"subtree: object"`
 }
 
+// defaultsID is the id of the file that is deep-merged beneath every other configuration file of the same BotType.
+// e.g. _default.yml
+const defaultsID = "_default"
+
 type file struct {
-	id        string
-	fileName  string
+	id       string
+	fileName string
+	// path is the path of the file relative to the repository root. This is empty for a local override file.
+	path      string
 	extension string
 	objectID  string
+	// commitSHA is the SHA of the commit the file is fetched from. This is empty for a local override file.
+	commitSHA string
+	// branch is the branch the file is fetched from. This is empty for a local override file and a file fetched by ReadAt.
+	branch string
+	// url is the URL of the file on GitHub as of the commit it is fetched from. This is empty for a local override file.
+	url string
+	// source is the repository the file is fetched from when it differs from the BotType's repository.
+	source    *Source
 	content   string
+	fetchedAt time.Time
+	// origin refers to the file stored in the repository when this file is one of the documents expanded from it.
+	origin *file
+	// defaults refers to the defaults file of the BotType that is merged beneath this file.
+	defaults *file
+	// overlay refers to the environment-specific file that is merged over this file.
+	overlay *file
+	// anchors refers to the file that defines YAML anchors this file may refer to.
+	anchors *file
+	// fragments refers to the files under the {id}.d/ directory that are merged over this file in lexical order.
+	fragments []*file
+	// bases refers to the files with the same id in the lower layers of Config.Layers that are merged beneath this file in order.
+	bases []*file
+	// invalid is set when the file does not conform to its JSON Schema or fails to decode.
+	invalid error
+}
+
+// revision returns a value that changes whenever the file or any of the files it depends on changes.
+func (f *file) revision() string {
+	revision := f.objectID
+	dependencies := append([]*file{f.defaults, f.overlay, f.anchors}, f.fragments...)
+	for _, dependency := range append(dependencies, f.bases...) {
+		if dependency != nil {
+			revision += "+" + dependency.objectID
+		}
+	}
+	return revision
+}
+
+// newFile builds a file whose id is the given name without its known extension.
+func (w *watcher) newFile(name string, objectID string, content string) *file {
+	f := newFile(name, objectID, content)
+	if w.extension(name) == "" {
+		f.id = name
+		f.extension = ""
+	}
+	return f
+}
+
+func newFile(name string, objectID string, content string) *file {
+	extension := filepath.Ext(name)
+	return &file{
+		id:        strings.TrimSuffix(name, extension),
+		fileName:  name,
+		extension: extension,
+		objectID:  objectID,
+		content:   content,
+	}
 }
@@ -6,7 +6,9 @@ import (
 	"fmt"
 	"github.com/oklahomer/go-sarah/v4"
 	"github.com/shurcooL/githubv4"
+	"net/url"
 	"strconv"
+	"strings"
 	"testing"
 	"time"
 )
@@ -77,10 +79,8 @@ func TestNew(t *testing.T) {
 			config := &Config{
 				Interval: time.Second,
 			}
-			ctx, cancel := context.WithCancel(context.Background())
-			defer cancel()
 
-			w, err := New(ctx, config, tt.opts...)
+			w, err := New(config, tt.opts...)
 			if tt.error {
 				if err == nil {
 					t.Fatal("Expected error is not returned.")
@@ -133,6 +133,7 @@ func TestWatcher_Read(t *testing.T) {
 				subscription:   nil,
 				unsubscription: nil,
 			}
+			w.started.Store(true)
 
 			expected := errors.New("dummy")
 			go func() {
@@ -171,6 +172,7 @@ func TestWatcher_Watch(t *testing.T) {
 	w := &watcher{
 		subscription: make(chan *subscription, 1),
 	}
+	w.started.Store(true)
 
 	var botType sarah.BotType = "bot"
 	id := "id"
@@ -201,6 +203,7 @@ func TestWatcher_Unwatch(t *testing.T) {
 	w := &watcher{
 		unsubscription: make(chan sarah.BotType, 1),
 	}
+	w.started.Store(true)
 
 	var botType sarah.BotType = "bot"
 	err := w.Unwatch(botType)
@@ -229,6 +232,7 @@ func TestWatcher_get(t *testing.T) {
 	id := "hello"
 	ext := ".yml"
 	oid := "oid"
+	sha := "sha"
 	text := "name: oklahomer\nrole: member\n"
 	querier := &DummyQuerier{QueryFunc: func(_ context.Context, q interface{}, v map[string]interface{}) error {
 		typed, ok := q.(*query)
@@ -252,6 +256,7 @@ func TestWatcher_get(t *testing.T) {
 			t.Errorf("Expected 'expression' value of %s but was %s", e, expectedExp)
 		}
 
+		typed.Repository.Head.Commit.Oid = githubv4.String(sha)
 		typed.Repository.Object.Tree.Entries = []entry{
 			{
 				Name: githubv4.String(fmt.Sprintf("%s%s", id, ext)),
@@ -299,6 +304,34 @@ func TestWatcher_get(t *testing.T) {
 	if cfg.content != text {
 		t.Errorf("Content does not match: %s.", cfg.content)
 	}
+
+	if cfg.commitSHA != sha {
+		t.Errorf("Commit SHA does not match: %s.", cfg.commitSHA)
+	}
+}
+
+func TestBlobURL(t *testing.T) {
+	repository := githubv4.URI{URL: &url.URL{Scheme: "https", Host: "github.com", Path: "/oklahomer/config"}}
+	tests := []struct {
+		repository githubv4.URI
+		commitSHA  string
+		path       string
+		expected   string
+	}{
+		{repository: repository, commitSHA: "sha", path: "config/bot/hello.yml", expected: "https://github.com/oklahomer/config/blob/sha/config/bot/hello.yml"},
+		{repository: repository, commitSHA: "sha", path: "/config/bot/hello.yml", expected: "https://github.com/oklahomer/config/blob/sha/config/bot/hello.yml"},
+		{repository: repository, commitSHA: "", path: "config/bot/hello.yml", expected: ""},
+		{repository: githubv4.URI{}, commitSHA: "sha", path: "config/bot/hello.yml", expected: ""},
+	}
+
+	for i, tt := range tests {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			u := blobURL(tt.repository, tt.commitSHA, tt.path)
+			if u != tt.expected {
+				t.Errorf("Unexpected URL is returned: %s.", u)
+			}
+		})
+	}
 }
 
 func TestWithClient(t *testing.T) {
@@ -358,6 +391,7 @@ func TestWatcher_operate(t *testing.T) {
 			subscription:   nil,
 			unsubscription: nil,
 		}
+		w.started.Store(true)
 		go w.operate(ctx)
 
 		type config struct {
@@ -377,3 +411,546 @@ func TestWatcher_operate(t *testing.T) {
 		}
 	})
 }
+
+func TestRead(t *testing.T) {
+	type server struct {
+		Host string `json:"host" yaml:"host" ini:"host"`
+	}
+	type config struct {
+		Name   string `json:"name" yaml:"name" ini:"name"`
+		Server server `json:"server" yaml:"server" ini:"server"`
+	}
+
+	tests := []struct {
+		file  *file
+		error bool
+	}{
+		{
+			file: newFile("hello.yml", "oid", "name: oklahomer\nserver:\n  host: localhost\n"),
+		},
+		{
+			file: newFile("hello.yaml", "oid", "name: oklahomer\nserver:\n  host: localhost\n"),
+		},
+		{
+			file: newFile("hello.json", "oid", `{"name": "oklahomer", "server": {"host": "localhost"}}`),
+		},
+		{
+			file: newFile("hello.jsonc", "oid", "{\n  // comment\n  \"name\": \"oklahomer\",\n  /* block */\n  \"server\": {\"host\": \"localhost\",},\n}"),
+		},
+		{
+			file: newFile("hello.json5", "oid", "{\n  // comment\n  name: 'oklahomer',\n  server: {host: 'localhost'},\n}"),
+		},
+		{
+			file: newFile("hello.cue", "oid", "#Server: {host: string}\nname: \"okla\" + \"homer\"\nserver: #Server & {host: \"localhost\"}\n"),
+		},
+		{
+			file:  newFile("hello.cue", "oid", "name: string & =~\"^[a-z]+$\"\nname: \"Oklahomer\"\n"),
+			error: true,
+		},
+		{
+			file:  newFile("hello.cue", "oid", "name: string\n"),
+			error: true,
+		},
+		{
+			file: newFile("hello.ini", "oid", "name = oklahomer\n\n[server]\nhost = localhost\n"),
+		},
+		{
+			file:  newFile("hello.txt", "oid", "name: oklahomer"),
+			error: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.file.fileName, func(t *testing.T) {
+			cfg := &config{}
+			err := (&watcher{}).read(context.TODO(), "bot", tt.file, cfg)
+
+			if tt.error {
+				if err == nil {
+					t.Fatal("Expected error is not returned.")
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("Unexpected error is returned: %s.", err.Error())
+			}
+
+			if cfg.Name != "oklahomer" {
+				t.Errorf("Unexpected name is set: %s.", cfg.Name)
+			}
+
+			if cfg.Server.Host != "localhost" {
+				t.Errorf("Unexpected host is set: %s.", cfg.Server.Host)
+			}
+		})
+	}
+}
+
+func TestWatcher_get_withDefaults(t *testing.T) {
+	w := &watcher{
+		client: &DummyQuerier{
+			QueryFunc: func(_ context.Context, q interface{}, _ map[string]interface{}) error {
+				typed := q.(*query)
+				typed.Repository.Object.Tree.Entries = []entry{
+					{
+						Name: "_default.yml",
+						Object: entryObject{
+							Blob: blob{
+								Oid:  "defaults",
+								Text: "server:\n  host: localhost\n  port: 8080\n",
+							},
+						},
+					},
+					{
+						Name: "hello.yml",
+						Object: entryObject{
+							Blob: blob{
+								Oid:  "hello",
+								Text: "name: hello\nserver:\n  port: 80\n",
+							},
+						},
+					},
+				}
+				return nil
+			},
+		},
+		config: &Config{},
+	}
+
+	files, err := w.get(context.Background(), "bot")
+	if err != nil {
+		t.Fatalf("Unexpected error is returned: %s.", err.Error())
+	}
+
+	if _, ok := files[defaultsID]; ok {
+		t.Error("Defaults file must not be served as a configuration.")
+	}
+
+	hello := files["hello"]
+	if hello.defaults == nil || hello.defaults.objectID != "defaults" {
+		t.Fatalf("Defaults file is not set: %+v.", hello.defaults)
+	}
+
+	if hello.revision() != "hello+defaults" {
+		t.Errorf("Revision must reflect the defaults file: %s.", hello.revision())
+	}
+
+	type server struct {
+		Host string `yaml:"host"`
+		Port int    `yaml:"port"`
+	}
+	cfg := &struct {
+		Name   string `yaml:"name"`
+		Server server `yaml:"server"`
+	}{}
+	err = w.read(context.TODO(), "bot", hello, cfg)
+	if err != nil {
+		t.Fatalf("Unexpected error is returned: %s.", err.Error())
+	}
+
+	if cfg.Name != "hello" || cfg.Server.Host != "localhost" || cfg.Server.Port != 80 {
+		t.Errorf("Values are not merged as expected: %+v.", cfg)
+	}
+}
+
+func TestWithEnvironmentOverlay(t *testing.T) {
+	opt := WithEnvironmentOverlay("production")
+	w := &watcher{}
+
+	opt(w)
+
+	if w.environment != "production" {
+		t.Errorf("Unexpected environment is set: %s.", w.environment)
+	}
+}
+
+func TestWatcher_get_withEnvironmentOverlay(t *testing.T) {
+	w := &watcher{
+		client: &DummyQuerier{
+			QueryFunc: func(_ context.Context, q interface{}, _ map[string]interface{}) error {
+				typed := q.(*query)
+				for _, e := range []struct {
+					name    string
+					content string
+				}{
+					{name: "hello.yml", content: "name: hello\nserver:\n  host: localhost\n  port: 8080\n"},
+					{name: "hello.production.yml", content: "server:\n  host: example.com\n"},
+					{name: "hello.staging.yml", content: "server:\n  host: staging.example.com\n"},
+				} {
+					typed.Repository.Object.Tree.Entries = append(typed.Repository.Object.Tree.Entries, entry{
+						Name: githubv4.String(e.name),
+						Object: entryObject{
+							Blob: blob{
+								Oid:  githubv4.String(e.name),
+								Text: githubv4.String(e.content),
+							},
+						},
+					})
+				}
+				return nil
+			},
+		},
+		config:      &Config{},
+		environment: "production",
+	}
+
+	files, err := w.get(context.Background(), "bot")
+	if err != nil {
+		t.Fatalf("Unexpected error is returned: %s.", err.Error())
+	}
+
+	if _, ok := files["hello.production"]; ok {
+		t.Error("Overlay file must not be served as a configuration.")
+	}
+
+	hello := files["hello"]
+	if hello.revision() != "hello.yml+hello.production.yml" {
+		t.Errorf("Revision must reflect the overlay file: %s.", hello.revision())
+	}
+
+	type server struct {
+		Host string `yaml:"host"`
+		Port int    `yaml:"port"`
+	}
+	cfg := &struct {
+		Name   string `yaml:"name"`
+		Server server `yaml:"server"`
+	}{}
+	err = w.read(context.TODO(), "bot", hello, cfg)
+	if err != nil {
+		t.Fatalf("Unexpected error is returned: %s.", err.Error())
+	}
+
+	if cfg.Name != "hello" || cfg.Server.Host != "example.com" || cfg.Server.Port != 8080 {
+		t.Errorf("Values are not merged as expected: %+v.", cfg)
+	}
+}
+
+func TestWithPreDecodeHook(t *testing.T) {
+	var givenBotType sarah.BotType
+	var givenID string
+	hook := func(botType sarah.BotType, id string, raw []byte) ([]byte, error) {
+		givenBotType = botType
+		givenID = id
+		return []byte(strings.ReplaceAll(string(raw), "ENCRYPTED", "decrypted")), nil
+	}
+	opt := WithPreDecodeHook(hook)
+	w := &watcher{}
+
+	opt(w)
+
+	if w.preDecodeHook == nil {
+		t.Fatal("Hook is not set.")
+	}
+
+	cfg := &struct {
+		Value string `yaml:"value"`
+	}{}
+	err := w.read(context.TODO(), "bot", newFile("hello.yml", "oid", "value: ENCRYPTED\n"), cfg)
+	if err != nil {
+		t.Fatalf("Unexpected error is returned: %s.", err.Error())
+	}
+
+	if givenBotType != "bot" || givenID != "hello" {
+		t.Errorf("Unexpected arguments are passed: %s, %s.", givenBotType, givenID)
+	}
+
+	if cfg.Value != "decrypted" {
+		t.Errorf("Transformed content is not decoded: %s.", cfg.Value)
+	}
+
+	expected := errors.New("dummy")
+	w.preDecodeHook = func(_ sarah.BotType, _ string, _ []byte) ([]byte, error) {
+		return nil, expected
+	}
+	err = w.read(context.TODO(), "bot", newFile("hello.yml", "oid", "value: ENCRYPTED\n"), cfg)
+	if !errors.Is(err, expected) {
+		t.Errorf("Expected error is not returned: %+v.", err)
+	}
+}
+
+func TestWithPostDecodeHook(t *testing.T) {
+	type config struct {
+		Value string `yaml:"value"`
+	}
+	expected := errors.New("dummy")
+	hook := func(botType sarah.BotType, id string, out interface{}) error {
+		if botType != "bot" || id != "hello" {
+			t.Errorf("Unexpected arguments are passed: %s, %s.", botType, id)
+		}
+
+		if out.(*config).Value == "" {
+			return expected
+		}
+		return nil
+	}
+	opt := WithPostDecodeHook(hook)
+	w := &watcher{}
+
+	opt(w)
+
+	if w.postDecodeHook == nil {
+		t.Fatal("Hook is not set.")
+	}
+
+	err := w.read(context.TODO(), "bot", newFile("hello.yml", "oid", "value: valid\n"), &config{})
+	if err != nil {
+		t.Errorf("Unexpected error is returned: %s.", err.Error())
+	}
+
+	err = w.read(context.TODO(), "bot", newFile("hello.yml", "oid", "value: \"\"\n"), &config{})
+	if !errors.Is(err, expected) {
+		t.Errorf("Expected error is not returned: %+v.", err)
+	}
+}
+
+func TestWatcher_Read_withContext(t *testing.T) {
+	t.Run("canceled before enqueue", func(t *testing.T) {
+		w := &watcher{
+			config: &Config{
+				TimeOut: 1 * time.Second,
+			},
+			request: make(chan *request),
+		}
+		w.started.Store(true)
+
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		err := w.Read(ctx, "bot", "id", &struct{}{})
+
+		var canceled *CanceledError
+		if !errors.As(err, &canceled) {
+			t.Fatalf("Expected error is not returned: %+v.", err)
+		}
+
+		if !errors.Is(err, context.Canceled) {
+			t.Errorf("Underlying error is not returned: %+v.", err)
+		}
+	})
+
+	t.Run("deadline exceeded while waiting", func(t *testing.T) {
+		req := make(chan *request, 1)
+		w := &watcher{
+			config: &Config{
+				TimeOut: 1 * time.Second,
+			},
+			request: req,
+		}
+		w.started.Store(true)
+
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+		defer cancel()
+		err := w.Read(ctx, "bot", "id", &struct{}{})
+
+		if !errors.Is(err, context.DeadlineExceeded) {
+			t.Errorf("Expected error is not returned: %+v.", err)
+		}
+	})
+}
+
+func TestCanceledError_Error(t *testing.T) {
+	err := &CanceledError{BotType: "bot", ID: "id", Err: context.Canceled}
+	if err.Error() != "operation for bot:id is canceled: context canceled" {
+		t.Errorf("Unexpected message is returned: %s.", err.Error())
+	}
+
+	err = &CanceledError{BotType: "bot", Err: context.Canceled}
+	if err.Error() != "operation for bot is canceled: context canceled" {
+		t.Errorf("Unexpected message is returned: %s.", err.Error())
+	}
+
+	err = &CanceledError{Err: context.Canceled}
+	if err.Error() != "operation is canceled: context canceled" {
+		t.Errorf("Unexpected message is returned: %s.", err.Error())
+	}
+}
+
+func TestWatcher_WatchContext(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	oid := make(chan string, 2)
+	oid <- "first"
+	oid <- "second"
+	current := ""
+	w := &watcher{
+		client: &DummyQuerier{
+			QueryFunc: func(_ context.Context, q interface{}, _ map[string]interface{}) error {
+				select {
+				case current = <-oid:
+				default:
+				}
+
+				typed := q.(*query)
+				typed.Repository.Object.Tree.Entries = []entry{
+					{
+						Name:   "hello.json",
+						Object: entryObject{Blob: blob{Oid: githubv4.String(current), Text: "{}"}},
+					},
+				}
+				return nil
+			},
+		},
+		config: &Config{
+			TimeOut:  100 * time.Millisecond,
+			Interval: 10 * time.Millisecond,
+		},
+		request:      make(chan *request),
+		subscription: make(chan *subscription),
+	}
+	w.started.Store(true)
+	go w.operate(ctx)
+
+	err := w.Read(ctx, "bot", "hello", &struct{}{})
+	if err != nil {
+		t.Fatalf("Unexpected error is returned: %s.", err.Error())
+	}
+
+	called := make(chan string, 1)
+	_, err = w.WatchContext(ctx, "bot", "hello", func(callbackCtx context.Context, id string) {
+		if callbackCtx.Err() != nil {
+			t.Error("Given context must be alive.")
+		}
+		called <- id
+	})
+	if err != nil {
+		t.Fatalf("Unexpected error is returned: %s.", err.Error())
+	}
+
+	select {
+	case id := <-called:
+		if id != "hello" {
+			t.Errorf("Unexpected id is passed: %s.", id)
+		}
+
+	case <-time.NewTimer(1 * time.Second).C:
+		t.Error("Callback is not called.")
+
+	}
+}
+
+func TestWatcher_WatchContext_absentID(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	present := make(chan bool, 2)
+	present <- false
+	present <- true
+	exists := false
+	w := &watcher{
+		client: &DummyQuerier{
+			QueryFunc: func(_ context.Context, q interface{}, _ map[string]interface{}) error {
+				select {
+				case exists = <-present:
+				default:
+				}
+
+				typed := q.(*query)
+				typed.Repository.Head.Commit.Oid = "sha"
+				if exists {
+					typed.Repository.Object.Tree.Entries = []entry{
+						{
+							Name:   "hello.json",
+							Object: entryObject{Blob: blob{Oid: "oid", Text: "{}"}},
+						},
+					}
+				}
+				return nil
+			},
+		},
+		config: &Config{
+			TimeOut:  100 * time.Millisecond,
+			Interval: 10 * time.Millisecond,
+		},
+		request:      make(chan *request),
+		subscription: make(chan *subscription),
+	}
+	w.started.Store(true)
+	go w.operate(ctx)
+
+	// The directory does not exist yet, so the Command starts with its defaults.
+	err := w.Read(ctx, "bot", "hello", &struct{}{})
+	var notFound *DirectoryNotFoundError
+	if !errors.As(err, &notFound) {
+		t.Fatalf("Expected error is not returned: %#v.", err)
+	}
+
+	called := make(chan string, 1)
+	_, err = w.WatchContext(ctx, "bot", "hello", func(_ context.Context, id string) {
+		called <- id
+	})
+	if err != nil {
+		t.Fatalf("Unexpected error is returned: %s.", err.Error())
+	}
+
+	select {
+	case id := <-called:
+		if id != "hello" {
+			t.Errorf("Unexpected id is passed: %s.", id)
+		}
+
+	case <-time.NewTimer(1 * time.Second).C:
+		t.Error("Callback is not called.")
+
+	}
+}
+
+func TestWatcher_UnwatchID(t *testing.T) {
+	w := &watcher{
+		idUnsubscription: make(chan *subscription, 1),
+	}
+	w.started.Store(true)
+
+	var botType sarah.BotType = "bot"
+	err := w.UnwatchID(botType, "id")
+	if err != nil {
+		t.Fatalf("Unexpected error is returned: %s", err)
+	}
+
+	select {
+	case u := <-w.idUnsubscription:
+		if u.botType != botType || u.id != "id" {
+			t.Errorf("Expected target is not passed: %+v.", u)
+		}
+
+	case <-time.NewTimer(1 * time.Second).C:
+		t.Error("Target is not passed.")
+
+	}
+}
+
+func TestWatcher_pruneChecks(t *testing.T) {
+	current := newFile("hello.json", "new", "{}")
+	current.path = "config/bot/hello.json"
+	stale := newFile("hello.json", "old", "{}")
+	stale.path = "config/bot/hello.json"
+	w := &watcher{
+		disallowed: map[string]bool{
+			disallowedKey("bot", "hello", current): true,
+			disallowedKey("bot", "hello", stale):   true,
+			disallowedKey("other", "hello", stale): true,
+		},
+		approvals: map[string]error{
+			approvalKey("bot", current): nil,
+			approvalKey("bot", stale):   nil,
+			approvalKey("other", stale): nil,
+		},
+	}
+
+	w.pruneChecks("bot", map[string]*file{"hello": current})
+
+	if len(w.disallowed) != 2 || !w.disallowed[disallowedKey("bot", "hello", current)] || !w.disallowed[disallowedKey("other", "hello", stale)] {
+		t.Errorf("Unexpected allowlist check results are kept: %+v.", w.disallowed)
+	}
+
+	if _, ok := w.approvals[approvalKey("bot", stale)]; ok || len(w.approvals) != 2 {
+		t.Errorf("Unexpected approval check results are kept: %+v.", w.approvals)
+	}
+
+	w.pruneChecks("bot", nil)
+
+	if len(w.disallowed) != 1 || len(w.approvals) != 1 {
+		t.Errorf("Check results of the BotType are not dropped: %+v, %+v.", w.disallowed, w.approvals)
+	}
+}
@@ -0,0 +1,128 @@
+package githubconfig
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"gopkg.in/yaml.v3"
+	"io"
+	"strings"
+)
+
+// anchorsID is the id of the file that defines YAML anchors shared by every YAML configuration file of the same BotType.
+// e.g. _anchors.yml
+const anchorsID = "_anchors"
+
+// anchorsKey is the temporary top-level key to hold the shared anchors while a YAML configuration file is decoded.
+const anchorsKey = "__anchors__"
+
+func (w *watcher) decodeYAML(f *file, out interface{}) error {
+	content := []byte(f.content)
+
+	var dropping []string
+	if f.anchors != nil {
+		content = withAnchors(f.anchors.content, content)
+		dropping = append(dropping, anchorsKey)
+	}
+	if w.strictDecode {
+		// The metadata is not a part of the configuration.
+		dropping = append(dropping, metadataKey)
+	}
+	if w.strictDecode && f.origin != nil {
+		// A document expanded from a multi-document file declares its id, which is not a part of the configuration.
+		dropping = append(dropping, "id")
+	}
+	if len(dropping) > 0 {
+		var err error
+		content, err = dropKeys(content, dropping...)
+		if err != nil {
+			return fmt.Errorf("failed to parse %s: %w", f.fileName, err)
+		}
+	}
+
+	if !w.strictDecode {
+		return yaml.Unmarshal(content, out)
+	}
+
+	decoder := yaml.NewDecoder(bytes.NewReader(content))
+	decoder.KnownFields(true)
+	err := decoder.Decode(out)
+	if errors.Is(err, io.EOF) {
+		// Empty document
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to decode %s: %w", f.fileName, err)
+	}
+
+	return nil
+}
+
+// withAnchors places the shared anchors under a temporary top-level key so the content can refer to them with aliases.
+func withAnchors(anchors string, content []byte) []byte {
+	buf := &bytes.Buffer{}
+	buf.WriteString(anchorsKey + ":\n")
+	for _, line := range strings.Split(anchors, "\n") {
+		buf.WriteString("  " + line + "\n")
+	}
+	buf.Write(content)
+	return buf.Bytes()
+}
+
+// dropKeys removes the given top-level keys from the YAML document.
+// Aliases are resolved beforehand so the remaining values do not lose the anchors defined under the removed keys.
+func dropKeys(content []byte, keys ...string) ([]byte, error) {
+	node := &yaml.Node{}
+	err := yaml.Unmarshal(content, node)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(node.Content) == 0 || node.Content[0].Kind != yaml.MappingNode {
+		return content, nil
+	}
+
+	resolveAliases(node)
+
+	mapping := node.Content[0]
+	var pairs []*yaml.Node
+	for i := 0; i+1 < len(mapping.Content); i += 2 {
+		if contains(keys, mapping.Content[i].Value) {
+			continue
+		}
+		pairs = append(pairs, mapping.Content[i], mapping.Content[i+1])
+	}
+	mapping.Content = pairs
+
+	return yaml.Marshal(node)
+}
+
+// resolveAliases replaces each alias node with a copy of the node it refers to.
+func resolveAliases(node *yaml.Node) {
+	if node.Kind == yaml.AliasNode && node.Alias != nil {
+		*node = *copyNode(node.Alias)
+	}
+	node.Anchor = ""
+
+	for _, child := range node.Content {
+		resolveAliases(child)
+	}
+}
+
+func copyNode(node *yaml.Node) *yaml.Node {
+	copied := *node
+	copied.Content = nil
+	for _, child := range node.Content {
+		copied.Content = append(copied.Content, copyNode(child))
+	}
+	return &copied
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,115 @@
+package githubconfig
+
+import (
+	"strconv"
+	"testing"
+)
+
+func TestWithStrictDecode(t *testing.T) {
+	opt := WithStrictDecode()
+	w := &watcher{}
+
+	opt(w)
+
+	if !w.strictDecode {
+		t.Error("Strict decoding is not enabled.")
+	}
+}
+
+func TestWatcher_decodeYAML(t *testing.T) {
+	type config struct {
+		Name string `yaml:"name"`
+	}
+
+	expanded, err := expandDocuments(newFile("commands.yml", "oid", "id: hello\nname: oklahomer\n---\nid: bye\nname: oklahomer\n"))
+	if err != nil {
+		t.Fatalf("Unexpected error is returned: %s.", err.Error())
+	}
+
+	tests := []struct {
+		strict bool
+		file   *file
+		error  bool
+	}{
+		{
+			strict: false,
+			file:   newFile("hello.yml", "oid", "name: oklahomer\nnmae: typo\n"),
+			error:  false,
+		},
+		{
+			strict: true,
+			file:   newFile("hello.yml", "oid", "name: oklahomer\nnmae: typo\n"),
+			error:  true,
+		},
+		{
+			strict: true,
+			file:   newFile("hello.yml", "oid", "name: oklahomer\n"),
+			error:  false,
+		},
+		{
+			strict: true,
+			file:   newFile("hello.yml", "oid", ""),
+			error:  false,
+		},
+		{
+			strict: true,
+			file:   expanded[0],
+			error:  false,
+		},
+	}
+
+	for i, tt := range tests {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			w := &watcher{strictDecode: tt.strict}
+			cfg := &config{}
+			err := w.decodeYAML(tt.file, cfg)
+
+			if tt.error {
+				if err == nil {
+					t.Fatal("Expected error is not returned.")
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("Unexpected error is returned: %s.", err.Error())
+			}
+		})
+	}
+}
+
+func TestWatcher_decodeYAML_withAnchors(t *testing.T) {
+	type server struct {
+		Host string `yaml:"host"`
+		Port int    `yaml:"port"`
+	}
+	type config struct {
+		Name    string `yaml:"name"`
+		Primary server `yaml:"primary"`
+		Backup  server `yaml:"backup"`
+	}
+
+	anchors := newFile("_anchors.yml", "anchors", "server: &server\n  host: example.com\n  port: 443\n")
+	f := newFile("hello.yml", "oid", "name: hello\nprimary: *server\nbackup:\n  <<: *server\n  port: 8443\n")
+	f.anchors = anchors
+
+	for _, strict := range []bool{false, true} {
+		t.Run(strconv.FormatBool(strict), func(t *testing.T) {
+			w := &watcher{strictDecode: strict}
+			cfg := &config{}
+			err := w.decodeYAML(f, cfg)
+			if err != nil {
+				t.Fatalf("Unexpected error is returned: %s.", err.Error())
+			}
+
+			expected := config{
+				Name:    "hello",
+				Primary: server{Host: "example.com", Port: 443},
+				Backup:  server{Host: "example.com", Port: 8443},
+			}
+			if *cfg != expected {
+				t.Errorf("Unexpected value is decoded: %+v.", cfg)
+			}
+		})
+	}
+}